@@ -0,0 +1,26 @@
+// Command converter converts documents, spreadsheets and images from the
+// command line, using the same engines as the desktop application. It needs
+// no display; PDF output still requires a locally installed Chrome,
+// Chromium or Edge.
+//
+// Run converter --help for usage.
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/eka026/File-Format-Converter/internal/adapters/cli"
+)
+
+func main() {
+	// Interrupting cancels the conversions still running; the partial
+	// outputs are removed and the exit code reports the interruption.
+	// SIGTERM stops watch mode cleanly under a service manager.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := cli.Run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
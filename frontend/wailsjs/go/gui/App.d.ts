@@ -1,23 +1,39 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
+import {cache} from '../models';
 import {gui} from '../models';
+import {scheduler} from '../models';
 
-export function BatchConvertFiles(arg1:Array<string>,arg2:string):Promise<Array<gui.ConversionResult>>;
+export function BatchConvertFiles(arg1:Array<string>,arg2:string,arg3:gui.ConversionOptions):Promise<Array<gui.ConversionResult>>;
+
+export function ChooseManifest():Promise<string>;
 
 export function CleanupTempFiles():Promise<void>;
 
 export function CleanupTempInputFile(arg1:string):Promise<void>;
 
-export function ConvertFile(arg1:string,arg2:string):Promise<gui.ConversionResult>;
+export function ClearCache():Promise<void>;
+
+export function ConvertFile(arg1:string,arg2:string,arg3:gui.ConversionOptions):Promise<gui.ConversionResult>;
 
-export function ConvertFileWithPath(arg1:string,arg2:string,arg3:string):Promise<gui.ConversionResult>;
+export function ConvertFileWithPath(arg1:string,arg2:string,arg3:string,arg4:gui.ConversionOptions):Promise<gui.ConversionResult>;
+
+export function ConvertFromBytes(arg1:string,arg2:Array<number>,arg3:string,arg4:gui.ConversionOptions):Promise<gui.ConversionResult>;
 
 export function DeleteTempFile(arg1:string):Promise<void>;
 
+export function GetCacheStats():Promise<cache.Stats>;
+
 export function GetFileInfo(arg1:string):Promise<Record<string, any>>;
 
-export function GetSupportedFormats():Promise<Array<string>>;
+export function GetSchedulerMetrics():Promise<scheduler.Metrics>;
+
+export function GetSupportedFormats():Promise<Array<gui.SupportedInputType>>;
+
+export function LoadManifest(arg1:string):Promise<gui.ManifestPlan>;
 
 export function OpenFile(arg1:string):Promise<void>;
 
+export function RunManifest(arg1:string):Promise<gui.ManifestRun>;
+
 export function SaveFileFromBytes(arg1:string,arg2:Array<number>):Promise<string>;
@@ -2,8 +2,12 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
-export function BatchConvertFiles(arg1, arg2) {
-  return window['go']['gui']['App']['BatchConvertFiles'](arg1, arg2);
+export function BatchConvertFiles(arg1, arg2, arg3) {
+  return window['go']['gui']['App']['BatchConvertFiles'](arg1, arg2, arg3);
+}
+
+export function ChooseManifest() {
+  return window['go']['gui']['App']['ChooseManifest']();
 }
 
 export function CleanupTempFiles() {
@@ -14,30 +18,54 @@ export function CleanupTempInputFile(arg1) {
   return window['go']['gui']['App']['CleanupTempInputFile'](arg1);
 }
 
-export function ConvertFile(arg1, arg2) {
-  return window['go']['gui']['App']['ConvertFile'](arg1, arg2);
+export function ClearCache() {
+  return window['go']['gui']['App']['ClearCache']();
+}
+
+export function ConvertFile(arg1, arg2, arg3) {
+  return window['go']['gui']['App']['ConvertFile'](arg1, arg2, arg3);
 }
 
-export function ConvertFileWithPath(arg1, arg2, arg3) {
-  return window['go']['gui']['App']['ConvertFileWithPath'](arg1, arg2, arg3);
+export function ConvertFileWithPath(arg1, arg2, arg3, arg4) {
+  return window['go']['gui']['App']['ConvertFileWithPath'](arg1, arg2, arg3, arg4);
+}
+
+export function ConvertFromBytes(arg1, arg2, arg3, arg4) {
+  return window['go']['gui']['App']['ConvertFromBytes'](arg1, arg2, arg3, arg4);
 }
 
 export function DeleteTempFile(arg1) {
   return window['go']['gui']['App']['DeleteTempFile'](arg1);
 }
 
+export function GetCacheStats() {
+  return window['go']['gui']['App']['GetCacheStats']();
+}
+
 export function GetFileInfo(arg1) {
   return window['go']['gui']['App']['GetFileInfo'](arg1);
 }
 
+export function GetSchedulerMetrics() {
+  return window['go']['gui']['App']['GetSchedulerMetrics']();
+}
+
 export function GetSupportedFormats() {
   return window['go']['gui']['App']['GetSupportedFormats']();
 }
 
+export function LoadManifest(arg1) {
+  return window['go']['gui']['App']['LoadManifest'](arg1);
+}
+
 export function OpenFile(arg1) {
   return window['go']['gui']['App']['OpenFile'](arg1);
 }
 
+export function RunManifest(arg1) {
+  return window['go']['gui']['App']['RunManifest'](arg1);
+}
+
 export function SaveFileFromBytes(arg1, arg2) {
   return window['go']['gui']['App']['SaveFileFromBytes'](arg1, arg2);
 }
@@ -1,9 +1,251 @@
+export namespace cache {
+	
+	export class Stats {
+	    dir: string;
+	    entries: number;
+	    sizeBytes: number;
+	    maxBytes: number;
+	    hits: number;
+	    misses: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Stats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.dir = source["dir"];
+	        this.entries = source["entries"];
+	        this.sizeBytes = source["sizeBytes"];
+	        this.maxBytes = source["maxBytes"];
+	        this.hits = source["hits"];
+	        this.misses = source["misses"];
+	    }
+	}
+
+}
+
 export namespace gui {
 	
+	export class PageMargins {
+	    top: number;
+	    right: number;
+	    bottom: number;
+	    left: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new PageMargins(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.top = source["top"];
+	        this.right = source["right"];
+	        this.bottom = source["bottom"];
+	        this.left = source["left"];
+	    }
+	}
+	export class ImageTransform {
+	    kind: string;
+	    width?: number;
+	    height?: number;
+	    mode?: string;
+	    filter?: string;
+	    x?: number;
+	    y?: number;
+	    centered?: boolean;
+	    aspect?: string;
+	    angle?: number;
+	    flip?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImageTransform(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.kind = source["kind"];
+	        this.width = source["width"];
+	        this.height = source["height"];
+	        this.mode = source["mode"];
+	        this.filter = source["filter"];
+	        this.x = source["x"];
+	        this.y = source["y"];
+	        this.centered = source["centered"];
+	        this.aspect = source["aspect"];
+	        this.angle = source["angle"];
+	        this.flip = source["flip"];
+	    }
+	}
+	export class ConversionOptions {
+	    quality?: number;
+	    width?: number;
+	    height?: number;
+	    pageSize?: string;
+	    orientation?: string;
+	    margins?: PageMargins;
+	    sheets?: string[];
+	    collision?: string;
+	    transforms?: ImageTransform[];
+	    subsampling?: string;
+	    compression?: string;
+	    webpExtended?: boolean;
+	    metadata?: string;
+	    ignoreOrientation?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ConversionOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.quality = source["quality"];
+	        this.width = source["width"];
+	        this.height = source["height"];
+	        this.pageSize = source["pageSize"];
+	        this.orientation = source["orientation"];
+	        this.margins = this.convertValues(source["margins"], PageMargins);
+	        this.sheets = source["sheets"];
+	        this.collision = source["collision"];
+	        this.transforms = this.convertValues(source["transforms"], ImageTransform);
+	        this.subsampling = source["subsampling"];
+	        this.compression = source["compression"];
+	        this.webpExtended = source["webpExtended"];
+	        this.metadata = source["metadata"];
+	        this.ignoreOrientation = source["ignoreOrientation"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class AttemptInfo {
+	    operation: string;
+	    engine: string;
+	    from?: string;
+	    to?: string;
+	    number: number;
+	    durationMs: number;
+	    error?: string;
+	    code?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new AttemptInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.operation = source["operation"];
+	        this.engine = source["engine"];
+	        this.from = source["from"];
+	        this.to = source["to"];
+	        this.number = source["number"];
+	        this.durationMs = source["durationMs"];
+	        this.error = source["error"];
+	        this.code = source["code"];
+	    }
+	}
+	export class EngineInfo {
+	    name: string;
+	    version?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new EngineInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.version = source["version"];
+	    }
+	}
+	export class StageInfo {
+	    engine: string;
+	    stage: string;
+	    durationMs: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new StageInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.engine = source["engine"];
+	        this.stage = source["stage"];
+	        this.durationMs = source["durationMs"];
+	    }
+	}
+	export class FidelityInfo {
+	    engine?: string;
+	    code: string;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FidelityInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.engine = source["engine"];
+	        this.code = source["code"];
+	        this.message = source["message"];
+	    }
+	}
+	export class EncoderInfo {
+	    format: string;
+	    quality?: number;
+	    subsampling?: string;
+	    compression?: string;
+	    extended?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new EncoderInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.format = source["format"];
+	        this.quality = source["quality"];
+	        this.subsampling = source["subsampling"];
+	        this.compression = source["compression"];
+	        this.extended = source["extended"];
+	    }
+	}
 	export class ConversionResult {
 	    success: boolean;
 	    outputPath?: string;
 	    error?: string;
+	    code?: string;
+	    retryable?: boolean;
+	    skipped?: boolean;
+	    cached?: boolean;
+	    warnings?: string[];
+	    attempts?: AttemptInfo[];
+	    durationMs?: number;
+	    inputType?: string;
+	    inputSize?: number;
+	    outputSize?: number;
+	    engines?: EngineInfo[];
+	    pages?: number;
+	    sheets?: number;
+	    images?: number;
+	    stages?: StageInfo[];
+	    fidelity?: FidelityInfo[];
+	    encoder?: EncoderInfo;
 	
 	    static createFrom(source: any = {}) {
 	        return new ConversionResult(source);
@@ -14,6 +256,184 @@ export namespace gui {
 	        this.success = source["success"];
 	        this.outputPath = source["outputPath"];
 	        this.error = source["error"];
+	        this.code = source["code"];
+	        this.retryable = source["retryable"];
+	        this.skipped = source["skipped"];
+	        this.cached = source["cached"];
+	        this.warnings = source["warnings"];
+	        this.attempts = this.convertValues(source["attempts"], AttemptInfo);
+	        this.durationMs = source["durationMs"];
+	        this.inputType = source["inputType"];
+	        this.inputSize = source["inputSize"];
+	        this.outputSize = source["outputSize"];
+	        this.engines = this.convertValues(source["engines"], EngineInfo);
+	        this.pages = source["pages"];
+	        this.sheets = source["sheets"];
+	        this.images = source["images"];
+	        this.stages = this.convertValues(source["stages"], StageInfo);
+	        this.fidelity = this.convertValues(source["fidelity"], FidelityInfo);
+	        this.encoder = this.convertValues(source["encoder"], EncoderInfo);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ManifestRun {
+	    reportPath: string;
+	    succeeded: number;
+	    skipped: number;
+	    failed: number;
+	    results: ConversionResult[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ManifestRun(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.reportPath = source["reportPath"];
+	        this.succeeded = source["succeeded"];
+	        this.skipped = source["skipped"];
+	        this.failed = source["failed"];
+	        this.results = this.convertValues(source["results"], ConversionResult);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PlannedConversion {
+	    input: string;
+	    output: string;
+	    format: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new PlannedConversion(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.input = source["input"];
+	        this.output = source["output"];
+	        this.format = source["format"];
+	    }
+	}
+	export class ManifestPlan {
+	    path: string;
+	    reportPath: string;
+	    entries: number;
+	    conversions: PlannedConversion[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ManifestPlan(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.reportPath = source["reportPath"];
+	        this.entries = source["entries"];
+	        this.conversions = this.convertValues(source["conversions"], PlannedConversion);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SupportedInputType {
+	    type: string;
+	    extensions: string[];
+	    formats: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SupportedInputType(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.type = source["type"];
+	        this.extensions = source["extensions"];
+	        this.formats = source["formats"];
+	    }
+	}
+
+}
+
+export namespace scheduler {
+	
+	export class Metrics {
+	    workers: number;
+	    queued: number;
+	    queuedByPriority: Record<string, number>;
+	    queuedByKey: Record<string, number>;
+	    running: number;
+	    runningByKey: Record<string, number>;
+	    limits: Record<string, number>;
+	    completed: number;
+	    failed: number;
+	    panicked: number;
+	    cancelled: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Metrics(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.workers = source["workers"];
+	        this.queued = source["queued"];
+	        this.queuedByPriority = source["queuedByPriority"];
+	        this.queuedByKey = source["queuedByKey"];
+	        this.running = source["running"];
+	        this.runningByKey = source["runningByKey"];
+	        this.limits = source["limits"];
+	        this.completed = source["completed"];
+	        this.failed = source["failed"];
+	        this.panicked = source["panicked"];
+	        this.cancelled = source["cancelled"];
 	    }
 	}
 
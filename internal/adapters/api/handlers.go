@@ -0,0 +1,448 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/eka026/File-Format-Converter/internal/adapters/dto"
+	"github.com/eka026/File-Format-Converter/internal/domain"
+)
+
+// maxFieldSize bounds the form fields and JSON body of a submission
+const maxFieldSize = 64 << 10
+
+// heartbeatInterval is the time between comments sent on an idle event
+// stream, so proxies and clients do not time it out
+const heartbeatInterval = 15 * time.Second
+
+// PathRequest is the JSON body submitting a file already on this machine
+type PathRequest struct {
+	// Path is the absolute path of the file to convert
+	Path    string       `json:"path"`
+	To      string       `json:"to"`
+	Options *dto.Options `json:"options,omitempty"`
+	// Output is the absolute path to write the result to; empty keeps it in
+	// the job's directory until the job expires
+	Output string `json:"output,omitempty"`
+}
+
+// InputFormat is an accepted input type and the formats it converts to
+type InputFormat struct {
+	Type       string   `json:"type"`
+	Extensions []string `json:"extensions"`
+	Outputs    []string `json:"outputs"`
+}
+
+// FormatsResponse is the body of GET /api/formats
+type FormatsResponse struct {
+	Inputs []InputFormat `json:"inputs"`
+}
+
+// ErrorResponse is the body of every failed request
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// requestError is a submission rejected with an HTTP status
+type requestError struct {
+	status int
+	msg    string
+}
+
+func (e *requestError) Error() string { return e.msg }
+
+func badRequest(format string, args ...interface{}) error {
+	return &requestError{status: http.StatusBadRequest, msg: fmt.Sprintf(format, args...)}
+}
+
+// handleFormats lists the input types and the formats each converts to
+func (s *Server) handleFormats(w http.ResponseWriter, r *http.Request) {
+	response := FormatsResponse{Inputs: []InputFormat{}}
+	for fileType, formats := range s.converter.GetConversionMatrix() {
+		input := InputFormat{Type: string(fileType), Extensions: fileType.Extensions(), Outputs: []string{}}
+		for _, format := range formats {
+			input.Outputs = append(input.Outputs, string(format))
+		}
+		response.Inputs = append(response.Inputs, input)
+	}
+	sort.Slice(response.Inputs, func(i, j int) bool { return response.Inputs[i].Type < response.Inputs[j].Type })
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleListJobs lists the jobs in the order they were submitted
+func (s *Server) handleListJobs(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	jobs := make([]Job, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		view, _ := j.snapshot()
+		jobs = append(jobs, view)
+	}
+	s.mu.Unlock()
+	sort.Slice(jobs, func(i, k int) bool { return jobs[i].Created.Before(jobs[k].Created) })
+	writeJSON(w, http.StatusOK, jobs)
+}
+
+// handleSubmit starts a job from a multipart upload or a JSON path request
+func (s *Server) handleSubmit(w http.ResponseWriter, r *http.Request) {
+	id, err := newJobID()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	j := &job{
+		id:      id,
+		dir:     filepath.Join(s.config.Dir, id),
+		done:    make(chan struct{}),
+		changed: make(chan struct{}),
+	}
+	if err := os.Mkdir(j.dir, 0700); err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("creating job directory: %v", err))
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "multipart/form-data":
+		err = s.receiveUpload(w, r, j)
+	case "application/json":
+		err = s.receivePath(w, r, j)
+	default:
+		err = &requestError{
+			status: http.StatusUnsupportedMediaType,
+			msg:    "submit a multipart/form-data upload or an application/json path request",
+		}
+	}
+	if err != nil {
+		os.RemoveAll(j.dir)
+		var reqErr *requestError
+		var maxErr *http.MaxBytesError
+		switch {
+		case errors.As(err, &reqErr):
+			writeError(w, reqErr.status, reqErr.msg)
+		case errors.As(err, &maxErr):
+			writeError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("upload is larger than %d bytes", maxErr.Limit))
+		default:
+			writeError(w, http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+
+	j.view = Job{ID: id, Status: StatusQueued, Input: j.input, Format: string(j.format()), Created: s.now()}
+	s.mu.Lock()
+	s.jobs[id] = j
+	s.mu.Unlock()
+	s.start(j)
+
+	view, _ := j.snapshot()
+	w.Header().Set("Location", "/api/jobs/"+id)
+	writeJSON(w, http.StatusAccepted, view)
+}
+
+// receiveUpload reads a multipart upload with a "file" part and "to" and
+// optional "options" fields, streaming the file into the job's directory
+func (s *Server) receiveUpload(w http.ResponseWriter, r *http.Request, j *job) error {
+	r.Body = http.MaxBytesReader(w, r.Body, s.config.MaxUploadSize)
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return badRequest("invalid multipart body: %v", err)
+	}
+
+	var to, options string
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		switch part.FormName() {
+		case "file":
+			if j.source != "" {
+				return badRequest("only one file can be uploaded per job")
+			}
+			if err := receiveFile(part.FileName(), part, j); err != nil {
+				return err
+			}
+		case "to", "options":
+			value, err := io.ReadAll(io.LimitReader(part, maxFieldSize))
+			if err != nil {
+				return err
+			}
+			if part.FormName() == "to" {
+				to = string(value)
+			} else {
+				options = string(value)
+			}
+		}
+		part.Close()
+	}
+	if j.source == "" {
+		return badRequest("the upload has no file part")
+	}
+
+	var opts *dto.Options
+	if options != "" {
+		opts = &dto.Options{}
+		if err := decodeStrict([]byte(options), opts); err != nil {
+			return badRequest("invalid options: %v", err)
+		}
+	}
+	return s.prepare(j, to, opts, "")
+}
+
+// receiveFile writes an uploaded file into the job's directory under its
+// own base name
+func receiveFile(fileName string, content io.Reader, j *job) error {
+	name := filepath.Base(filepath.Clean("/" + strings.ReplaceAll(fileName, `\`, "/")))
+	if name == "/" || name == "." {
+		return badRequest("the file part has no file name")
+	}
+	if _, ok := domain.FileTypeFromExtension(filepath.Ext(name)); !ok {
+		return &requestError{status: http.StatusUnsupportedMediaType, msg: fmt.Sprintf("unsupported input file %q", name)}
+	}
+
+	path := filepath.Join(j.dir, "input", name)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, content); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	j.input, j.source = name, path
+	return nil
+}
+
+// receivePath reads a PathRequest. Local paths are only accepted from
+// clients on this machine, even when remote access is allowed.
+func (s *Server) receivePath(w http.ResponseWriter, r *http.Request, j *job) error {
+	if !isLoopbackHost(r.RemoteAddr) {
+		return &requestError{status: http.StatusForbidden, msg: "local paths can only be submitted from this machine"}
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxFieldSize))
+	if err != nil {
+		return err
+	}
+	var request PathRequest
+	if err := decodeStrict(body, &request); err != nil {
+		return badRequest("invalid request: %v", err)
+	}
+	if !filepath.IsAbs(request.Path) {
+		return badRequest("path must be absolute")
+	}
+	if request.Output != "" && !filepath.IsAbs(request.Output) {
+		return badRequest("output must be absolute")
+	}
+	info, err := os.Stat(request.Path)
+	if err != nil {
+		return &requestError{status: http.StatusNotFound, msg: fmt.Sprintf("cannot read %s: %v", request.Path, err)}
+	}
+	if !info.Mode().IsRegular() {
+		return badRequest("%s is not a regular file", request.Path)
+	}
+	j.input, j.source = request.Path, request.Path
+	return s.prepare(j, request.To, request.Options, request.Output)
+}
+
+// prepare sets the job's target and options, rejecting conversions the
+// engines cannot perform
+func (s *Server) prepare(j *job, to string, options *dto.Options, output string) error {
+	if strings.TrimSpace(to) == "" {
+		return badRequest("the output format is required")
+	}
+	format, ok := domain.FormatFromExtension(strings.TrimSpace(to))
+	if !ok {
+		return badRequest("unsupported output format %q", to)
+	}
+	if options != nil {
+		j.opts = options.ToDomain()
+	}
+	if err := j.opts.Validate(); err != nil {
+		return badRequest("invalid options: %v", err)
+	}
+	// Content sniffing may still reject or accept a mislabelled file; this
+	// only catches requests that cannot succeed
+	if fileType, ok := domain.FileTypeFromExtension(filepath.Ext(j.source)); ok {
+		if !slices.Contains(s.converter.GetConversionMatrix()[fileType], format) {
+			return badRequest("cannot convert %s to %s", fileType, format)
+		}
+	}
+
+	if output != "" {
+		if outputFormat, _ := domain.FormatFromExtension(filepath.Ext(output)); outputFormat != format {
+			return badRequest("output must have the %s extension", format.Extension())
+		}
+	}
+	j.target = output
+	if j.target == "" {
+		base := strings.TrimSuffix(filepath.Base(j.source), filepath.Ext(j.source))
+		j.target = filepath.Join(j.dir, "output", base+format.Extension())
+		if err := os.MkdirAll(filepath.Dir(j.target), 0700); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// format returns the output format of the job
+func (j *job) format() domain.Format {
+	format, _ := domain.FormatFromExtension(filepath.Ext(j.target))
+	return format
+}
+
+// handleGetJob returns the state of a job
+func (s *Server) handleGetJob(w http.ResponseWriter, r *http.Request) {
+	j := s.lookup(w, r)
+	if j == nil {
+		return
+	}
+	view, _ := j.snapshot()
+	writeJSON(w, http.StatusOK, view)
+}
+
+// handleDeleteJob cancels a job if it is running and removes it with its files
+func (s *Server) handleDeleteJob(w http.ResponseWriter, r *http.Request) {
+	j := s.lookup(w, r)
+	if j == nil {
+		return
+	}
+	s.mu.Lock()
+	delete(s.jobs, j.id)
+	s.mu.Unlock()
+	j.stop()
+	go j.removeFiles()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleEvents streams a job's progress as Server-Sent Events: a status
+// event carrying the Job whenever its status changes, and a stage event for
+// each engine stage it finishes. The stream ends when the job has finished.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	j := s.lookup(w, r)
+	if j == nil {
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	var (
+		sentStages int
+		sentStatus JobStatus
+	)
+	for {
+		view, changed := j.snapshot()
+		for ; sentStages < len(view.Stages); sentStages++ {
+			writeEvent(w, "stage", view.Stages[sentStages])
+		}
+		if view.Status != sentStatus {
+			writeEvent(w, "status", view)
+			sentStatus = view.Status
+		}
+		flusher.Flush()
+		if view.Status.Terminal() {
+			return
+		}
+
+		select {
+		case <-changed:
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// handleResult downloads the converted file of a succeeded job
+func (s *Server) handleResult(w http.ResponseWriter, r *http.Request) {
+	j := s.lookup(w, r)
+	if j == nil {
+		return
+	}
+	output := j.outputPath()
+	if output == "" {
+		writeError(w, http.StatusConflict, "the job has not succeeded")
+		return
+	}
+	file, err := os.Open(output)
+	if err != nil {
+		writeError(w, http.StatusGone, "the converted file is no longer available")
+		return
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	name := filepath.Base(output)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	http.ServeContent(w, r, name, info.ModTime(), file)
+}
+
+// lookup returns the job named by the request path, writing a 404 response
+// when there is none
+func (s *Server) lookup(w http.ResponseWriter, r *http.Request) *job {
+	s.mu.Lock()
+	j := s.jobs[r.PathValue("id")]
+	s.mu.Unlock()
+	if j == nil {
+		writeError(w, http.StatusNotFound, "no such job")
+	}
+	return j
+}
+
+// decodeStrict decodes a JSON object, rejecting unknown fields
+func decodeStrict(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
+
+// writeJSON writes v as the JSON body of a response
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an ErrorResponse
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, ErrorResponse{Error: msg})
+}
+
+// writeEvent writes a Server-Sent Event with a JSON payload
+func writeEvent(w io.Writer, event string, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+}
@@ -0,0 +1,188 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/eka026/File-Format-Converter/internal/adapters/dto"
+	"github.com/eka026/File-Format-Converter/internal/domain"
+)
+
+// JobStatus is the state of a job
+type JobStatus string
+
+const (
+	StatusQueued    JobStatus = "queued"
+	StatusRunning   JobStatus = "running"
+	StatusSucceeded JobStatus = "succeeded"
+	StatusFailed    JobStatus = "failed"
+	StatusCancelled JobStatus = "cancelled"
+)
+
+// Terminal reports whether a job in this state has finished
+func (s JobStatus) Terminal() bool {
+	return s == StatusSucceeded || s == StatusFailed || s == StatusCancelled
+}
+
+// Job is the JSON form of a job, returned when it is submitted or polled and
+// sent as the status event of its event stream
+type Job struct {
+	ID     string    `json:"id"`
+	Status JobStatus `json:"status"`
+	// Input is the uploaded file name or the submitted path
+	Input    string     `json:"input"`
+	Format   string     `json:"format"`
+	Created  time.Time  `json:"created"`
+	Finished *time.Time `json:"finished,omitempty"`
+	// Stages lists the engine stages finished so far
+	Stages []Stage     `json:"stages,omitempty"`
+	Result *dto.Result `json:"result,omitempty"`
+	// Download is the URL of the converted file once the job succeeded
+	Download string `json:"download,omitempty"`
+}
+
+// Stage is an engine stage finished by a job, sent as a stage event
+type Stage struct {
+	Engine     string `json:"engine,omitempty"`
+	Stage      string `json:"stage"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+// job is a submitted conversion and the files it owns
+type job struct {
+	id     string
+	input  string
+	source string
+	target string
+	opts   domain.ConversionOptions
+	// dir holds the upload and the output unless the client chose the
+	// output path; it is removed with the job
+	dir string
+	// done is closed when the conversion has returned
+	done chan struct{}
+
+	mu     sync.Mutex
+	view   Job
+	output string
+	cancel context.CancelFunc
+	// changed is closed and replaced whenever view changes
+	changed chan struct{}
+}
+
+// newJobID returns a random job identifier
+func newJobID() (string, error) {
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return "", fmt.Errorf("generating job id: %w", err)
+	}
+	return hex.EncodeToString(id[:]), nil
+}
+
+// snapshot returns the job's current state and a channel closed when it
+// next changes
+func (j *job) snapshot() (Job, <-chan struct{}) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	view := j.view
+	view.Stages = append([]Stage(nil), j.view.Stages...)
+	return view, j.changed
+}
+
+// update changes the job's state and wakes the goroutines waiting for it
+func (j *job) update(change func(view *Job)) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	change(&j.view)
+	close(j.changed)
+	j.changed = make(chan struct{})
+}
+
+// finish records the result of the conversion
+func (j *job) finish(ctx context.Context, result domain.Result, finished time.Time) {
+	converted := dto.NewResult(j.input, result)
+	j.update(func(view *Job) {
+		view.Finished = &finished
+		view.Result = &converted
+		switch {
+		case result.Success:
+			view.Status = StatusSucceeded
+			view.Download = "/api/jobs/" + j.id + "/result"
+			j.output = result.OutputPath
+		case ctx.Err() != nil:
+			view.Status = StatusCancelled
+		default:
+			view.Status = StatusFailed
+		}
+	})
+}
+
+// finishedAt returns when the job finished, or the zero time if it is running
+func (j *job) finishedAt() time.Time {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.view.Finished == nil {
+		return time.Time{}
+	}
+	return *j.view.Finished
+}
+
+// outputPath returns the converted file of a succeeded job
+func (j *job) outputPath() string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.output
+}
+
+// stop cancels the conversion if it is still running
+func (j *job) stop() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.cancel != nil {
+		j.cancel()
+	}
+}
+
+// removeFiles waits for the conversion to return and deletes the job's directory
+func (j *job) removeFiles() {
+	<-j.done
+	os.RemoveAll(j.dir)
+}
+
+// start runs the job's conversion in the background
+func (s *Server) start(j *job) {
+	ctx, cancel := context.WithCancel(s.ctx)
+	j.mu.Lock()
+	j.cancel = cancel
+	j.mu.Unlock()
+
+	s.running.Add(1)
+	go func() {
+		defer s.running.Done()
+		defer close(j.done)
+		defer cancel()
+
+		j.update(func(view *Job) { view.Status = StatusRunning })
+		ctx := domain.WithStageObserver(ctx, func(timing domain.StageTiming) {
+			j.update(func(view *Job) {
+				view.Stages = append(view.Stages, Stage{
+					Engine:     timing.Engine,
+					Stage:      timing.Stage,
+					DurationMs: timing.Duration.Milliseconds(),
+				})
+			})
+		})
+		result := s.converter.Convert(ctx, j.source, j.target, j.opts)
+		j.finish(ctx, result, s.now())
+
+		if result.Success {
+			s.logger.Info(fmt.Sprintf("Job %s converted %s", j.id, j.input))
+		} else {
+			s.logger.Error(fmt.Sprintf("Job %s failed", j.id), result.Error)
+		}
+	}()
+}
@@ -0,0 +1,235 @@
+// Package api is the HTTP adapter: a local REST API that lets other tools
+// submit conversions, follow their progress and download the results
+// without linking the converter.
+package api
+
+// NFR-01 (Data Sovereignty): The server binds to loopback unless remote
+// access is explicitly allowed, and uploads stay in a local directory.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eka026/File-Format-Converter/internal/domain"
+)
+
+// DefaultAddr is the address the server listens on unless told otherwise
+const DefaultAddr = "127.0.0.1:8750"
+
+// Defaults for Config fields left zero
+const (
+	// DefaultRetention is how long a finished job and its files are kept
+	DefaultRetention = time.Hour
+	// DefaultMaxUploadSize bounds the size of a multipart upload
+	DefaultMaxUploadSize = 512 << 20
+)
+
+// Converter is the part of domain.ConverterService the server uses
+type Converter interface {
+	Convert(ctx context.Context, source, target string, opts domain.ConversionOptions) domain.Result
+	GetConversionMatrix() map[domain.FileType][]domain.Format
+}
+
+// Config configures a Server
+type Config struct {
+	// Dir holds the uploads and outputs of jobs; empty uses a new directory
+	// under the system temporary directory
+	Dir string
+	// Retention is how long a finished job is kept before it and its files
+	// are removed
+	Retention time.Duration
+	// MaxUploadSize bounds the request body of an upload, in bytes
+	MaxUploadSize int64
+	// AllowRemote accepts requests for any host name. By default only
+	// requests addressed to a loopback host are served, which keeps web
+	// pages from reaching the server through DNS rebinding.
+	AllowRemote bool
+}
+
+// Server runs conversions submitted over HTTP as jobs, each converting one
+// file to one format
+type Server struct {
+	converter Converter
+	logger    domain.Logger
+	config    Config
+	// ownsDir is set when the job directory was created by NewServer and is
+	// removed by Close
+	ownsDir bool
+
+	mu   sync.Mutex
+	jobs map[string]*job
+	// ctx is cancelled by Close to stop running jobs
+	ctx     context.Context
+	cancel  context.CancelFunc
+	running sync.WaitGroup
+	now     func() time.Time
+}
+
+// NewServer creates a server storing job files under config.Dir
+func NewServer(converter Converter, logger domain.Logger, config Config) (*Server, error) {
+	if config.Retention <= 0 {
+		config.Retention = DefaultRetention
+	}
+	if config.MaxUploadSize <= 0 {
+		config.MaxUploadSize = DefaultMaxUploadSize
+	}
+	ownsDir := config.Dir == ""
+	if ownsDir {
+		dir, err := os.MkdirTemp("", "file-format-converter-api-")
+		if err != nil {
+			return nil, fmt.Errorf("creating job directory: %w", err)
+		}
+		config.Dir = dir
+	} else if err := os.MkdirAll(config.Dir, 0700); err != nil {
+		return nil, fmt.Errorf("creating job directory: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Server{
+		converter: converter,
+		logger:    logger,
+		config:    config,
+		ownsDir:   ownsDir,
+		jobs:      make(map[string]*job),
+		ctx:       ctx,
+		cancel:    cancel,
+		now:       time.Now,
+	}, nil
+}
+
+// Handler returns the HTTP handler serving the API
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/formats", s.handleFormats)
+	mux.HandleFunc("GET /api/jobs", s.handleListJobs)
+	mux.HandleFunc("POST /api/jobs", s.handleSubmit)
+	mux.HandleFunc("GET /api/jobs/{id}", s.handleGetJob)
+	mux.HandleFunc("DELETE /api/jobs/{id}", s.handleDeleteJob)
+	mux.HandleFunc("GET /api/jobs/{id}/events", s.handleEvents)
+	mux.HandleFunc("GET /api/jobs/{id}/result", s.handleResult)
+	return s.guard(mux)
+}
+
+// ListenAndServe serves the API on addr until ctx is cancelled, then shuts
+// down and removes the files of every job
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	server := &http.Server{Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	s.logger.Info(fmt.Sprintf("Serving the conversion API on http://%s", listener.Addr()))
+
+	go s.expireLoop(ctx)
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- server.Serve(listener) }()
+
+	select {
+	case err = <-serveErr:
+	case <-ctx.Done():
+		// Event streams only end with their jobs, so the jobs are stopped
+		// before waiting for requests to finish
+		s.cancel()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		err = server.Shutdown(shutdownCtx)
+		cancel()
+	}
+	s.Close()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Close cancels the running jobs, waits for them and removes their files
+func (s *Server) Close() {
+	s.cancel()
+	s.running.Wait()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, job := range s.jobs {
+		job.removeFiles()
+		delete(s.jobs, id)
+	}
+	if s.ownsDir {
+		os.RemoveAll(s.config.Dir)
+	}
+}
+
+// expireLoop removes expired jobs until ctx is cancelled
+func (s *Server) expireLoop(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.expire()
+		}
+	}
+}
+
+// expire removes the jobs that finished more than Retention ago
+func (s *Server) expire() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cutoff := s.now().Add(-s.config.Retention)
+	for id, job := range s.jobs {
+		if finished := job.finishedAt(); !finished.IsZero() && finished.Before(cutoff) {
+			job.removeFiles()
+			delete(s.jobs, id)
+		}
+	}
+}
+
+// guard rejects requests that may come from a web page rather than a local
+// tool: requests addressed to a host name other than loopback, unless remote
+// access is allowed, and cross-origin requests
+func (s *Server) guard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.config.AllowRemote && !isLoopbackHost(r.Host) {
+			writeError(w, http.StatusForbidden, "requests must be addressed to a loopback host")
+			return
+		}
+		if origin := r.Header.Get("Origin"); origin != "" {
+			if parsed, err := url.Parse(origin); err != nil || parsed.Host != r.Host {
+				writeError(w, http.StatusForbidden, "cross-origin requests are not allowed")
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isLoopbackHost reports whether a host, with or without a port, names the
+// local machine
+func isLoopbackHost(hostport string) bool {
+	host := strings.Trim(hostport, "[]")
+	if h, _, err := net.SplitHostPort(hostport); err == nil {
+		host = h
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// IsLoopbackAddr reports whether a listen address such as "127.0.0.1:8750"
+// only accepts connections from the local machine
+func IsLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	return isLoopbackHost(host)
+}
@@ -0,0 +1,320 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eka026/File-Format-Converter/internal/domain"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(msg string)             {}
+func (nopLogger) Error(msg string, err error) {}
+func (nopLogger) Debug(msg string)            {}
+
+// fakeConverter converts PNG to JPEG by prefixing the input, reporting an
+// encode stage. Sources named "slow*" wait until the conversion is cancelled.
+type fakeConverter struct{}
+
+func (fakeConverter) Convert(ctx context.Context, source, target string, opts domain.ConversionOptions) domain.Result {
+	if strings.HasPrefix(filepath.Base(source), "slow") {
+		<-ctx.Done()
+		return domain.Result{Error: domain.NewError(domain.ErrorCodeCancelled, ctx.Err())}
+	}
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return domain.Result{Error: err}
+	}
+	domain.RecordStage(ctx, domain.StageEncode, time.Millisecond)
+	if err := os.WriteFile(target, append([]byte("jpeg:"), data...), 0644); err != nil {
+		return domain.Result{Error: err}
+	}
+	return domain.Result{Success: true, OutputPath: target}
+}
+
+func (fakeConverter) GetConversionMatrix() map[domain.FileType][]domain.Format {
+	return map[domain.FileType][]domain.Format{domain.FileTypePNG: {domain.FormatJPEG}}
+}
+
+// newTestServer starts a server over fakeConverter
+func newTestServer(t *testing.T) (*Server, *httptest.Server) {
+	t.Helper()
+	server, err := NewServer(fakeConverter{}, nopLogger{}, Config{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	ts := httptest.NewServer(server.Handler())
+	t.Cleanup(func() {
+		ts.Close()
+		server.Close()
+	})
+	return server, ts
+}
+
+// upload submits a multipart upload of a file with the given name and content
+func upload(t *testing.T, ts *httptest.Server, name, content, to string) *http.Response {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	form.WriteField("to", to)
+	part, err := form.CreateFormFile("file", name)
+	if err != nil {
+		t.Fatalf("Failed to create form file: %v", err)
+	}
+	part.Write([]byte(content))
+	form.Close()
+	resp, err := http.Post(ts.URL+"/api/jobs", form.FormDataContentType(), &body)
+	if err != nil {
+		t.Fatalf("Failed to submit: %v", err)
+	}
+	return resp
+}
+
+// decode reads a JSON response body into v
+func decode(t *testing.T, resp *http.Response, v interface{}) {
+	t.Helper()
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+}
+
+// waitForJob polls a job until it has finished
+func waitForJob(t *testing.T, ts *httptest.Server, id string) Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		resp, err := http.Get(ts.URL + "/api/jobs/" + id)
+		if err != nil {
+			t.Fatalf("Failed to poll job: %v", err)
+		}
+		var job Job
+		decode(t, resp, &job)
+		if job.Status.Terminal() {
+			return job
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Job %s did not finish", id)
+	return Job{}
+}
+
+// TestServer_Upload tests that an uploaded file is converted and can be downloaded
+func TestServer_Upload(t *testing.T) {
+	_, ts := newTestServer(t)
+
+	resp := upload(t, ts, "photo.png", "png", "jpeg")
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("Expected status %d, got %d", http.StatusAccepted, resp.StatusCode)
+	}
+	var submitted Job
+	decode(t, resp, &submitted)
+
+	job := waitForJob(t, ts, submitted.ID)
+	if job.Status != StatusSucceeded || job.Result == nil || !job.Result.Success {
+		t.Fatalf("Expected the job to succeed, got %+v", job)
+	}
+	if len(job.Stages) != 1 || job.Stages[0].Stage != domain.StageEncode {
+		t.Errorf("Expected the encode stage, got %+v", job.Stages)
+	}
+
+	download, err := http.Get(ts.URL + job.Download)
+	if err != nil {
+		t.Fatalf("Failed to download: %v", err)
+	}
+	defer download.Body.Close()
+	data, _ := io.ReadAll(download.Body)
+	if string(data) != "jpeg:png" {
+		t.Errorf("Expected the converted file, got %q", data)
+	}
+	if disposition := download.Header.Get("Content-Disposition"); !strings.Contains(disposition, "photo.jpeg") {
+		t.Errorf("Expected the output file name in %q", disposition)
+	}
+}
+
+// TestServer_PathRequest tests that a local file is converted to the requested output path
+func TestServer_PathRequest(t *testing.T) {
+	_, ts := newTestServer(t)
+	dir := t.TempDir()
+	source := filepath.Join(dir, "scan.png")
+	if err := os.WriteFile(source, []byte("png"), 0644); err != nil {
+		t.Fatalf("Failed to write source: %v", err)
+	}
+	output := filepath.Join(dir, "scan.jpeg")
+
+	body, _ := json.Marshal(PathRequest{Path: source, To: "jpeg", Output: output})
+	resp, err := http.Post(ts.URL+"/api/jobs", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to submit: %v", err)
+	}
+	var submitted Job
+	decode(t, resp, &submitted)
+	if job := waitForJob(t, ts, submitted.ID); job.Status != StatusSucceeded {
+		t.Fatalf("Expected the job to succeed, got %+v", job)
+	}
+	if data, err := os.ReadFile(output); err != nil || string(data) != "jpeg:png" {
+		t.Errorf("Expected the output at %s, got %q: %v", output, data, err)
+	}
+}
+
+// TestServer_RejectsInvalidSubmissions tests the status codes of submissions that cannot succeed
+func TestServer_RejectsInvalidSubmissions(t *testing.T) {
+	_, ts := newTestServer(t)
+
+	tests := []struct {
+		name string
+		resp func() *http.Response
+		want int
+	}{
+		{"unknown format", func() *http.Response { return upload(t, ts, "photo.png", "png", "xyz") }, http.StatusBadRequest},
+		{"unsupported route", func() *http.Response { return upload(t, ts, "photo.png", "png", "pdf") }, http.StatusBadRequest},
+		{"unsupported input", func() *http.Response { return upload(t, ts, "notes.txt", "text", "jpeg") }, http.StatusUnsupportedMediaType},
+		{"relative path", func() *http.Response {
+			resp, _ := http.Post(ts.URL+"/api/jobs", "application/json", strings.NewReader(`{"path": "scan.png", "to": "jpeg"}`))
+			return resp
+		}, http.StatusBadRequest},
+		{"form body", func() *http.Response {
+			resp, _ := http.Post(ts.URL+"/api/jobs", "application/x-www-form-urlencoded", strings.NewReader("to=jpeg"))
+			return resp
+		}, http.StatusUnsupportedMediaType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := tt.resp()
+			resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, resp.StatusCode)
+			}
+		})
+	}
+}
+
+// TestServer_Events tests that the event stream reports stages and ends with the final status
+func TestServer_Events(t *testing.T) {
+	_, ts := newTestServer(t)
+	var submitted Job
+	decode(t, upload(t, ts, "photo.png", "png", "jpeg"), &submitted)
+
+	resp, err := http.Get(ts.URL + "/api/jobs/" + submitted.ID + "/events")
+	if err != nil {
+		t.Fatalf("Failed to open event stream: %v", err)
+	}
+	defer resp.Body.Close()
+	if contentType := resp.Header.Get("Content-Type"); contentType != "text/event-stream" {
+		t.Fatalf("Expected an event stream, got %q", contentType)
+	}
+
+	var events []string
+	var last Job
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if event, ok := strings.CutPrefix(line, "event: "); ok {
+			events = append(events, event)
+		}
+		if data, ok := strings.CutPrefix(line, "data: "); ok && events[len(events)-1] == "status" {
+			json.Unmarshal([]byte(data), &last)
+		}
+	}
+	if last.Status != StatusSucceeded {
+		t.Errorf("Expected the stream to end with the succeeded status, got %q", last.Status)
+	}
+	if !strings.Contains(strings.Join(events, ","), "stage") {
+		t.Errorf("Expected a stage event, got %v", events)
+	}
+}
+
+// TestServer_Delete tests that deleting a running job cancels it and removes its files
+func TestServer_Delete(t *testing.T) {
+	server, ts := newTestServer(t)
+	var submitted Job
+	decode(t, upload(t, ts, "slow.png", "png", "jpeg"), &submitted)
+
+	req, _ := http.NewRequest(http.MethodDelete, ts.URL+"/api/jobs/"+submitted.ID, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to delete job: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("Expected status %d, got %d", http.StatusNoContent, resp.StatusCode)
+	}
+
+	resp, _ = http.Get(ts.URL + "/api/jobs/" + submitted.ID)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected the job to be gone, got status %d", resp.StatusCode)
+	}
+	dir := filepath.Join(server.config.Dir, submitted.ID)
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("Expected the job directory to be removed")
+}
+
+// TestServer_Guard tests that requests for other hosts and from other origins are rejected
+func TestServer_Guard(t *testing.T) {
+	_, ts := newTestServer(t)
+
+	for name, header := range map[string][2]string{
+		"rebound host": {"Host", "converter.example.com"},
+		"cross origin": {"Origin", "http://example.com"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, ts.URL+"/api/formats", nil)
+			if header[0] == "Host" {
+				req.Host = header[1]
+			} else {
+				req.Header.Set(header[0], header[1])
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusForbidden {
+				t.Errorf("Expected status %d, got %d", http.StatusForbidden, resp.StatusCode)
+			}
+		})
+	}
+
+	resp, err := http.Get(ts.URL + "/api/formats")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	var formats FormatsResponse
+	decode(t, resp, &formats)
+	if len(formats.Inputs) != 1 || formats.Inputs[0].Type != string(domain.FileTypePNG) {
+		t.Errorf("Expected the PNG input, got %+v", formats.Inputs)
+	}
+}
+
+// TestServer_Expire tests that finished jobs are removed after the retention period
+func TestServer_Expire(t *testing.T) {
+	server, ts := newTestServer(t)
+	var submitted Job
+	decode(t, upload(t, ts, "photo.png", "png", "jpeg"), &submitted)
+	waitForJob(t, ts, submitted.ID)
+
+	server.now = func() time.Time { return time.Now().Add(DefaultRetention + time.Minute) }
+	server.expire()
+	if _, err := os.Stat(filepath.Join(server.config.Dir, submitted.ID)); !os.IsNotExist(err) {
+		t.Errorf("Expected the job directory to be removed: %v", err)
+	}
+}
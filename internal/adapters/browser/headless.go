@@ -2,19 +2,37 @@ package browser
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"os"
 	"os/exec"
+	"regexp"
 	"runtime"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
 
+	"github.com/eka026/File-Format-Converter/internal/config"
+	"github.com/eka026/File-Format-Converter/internal/domain"
 	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/cdp"
 	"github.com/go-rod/rod/lib/launcher"
 	"github.com/go-rod/rod/lib/proto"
 )
 
-// HeadlessBrowser provides headless browser functionality for PDF generation
+// stableWait is how long a page must stay idle before it is rendered
+const stableWait = time.Second
+
+// HeadlessBrowser provides headless browser functionality for PDF generation.
+// A browser that crashes or disconnects is relaunched on the next render.
 type HeadlessBrowser struct {
+	chromePath    string
+	renderTimeout time.Duration
+
+	mu      sync.Mutex
 	browser *rod.Browser
 }
 
@@ -94,19 +112,39 @@ func findChromeExecutable() (string, error) {
 // NFR-01 Compliance: This function only uses locally installed browsers.
 // It will NOT download Chromium or any other browser binaries from the internet.
 // If no local browser is found, it returns an error to maintain data sovereignty.
-func NewHeadlessBrowser() (*HeadlessBrowser, error) {
+// cfg chooses the browser executable and the render timeout; nil uses the defaults.
+func NewHeadlessBrowser(cfg *config.Config) (*HeadlessBrowser, error) {
+	if cfg == nil {
+		cfg = config.DefaultConfig()
+	}
+
 	// NFR-01 (Data Sovereignty): Only use locally installed browsers.
 	// Do not allow auto-download of browser binaries from external servers.
-	chromePath, err := findChromeExecutable()
+	chromePath := cfg.BrowserPath
+	var err error
+	if chromePath == "" {
+		chromePath, err = findChromeExecutable()
+	}
 	if err != nil {
-		return nil, fmt.Errorf("no local browser found: %w\n\n"+
+		return nil, domain.Errorf(domain.ErrorCodeBrowserMissing, "no local browser found: %w\n\n"+
 			"Please install Chrome, Chromium, or Edge locally. "+
 			"The application does not download browsers from the internet to maintain data sovereignty. "+
 			"You can install Chrome from: https://www.google.com/chrome/", err)
 	}
 
+	h := &HeadlessBrowser{chromePath: chromePath, renderTimeout: time.Duration(cfg.BrowserTimeout)}
+	browser, err := h.launch()
+	if err != nil {
+		return nil, err
+	}
+	h.browser = browser
+	return h, nil
+}
+
+// launch starts the local browser and connects to it
+func (h *HeadlessBrowser) launch() (*rod.Browser, error) {
 	// Use only the locally found Chrome/Chromium installation
-	l := launcher.New().Bin(chromePath)
+	l := launcher.New().Bin(h.chromePath)
 
 	// Configure launcher
 	l = l.
@@ -118,7 +156,7 @@ func NewHeadlessBrowser() (*HeadlessBrowser, error) {
 	url, err := l.Launch()
 	if err != nil {
 		// Provide helpful error message
-		return nil, fmt.Errorf("failed to launch browser: %w\n\n"+
+		return nil, domain.Errorf(domain.ErrorCodeBrowserMissing, "failed to launch browser: %w\n\n"+
 			"Please ensure Chrome, Chromium, or Edge is installed locally. "+
 			"The application only uses locally installed browsers to maintain data sovereignty. "+
 			"You can install Chrome from: https://www.google.com/chrome/", err)
@@ -126,54 +164,206 @@ func NewHeadlessBrowser() (*HeadlessBrowser, error) {
 
 	browser := rod.New().ControlURL(url)
 	if err := browser.Connect(); err != nil {
-		return nil, fmt.Errorf("failed to connect to browser: %w", err)
+		return nil, domain.Errorf(domain.ErrorCodeBrowserMissing, "failed to connect to browser: %w", err)
+	}
+	return browser, nil
+}
+
+// connection returns the connected browser, relaunching it if it was lost
+func (h *HeadlessBrowser) connection() (*rod.Browser, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.browser == nil {
+		browser, err := h.launch()
+		if err != nil {
+			return nil, err
+		}
+		h.browser = browser
+	}
+	return h.browser, nil
+}
+
+// disconnect drops a browser whose connection failed so the next render relaunches it
+func (h *HeadlessBrowser) disconnect(browser *rod.Browser) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.browser == browser {
+		h.browser = nil
+		browser.Close()
+	}
+}
+
+// render opens a blank page bound to ctx, loads htmlContent into it, waits
+// for it to settle and passes it to capture. Failures are classified so that
+// crashed pages and lost connections can be retried.
+func (h *HeadlessBrowser) render(ctx context.Context, htmlContent string, capture func(page *rod.Page) ([]byte, error)) ([]byte, error) {
+	if _, ok := ctx.Deadline(); !ok && h.renderTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.renderTimeout)
+		defer cancel()
+	}
+
+	browser, err := h.connection()
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := browser.Context(ctx).Page(proto.TargetCreateTarget{URL: "about:blank"})
+	if err != nil {
+		return nil, h.classify(ctx, browser, err)
+	}
+	// Close with a fresh context so pages are released even after a timeout
+	defer page.Context(context.Background()).Close()
+
+	if err := page.SetDocumentContent(htmlContent); err != nil {
+		return nil, h.classify(ctx, browser, err)
+	}
+
+	// Wait for page to be ready; the page context bounds the wait
+	if err := page.WaitStable(stableWait); err != nil {
+		return nil, h.classify(ctx, browser, err)
+	}
+
+	data, err := capture(page)
+	if err != nil {
+		return nil, h.classify(ctx, browser, err)
 	}
+	return data, nil
+}
 
-	return &HeadlessBrowser{browser: browser}, nil
+// classify tags a browser failure: context errors as timeouts or
+// cancellations, and crashed targets or lost connections as transient
+// engine failures. A lost connection also drops the browser for relaunch.
+func (h *HeadlessBrowser) classify(ctx context.Context, browser *rod.Browser, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		if errors.Is(ctxErr, context.DeadlineExceeded) {
+			return domain.Errorf(domain.ErrorCodeTimeout, "browser render timed out: %w", err)
+		}
+		return domain.Errorf(domain.ErrorCodeCancelled, "browser render cancelled: %w", err)
+	}
+	if isConnectionLost(err) {
+		h.disconnect(browser)
+		return domain.Errorf(domain.ErrorCodeEngineUnavailable, "browser connection lost: %w", err)
+	}
+	if isTargetLost(err) {
+		return domain.Errorf(domain.ErrorCodeEngineUnavailable, "browser page crashed: %w", err)
+	}
+	return err
+}
+
+// isConnectionLost reports whether err means the browser process or its
+// DevTools connection is gone
+func isConnectionLost(err error) bool {
+	return errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE)
+}
+
+// isTargetLost reports whether err means the page crashed or was closed under us
+func isTargetLost(err error) bool {
+	var pageNotFound *rod.ErrPageNotFound
+	if errors.As(err, &pageNotFound) ||
+		errors.Is(err, cdp.ErrSessionNotFound) ||
+		errors.Is(err, cdp.ErrCtxNotFound) ||
+		errors.Is(err, cdp.ErrCtxDestroyed) {
+		return true
+	}
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "target closed") || strings.Contains(message, "target crashed")
 }
 
 // GeneratePDFFromHTML generates a PDF from HTML content and writes it to a file
-func (h *HeadlessBrowser) GeneratePDFFromHTML(ctx context.Context, htmlContent string, outputPath string) error {
-	pdf, err := h.GeneratePDFFromHTMLBytes(ctx, htmlContent)
+func (h *HeadlessBrowser) GeneratePDFFromHTML(ctx context.Context, htmlContent string, outputPath string, page domain.PageOptions) error {
+	pdf, err := h.GeneratePDFFromHTMLBytes(ctx, htmlContent, page)
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(outputPath, pdf, 0644)
+	if err := os.WriteFile(outputPath, pdf, 0644); err != nil {
+		return domain.NewError(domain.ErrorCodeOutputNotWritable, err)
+	}
+	return nil
 }
 
 // GeneratePDFFromHTMLBytes generates a PDF from HTML content and returns the PDF bytes
-func (h *HeadlessBrowser) GeneratePDFFromHTMLBytes(ctx context.Context, htmlContent string) ([]byte, error) {
-	page, err := h.browser.Page(proto.TargetCreateTarget{URL: "about:blank"})
+func (h *HeadlessBrowser) GeneratePDFFromHTMLBytes(ctx context.Context, htmlContent string, pageOptions domain.PageOptions) ([]byte, error) {
+	defer domain.StartStage(ctx, domain.StagePDF)()
+	pdf, err := h.render(ctx, htmlContent, func(page *rod.Page) ([]byte, error) {
+		reader, err := page.PDF(printToPDFParams(pageOptions))
+		if err != nil {
+			return nil, err
+		}
+		return io.ReadAll(reader)
+	})
 	if err != nil {
 		return nil, err
 	}
-	defer page.Close()
+	domain.RecordCounts(ctx, domain.ContentCounts{Pages: countPDFPages(pdf)})
+	return pdf, nil
+}
 
-	if err := page.SetDocumentContent(htmlContent); err != nil {
-		return nil, err
-	}
+// pdfPageObject matches the page objects of a PDF but not its page tree nodes
+var pdfPageObject = regexp.MustCompile(`/Type\s*/Page\b`)
 
-	// Wait for page to be ready
-	page.MustWaitStable()
+// countPDFPages counts the pages of a PDF printed by the browser, which
+// writes its page objects uncompressed
+func countPDFPages(pdf []byte) int {
+	return len(pdfPageObject.FindAllIndex(pdf, -1))
+}
 
-	reader, err := page.PDF(&proto.PagePrintToPDF{
-		Landscape:       false,
+// printToPDFParams maps page layout options onto Chrome's print parameters,
+// leaving unset values at Chrome's defaults
+func printToPDFParams(page domain.PageOptions) *proto.PagePrintToPDF {
+	params := &proto.PagePrintToPDF{
+		Landscape:       page.Orientation == domain.OrientationLandscape,
 		PrintBackground: true,
-	})
-	if err != nil {
-		return nil, err
 	}
+	if width, height, ok := page.Size.Dimensions(); ok {
+		params.PaperWidth = &width
+		params.PaperHeight = &height
+	}
+	if m := page.Margins; m != nil {
+		params.MarginTop = &m.Top
+		params.MarginRight = &m.Right
+		params.MarginBottom = &m.Bottom
+		params.MarginLeft = &m.Left
+	}
+	return params
+}
 
-	pdf, err := io.ReadAll(reader)
+// GenerateScreenshotFromHTML renders HTML content to a full-page PNG and writes it to a file
+func (h *HeadlessBrowser) GenerateScreenshotFromHTML(ctx context.Context, htmlContent string, outputPath string) error {
+	png, err := h.GenerateScreenshotFromHTMLBytes(ctx, htmlContent)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return pdf, nil
+	if err := os.WriteFile(outputPath, png, 0644); err != nil {
+		return domain.NewError(domain.ErrorCodeOutputNotWritable, err)
+	}
+	return nil
+}
+
+// GenerateScreenshotFromHTMLBytes renders HTML content to a full-page PNG and returns the image bytes
+func (h *HeadlessBrowser) GenerateScreenshotFromHTMLBytes(ctx context.Context, htmlContent string) ([]byte, error) {
+	defer domain.StartStage(ctx, domain.StageScreenshot)()
+	return h.render(ctx, htmlContent, func(page *rod.Page) ([]byte, error) {
+		return page.Screenshot(true, &proto.PageCaptureScreenshot{
+			Format: proto.PageCaptureScreenshotFormatPng,
+		})
+	})
 }
 
 // Close closes the browser
 func (h *HeadlessBrowser) Close() error {
-	return h.browser.Close()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.browser == nil {
+		return nil
+	}
+	err := h.browser.Close()
+	h.browser = nil
+	return err
 }
@@ -3,17 +3,21 @@ package browser
 import (
 	"context"
 
+	"github.com/eka026/File-Format-Converter/internal/config"
+	"github.com/eka026/File-Format-Converter/internal/domain"
 	"github.com/eka026/File-Format-Converter/internal/ports"
 )
 
 // HeadlessBrowserAdapter is the headless browser driven adapter that implements IPDFGenerator
 type HeadlessBrowserAdapter struct {
+	config          *config.Config
 	headlessBrowser *HeadlessBrowser
 }
 
 // NewHeadlessBrowserAdapter creates a new headless browser adapter
-func NewHeadlessBrowserAdapter() ports.IPDFGenerator {
-	return &HeadlessBrowserAdapter{}
+// The browser is launched with cfg on first use; nil uses the defaults
+func NewHeadlessBrowserAdapter(cfg *config.Config) ports.IPDFGenerator {
+	return &HeadlessBrowserAdapter{config: cfg}
 }
 
 // GenerateFromHTML generates a PDF from HTML content and returns PDF bytes
@@ -22,7 +26,7 @@ func (a *HeadlessBrowserAdapter) GenerateFromHTML(html []byte) []byte {
 
 	// Lazy initialization of headless browser
 	if a.headlessBrowser == nil {
-		browser, err := NewHeadlessBrowser()
+		browser, err := NewHeadlessBrowser(a.config)
 		if err != nil {
 			return nil
 		}
@@ -33,7 +37,7 @@ func (a *HeadlessBrowserAdapter) GenerateFromHTML(html []byte) []byte {
 	htmlContent := string(html)
 
 	// Generate PDF using headless browser
-	pdfBytes, err := a.headlessBrowser.GeneratePDFFromHTMLBytes(ctx, htmlContent)
+	pdfBytes, err := a.headlessBrowser.GeneratePDFFromHTMLBytes(ctx, htmlContent, domain.PageOptions{})
 	if err != nil {
 		return nil
 	}
@@ -0,0 +1,343 @@
+package cache
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/eka026/File-Format-Converter/internal/config"
+	"github.com/eka026/File-Format-Converter/internal/domain"
+)
+
+// DefaultMaxBytes bounds the cache size when no limit is configured
+const DefaultMaxBytes int64 = 512 << 20
+
+// DiskCache is a size-bounded conversion cache stored in a local directory.
+// Each entry is one file named after its key, with its metadata in a
+// sidecar file; the least recently used entries are evicted once the total
+// size exceeds the bound.
+// All data stays on this machine (NFR-01).
+type DiskCache struct {
+	dir      string
+	maxBytes int64
+
+	mu      sync.Mutex
+	entries map[string]*Entry
+	size    int64
+	hits    int64
+	misses  int64
+}
+
+// Entry describes a cached conversion output
+type Entry struct {
+	Key       string    `json:"key"`
+	SizeBytes int64     `json:"sizeBytes"`
+	LastUsed  time.Time `json:"lastUsed"`
+}
+
+// Stats is a snapshot of the cache usage
+type Stats struct {
+	Dir       string `json:"dir"`
+	Entries   int    `json:"entries"`
+	SizeBytes int64  `json:"sizeBytes"`
+	MaxBytes  int64  `json:"maxBytes"`
+	Hits      int64  `json:"hits"`
+	Misses    int64  `json:"misses"`
+}
+
+var _ domain.ConversionCache = (*DiskCache)(nil)
+
+// DefaultDir returns the cache directory inside the user's config directory
+func DefaultDir() (string, error) {
+	configDir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "cache"), nil
+}
+
+// NewDiskCache opens the cache in dir, creating the directory if needed and
+// indexing the entries left by previous runs. maxBytes ≤ 0 uses DefaultMaxBytes.
+func NewDiskCache(dir string, maxBytes int64) (*DiskCache, error) {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytes
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("creating cache directory: %w", err)
+	}
+
+	c := &DiskCache{
+		dir:      dir,
+		maxBytes: maxBytes,
+		entries:  make(map[string]*Entry),
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading cache directory: %w", err)
+	}
+	for _, file := range files {
+		if file.IsDir() || !isKey(file.Name()) {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+		size := info.Size()
+		if metadata, err := os.Stat(c.metadataPath(file.Name())); err == nil {
+			size += metadata.Size()
+		}
+		c.entries[file.Name()] = &Entry{Key: file.Name(), SizeBytes: size, LastUsed: info.ModTime()}
+		c.size += size
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.evict()
+	return c, nil
+}
+
+// Get copies the cached output for key to dstPath and returns the metadata
+// stored with it, reporting whether it was found
+func (c *DiskCache) Get(key, dstPath string) ([]byte, bool, error) {
+	if !isKey(key) {
+		return nil, false, fmt.Errorf("invalid cache key: %q", key)
+	}
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		c.mu.Unlock()
+		return nil, false, nil
+	}
+	// Hold the lock while copying so the entry cannot be evicted mid-read
+	defer c.mu.Unlock()
+
+	if err := copyFile(c.path(key), dstPath); err != nil {
+		if _, statErr := os.Stat(c.path(key)); os.IsNotExist(statErr) {
+			// Removed behind our back; forget it
+			c.removeFiles(key)
+			c.remove(key)
+			c.misses++
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	// Entries stored without metadata have no sidecar
+	metadata, err := os.ReadFile(c.metadataPath(key))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, false, err
+	}
+
+	c.hits++
+	entry.LastUsed = time.Now()
+	os.Chtimes(c.path(key), entry.LastUsed, entry.LastUsed)
+	return metadata, true, nil
+}
+
+// Put stores a copy of the file at srcPath and metadata under key, evicting
+// the least recently used entries to stay within the size bound. Entries
+// larger than the whole cache are not stored.
+func (c *DiskCache) Put(key, srcPath string, metadata []byte) error {
+	if !isKey(key) {
+		return fmt.Errorf("invalid cache key: %q", key)
+	}
+	info, err := os.Stat(srcPath)
+	if err != nil {
+		return err
+	}
+	size := info.Size() + int64(len(metadata))
+	if size > c.maxBytes {
+		return nil
+	}
+
+	// Copy outside the lock into a temporary file, then publish it by rename
+	temp, err := os.CreateTemp(c.dir, ".put-*")
+	if err != nil {
+		return err
+	}
+	tempPath := temp.Name()
+	temp.Close()
+	if err := copyFile(srcPath, tempPath); err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := os.Rename(tempPath, c.path(key)); err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+	if err := c.writeMetadata(key, metadata); err != nil {
+		c.removeFiles(key)
+		c.remove(key)
+		return err
+	}
+	if existing, ok := c.entries[key]; ok {
+		c.size -= existing.SizeBytes
+	}
+	c.entries[key] = &Entry{Key: key, SizeBytes: size, LastUsed: time.Now()}
+	c.size += size
+	c.evict()
+	return nil
+}
+
+// Stats returns a snapshot of the cache usage
+func (c *DiskCache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return Stats{
+		Dir:       c.dir,
+		Entries:   len(c.entries),
+		SizeBytes: c.size,
+		MaxBytes:  c.maxBytes,
+		Hits:      c.hits,
+		Misses:    c.misses,
+	}
+}
+
+// Entries returns the cached outputs, most recently used first
+func (c *DiskCache) Entries() []Entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entries := make([]Entry, 0, len(c.entries))
+	for _, entry := range c.entries {
+		entries = append(entries, *entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].LastUsed.After(entries[j].LastUsed)
+	})
+	return entries
+}
+
+// Clear removes every cached output and resets the hit and miss counters
+func (c *DiskCache) Clear() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var firstErr error
+	for key := range c.entries {
+		if err := c.removeFiles(key); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		c.remove(key)
+	}
+	c.hits, c.misses = 0, 0
+	return firstErr
+}
+
+// evict removes the least recently used entries until the cache fits its bound.
+// The caller must hold c.mu.
+func (c *DiskCache) evict() {
+	if c.size <= c.maxBytes {
+		return
+	}
+	entries := make([]*Entry, 0, len(c.entries))
+	for _, entry := range c.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].LastUsed.Before(entries[j].LastUsed)
+	})
+	for _, entry := range entries {
+		if c.size <= c.maxBytes {
+			return
+		}
+		c.removeFiles(entry.Key)
+		c.remove(entry.Key)
+	}
+}
+
+// remove drops key from the index. The caller must hold c.mu.
+func (c *DiskCache) remove(key string) {
+	if entry, ok := c.entries[key]; ok {
+		c.size -= entry.SizeBytes
+		delete(c.entries, key)
+	}
+}
+
+// writeMetadata replaces the metadata sidecar of key, removing it when
+// metadata is empty. The caller must hold c.mu.
+func (c *DiskCache) writeMetadata(key string, metadata []byte) error {
+	if len(metadata) == 0 {
+		if err := os.Remove(c.metadataPath(key)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	temp, err := os.CreateTemp(c.dir, ".put-*")
+	if err != nil {
+		return err
+	}
+	tempPath := temp.Name()
+	_, err = temp.Write(metadata)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tempPath, c.metadataPath(key))
+	}
+	if err != nil {
+		os.Remove(tempPath)
+	}
+	return err
+}
+
+// removeFiles deletes the output and metadata files of key. The caller must hold c.mu.
+func (c *DiskCache) removeFiles(key string) error {
+	var firstErr error
+	for _, path := range []string{c.path(key), c.metadataPath(key)} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// path returns the file holding the entry for key
+func (c *DiskCache) path(key string) string {
+	return filepath.Join(c.dir, key)
+}
+
+// metadataPath returns the sidecar file holding the metadata of key
+func (c *DiskCache) metadataPath(key string) string {
+	return filepath.Join(c.dir, key+".meta")
+}
+
+// isKey reports whether name is a hex SHA-256 digest, which keeps keys from
+// escaping the cache directory
+func isKey(name string) bool {
+	if len(name) != 64 {
+		return false
+	}
+	for _, r := range name {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// copyFile copies the content of src into dst, truncating dst
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
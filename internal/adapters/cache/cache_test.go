@@ -0,0 +1,162 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testKey returns a valid cache key made of a repeated hex digit
+func testKey(digit string) string {
+	return strings.Repeat(digit, 64)
+}
+
+// writeSource writes content to a fresh file and returns its path
+func writeSource(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "output.bin")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create source: %v", err)
+	}
+	return path
+}
+
+// TestDiskCache_PutGet tests storing and retrieving an entry
+func TestDiskCache_PutGet(t *testing.T) {
+	c, err := NewDiskCache(t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("Failed to open cache: %v", err)
+	}
+
+	dst := filepath.Join(t.TempDir(), "restored.bin")
+	if _, hit, err := c.Get(testKey("a"), dst); err != nil || hit {
+		t.Fatalf("Expected a miss on an empty cache, got hit=%v err=%v", hit, err)
+	}
+
+	if err := c.Put(testKey("a"), writeSource(t, "converted"), nil); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	_, hit, err := c.Get(testKey("a"), dst)
+	if err != nil || !hit {
+		t.Fatalf("Expected a hit, got hit=%v err=%v", hit, err)
+	}
+	data, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatalf("Failed to read restored file: %v", err)
+	}
+	if string(data) != "converted" {
+		t.Errorf("Expected restored content %q, got %q", "converted", data)
+	}
+
+	stats := c.Stats()
+	if stats.Entries != 1 || stats.SizeBytes != int64(len("converted")) || stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+// TestDiskCache_EvictsLeastRecentlyUsed tests that the size bound evicts the oldest entry
+func TestDiskCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	c, err := NewDiskCache(t.TempDir(), 10)
+	if err != nil {
+		t.Fatalf("Failed to open cache: %v", err)
+	}
+
+	dst := filepath.Join(t.TempDir(), "restored.bin")
+	if err := c.Put(testKey("a"), writeSource(t, "aaaa"), nil); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if err := c.Put(testKey("b"), writeSource(t, "bbbb"), nil); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	// Touch a so that b becomes the least recently used entry
+	if _, hit, _ := c.Get(testKey("a"), dst); !hit {
+		t.Fatal("Expected a hit for a")
+	}
+	if err := c.Put(testKey("c"), writeSource(t, "cccc"), nil); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	if _, hit, _ := c.Get(testKey("b"), dst); hit {
+		t.Error("Expected b to be evicted")
+	}
+	for _, key := range []string{testKey("a"), testKey("c")} {
+		if _, hit, _ := c.Get(key, dst); !hit {
+			t.Errorf("Expected %s to be kept", key[:1])
+		}
+	}
+	if size := c.Stats().SizeBytes; size > 10 {
+		t.Errorf("Expected the cache to stay within 10 bytes, got %d", size)
+	}
+}
+
+// TestDiskCache_SkipsOversizedEntries tests that files larger than the cache are not stored
+func TestDiskCache_SkipsOversizedEntries(t *testing.T) {
+	c, err := NewDiskCache(t.TempDir(), 4)
+	if err != nil {
+		t.Fatalf("Failed to open cache: %v", err)
+	}
+	if err := c.Put(testKey("a"), writeSource(t, "too large"), nil); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if stats := c.Stats(); stats.Entries != 0 {
+		t.Errorf("Expected no entries, got %d", stats.Entries)
+	}
+}
+
+// TestDiskCache_ReopenAndClear tests that entries and their metadata survive
+// a reopen and that Clear removes them
+func TestDiskCache_ReopenAndClear(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewDiskCache(dir, 0)
+	if err != nil {
+		t.Fatalf("Failed to open cache: %v", err)
+	}
+	if err := c.Put(testKey("a"), writeSource(t, "converted"), []byte("report")); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	reopened, err := NewDiskCache(dir, 0)
+	if err != nil {
+		t.Fatalf("Failed to reopen cache: %v", err)
+	}
+	entries := reopened.Entries()
+	if len(entries) != 1 || entries[0].Key != testKey("a") {
+		t.Fatalf("Expected the entry to survive a reopen, got %+v", entries)
+	}
+	if size := entries[0].SizeBytes; size != int64(len("converted")+len("report")) {
+		t.Errorf("Expected the entry size to include its metadata, got %d bytes", size)
+	}
+	metadata, hit, err := reopened.Get(testKey("a"), filepath.Join(t.TempDir(), "restored.bin"))
+	if err != nil || !hit {
+		t.Fatalf("Expected a hit, got hit=%v err=%v", hit, err)
+	}
+	if string(metadata) != "report" {
+		t.Errorf("Expected metadata %q, got %q", "report", metadata)
+	}
+
+	if err := reopened.Clear(); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if stats := reopened.Stats(); stats.Entries != 0 || stats.SizeBytes != 0 {
+		t.Errorf("Expected an empty cache, got %+v", stats)
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to list cache directory: %v", err)
+	}
+	if len(files) != 0 {
+		t.Errorf("Expected an empty cache directory, found %d files", len(files))
+	}
+}
+
+// TestDiskCache_RejectsInvalidKeys tests that keys cannot name paths outside the cache
+func TestDiskCache_RejectsInvalidKeys(t *testing.T) {
+	c, err := NewDiskCache(t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("Failed to open cache: %v", err)
+	}
+	if err := c.Put("../escape", writeSource(t, "x"), nil); err == nil {
+		t.Error("Expected an error for an invalid key")
+	}
+}
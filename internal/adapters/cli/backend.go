@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/eka026/File-Format-Converter/internal/adapters/browser"
+	"github.com/eka026/File-Format-Converter/internal/adapters/cache"
+	"github.com/eka026/File-Format-Converter/internal/adapters/filesystem"
+	"github.com/eka026/File-Format-Converter/internal/adapters/sniffer"
+	"github.com/eka026/File-Format-Converter/internal/config"
+	"github.com/eka026/File-Format-Converter/internal/domain"
+	"github.com/eka026/File-Format-Converter/internal/engines"
+	"github.com/eka026/File-Format-Converter/internal/scheduler"
+)
+
+// backend is the converter service the command line drives, wired with the
+// same engines as the GUI. The headless browser is only started when a
+// conversion needs it.
+type backend struct {
+	service         *domain.ConverterService
+	config          *config.Config
+	browserMu       sync.Mutex
+	headlessBrowser *browser.HeadlessBrowser
+}
+
+// newBackend registers the built-in engines and creates the converter
+// service configured by cfg
+func newBackend(cfg *config.Config, logger domain.Logger) (*backend, error) {
+	b := &backend{config: cfg}
+	registry := engines.Default()
+	jobs := scheduler.Default()
+	if cfg.Workers > 0 {
+		jobs = scheduler.New(cfg.Workers)
+	}
+	if err := engines.RegisterBuiltins(registry, engines.BuiltinDeps{
+		Scheduler: jobs,
+		Browser:   b.browser,
+		Settings:  cfg.EngineSettings(),
+	}); err != nil {
+		return nil, fmt.Errorf("failed to register engines: %w", err)
+	}
+
+	serviceOptions := []domain.ServiceOption{
+		domain.WithEngineSource(registry),
+		domain.WithFileTypeDetector(sniffer.NewSniffer()),
+		domain.WithScheduler(jobs),
+	}
+	serviceOptions = append(serviceOptions, cfg.ServiceOptions()...)
+
+	// Conversions work without the cache, so a failure to open it is not fatal
+	if !cfg.NoCache {
+		if cacheDir, err := cache.DefaultDir(); err != nil {
+			logger.Error("Conversion cache disabled", err)
+		} else if diskCache, err := cache.NewDiskCache(cacheDir, cfg.CacheMaxBytes); err != nil {
+			logger.Error("Conversion cache disabled", err)
+		} else {
+			serviceOptions = append(serviceOptions, domain.WithCache(diskCache))
+		}
+	}
+
+	b.service = domain.NewConverterService(
+		nil,
+		logger,
+		nopNotifier{},
+		filesystem.NewDomainFileWriterAdapter(""),
+		serviceOptions...,
+	)
+	return b, nil
+}
+
+// browser returns the shared headless browser, launching it on first use
+func (b *backend) browser() (*browser.HeadlessBrowser, error) {
+	b.browserMu.Lock()
+	defer b.browserMu.Unlock()
+	if b.headlessBrowser == nil {
+		headlessBrowser, err := browser.NewHeadlessBrowser(b.config)
+		if err != nil {
+			return nil, err
+		}
+		b.headlessBrowser = headlessBrowser
+	}
+	return b.headlessBrowser, nil
+}
+
+// close shuts down the headless browser if one was started
+func (b *backend) close() {
+	b.browserMu.Lock()
+	defer b.browserMu.Unlock()
+	if b.headlessBrowser != nil {
+		b.headlessBrowser.Close()
+		b.headlessBrowser = nil
+	}
+}
+
+// configFlags defines the settings of cfg as flags on fs, with --verbose and
+// -v as shorthands for --log-level debug
+func configFlags(fs *flag.FlagSet, cfg *config.Config) {
+	cfg.RegisterFlags(fs)
+	debug := func(string) error {
+		cfg.LogLevel = "debug"
+		return nil
+	}
+	fs.BoolFunc("verbose", "log engine activity; same as --log-level debug", debug)
+	fs.BoolFunc("v", "shorthand for --verbose", debug)
+}
+
+// newStreamLogger returns the logger of the service, which writes engine
+// activity to w at the debug log level only
+func newStreamLogger(w io.Writer, cfg *config.Config, timestamps bool) *streamLogger {
+	return &streamLogger{w: w, verbose: cfg.LogLevel == "debug", timestamps: timestamps}
+}
+
+// streamLogger writes service log messages to w when verbose is set; the
+// command reports results and failures itself. A daemon sets timestamps and
+// always writes its own messages.
+type streamLogger struct {
+	mu         sync.Mutex
+	w          io.Writer
+	verbose    bool
+	timestamps bool
+}
+
+func (l *streamLogger) Info(msg string) {
+	if l.verbose {
+		l.write("[INFO] " + msg)
+	}
+}
+
+func (l *streamLogger) Error(msg string, err error) {
+	if !l.verbose {
+		return
+	}
+	if err != nil {
+		msg = fmt.Sprintf("%s: %v", msg, err)
+	}
+	l.write("[ERROR] " + msg)
+}
+
+func (l *streamLogger) Debug(msg string) {
+	if l.verbose {
+		l.write("[DEBUG] " + msg)
+	}
+}
+
+func (l *streamLogger) write(line string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.timestamps {
+		line = time.Now().Format("2006-01-02 15:04:05 ") + line
+	}
+	fmt.Fprintln(l.w, line)
+}
+
+// nopNotifier ignores progress; the command prints results once done
+type nopNotifier struct{}
+
+func (nopNotifier) NotifyProgress(pct int, msg string)  {}
+func (nopNotifier) NotifyComplete(result domain.Result) {}
+func (nopNotifier) NotifyError(err error)               {}
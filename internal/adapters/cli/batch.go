@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/eka026/File-Format-Converter/internal/adapters/dto"
+	"github.com/eka026/File-Format-Converter/internal/adapters/manifest"
+	"github.com/eka026/File-Format-Converter/internal/config"
+)
+
+const batchUsageText = `Usage: converter batch [flags] MANIFEST
+
+Runs the conversions described in a JSON or YAML manifest and writes a JSON
+report of their results next to it, or to the manifest's "report" path.
+With --check the manifest is validated and the planned conversions are
+listed without converting anything.
+
+Flags:
+`
+
+// runBatch executes "converter batch" and returns the process exit code
+func runBatch(ctx context.Context, args []string, std stdio, cfg *config.Config) int {
+	var (
+		reportPath        string
+		check, jsonOutput bool
+	)
+	fs := flag.NewFlagSet("converter batch", flag.ContinueOnError)
+	fs.SetOutput(std.err)
+	fs.Usage = func() {
+		fmt.Fprint(std.err, batchUsageText)
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&reportPath, "report", "", "report `file`; overrides the manifest's")
+	fs.BoolVar(&check, "check", false, "validate the manifest and list the planned conversions")
+	fs.BoolVar(&jsonOutput, "json", false, "print the report as JSON")
+	configFlags(fs, cfg)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitOK
+		}
+		return ExitUsage
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(std.err, "converter batch: %v\n", err)
+		return ExitUsage
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintf(std.err, "converter batch: expected one manifest\n")
+		return ExitUsage
+	}
+
+	m, err := manifest.Load(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(std.err, "converter batch: %v\n", err)
+		return ExitUsage
+	}
+	if check {
+		jobs, err := m.Plan(time.Now())
+		if err != nil {
+			fmt.Fprintf(std.err, "converter batch: %v\n", err)
+			return ExitUsage
+		}
+		for _, job := range jobs {
+			fmt.Fprintf(std.out, "%s -> %s\n", job.Source, job.Target)
+		}
+		return ExitOK
+	}
+
+	b, err := newBackend(cfg, newStreamLogger(std.err, cfg, false))
+	if err != nil {
+		fmt.Fprintf(std.err, "converter batch: %v\n", err)
+		return ExitFailed
+	}
+	defer b.close()
+
+	report, err := m.Run(ctx, b.service)
+	if err != nil {
+		fmt.Fprintf(std.err, "converter batch: %v\n", err)
+		return ExitFailed
+	}
+	if len(report.Results) == 0 {
+		fmt.Fprintf(std.err, "converter batch: no input files found\n")
+		return ExitNoInput
+	}
+	if reportPath == "" {
+		reportPath = m.ReportPath()
+	}
+	if err := report.Write(reportPath); err != nil {
+		fmt.Fprintf(std.err, "converter batch: %v\n", err)
+		return ExitFailed
+	}
+
+	results := make([]dto.Result, len(report.Results))
+	for i, result := range report.Results {
+		results[i] = result.Result
+	}
+	if jsonOutput {
+		encoder := json.NewEncoder(std.out)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			fmt.Fprintf(std.err, "converter batch: %v\n", err)
+			return ExitFailed
+		}
+	} else {
+		writeText(std.out, newReport(results))
+		fmt.Fprintf(std.out, "Report written to %s\n", reportPath)
+	}
+	return exitCode(ctx, results)
+}
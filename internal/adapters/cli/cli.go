@@ -0,0 +1,409 @@
+// Package cli is the command-line adapter: it converts files with the same
+// engines and ConverterService as the GUI, without a display or WebView.
+package cli
+
+// NFR-01 (Data Sovereignty): The command line converts local files and
+// streams only. Nothing is sent to external servers.
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/eka026/File-Format-Converter/internal/adapters/dto"
+	"github.com/eka026/File-Format-Converter/internal/adapters/inputs"
+	"github.com/eka026/File-Format-Converter/internal/adapters/sniffer"
+	"github.com/eka026/File-Format-Converter/internal/config"
+	"github.com/eka026/File-Format-Converter/internal/domain"
+)
+
+// Exit codes returned by Run
+const (
+	// ExitOK means every conversion succeeded or was skipped
+	ExitOK = 0
+	// ExitFailed means at least one conversion failed
+	ExitFailed = 1
+	// ExitUsage means the command line was invalid
+	ExitUsage = 2
+	// ExitNoInput means the inputs matched no files
+	ExitNoInput = 3
+	// ExitRetryable means every failed conversion failed with a transient
+	// error, such as a timeout, and may succeed when run again
+	ExitRetryable = 4
+	// ExitInterrupted means the conversions were cancelled, usually by a signal
+	ExitInterrupted = 130
+)
+
+// stdio is the standard input and output the command works with
+type stdio struct {
+	in  io.Reader
+	out io.Writer
+	err io.Writer
+}
+
+// command holds the parsed command line
+type command struct {
+	to         string
+	out        string
+	options    string
+	transforms []domain.ImageTransform
+	from       string
+	recursive  bool
+	jsonOutput bool
+	quiet      bool
+	formats    bool
+	inputs     []string
+	config     *config.Config
+}
+
+// usageError is an invalid command line
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+func usagef(format string, args ...interface{}) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+const usageText = `Usage: converter --to FORMAT [flags] INPUT...
+       converter batch --help
+       converter watch --help
+       converter serve --help
+
+Converts files, directories and glob patterns to FORMAT (pdf, html, png, jpeg, webp, gif, bmp, tiff).
+Use - as the only INPUT to read from standard input. Outputs are written next to
+their inputs unless --out is given; --out - writes a single conversion to
+standard output. Defaults come from the config file in the user config
+directory and CONVERTER_* environment variables, such as CONVERTER_WORKERS for
+--workers; flags override both.
+
+Flags:
+`
+
+// Run executes the command line args and returns the process exit code
+func Run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	std := stdio{in: stdin, out: stdout, err: stderr}
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(stderr, "converter: %v\n", err)
+		return ExitUsage
+	}
+	if len(args) > 0 {
+		switch args[0] {
+		case "batch":
+			return runBatch(ctx, args[1:], std, cfg)
+		case "watch":
+			return runWatch(ctx, args[1:], std, cfg)
+		case "serve":
+			return runServe(ctx, args[1:], std, cfg)
+		}
+	}
+	cmd, err := parseArgs(args, stderr, cfg)
+	if errors.Is(err, flag.ErrHelp) {
+		return ExitOK
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "converter: %v\nRun 'converter --help' for usage.\n", err)
+		return ExitUsage
+	}
+
+	code, err := cmd.run(ctx, std)
+	if err != nil {
+		fmt.Fprintf(stderr, "converter: %v\n", err)
+		var usage *usageError
+		if errors.As(err, &usage) {
+			return ExitUsage
+		}
+	}
+	return code
+}
+
+// parseArgs parses flags and inputs; flags may follow the inputs
+func parseArgs(args []string, stderr io.Writer, cfg *config.Config) (*command, error) {
+	cmd := &command{config: cfg}
+	fs := flag.NewFlagSet("converter", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, usageText)
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&cmd.to, "to", "", "output `format`")
+	fs.StringVar(&cmd.out, "out", "", "output file, or directory for several inputs; - for standard output")
+	fs.StringVar(&cmd.options, "options", "", "conversion options as a JSON object, or @file to read them from a file")
+	fs.Func("transform", "image transform `step` such as rotate=90, flip=h, crop=16:9 or resize=800x600:fill; repeat to chain steps", func(value string) error {
+		transform, err := domain.ParseImageTransform(value)
+		if err != nil {
+			return err
+		}
+		cmd.transforms = append(cmd.transforms, transform)
+		return nil
+	})
+	fs.StringVar(&cmd.from, "from", "", "input `type` of standard input; detected from its content by default")
+	fs.BoolVar(&cmd.recursive, "recursive", false, "include files in subdirectories of directory inputs")
+	fs.BoolVar(&cmd.recursive, "r", false, "shorthand for --recursive")
+	fs.BoolVar(&cmd.jsonOutput, "json", false, "print results as JSON")
+	fs.BoolVar(&cmd.quiet, "quiet", false, "only print errors")
+	fs.BoolVar(&cmd.quiet, "q", false, "shorthand for --quiet")
+	fs.BoolVar(&cmd.formats, "formats", false, "list the supported conversions and exit")
+	configFlags(fs, cfg)
+
+	// The flag package stops at the first input, so parse again after each one
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		cmd.inputs = append(cmd.inputs, args[0])
+		args = args[1:]
+	}
+	return cmd, nil
+}
+
+// run performs the conversions and returns the exit code. A non-nil error is
+// printed by Run, which exits with ExitUsage for a usageError.
+func (c *command) run(ctx context.Context, std stdio) (int, error) {
+	if err := c.config.Validate(); err != nil {
+		return ExitUsage, usagef("%v", err)
+	}
+	opts, err := parseOptions(c.options)
+	if err != nil {
+		return ExitUsage, usagef("%v", err)
+	}
+	// Transform flags run after the transforms of --options
+	opts.Image.Transforms = append(opts.Image.Transforms, c.transforms...)
+	var format domain.Format
+	if !c.formats {
+		if c.to == "" {
+			return ExitUsage, usagef("--to is required")
+		}
+		var ok bool
+		if format, ok = domain.FormatFromExtension(c.to); !ok {
+			return ExitUsage, usagef("unsupported output format %q", c.to)
+		}
+		if len(c.inputs) == 0 {
+			return ExitUsage, usagef("no input given")
+		}
+	}
+
+	b, err := newBackend(c.config, newStreamLogger(std.err, c.config, false))
+	if err != nil {
+		return ExitFailed, err
+	}
+	defer b.close()
+
+	if c.formats {
+		return ExitOK, c.printFormats(b.service, std.out)
+	}
+
+	var results []dto.Result
+	resultsOut := std.out
+	switch {
+	case len(c.inputs) == 1 && c.inputs[0] == "-":
+		if c.out == "" || c.out == "-" {
+			resultsOut = std.err
+		}
+		result, err := c.convertStdin(ctx, b.service, std, format, opts)
+		if err != nil {
+			return ExitFailed, err
+		}
+		results = []dto.Result{result}
+	case c.out == "-":
+		resultsOut = std.err
+		result, err := c.convertToStdout(ctx, b.service, std, format, opts)
+		if err != nil {
+			return ExitFailed, err
+		}
+		results = []dto.Result{result}
+	default:
+		results, err = c.convertFiles(ctx, b.service, format, opts)
+		if err != nil {
+			return ExitFailed, err
+		}
+		if len(results) == 0 {
+			return ExitNoInput, fmt.Errorf("no input files found")
+		}
+	}
+
+	report := newReport(results)
+	if c.jsonOutput {
+		if err := writeJSON(resultsOut, report); err != nil {
+			return ExitFailed, err
+		}
+	} else if !c.quiet {
+		writeText(resultsOut, report)
+	} else {
+		for _, result := range results {
+			if !result.Success {
+				fmt.Fprintf(std.err, "%s: failed: %s\n", result.Input, result.Error)
+			}
+		}
+	}
+	return exitCode(ctx, results), nil
+}
+
+// convertFiles converts every input file, as one batch
+func (c *command) convertFiles(ctx context.Context, service *domain.ConverterService, format domain.Format, opts domain.ConversionOptions) ([]dto.Result, error) {
+	for _, input := range c.inputs {
+		if input == "-" {
+			return nil, usagef("- must be the only input")
+		}
+	}
+	files, expanded, err := inputs.Expand(c.inputs, c.recursive)
+	if err != nil {
+		return nil, usagef("%v", err)
+	}
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	outDir := c.out != "" && (expanded || len(files) > 1 || isDirectoryPath(c.out))
+	jobs := make([]domain.BatchJob, len(files))
+	results := make([]dto.Result, len(files))
+	for i, file := range files {
+		jobs[i] = domain.BatchJob{Source: file.Path, Target: outputPath(file, format, c.out, outDir), Options: opts}
+		// The service writes into existing directories only
+		if err := os.MkdirAll(filepath.Dir(jobs[i].Target), 0755); err != nil {
+			return nil, fmt.Errorf("creating output directory: %w", err)
+		}
+	}
+
+	for i, result := range service.BatchConvertJobs(ctx, jobs) {
+		results[i] = dto.NewResult(files[i].Path, result)
+	}
+	return results, nil
+}
+
+// convertToStdout converts the single input file to standard output
+func (c *command) convertToStdout(ctx context.Context, service *domain.ConverterService, std stdio, format domain.Format, opts domain.ConversionOptions) (dto.Result, error) {
+	if len(c.inputs) != 1 || c.inputs[0] == "-" || inputs.IsPattern(c.inputs[0]) {
+		return dto.Result{}, usagef("--out - needs exactly one input file")
+	}
+	input := c.inputs[0]
+	file, err := os.Open(input)
+	if err != nil {
+		return dto.NewResult(input, domain.Result{Error: domain.Errorf(domain.ErrorCodeInputNotFound, "opening input: %w", err)}), nil
+	}
+	defer file.Close()
+
+	inputType, warnings, err := c.inputType(input, func() (domain.FileTypeDetection, error) {
+		return sniffer.NewSniffer().Detect(input)
+	})
+	if err != nil {
+		return dto.Result{}, err
+	}
+	result := service.ConvertStream(ctx, file, inputType, std.out, format, opts)
+	result.Warnings = append(warnings, result.Warnings...)
+	return dto.NewResult(input, result), nil
+}
+
+// convertStdin converts standard input to --out, or to standard output
+func (c *command) convertStdin(ctx context.Context, service *domain.ConverterService, std stdio, format domain.Format, opts domain.ConversionOptions) (dto.Result, error) {
+	input := std.in
+	// Without --from the type is detected from the content, which for
+	// DOCX and XLSX needs the whole archive
+	inputType, warnings, err := c.inputType("", func() (domain.FileTypeDetection, error) {
+		data, err := io.ReadAll(c.config.ResourceLimits().LimitReader(input))
+		if err != nil {
+			return domain.FileTypeDetection{}, err
+		}
+		input = bytes.NewReader(data)
+		return sniffer.DetectReader(bytes.NewReader(data), int64(len(data)), "")
+	})
+	if err != nil {
+		return dto.Result{}, err
+	}
+
+	var result domain.Result
+	if c.out == "" || c.out == "-" {
+		result = service.ConvertStream(ctx, input, inputType, std.out, format, opts)
+	} else {
+		if err := os.MkdirAll(filepath.Dir(c.out), 0755); err != nil {
+			return dto.Result{}, fmt.Errorf("creating output directory: %w", err)
+		}
+		result = service.ConvertStreamToFile(ctx, input, inputType, c.out, opts)
+	}
+	result.Warnings = append(warnings, result.Warnings...)
+	return dto.NewResult("-", result), nil
+}
+
+// inputType returns the type named by --from, or the one detect finds
+func (c *command) inputType(name string, detect func() (domain.FileTypeDetection, error)) (domain.FileType, []string, error) {
+	if c.from != "" {
+		fileType, ok := domain.FileTypeFromExtension(c.from)
+		if !ok {
+			return "", nil, usagef("unsupported input type %q", c.from)
+		}
+		return fileType, nil, nil
+	}
+	detection, err := detect()
+	if err != nil {
+		return "", nil, fmt.Errorf("detecting input type: %w", err)
+	}
+	if detection.FileType == "" {
+		if name == "" {
+			name = "standard input"
+		}
+		return "", nil, usagef("cannot detect the type of %s; use --from", name)
+	}
+	return detection.FileType, detection.Warnings, nil
+}
+
+// printFormats lists, for each input type, the formats it converts to
+func (c *command) printFormats(service *domain.ConverterService, w io.Writer) error {
+	matrix := service.GetConversionMatrix()
+	types := make([]string, 0, len(matrix))
+	for fileType := range matrix {
+		types = append(types, string(fileType))
+	}
+	sort.Strings(types)
+
+	if c.jsonOutput {
+		formats := make(map[string][]domain.Format, len(matrix))
+		for fileType, targets := range matrix {
+			formats[string(fileType)] = targets
+		}
+		return json.NewEncoder(w).Encode(formats)
+	}
+	for _, fileType := range types {
+		targets := matrix[domain.FileType(fileType)]
+		names := make([]string, len(targets))
+		for i, target := range targets {
+			names[i] = strings.ToLower(string(target))
+		}
+		fmt.Fprintf(w, "%-5s -> %s\n", strings.ToLower(fileType), strings.Join(names, ", "))
+	}
+	return nil
+}
+
+// exitCode maps the results to the process exit code
+func exitCode(ctx context.Context, results []dto.Result) int {
+	if ctx.Err() != nil {
+		return ExitInterrupted
+	}
+	code := ExitOK
+	for _, result := range results {
+		if result.Success {
+			continue
+		}
+		if !result.Retryable {
+			return ExitFailed
+		}
+		code = ExitRetryable
+	}
+	return code
+}
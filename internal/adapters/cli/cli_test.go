@@ -0,0 +1,298 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eka026/File-Format-Converter/internal/adapters/dto"
+	"github.com/eka026/File-Format-Converter/internal/adapters/watch"
+	"github.com/eka026/File-Format-Converter/internal/domain"
+)
+
+// writePNG creates a small PNG image at path
+func writePNG(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for i := range img.Pix {
+		img.Pix[i] = 0xFF
+	}
+	img.Set(1, 1, color.RGBA{R: 0xFF, A: 0xFF})
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("Failed to encode PNG: %v", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("Failed to write PNG: %v", err)
+	}
+}
+
+// run executes the command line with stdin and returns the exit code and output
+func run(t *testing.T, stdin []byte, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	args = append([]string{"--no-cache"}, args...)
+	code := Run(context.Background(), args, bytes.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+// TestRun_SingleFile tests that a file is converted next to its input by default
+func TestRun_SingleFile(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "photo.png")
+	writePNG(t, source)
+
+	code, stdout, stderr := run(t, nil, "--to", "jpeg", source)
+	if code != ExitOK {
+		t.Fatalf("Expected exit code %d, got %d: %s", ExitOK, code, stderr)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "photo.jpeg")); err != nil {
+		t.Errorf("Expected the output next to the input: %v", err)
+	}
+	if !strings.Contains(stdout, "photo.jpeg") {
+		t.Errorf("Expected the output path to be printed, got %q", stdout)
+	}
+}
+
+// TestRun_RecursiveDirectory tests that directory inputs keep their layout under --out and report JSON
+func TestRun_RecursiveDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writePNG(t, filepath.Join(dir, "in", "a.png"))
+	writePNG(t, filepath.Join(dir, "in", "nested", "b.png"))
+	writePNG(t, filepath.Join(dir, "in", ".hidden", "c.png"))
+	if err := os.WriteFile(filepath.Join(dir, "in", "notes.txt"), []byte("notes"), 0644); err != nil {
+		t.Fatalf("Failed to write notes: %v", err)
+	}
+	out := filepath.Join(dir, "out")
+
+	code, stdout, stderr := run(t, nil, "--json", "-r", "--to", "webp", "--out", out, filepath.Join(dir, "in"))
+	if code != ExitOK {
+		t.Fatalf("Expected exit code %d, got %d: %s", ExitOK, code, stderr)
+	}
+	var report Report
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("Expected a JSON report, got %q: %v", stdout, err)
+	}
+	if report.Succeeded != 2 || len(report.Results) != 2 {
+		t.Fatalf("Expected 2 conversions, got %+v", report)
+	}
+	for _, name := range []string{"a.webp", filepath.Join("nested", "b.webp")} {
+		if _, err := os.Stat(filepath.Join(out, name)); err != nil {
+			t.Errorf("Expected %s under the output directory: %v", name, err)
+		}
+	}
+}
+
+// TestRun_Glob tests that glob patterns are expanded without a shell
+func TestRun_Glob(t *testing.T) {
+	dir := t.TempDir()
+	writePNG(t, filepath.Join(dir, "a.png"))
+	writePNG(t, filepath.Join(dir, "b.png"))
+
+	code, _, stderr := run(t, nil, "--to", "jpeg", filepath.Join(dir, "*.png"))
+	if code != ExitOK {
+		t.Fatalf("Expected exit code %d, got %d: %s", ExitOK, code, stderr)
+	}
+	for _, name := range []string{"a.jpeg", "b.jpeg"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("Expected %s: %v", name, err)
+		}
+	}
+}
+
+// TestRun_Stdio tests that standard input is converted to standard output
+func TestRun_Stdio(t *testing.T) {
+	source := filepath.Join(t.TempDir(), "photo.png")
+	writePNG(t, source)
+	data, err := os.ReadFile(source)
+	if err != nil {
+		t.Fatalf("Failed to read PNG: %v", err)
+	}
+
+	code, stdout, stderr := run(t, data, "--to", "jpeg", "-")
+	if code != ExitOK {
+		t.Fatalf("Expected exit code %d, got %d: %s", ExitOK, code, stderr)
+	}
+	if !strings.HasPrefix(stdout, "\xFF\xD8\xFF") {
+		t.Errorf("Expected JPEG data on standard output")
+	}
+	if !strings.Contains(stderr, "stdout") {
+		t.Errorf("Expected the result on standard error, got %q", stderr)
+	}
+}
+
+// TestRun_ExitCodes tests the exit codes of failed and invalid invocations
+func TestRun_ExitCodes(t *testing.T) {
+	dir := t.TempDir()
+	corrupt := filepath.Join(dir, "corrupt.png")
+	if err := os.WriteFile(corrupt, []byte("not an image"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"missing format", []string{corrupt}, ExitUsage},
+		{"unknown format", []string{"--to", "xyz", corrupt}, ExitUsage},
+		{"invalid options", []string{"--to", "jpeg", "--options", "{", corrupt}, ExitUsage},
+		{"invalid transform", []string{"--to", "jpeg", "--transform", "rotate=left", corrupt}, ExitUsage},
+		{"no matches", []string{"--to", "jpeg", filepath.Join(dir, "*.webp")}, ExitNoInput},
+		{"corrupt input", []string{"--to", "jpeg", corrupt}, ExitFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code, _, stderr := run(t, nil, tt.args...); code != tt.want {
+				t.Errorf("Expected exit code %d, got %d: %s", tt.want, code, stderr)
+			}
+		})
+	}
+}
+
+// TestRun_Transforms tests that --transform steps run after the transforms of --options
+func TestRun_Transforms(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "photo.png")
+	writePNG(t, source)
+	output := filepath.Join(dir, "turned.png")
+
+	code, _, stderr := run(t, nil, "--to", "png", "--out", output, "--options", `{"transforms": ["crop=4x2+0+0"]}`, "--transform", "rotate=90", source)
+	if code != ExitOK {
+		t.Fatalf("Expected exit code %d, got %d: %s", ExitOK, code, stderr)
+	}
+	file, err := os.Open(output)
+	if err != nil {
+		t.Fatalf("Failed to open output: %v", err)
+	}
+	defer file.Close()
+	config, err := png.DecodeConfig(file)
+	if err != nil {
+		t.Fatalf("Failed to decode output: %v", err)
+	}
+	if config.Width != 2 || config.Height != 4 {
+		t.Errorf("Expected the cropped image turned to 2x4, got %dx%d", config.Width, config.Height)
+	}
+}
+
+// TestRun_EncoderSettings tests that the JSON report records the resolved encoder settings
+func TestRun_EncoderSettings(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "photo.png")
+	writePNG(t, source)
+
+	code, stdout, stderr := run(t, nil, "--json", "--to", "jpeg", "--options", `{"subsampling": "4:4:4"}`, source)
+	if code != ExitOK {
+		t.Fatalf("Expected exit code %d, got %d: %s", ExitOK, code, stderr)
+	}
+	var report Report
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("Expected a JSON report, got %q: %v", stdout, err)
+	}
+	want := dto.Encoder{Format: "JPEG", Quality: 95, Subsampling: "4:4:4"}
+	if len(report.Results) != 1 || report.Results[0].Encoder == nil || *report.Results[0].Encoder != want {
+		t.Errorf("Expected the encoder settings %+v, got %+v", want, report.Results)
+	}
+
+	if code, _, stderr := run(t, nil, "--to", "webp", "--options", `{"compression": "best"}`, source); code != ExitFailed {
+		t.Errorf("Expected PNG compression for WebP output to fail, got %d: %s", code, stderr)
+	}
+}
+
+// TestRun_Config tests that the config file and environment set defaults that flags override
+func TestRun_Config(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "photo.png")
+	writePNG(t, source)
+	if err := os.WriteFile(filepath.Join(dir, "photo.jpeg"), []byte("existing"), 0644); err != nil {
+		t.Fatalf("Failed to write existing output: %v", err)
+	}
+	configPath := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(configPath, []byte(`{"collision": "fail"}`), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	t.Setenv("CONVERTER_CONFIG", configPath)
+
+	if code, _, stderr := run(t, nil, "--to", "jpeg", source); code != ExitFailed {
+		t.Errorf("Expected the configured collision policy to fail the conversion, got %d: %s", code, stderr)
+	}
+	if code, _, stderr := run(t, nil, "--to", "jpeg", "--collision", "skip", source); code != ExitOK {
+		t.Errorf("Expected --collision to override the config, got %d: %s", code, stderr)
+	}
+
+	t.Setenv("CONVERTER_LOG_LEVEL", "loud")
+	if code, _, stderr := run(t, nil, "--to", "jpeg", source); code != ExitUsage || !strings.Contains(stderr, "log level") {
+		t.Errorf("Expected an invalid environment setting to be a usage error, got %d: %s", code, stderr)
+	}
+}
+
+// TestLoadWatchConfig tests that a watch config file resolves paths against its own directory
+func TestLoadWatchConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "watch.json")
+	content := `{
+		"settle": "10s",
+		"state": "state.json",
+		"folders": [{"dir": "scans", "to": ["pdf", "png"], "options": {"pageSize": "A4"}}]
+	}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	var config watch.Config
+	if err := loadWatchConfig(path, &config, flag.NewFlagSet("watch", flag.ContinueOnError)); err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Settle != 10*time.Second || config.StatePath != filepath.Join(dir, "state.json") {
+		t.Errorf("Expected settle and state from the file, got %v and %s", config.Settle, config.StatePath)
+	}
+	if len(config.Folders) != 1 {
+		t.Fatalf("Expected one folder, got %d", len(config.Folders))
+	}
+	folder := config.Folders[0]
+	if folder.Dir != filepath.Join(dir, "scans") || len(folder.Targets) != 2 || folder.Options.Page.Size != domain.PageSizeA4 {
+		t.Errorf("Unexpected folder %+v", folder)
+	}
+}
+
+// TestRun_Batch tests that a manifest is run and its report written next to it
+func TestRun_Batch(t *testing.T) {
+	dir := t.TempDir()
+	writePNG(t, filepath.Join(dir, "in", "a.png"))
+	manifest := filepath.Join(dir, "nightly.yaml")
+	content := "output: out\nentries:\n  - inputs: [in]\n    to: [jpeg, webp]\n"
+	if err := os.WriteFile(manifest, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := Run(context.Background(), []string{"batch", "--check", manifest}, nil, &stdout, &stderr)
+	if code != ExitOK || !strings.Contains(stdout.String(), filepath.Join(dir, "out", "a.webp")) {
+		t.Fatalf("Expected the planned conversions, got %d: %s%s", code, &stdout, &stderr)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "out")); !os.IsNotExist(err) {
+		t.Errorf("Expected --check not to convert anything")
+	}
+
+	code = Run(context.Background(), []string{"batch", "--no-cache", manifest}, nil, &stdout, &stderr)
+	if code != ExitOK {
+		t.Fatalf("Expected exit code %d, got %d: %s", ExitOK, code, &stderr)
+	}
+	for _, name := range []string{filepath.Join("out", "a.jpeg"), filepath.Join("out", "a.webp"), "nightly.report.json"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("Expected %s: %v", name, err)
+		}
+	}
+}
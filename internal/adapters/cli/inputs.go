@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/eka026/File-Format-Converter/internal/adapters/inputs"
+	"github.com/eka026/File-Format-Converter/internal/domain"
+)
+
+// outputPath returns where the conversion of file to format is written: next
+// to the input when out is empty, under out when outDir is set, keeping the
+// layout of recursed directories, or out itself
+func outputPath(file inputs.File, format domain.Format, out string, outDir bool) string {
+	name := strings.TrimSuffix(file.Rel, filepath.Ext(file.Rel)) + format.Extension()
+	switch {
+	case out == "":
+		return filepath.Join(filepath.Dir(file.Path), filepath.Base(name))
+	case outDir:
+		return filepath.Join(out, name)
+	default:
+		return out
+	}
+}
+
+// isDirectoryPath reports whether out names a directory: an existing one or
+// a path ending in a separator
+func isDirectoryPath(out string) bool {
+	if strings.HasSuffix(out, "/") || strings.HasSuffix(out, string(filepath.Separator)) {
+		return true
+	}
+	info, err := os.Stat(out)
+	return err == nil && info.IsDir()
+}
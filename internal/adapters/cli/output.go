@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/eka026/File-Format-Converter/internal/adapters/dto"
+	"github.com/eka026/File-Format-Converter/internal/domain"
+)
+
+// parseOptions reads --options: a JSON object, or @path to a file holding one
+func parseOptions(value string) (domain.ConversionOptions, error) {
+	if value == "" {
+		return domain.ConversionOptions{}, nil
+	}
+	data := []byte(value)
+	if path, ok := strings.CutPrefix(value, "@"); ok {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return domain.ConversionOptions{}, fmt.Errorf("reading options: %w", err)
+		}
+	}
+
+	var options dto.Options
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&options); err != nil {
+		return domain.ConversionOptions{}, fmt.Errorf("invalid options: %w", err)
+	}
+	return options.ToDomain(), nil
+}
+
+// Report is the document printed by --json
+type Report struct {
+	Results   []dto.Result `json:"results"`
+	Succeeded int          `json:"succeeded"`
+	Skipped   int          `json:"skipped"`
+	Failed    int          `json:"failed"`
+}
+
+// newReport summarises results
+func newReport(results []dto.Result) Report {
+	report := Report{Results: results}
+	for _, result := range results {
+		switch {
+		case !result.Success:
+			report.Failed++
+		case result.Skipped:
+			report.Skipped++
+		default:
+			report.Succeeded++
+		}
+	}
+	return report
+}
+
+// writeJSON prints the report as indented JSON
+func writeJSON(w io.Writer, report Report) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// writeText prints one line per result, its warnings, and a summary when
+// there was more than one input
+func writeText(w io.Writer, report Report) {
+	for _, result := range report.Results {
+		switch {
+		case !result.Success:
+			fmt.Fprintf(w, "%s: failed: %s\n", result.Input, result.Error)
+		case result.Skipped:
+			fmt.Fprintf(w, "%s: skipped, %s already exists\n", result.Input, result.Output)
+		default:
+			output := result.Output
+			if output == "" {
+				output = "stdout"
+			}
+			details := []string{fmt.Sprintf("%d ms", result.DurationMs)}
+			if result.OutputSize > 0 {
+				details = append([]string{formatBytes(result.OutputSize)}, details...)
+			}
+			if result.Cached {
+				details = append(details, "cached")
+			}
+			fmt.Fprintf(w, "%s -> %s (%s)\n", result.Input, output, strings.Join(details, ", "))
+		}
+		for _, warning := range result.Warnings {
+			fmt.Fprintf(w, "  warning: %s\n", warning)
+		}
+		for _, note := range result.Fidelity {
+			fmt.Fprintf(w, "  warning: %s\n", note.Message)
+		}
+	}
+	if len(report.Results) > 1 {
+		fmt.Fprintf(w, "%d converted, %d skipped, %d failed\n", report.Succeeded, report.Skipped, report.Failed)
+	}
+}
+
+// formatBytes formats a byte count for display
+func formatBytes(bytes int64) string {
+	if bytes < 1024 {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	units := []string{"KB", "MB", "GB"}
+	value := float64(bytes) / 1024
+	unit := 0
+	for value >= 1024 && unit < len(units)-1 {
+		value /= 1024
+		unit++
+	}
+	return fmt.Sprintf("%.1f %s", value, units[unit])
+}
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/eka026/File-Format-Converter/internal/adapters/api"
+	"github.com/eka026/File-Format-Converter/internal/config"
+)
+
+const serveUsageText = `Usage: converter serve [flags]
+
+Serves a REST API for other local tools: POST /api/jobs submits a conversion
+as a multipart upload or a JSON path request, GET /api/jobs/{id} polls it,
+GET /api/jobs/{id}/events streams its progress as Server-Sent Events and
+GET /api/jobs/{id}/result downloads the converted file. GET /api/formats lists
+the supported conversions. Runs until interrupted.
+
+Flags:
+`
+
+// runServe executes "converter serve" and returns the process exit code
+func runServe(ctx context.Context, args []string, std stdio, cfg *config.Config) int {
+	var (
+		addr, dir   string
+		allowRemote bool
+	)
+	fs := flag.NewFlagSet("converter serve", flag.ContinueOnError)
+	fs.SetOutput(std.err)
+	fs.Usage = func() {
+		fmt.Fprint(std.err, serveUsageText)
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&addr, "addr", api.DefaultAddr, "`address` to listen on")
+	fs.StringVar(&dir, "dir", "", "`directory` for uploads and results; default a temporary directory")
+	fs.BoolVar(&allowRemote, "allow-remote", false, "allow listening on a non-loopback address")
+	configFlags(fs, cfg)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitOK
+		}
+		return ExitUsage
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(std.err, "converter serve: %v\n", err)
+		return ExitUsage
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(std.err, "converter serve: unexpected argument %q\n", fs.Arg(0))
+		return ExitUsage
+	}
+	if !allowRemote && !api.IsLoopbackAddr(addr) {
+		fmt.Fprintf(std.err, "converter serve: %s is not a loopback address; use --allow-remote to serve other machines\n", addr)
+		return ExitUsage
+	}
+
+	logger := &streamLogger{w: std.err, verbose: true, timestamps: true}
+	b, err := newBackend(cfg, newStreamLogger(std.err, cfg, true))
+	if err != nil {
+		fmt.Fprintf(std.err, "converter serve: %v\n", err)
+		return ExitFailed
+	}
+	defer b.close()
+
+	server, err := api.NewServer(b.service, logger, api.Config{Dir: dir, AllowRemote: allowRemote})
+	if err != nil {
+		fmt.Fprintf(std.err, "converter serve: %v\n", err)
+		return ExitFailed
+	}
+	if err := server.ListenAndServe(ctx, addr); err != nil {
+		fmt.Fprintf(std.err, "converter serve: %v\n", err)
+		return ExitFailed
+	}
+	return ExitOK
+}
@@ -0,0 +1,224 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/eka026/File-Format-Converter/internal/adapters/dto"
+	"github.com/eka026/File-Format-Converter/internal/adapters/watch"
+	"github.com/eka026/File-Format-Converter/internal/config"
+	"github.com/eka026/File-Format-Converter/internal/domain"
+)
+
+// WatchConfig is the file read by watch --config, for folders that each
+// convert to their own targets
+type WatchConfig struct {
+	// Interval and Settle are durations such as "2s"; see watch.Config
+	Interval config.Duration `json:"interval,omitempty"`
+	Settle   config.Duration `json:"settle,omitempty"`
+	State    string          `json:"state,omitempty"`
+	Folders  []WatchFolder   `json:"folders"`
+}
+
+// WatchFolder is a watched folder in a WatchConfig
+type WatchFolder struct {
+	Dir     string       `json:"dir"`
+	To      []string     `json:"to"`
+	Out     string       `json:"out,omitempty"`
+	Options *dto.Options `json:"options,omitempty"`
+}
+
+const watchUsageText = `Usage: converter watch --to FORMAT[,FORMAT...] [flags] DIR...
+       converter watch --config FILE [flags]
+
+Watches folders for new files and converts each one once it is fully written.
+Outputs go to DIR/converted unless --out is given; originals are moved to
+DIR/processed, or to DIR/failed with an .error.txt report. A journal of
+handled files keeps a restart from converting them again. Runs until
+interrupted.
+
+Flags:
+`
+
+// runWatch executes "converter watch" and returns the process exit code
+func runWatch(ctx context.Context, args []string, std stdio, cfg *config.Config) int {
+	var (
+		to, out, options, configPath, state string
+		interval, settle                    time.Duration
+	)
+	fs := flag.NewFlagSet("converter watch", flag.ContinueOnError)
+	fs.SetOutput(std.err)
+	fs.Usage = func() {
+		fmt.Fprint(std.err, watchUsageText)
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&to, "to", "", "comma-separated output `formats`")
+	fs.StringVar(&out, "out", "", "output directory; default DIR/converted")
+	fs.StringVar(&options, "options", "", "conversion options as a JSON object, or @file to read them from a file")
+	fs.StringVar(&configPath, "config", "", "JSON `file` listing folders with their own targets")
+	fs.StringVar(&state, "state", "", "journal `file`; default in the user config directory")
+	fs.DurationVar(&interval, "interval", watch.DefaultInterval, "time between folder scans")
+	fs.DurationVar(&settle, "settle", watch.DefaultSettle, "how long a file must stay unchanged before it is converted")
+	configFlags(fs, cfg)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitOK
+		}
+		return ExitUsage
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(std.err, "converter watch: %v\n", err)
+		return ExitUsage
+	}
+
+	config := watch.Config{Interval: interval, Settle: settle, StatePath: state}
+	var err error
+	if configPath != "" {
+		err = loadWatchConfig(configPath, &config, fs)
+	} else {
+		err = flagWatchConfig(&config, fs.Args(), to, out, options)
+	}
+	if err != nil {
+		fmt.Fprintf(std.err, "converter watch: %v\n", err)
+		return ExitUsage
+	}
+	if config.StatePath == "" {
+		if config.StatePath, err = defaultStatePath(); err != nil {
+			fmt.Fprintf(std.err, "converter watch: %v; use --state\n", err)
+			return ExitUsage
+		}
+	}
+
+	logger := &streamLogger{w: std.err, verbose: true, timestamps: true}
+	b, err := newBackend(cfg, newStreamLogger(std.err, cfg, true))
+	if err != nil {
+		fmt.Fprintf(std.err, "converter watch: %v\n", err)
+		return ExitFailed
+	}
+	defer b.close()
+
+	watcher, err := watch.New(b.service, logger, config)
+	if err != nil {
+		fmt.Fprintf(std.err, "converter watch: %v\n", err)
+		return ExitUsage
+	}
+	if err := watcher.Run(ctx); err != nil {
+		fmt.Fprintf(std.err, "converter watch: %v\n", err)
+		return ExitFailed
+	}
+	return ExitOK
+}
+
+// flagWatchConfig builds the folders from the command line
+func flagWatchConfig(config *watch.Config, dirs []string, to, out, options string) error {
+	if len(dirs) == 0 {
+		return errors.New("no folder to watch")
+	}
+	if to == "" {
+		return errors.New("--to is required")
+	}
+	targets, err := parseTargets(strings.Split(to, ","))
+	if err != nil {
+		return err
+	}
+	opts, err := parseOptions(options)
+	if err != nil {
+		return err
+	}
+	for _, dir := range dirs {
+		config.Folders = append(config.Folders, watch.Folder{Dir: dir, Targets: targets, OutputDir: out, Options: opts})
+	}
+	return nil
+}
+
+// loadWatchConfig reads the folders, and the settings not given as flags,
+// from a WatchConfig file. Relative paths are relative to the file.
+func loadWatchConfig(path string, config *watch.Config, fs *flag.FlagSet) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config: %w", err)
+	}
+	var file WatchConfig
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&file); err != nil {
+		return fmt.Errorf("invalid config %s: %w", path, err)
+	}
+
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if file.Interval > 0 && !set["interval"] {
+		config.Interval = time.Duration(file.Interval)
+	}
+	if file.Settle > 0 && !set["settle"] {
+		config.Settle = time.Duration(file.Settle)
+	}
+	base := filepath.Dir(path)
+	resolve := func(p string) string {
+		if p == "" || filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(base, p)
+	}
+	if file.State != "" && config.StatePath == "" {
+		config.StatePath = resolve(file.State)
+	}
+
+	if len(file.Folders) == 0 {
+		return fmt.Errorf("config %s lists no folders", path)
+	}
+	for _, folder := range file.Folders {
+		targets, err := parseTargets(folder.To)
+		if err != nil {
+			return fmt.Errorf("folder %s: %w", folder.Dir, err)
+		}
+		var opts domain.ConversionOptions
+		if folder.Options != nil {
+			opts = folder.Options.ToDomain()
+		}
+		config.Folders = append(config.Folders, watch.Folder{
+			Dir:       resolve(folder.Dir),
+			Targets:   targets,
+			OutputDir: resolve(folder.Out),
+			Options:   opts,
+		})
+	}
+	return nil
+}
+
+// parseTargets maps format names to output formats
+func parseTargets(names []string) ([]domain.Format, error) {
+	var targets []domain.Format
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		format, ok := domain.FormatFromExtension(name)
+		if !ok {
+			return nil, fmt.Errorf("unsupported output format %q", name)
+		}
+		targets = append(targets, format)
+	}
+	if len(targets) == 0 {
+		return nil, errors.New("no output format given")
+	}
+	return targets, nil
+}
+
+// defaultStatePath returns the journal path in the user's config directory
+func defaultStatePath() (string, error) {
+	configDir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "watch-state.json"), nil
+}
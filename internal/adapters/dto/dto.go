@@ -0,0 +1,250 @@
+// Package dto holds the JSON forms of conversion options and results shared
+// by the command line and the HTTP API
+package dto
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/eka026/File-Format-Converter/internal/domain"
+)
+
+// Options are conversion options as a flat JSON object, with the same fields
+// as the GUI's conversion options
+type Options struct {
+	Quality     int      `json:"quality,omitempty"`
+	Width       int      `json:"width,omitempty"`
+	Height      int      `json:"height,omitempty"`
+	PageSize    string   `json:"pageSize,omitempty"`
+	Orientation string   `json:"orientation,omitempty"`
+	Margins     *Margins `json:"margins,omitempty"`
+	Sheets      []string `json:"sheets,omitempty"`
+	Collision   string   `json:"collision,omitempty"`
+	// Transforms run in order on images before the width and height bound
+	Transforms []Transform `json:"transforms,omitempty"`
+	// Subsampling, Compression and WebPExtended are the JPEG, PNG and WebP
+	// encoder settings
+	Subsampling  string `json:"subsampling,omitempty"`
+	Compression  string `json:"compression,omitempty"`
+	WebPExtended bool   `json:"webpExtended,omitempty"`
+	// Metadata is the strip, keep or copyright metadata policy of image
+	// output; IgnoreOrientation skips turning images upright
+	Metadata          string `json:"metadata,omitempty"`
+	IgnoreOrientation bool   `json:"ignoreOrientation,omitempty"`
+}
+
+// Transform is one image transform step. In JSON it is either an object with
+// these fields or the command line's short form, such as "rotate=90".
+type Transform struct {
+	Kind     string  `json:"kind"`
+	Width    int     `json:"width,omitempty"`
+	Height   int     `json:"height,omitempty"`
+	Mode     string  `json:"mode,omitempty"`
+	Filter   string  `json:"filter,omitempty"`
+	X        int     `json:"x,omitempty"`
+	Y        int     `json:"y,omitempty"`
+	Centered bool    `json:"centered,omitempty"`
+	Aspect   string  `json:"aspect,omitempty"`
+	Angle    float64 `json:"angle,omitempty"`
+	Flip     string  `json:"flip,omitempty"`
+}
+
+// UnmarshalJSON accepts a transform object or its short form
+func (t *Transform) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(`"`)) {
+		var spec string
+		if err := json.Unmarshal(data, &spec); err != nil {
+			return err
+		}
+		parsed, err := domain.ParseImageTransform(spec)
+		if err != nil {
+			return err
+		}
+		*t = NewTransform(parsed)
+		return nil
+	}
+	type plain Transform
+	return json.Unmarshal(data, (*plain)(t))
+}
+
+// NewTransform converts a domain image transform
+func NewTransform(t domain.ImageTransform) Transform {
+	return Transform{
+		Kind:     string(t.Kind),
+		Width:    t.Width,
+		Height:   t.Height,
+		Mode:     string(t.Mode),
+		Filter:   string(t.Filter),
+		X:        t.X,
+		Y:        t.Y,
+		Centered: t.Centered,
+		Aspect:   t.Aspect,
+		Angle:    t.Angle,
+		Flip:     string(t.Flip),
+	}
+}
+
+// ToDomain converts the transform into a domain image transform
+func (t Transform) ToDomain() domain.ImageTransform {
+	return domain.ImageTransform{
+		Kind:     domain.TransformKind(t.Kind),
+		Width:    t.Width,
+		Height:   t.Height,
+		Mode:     domain.ResizeMode(t.Mode),
+		Filter:   domain.ResampleFilter(t.Filter),
+		X:        t.X,
+		Y:        t.Y,
+		Centered: t.Centered,
+		Aspect:   t.Aspect,
+		Angle:    t.Angle,
+		Flip:     domain.FlipDirection(t.Flip),
+	}
+}
+
+// Margins are PDF page margins in inches
+type Margins struct {
+	Top    float64 `json:"top"`
+	Right  float64 `json:"right"`
+	Bottom float64 `json:"bottom"`
+	Left   float64 `json:"left"`
+}
+
+// ToDomain converts the options into domain conversion options
+func (o Options) ToDomain() domain.ConversionOptions {
+	opts := domain.ConversionOptions{
+		Image: domain.ImageOptions{
+			Quality:           o.Quality,
+			Width:             o.Width,
+			Height:            o.Height,
+			Subsampling:       domain.ChromaSubsampling(o.Subsampling),
+			Compression:       domain.CompressionLevel(o.Compression),
+			WebPExtended:      o.WebPExtended,
+			Metadata:          domain.MetadataPolicy(o.Metadata),
+			IgnoreOrientation: o.IgnoreOrientation,
+		},
+		Page: domain.PageOptions{
+			Size:        domain.PageSize(o.PageSize),
+			Orientation: domain.Orientation(o.Orientation),
+		},
+		Sheet: domain.SheetOptions{
+			Sheets: o.Sheets,
+		},
+		Output: domain.OutputOptions{
+			Collision: domain.CollisionPolicy(o.Collision),
+		},
+	}
+	for _, t := range o.Transforms {
+		opts.Image.Transforms = append(opts.Image.Transforms, t.ToDomain())
+	}
+	if o.Margins != nil {
+		opts.Page.Margins = &domain.Margins{
+			Top:    o.Margins.Top,
+			Right:  o.Margins.Right,
+			Bottom: o.Margins.Bottom,
+			Left:   o.Margins.Left,
+		}
+	}
+	return opts
+}
+
+// Result is the JSON form of one conversion
+type Result struct {
+	Input      string         `json:"input"`
+	Output     string         `json:"output,omitempty"`
+	Success    bool           `json:"success"`
+	Skipped    bool           `json:"skipped,omitempty"`
+	Cached     bool           `json:"cached,omitempty"`
+	Error      string         `json:"error,omitempty"`
+	Code       string         `json:"code,omitempty"`
+	Retryable  bool           `json:"retryable,omitempty"`
+	Warnings   []string       `json:"warnings,omitempty"`
+	DurationMs int64          `json:"durationMs"`
+	InputType  string         `json:"inputType,omitempty"`
+	InputSize  int64          `json:"inputSize,omitempty"`
+	OutputSize int64          `json:"outputSize,omitempty"`
+	Engines    []Engine       `json:"engines,omitempty"`
+	Pages      int            `json:"pages,omitempty"`
+	Sheets     int            `json:"sheets,omitempty"`
+	Images     int            `json:"images,omitempty"`
+	Retries    int            `json:"retries,omitempty"`
+	Fidelity   []FidelityNote `json:"fidelity,omitempty"`
+	Encoder    *Encoder       `json:"encoder,omitempty"`
+}
+
+// Encoder is the encoder settings of an image output, so it can be
+// reproduced with the same options
+type Encoder struct {
+	Format      string `json:"format"`
+	Quality     int    `json:"quality,omitempty"`
+	Subsampling string `json:"subsampling,omitempty"`
+	Compression string `json:"compression,omitempty"`
+	Extended    bool   `json:"extended,omitempty"`
+}
+
+// Engine names an engine of the conversion route and its version
+type Engine struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// FidelityNote reports content an engine skipped or approximated
+type FidelityNote struct {
+	Engine  string `json:"engine,omitempty"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// NewResult converts the domain result of converting input
+func NewResult(input string, result domain.Result) Result {
+	converted := Result{
+		Input:      input,
+		Output:     result.OutputPath,
+		Success:    result.Success,
+		Skipped:    result.Skipped,
+		Cached:     result.Cached,
+		Warnings:   result.Warnings,
+		DurationMs: result.Duration.Milliseconds(),
+		InputType:  string(result.InputType),
+		InputSize:  result.InputSize,
+		OutputSize: result.OutputSize,
+		Pages:      result.Counts.Pages,
+		Sheets:     result.Counts.Sheets,
+		Images:     result.Counts.Images,
+	}
+	if !result.Success {
+		err := result.Error
+		if err == nil {
+			err = fmt.Errorf("conversion failed")
+		}
+		code := domain.ErrorCodeOf(err)
+		converted.Error = err.Error()
+		converted.Code = string(code)
+		converted.Retryable = code.Retryable()
+	}
+	for _, engine := range result.Engines {
+		converted.Engines = append(converted.Engines, Engine{Name: engine.Name, Version: engine.Version})
+	}
+	for _, attempt := range result.Attempts {
+		if attempt.Number > 1 {
+			converted.Retries++
+		}
+	}
+	if encoder := result.Encoder; encoder != nil {
+		converted.Encoder = &Encoder{
+			Format:      string(encoder.Format),
+			Quality:     encoder.Quality,
+			Subsampling: string(encoder.Subsampling),
+			Compression: string(encoder.Compression),
+			Extended:    encoder.Extended,
+		}
+	}
+	for _, warning := range result.Fidelity {
+		converted.Fidelity = append(converted.Fidelity, FidelityNote{
+			Engine:  warning.Engine,
+			Code:    string(warning.Code),
+			Message: warning.Message,
+		})
+	}
+	return converted
+}
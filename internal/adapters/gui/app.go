@@ -5,22 +5,29 @@ package gui
 // All file I/O operations use the local filesystem only.
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/eka026/File-Format-Converter/internal/adapters/browser"
+	"github.com/eka026/File-Format-Converter/internal/adapters/cache"
 	"github.com/eka026/File-Format-Converter/internal/adapters/filesystem"
 	"github.com/eka026/File-Format-Converter/internal/adapters/logger"
 	"github.com/eka026/File-Format-Converter/internal/adapters/progress"
+	"github.com/eka026/File-Format-Converter/internal/adapters/sniffer"
+	"github.com/eka026/File-Format-Converter/internal/config"
 	"github.com/eka026/File-Format-Converter/internal/domain"
+	"github.com/eka026/File-Format-Converter/internal/engines"
 	"github.com/eka026/File-Format-Converter/internal/engines/document"
-	"github.com/eka026/File-Format-Converter/internal/engines/image"
-	"github.com/eka026/File-Format-Converter/internal/engines/spreadsheet"
+	"github.com/eka026/File-Format-Converter/internal/scheduler"
 )
 
 const (
@@ -30,27 +37,210 @@ const (
 )
 
 // ConversionResult represents the result of a file conversion
+// Failed results carry the stable error code (see domain.ErrorCode) and
+// whether retrying the same conversion may succeed
 type ConversionResult struct {
-	Success    bool   `json:"success"`
-	OutputPath string `json:"outputPath,omitempty"`
+	Success    bool     `json:"success"`
+	OutputPath string   `json:"outputPath,omitempty"`
+	Error      string   `json:"error,omitempty"`
+	Code       string   `json:"code,omitempty"`
+	Retryable  bool     `json:"retryable,omitempty"`
+	Skipped    bool     `json:"skipped,omitempty"`
+	Cached     bool     `json:"cached,omitempty"`
+	Warnings   []string `json:"warnings,omitempty"`
+	// Attempts lists the engine calls made, including retried failures
+	Attempts []AttemptInfo `json:"attempts,omitempty"`
+	// The remaining fields describe the conversion; see domain.Result
+	DurationMs int64          `json:"durationMs,omitempty"`
+	InputType  string         `json:"inputType,omitempty"`
+	InputSize  int64          `json:"inputSize,omitempty"`
+	OutputSize int64          `json:"outputSize,omitempty"`
+	Engines    []EngineInfo   `json:"engines,omitempty"`
+	Pages      int            `json:"pages,omitempty"`
+	Sheets     int            `json:"sheets,omitempty"`
+	Images     int            `json:"images,omitempty"`
+	Stages     []StageInfo    `json:"stages,omitempty"`
+	Fidelity   []FidelityInfo `json:"fidelity,omitempty"`
+	Encoder    *EncoderInfo   `json:"encoder,omitempty"`
+}
+
+// EngineInfo names an engine of the conversion route and its version
+type EngineInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// StageInfo is the time an engine spent in one conversion stage
+type StageInfo struct {
+	Engine     string `json:"engine"`
+	Stage      string `json:"stage"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+// EncoderInfo is the encoder settings of an image output
+type EncoderInfo struct {
+	Format      string `json:"format"`
+	Quality     int    `json:"quality,omitempty"`
+	Subsampling string `json:"subsampling,omitempty"`
+	Compression string `json:"compression,omitempty"`
+	Extended    bool   `json:"extended,omitempty"`
+}
+
+// FidelityInfo reports content an engine skipped or approximated
+type FidelityInfo struct {
+	Engine  string `json:"engine,omitempty"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// AttemptInfo describes one engine call of a conversion to the frontend
+type AttemptInfo struct {
+	Operation  string `json:"operation"`
+	Engine     string `json:"engine"`
+	From       string `json:"from,omitempty"`
+	To         string `json:"to,omitempty"`
+	Number     int    `json:"number"`
+	DurationMs int64  `json:"durationMs"`
 	Error      string `json:"error,omitempty"`
+	Code       string `json:"code,omitempty"`
+}
+
+// ConversionOptions carries per-conversion settings from the frontend
+// Zero values select the engine defaults
+type ConversionOptions struct {
+	Quality     int          `json:"quality,omitempty"`
+	Width       int          `json:"width,omitempty"`
+	Height      int          `json:"height,omitempty"`
+	PageSize    string       `json:"pageSize,omitempty"`
+	Orientation string       `json:"orientation,omitempty"`
+	Margins     *PageMargins `json:"margins,omitempty"`
+	Sheets      []string     `json:"sheets,omitempty"`
+	Collision   string       `json:"collision,omitempty"`
+	// Transforms run in order on images before the width and height bound
+	Transforms []ImageTransform `json:"transforms,omitempty"`
+	// Subsampling, Compression and WebPExtended are the JPEG, PNG and WebP
+	// encoder settings
+	Subsampling  string `json:"subsampling,omitempty"`
+	Compression  string `json:"compression,omitempty"`
+	WebPExtended bool   `json:"webpExtended,omitempty"`
+	// Metadata is the strip, keep or copyright metadata policy of image
+	// output; IgnoreOrientation skips turning images upright
+	Metadata          string `json:"metadata,omitempty"`
+	IgnoreOrientation bool   `json:"ignoreOrientation,omitempty"`
+}
+
+// ImageTransform is one resize, crop, rotate or flip step
+type ImageTransform struct {
+	Kind     string  `json:"kind"`
+	Width    int     `json:"width,omitempty"`
+	Height   int     `json:"height,omitempty"`
+	Mode     string  `json:"mode,omitempty"`
+	Filter   string  `json:"filter,omitempty"`
+	X        int     `json:"x,omitempty"`
+	Y        int     `json:"y,omitempty"`
+	Centered bool    `json:"centered,omitempty"`
+	Aspect   string  `json:"aspect,omitempty"`
+	Angle    float64 `json:"angle,omitempty"`
+	Flip     string  `json:"flip,omitempty"`
+}
+
+// PageMargins are PDF page margins in inches
+type PageMargins struct {
+	Top    float64 `json:"top"`
+	Right  float64 `json:"right"`
+	Bottom float64 `json:"bottom"`
+	Left   float64 `json:"left"`
+}
+
+// toDomain converts the frontend options into domain conversion options
+func (o ConversionOptions) toDomain() domain.ConversionOptions {
+	opts := domain.ConversionOptions{
+		Image: domain.ImageOptions{
+			Quality:           o.Quality,
+			Width:             o.Width,
+			Height:            o.Height,
+			Subsampling:       domain.ChromaSubsampling(o.Subsampling),
+			Compression:       domain.CompressionLevel(o.Compression),
+			WebPExtended:      o.WebPExtended,
+			Metadata:          domain.MetadataPolicy(o.Metadata),
+			IgnoreOrientation: o.IgnoreOrientation,
+		},
+		Page: domain.PageOptions{
+			Size:        domain.PageSize(o.PageSize),
+			Orientation: domain.Orientation(o.Orientation),
+		},
+		Sheet: domain.SheetOptions{
+			Sheets: o.Sheets,
+		},
+		Output: domain.OutputOptions{
+			Collision: domain.CollisionPolicy(o.Collision),
+		},
+	}
+	for _, t := range o.Transforms {
+		opts.Image.Transforms = append(opts.Image.Transforms, domain.ImageTransform{
+			Kind:     domain.TransformKind(t.Kind),
+			Width:    t.Width,
+			Height:   t.Height,
+			Mode:     domain.ResizeMode(t.Mode),
+			Filter:   domain.ResampleFilter(t.Filter),
+			X:        t.X,
+			Y:        t.Y,
+			Centered: t.Centered,
+			Aspect:   t.Aspect,
+			Angle:    t.Angle,
+			Flip:     domain.FlipDirection(t.Flip),
+		})
+	}
+	if o.Margins != nil {
+		opts.Page.Margins = &domain.Margins{
+			Top:    o.Margins.Top,
+			Right:  o.Margins.Right,
+			Bottom: o.Margins.Bottom,
+			Left:   o.Margins.Left,
+		}
+	}
+	return opts
+}
+
+// SupportedInputType describes the output formats available for one input file type
+type SupportedInputType struct {
+	Type       string   `json:"type"`
+	Extensions []string `json:"extensions"`
+	Formats    []string `json:"formats"`
 }
 
 // App represents the GUI application adapter
 type App struct {
-	ctx               context.Context
-	converterService  *domain.ConverterService
-	spreadsheetEngine domain.IConverter
-	documentEngine    domain.IConverter
-	imageEngine       domain.IConverter
-	headlessBrowser   *browser.HeadlessBrowser
-	logger            domain.Logger
-}
-
-// NewApp creates a new GUI application instance
-func NewApp() *App {
+	ctx              context.Context
+	config           *config.Config
+	converterService *domain.ConverterService
+	registry         *engines.Registry
+	browserMu        sync.Mutex
+	headlessBrowser  *browser.HeadlessBrowser
+	scheduler        *scheduler.Scheduler
+	cache            *cache.DiskCache
+	detector         domain.FileTypeDetector
+	logger           domain.Logger
+}
+
+// NewApp creates a new GUI application instance configured by cfg; nil uses
+// config.DefaultConfig()
+func NewApp(cfg *config.Config) *App {
+	if cfg == nil {
+		cfg = config.DefaultConfig()
+	}
+	level, _ := logger.ParseLogLevel(cfg.LogLevel)
+	jobs := scheduler.Default()
+	if cfg.Workers > 0 {
+		jobs = scheduler.New(cfg.Workers)
+	}
 	return &App{
-		logger: logger.NewDomainLoggerAdapter(logger.LogLevelInfo),
+		config: cfg,
+		// Engines registered by other packages are discovered here too
+		registry:  engines.Default(),
+		scheduler: jobs,
+		detector:  sniffer.NewSniffer(),
+		logger:    logger.NewDomainLoggerAdapter(level),
 	}
 }
 
@@ -58,23 +248,8 @@ func NewApp() *App {
 func (a *App) OnStartup(ctx context.Context) {
 	a.ctx = ctx
 
-	// Initialize headless browser
-	browser, err := browser.NewHeadlessBrowser()
-	if err != nil {
-		// Log error but don't fail startup - browser will be initialized lazily
-		a.logger.Error("Could not initialize headless browser", err)
-	} else {
-		a.headlessBrowser = browser
-	}
-
-	// Initialize engines
-	if a.headlessBrowser != nil {
-		if err := a.initializeEngines(); err != nil {
-			a.logger.Error("Could not initialize engines", err)
-		}
-	}
-
-	// Initialize ConverterService with engines
+	// Initialize ConverterService with engines. The headless browser is
+	// only launched by the first conversion that renders in it.
 	if err := a.initializeConverterService(); err != nil {
 		a.logger.Error("Could not initialize converter service", err)
 	}
@@ -88,14 +263,12 @@ func (a *App) OnDomReady(ctx context.Context) {
 // OnShutdown is called when the application shuts down
 func (a *App) OnShutdown(ctx context.Context) {
 	// Cleanup resources
+	a.browserMu.Lock()
 	if a.headlessBrowser != nil {
 		a.headlessBrowser.Close()
+		a.headlessBrowser = nil
 	}
-
-	// Close ImageEngine worker pool to prevent goroutine leaks
-	if imgEngine, ok := a.imageEngine.(*image.ImageEngine); ok {
-		imgEngine.Close()
-	}
+	a.browserMu.Unlock()
 
 	// Clean up all temp files on shutdown
 	if err := a.CleanupTempFiles(); err != nil {
@@ -106,27 +279,20 @@ func (a *App) OnShutdown(ctx context.Context) {
 // ConvertFile handles file conversion from the GUI
 // Returns the output file path on success
 // If outputPath is empty, shows a save dialog to let user choose location
-func (a *App) ConvertFile(sourcePath, targetFormat string) ConversionResult {
-	return a.ConvertFileWithPath(sourcePath, targetFormat, "")
+func (a *App) ConvertFile(sourcePath, targetFormat string, options ConversionOptions) ConversionResult {
+	return a.ConvertFileWithPath(sourcePath, targetFormat, "", options)
 }
 
 // ConvertFileWithPath handles file conversion with a specific output path
 // If outputPath is empty, shows a save dialog
-func (a *App) ConvertFileWithPath(sourcePath, targetFormat, outputPath string) ConversionResult {
+func (a *App) ConvertFileWithPath(sourcePath, targetFormat, outputPath string, options ConversionOptions) ConversionResult {
 	// Ensure ConverterService is initialized
 	if a.converterService == nil {
 		if err := a.initializeConverterService(); err != nil {
-			return ConversionResult{
-				Success: false,
-				Error:   fmt.Sprintf("Failed to initialize converter service: %v", err),
-			}
+			return failedResult(domain.Errorf(domain.ErrorCodeEngineUnavailable, "Failed to initialize converter service: %w", err), nil)
 		}
 	}
 
-	// Track if output is in temp directory for cleanup scheduling
-	var isTempFile bool
-	var tempFilePath string
-
 	// If no output path provided, use default location (skip dialog to avoid WebSocket issues)
 	if outputPath == "" {
 		// Use default location: same directory as source file, or Downloads folder if source is in temp
@@ -137,127 +303,95 @@ func (a *App) ConvertFileWithPath(sourcePath, targetFormat, outputPath string) C
 		cleanSourcePath := filepath.Clean(sourcePath)
 		cleanTempDir := filepath.Clean(tempDir)
 		if strings.HasPrefix(cleanSourcePath, cleanTempDir) {
-			// Get user's Downloads folder
-			homeDir, err := os.UserHomeDir()
-			if err == nil {
-				downloadsDir := filepath.Join(homeDir, "Downloads")
-				baseName := strings.TrimSuffix(filepath.Base(sourcePath), filepath.Ext(sourcePath))
-				formatExt := strings.ToLower(targetFormat)
-				// Normalize JPEG format (handle both "jpg" and "jpeg")
-				if formatExt == "jpg" {
-					formatExt = "jpeg"
-				}
-				outputPath = filepath.Join(downloadsDir, baseName+"."+formatExt)
+			if downloadsPath, ok := a.downloadsOutputPath(filepath.Base(sourcePath), targetFormat); ok {
+				outputPath = downloadsPath
 			}
 		}
 	}
 
-	// Check if output path is in temp directory
-	tempDir := filepath.Join(os.TempDir(), "file-format-converter")
-	cleanOutputPath := filepath.Clean(outputPath)
-	cleanTempDir := filepath.Clean(tempDir)
-	if strings.HasPrefix(cleanOutputPath, cleanTempDir) {
-		isTempFile = true
-		tempFilePath = outputPath
-	}
-
 	// Create output directory if it doesn't exist
 	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
-		return ConversionResult{
-			Success: false,
-			Error:   fmt.Sprintf("Failed to create output directory: %v", err),
-		}
+		return failedResult(domain.Errorf(domain.ErrorCodeOutputNotWritable, "Failed to create output directory: %w", err), nil)
 	}
 
-	// Convert directly to final destination (no temp file copy needed)
-	ctx := a.getContext()
-	result := a.converterService.Convert(ctx, sourcePath, outputPath)
+	// The service writes atomically and applies the collision policy, so a
+	// failed conversion never touches an existing file at outputPath.
+	// Interactive conversions run ahead of queued batch work.
+	ctx := scheduler.WithPriority(a.getContext(), scheduler.PriorityHigh)
+	result := a.converterService.Convert(ctx, sourcePath, outputPath, options.toDomain())
 	if !result.Success {
-		// Clean up output file on error if it was created
-		os.Remove(outputPath)
-		errorMsg := "Conversion failed"
-		if result.Error != nil {
-			errorMsg = result.Error.Error()
-		}
-		return ConversionResult{
-			Success: false,
-			Error:   errorMsg,
+		return toConversionResult(result)
+	}
+
+	// If the final output is in temp, schedule its cleanup
+	tempDir := filepath.Join(os.TempDir(), "file-format-converter")
+	if strings.HasPrefix(filepath.Clean(result.OutputPath), filepath.Clean(tempDir)) && !result.Skipped {
+		go a.scheduleTempFileCleanup(result.OutputPath)
+	}
+
+	return toConversionResult(result)
+}
+
+// ConvertFromBytes converts an uploaded file held in memory without staging it on disk
+// The result is saved to the user's Downloads folder (or the temp directory as a fallback)
+func (a *App) ConvertFromBytes(fileName string, fileData []byte, targetFormat string, options ConversionOptions) ConversionResult {
+	// Ensure ConverterService is initialized
+	if a.converterService == nil {
+		if err := a.initializeConverterService(); err != nil {
+			return failedResult(domain.Errorf(domain.ErrorCodeEngineUnavailable, "Failed to initialize converter service: %w", err), nil)
 		}
 	}
 
-	// If the final output is in temp, mark it for cleanup
-	if isTempFile {
-		// Schedule cleanup of temp file (could be done on app shutdown or after a delay)
-		go a.scheduleTempFileCleanup(tempFilePath)
+	if _, ok := domain.FormatFromExtension(targetFormat); !ok {
+		return failedResult(domain.Errorf(domain.ErrorCodeUnsupportedFormat, "Unsupported target format: %s", targetFormat), nil)
 	}
 
-	return ConversionResult{
-		Success:    true,
-		OutputPath: outputPath,
+	// Identify the upload from its content, using the file name only for its extension
+	detection, err := sniffer.DetectReader(bytes.NewReader(fileData), int64(len(fileData)), fileName)
+	if err != nil || detection.FileType == "" {
+		return failedResult(domain.Errorf(domain.ErrorCodeUnsupportedFormat, "Unsupported file type: %s", fileName), nil)
 	}
-}
 
-// BatchConvertFiles handles batch file conversion from the GUI
-// Uses parallel processing with worker pools to utilize all CPU cores for images, documents, and spreadsheets
-func (a *App) BatchConvertFiles(files []string, targetFormat string) []ConversionResult {
-	if len(files) == 0 {
-		return nil
+	outputPath, ok := a.downloadsOutputPath(fileName, targetFormat)
+	isTempFile := !ok
+	if isTempFile {
+		outputPath = filepath.Join(os.TempDir(), "file-format-converter", "output", filepath.Base(a.generateOutputPath(fileName, targetFormat)))
 	}
 
-	// Detect file types and check if all are the same type with short-circuiting
-	// Detect first file type
-	firstType := a.detectFileType(files[0])
+	// Create output directory if it doesn't exist
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return failedResult(domain.Errorf(domain.ErrorCodeOutputNotWritable, "Failed to create output directory: %w", err), nil)
+	}
 
-	// Check remaining files, short-circuiting on first mismatch
-	allSameType := true
-	for i := 1; i < len(files); i++ {
-		fileType := a.detectFileType(files[i])
-		if fileType != firstType {
-			allSameType = false
-			break
-		}
+	// Interactive conversions run ahead of queued batch work
+	ctx := scheduler.WithPriority(a.getContext(), scheduler.PriorityHigh)
+	result := a.converterService.ConvertStreamToFile(ctx, bytes.NewReader(fileData), detection.FileType, outputPath, options.toDomain())
+	result.Warnings = append(detection.Warnings, result.Warnings...)
+	if !result.Success {
+		return toConversionResult(result)
 	}
 
-	// Use parallel processing for homogeneous batches
-	if allSameType {
-		switch firstType {
-		case domain.FileTypeJPEG, domain.FileTypePNG, domain.FileTypeWEBP:
-			// All files are images - use parallel image conversion
-			if a.imageEngine != nil {
-				if imgEngine, ok := a.imageEngine.(*image.ImageEngine); ok {
-					return a.batchConvertImagesParallel(imgEngine, files, targetFormat)
-				} else {
-					a.logger.Error("Image engine type assertion failed, falling back to sequential processing", fmt.Errorf("expected *image.ImageEngine, got %T", a.imageEngine))
-				}
-			}
-		case domain.FileTypeDOCX:
-			// All files are documents - use parallel document conversion
-			if a.documentEngine != nil {
-				if docEngine, ok := a.documentEngine.(*document.DocumentEngine); ok {
-					return a.batchConvertDocumentsParallel(docEngine, files, targetFormat)
-				} else {
-					a.logger.Error("Document engine type assertion failed, falling back to sequential processing", fmt.Errorf("expected *document.DocumentEngine, got %T", a.documentEngine))
-				}
-			}
-		case domain.FileTypeXLSX:
-			// All files are spreadsheets - use parallel spreadsheet conversion
-			if a.spreadsheetEngine != nil {
-				if se, ok := a.spreadsheetEngine.(*spreadsheet.SpreadsheetEngine); ok {
-					return a.batchConvertSpreadsheetsParallel(se, files, targetFormat)
-				} else {
-					a.logger.Error("Spreadsheet engine type assertion failed, falling back to sequential processing", fmt.Errorf("expected *spreadsheet.SpreadsheetEngine, got %T", a.spreadsheetEngine))
-				}
-			}
-		}
+	if isTempFile && !result.Skipped {
+		go a.scheduleTempFileCleanup(result.OutputPath)
+	}
+
+	return toConversionResult(result)
+}
+
+// BatchConvertFiles handles batch file conversion from the GUI
+// ConverterService converts the files concurrently within each engine's limits
+// and emits per-file events (see fileEventNotifier)
+func (a *App) BatchConvertFiles(files []string, targetFormat string, options ConversionOptions) []ConversionResult {
+	if len(files) == 0 {
+		return nil
 	}
 
-	// Fall back to using ConverterService for mixed file types or if engines are not available
 	if a.converterService == nil {
 		if err := a.initializeConverterService(); err != nil {
 			// Last resort: sequential processing without service
 			results := make([]ConversionResult, len(files))
 			for i, file := range files {
-				results[i] = a.ConvertFile(file, targetFormat)
+				results[i] = a.ConvertFile(file, targetFormat, options)
 			}
 			return results
 		}
@@ -265,220 +399,67 @@ func (a *App) BatchConvertFiles(files []string, targetFormat string) []Conversio
 
 	// Use ConverterService for batch conversion
 	ctx := a.getContext()
-	domainResults := a.converterService.BatchConvert(ctx, files, targetFormat)
+	domainResults := a.converterService.BatchConvert(ctx, files, targetFormat, options.toDomain())
 	results := make([]ConversionResult, len(domainResults))
 	for i, domainResult := range domainResults {
-		if domainResult.Success {
-			results[i] = ConversionResult{
-				Success:    true,
-				OutputPath: domainResult.OutputPath,
-			}
-		} else {
-			errorMsg := "Conversion failed"
-			if domainResult.Error != nil {
-				errorMsg = domainResult.Error.Error()
-			}
-			results[i] = ConversionResult{
-				Success: false,
-				Error:   errorMsg,
-			}
-		}
+		results[i] = toConversionResult(domainResult)
 	}
 
 	return results
 }
 
-// batchConvertImagesParallel performs parallel batch conversion of images using worker pools
-func (a *App) batchConvertImagesParallel(
-	imgEngine *image.ImageEngine,
-	files []string,
-	targetFormat string,
-) []ConversionResult {
-	// Initialize image engine if needed
-	if imgEngine == nil {
-		if err := a.initializeImageEngine(); err != nil {
-			// Fall back to sequential if initialization fails
-			results := make([]ConversionResult, len(files))
-			for i, file := range files {
-				results[i] = a.ConvertFile(file, targetFormat)
-			}
-			return results
-		}
-		// Re-cast after initialization
-		if ie, ok := a.imageEngine.(*image.ImageEngine); ok {
-			imgEngine = ie
-		}
-	}
-
-	// Prepare batch conversion tasks
-	tasks := make([]image.BatchConversionTask, len(files))
-	for i, file := range files {
-		// Generate output path for each file
-		outputPath := a.generateOutputPath(file, targetFormat)
-
-		tasks[i] = image.BatchConversionTask{
-			InputPath:  file,
-			OutputPath: outputPath,
-			Index:      i,
+// GetSupportedFormats returns, for each accepted input type, the output formats
+// the registered engines can produce (directly or through multi-step routes)
+func (a *App) GetSupportedFormats() []SupportedInputType {
+	if a.converterService == nil {
+		if err := a.initializeConverterService(); err != nil {
+			return nil
 		}
 	}
 
-	// Perform parallel batch conversion using worker pool
-	batchResults := imgEngine.BatchConvert(tasks)
-
-	// Convert batch results to ConversionResult format
-	results := make([]ConversionResult, len(batchResults))
-	for i, batchResult := range batchResults {
-		if batchResult.Error != nil {
-			results[i] = ConversionResult{
-				Success: false,
-				Error:   batchResult.Error.Error(),
-			}
-		} else {
-			results[i] = ConversionResult{
-				Success:    true,
-				OutputPath: tasks[i].OutputPath,
-			}
+	matrix := a.converterService.GetConversionMatrix()
+	inputTypes := make([]SupportedInputType, 0, len(matrix))
+	for fileType, formats := range matrix {
+		names := make([]string, len(formats))
+		for i, format := range formats {
+			names[i] = strings.ToLower(string(format))
 		}
+		inputTypes = append(inputTypes, SupportedInputType{
+			Type:       strings.ToLower(string(fileType)),
+			Extensions: fileType.Extensions(),
+			Formats:    names,
+		})
 	}
 
-	return results
+	// Keep a stable order for the frontend
+	sort.Slice(inputTypes, func(i, j int) bool {
+		return inputTypes[i].Type < inputTypes[j].Type
+	})
+	return inputTypes
 }
 
-// batchConvertDocumentsParallel performs parallel batch conversion of documents using worker pools
-func (a *App) batchConvertDocumentsParallel(
-	docEngine *document.DocumentEngine,
-	files []string,
-	targetFormat string,
-) []ConversionResult {
-	// Initialize document engine if needed
-	if docEngine == nil {
-		if err := a.initializeDocumentEngine(); err != nil {
-			// Fall back to sequential if initialization fails
-			results := make([]ConversionResult, len(files))
-			for i, file := range files {
-				results[i] = a.ConvertFile(file, targetFormat)
-			}
-			return results
-		}
-		// Re-cast after initialization
-		if de, ok := a.documentEngine.(*document.DocumentEngine); ok {
-			docEngine = de
-		}
-	}
-
-	// Prepare batch conversion tasks
-	tasks := make([]document.BatchConversionTask, len(files))
-	for i, file := range files {
-		// Generate output path for each file
-		outputPath := a.generateOutputPath(file, targetFormat)
-
-		tasks[i] = document.BatchConversionTask{
-			InputPath:  file,
-			OutputPath: outputPath,
-			Index:      i,
-		}
-	}
-
-	// Perform parallel batch conversion using worker pool
-	batchResults := docEngine.BatchConvert(tasks)
-
-	// Convert batch results to ConversionResult format
-	results := make([]ConversionResult, len(batchResults))
-	for i, batchResult := range batchResults {
-		if batchResult.Error != nil {
-			results[i] = ConversionResult{
-				Success: false,
-				Error:   batchResult.Error.Error(),
-			}
-		} else {
-			results[i] = ConversionResult{
-				Success:    true,
-				OutputPath: tasks[i].OutputPath,
-			}
-		}
-	}
-
-	return results
+// GetSchedulerMetrics returns the queue depth and task counters of the shared conversion scheduler
+func (a *App) GetSchedulerMetrics() scheduler.Metrics {
+	return a.scheduler.Metrics()
 }
 
-// batchConvertSpreadsheetsParallel performs parallel batch conversion of spreadsheets using worker pools
-func (a *App) batchConvertSpreadsheetsParallel(
-	spreadsheetEngine *spreadsheet.SpreadsheetEngine,
-	files []string,
-	targetFormat string,
-) []ConversionResult {
-	// Initialize spreadsheet engine if needed
-	if spreadsheetEngine == nil {
-		if err := a.initializeSpreadsheetEngine(); err != nil {
-			// Fall back to sequential if initialization fails
-			results := make([]ConversionResult, len(files))
-			for i, file := range files {
-				results[i] = a.ConvertFile(file, targetFormat)
-			}
-			return results
-		}
-		// Re-cast after initialization
-		if se, ok := a.spreadsheetEngine.(*spreadsheet.SpreadsheetEngine); ok {
-			spreadsheetEngine = se
-		} else {
-			// Fall back to sequential if type assertion fails
-			results := make([]ConversionResult, len(files))
-			for i, file := range files {
-				results[i] = a.ConvertFile(file, targetFormat)
-			}
-			return results
-		}
-	}
-
-	// Prepare batch conversion tasks
-	tasks := make([]spreadsheet.BatchConversionTask, len(files))
-	for i, file := range files {
-		// Generate output path for each file
-		outputPath := a.generateOutputPath(file, targetFormat)
-
-		tasks[i] = spreadsheet.BatchConversionTask{
-			InputPath:  file,
-			OutputPath: outputPath,
-			Index:      i,
-		}
-	}
-
-	// Perform parallel batch conversion using worker pool
-	batchResults := spreadsheetEngine.BatchConvert(tasks)
-
-	// Convert batch results to ConversionResult format
-	results := make([]ConversionResult, len(batchResults))
-	for i, batchResult := range batchResults {
-		if batchResult.Error != nil {
-			results[i] = ConversionResult{
-				Success: false,
-				Error:   batchResult.Error.Error(),
-			}
-		} else {
-			results[i] = ConversionResult{
-				Success:    true,
-				OutputPath: tasks[i].OutputPath,
-			}
-		}
+// GetCacheStats returns the size and hit counters of the conversion cache
+func (a *App) GetCacheStats() cache.Stats {
+	if a.cache == nil {
+		return cache.Stats{}
 	}
-
-	return results
+	return a.cache.Stats()
 }
 
-// GetSupportedFormats returns supported formats for the GUI
-func (a *App) GetSupportedFormats() []string {
-	if a.converterService == nil {
-		// Fallback to default formats if service not initialized
-		return []string{"pdf", "html"}
+// ClearCache removes every cached conversion output
+func (a *App) ClearCache() error {
+	if a.cache == nil {
+		return nil
 	}
-
-	formats := a.converterService.GetSupportedFormats()
-	result := make([]string, len(formats))
-	for i, format := range formats {
-		result[i] = strings.ToLower(string(format))
+	if err := a.cache.Clear(); err != nil {
+		return fmt.Errorf("Failed to clear cache: %w", err)
 	}
-	return result
+	return nil
 }
 
 // OpenFile opens a file in the default system application
@@ -560,148 +541,100 @@ func (a *App) SaveFileFromBytes(fileName string, fileData []byte) (string, error
 	return tempFilePath, nil
 }
 
-// initializeEngines initializes all conversion engines
+// initializeEngines registers the built-in engines. They are constructed,
+// and the browser started, when a conversion first needs them.
 func (a *App) initializeEngines() error {
-	if err := a.initializeSpreadsheetEngine(); err != nil {
-		return fmt.Errorf("failed to initialize spreadsheet engine: %w", err)
-	}
-	if err := a.initializeDocumentEngine(); err != nil {
-		// Document engine initialization is optional - log warning but don't fail
-		a.logger.Error("Could not initialize document engine", err)
-	}
-	if err := a.initializeImageEngine(); err != nil {
-		// Image engine initialization is optional - log warning but don't fail
-		a.logger.Error("Could not initialize image engine", err)
-	}
-	return nil
-}
-
-// initializeSpreadsheetEngine initializes the spreadsheet conversion engine
-func (a *App) initializeSpreadsheetEngine() error {
+	return engines.RegisterBuiltins(a.registry, engines.BuiltinDeps{
+		// Batch work runs on the scheduler shared with the service
+		Scheduler: a.scheduler,
+		Browser:   a.browser,
+		Settings:  a.config.EngineSettings(),
+	})
+}
+
+// browser returns the shared headless browser, launching it on first use.
+// A failed launch is retried on the next call.
+func (a *App) browser() (*browser.HeadlessBrowser, error) {
+	a.browserMu.Lock()
+	defer a.browserMu.Unlock()
 	if a.headlessBrowser == nil {
-		browser, err := browser.NewHeadlessBrowser()
+		headlessBrowser, err := browser.NewHeadlessBrowser(a.config)
 		if err != nil {
-			return fmt.Errorf("failed to create headless browser: %w", err)
+			return nil, err
 		}
-		a.headlessBrowser = browser
+		a.headlessBrowser = headlessBrowser
 	}
-
-	htmlRenderer := spreadsheet.NewHTMLRenderer()
-	pdfGenerator := spreadsheet.NewPDFGenerator(a.headlessBrowser)
-	engine := spreadsheet.NewSpreadsheetEngine(htmlRenderer, pdfGenerator)
-
-	a.spreadsheetEngine = engine
-
-	return nil
-}
-
-// initializeDocumentEngine initializes the document conversion engine
-// Uses pure Go DOCX parsing + HTML rendering + headless browser for PDF generation
-func (a *App) initializeDocumentEngine() error {
-	if a.headlessBrowser == nil {
-		browser, err := browser.NewHeadlessBrowser()
-		if err != nil {
-			return fmt.Errorf("failed to create headless browser: %w", err)
-		}
-		a.headlessBrowser = browser
-	}
-
-	// Create document engine with pure Go approach (no WASM)
-	// Uses the same headless browser as spreadsheet engine
-	engine := document.NewDocumentEngine(a.headlessBrowser)
-	a.documentEngine = engine
-
-	return nil
-}
-
-// initializeImageEngine initializes the image conversion engine
-func (a *App) initializeImageEngine() error {
-	// Create worker pool for image engine
-	workerPool := image.NewWorkerPool()
-
-	engine := image.NewImageEngine(workerPool)
-	a.imageEngine = engine
-
-	return nil
+	return a.headlessBrowser, nil
 }
 
 // initializeConverterService initializes the ConverterService with all engines
 func (a *App) initializeConverterService() error {
 	// Create adapters for domain interfaces
-	domainLogger := logger.NewDomainLoggerAdapter(logger.LogLevelInfo)
-	domainProgressNotifier := progress.NewDomainProgressNotifierAdapter()
+	domainProgressNotifier := newFileEventNotifier(progress.NewDomainProgressNotifierAdapter(), a.getContext)
 	domainFileWriter := filesystem.NewDomainFileWriterAdapter("")
 
-	// Build engines map
-	engines := make(map[domain.FileType]domain.IConverter)
-
-	// Add engines if they're initialized
-	if a.spreadsheetEngine != nil {
-		engines[domain.FileTypeXLSX] = a.spreadsheetEngine
+	if err := a.initializeEngines(); err != nil {
+		return fmt.Errorf("failed to register engines: %w", err)
 	}
-	if a.documentEngine != nil {
-		engines[domain.FileTypeDOCX] = a.documentEngine
+
+	serviceOptions := []domain.ServiceOption{
+		// Every engine, built-in or contributed by another package, comes from the registry
+		domain.WithEngineSource(a.registry),
+		domain.WithFileTypeDetector(a.detector),
+		domain.WithScheduler(a.scheduler),
+		// Never replace a user's existing file unless they ask for it
+		domain.WithCollisionPolicy(domain.CollisionSuffix),
 	}
-	if a.imageEngine != nil {
-		engines[domain.FileTypeJPEG] = a.imageEngine
-		engines[domain.FileTypePNG] = a.imageEngine
-		engines[domain.FileTypeWEBP] = a.imageEngine
+	// The configured settings override the defaults above
+	serviceOptions = append(serviceOptions, a.config.ServiceOptions()...)
+
+	// Conversions work without the cache, so a failure to open it is not fatal
+	if a.config.NoCache {
+		a.logger.Info("Conversion cache disabled by configuration")
+	} else if cacheDir, err := cache.DefaultDir(); err != nil {
+		a.logger.Error("Conversion cache disabled", err)
+	} else if diskCache, err := cache.NewDiskCache(cacheDir, a.config.CacheMaxBytes); err != nil {
+		a.logger.Error("Conversion cache disabled", err)
+	} else {
+		a.cache = diskCache
+		serviceOptions = append(serviceOptions, domain.WithCache(diskCache))
 	}
 
 	// Create ConverterService
 	a.converterService = domain.NewConverterService(
-		engines,
-		domainLogger,
+		nil,
+		a.logger,
 		domainProgressNotifier,
 		domainFileWriter,
+		serviceOptions...,
 	)
 
 	return nil
 }
 
-// detectFileType detects the file type from the file extension
+// detectFileType detects the file type from the file content,
+// falling back to the extension when the file cannot be read
 func (a *App) detectFileType(filePath string) domain.FileType {
-	ext := strings.ToLower(filepath.Ext(filePath))
-	switch ext {
-	case ".docx":
-		return domain.FileTypeDOCX
-	case ".xlsx":
-		return domain.FileTypeXLSX
-	case ".jpeg", ".jpg":
-		return domain.FileTypeJPEG
-	case ".png":
-		return domain.FileTypePNG
-	case ".webp":
-		return domain.FileTypeWEBP
-	default:
-		return ""
+	if a.detector != nil {
+		if detection, err := a.detector.Detect(filePath); err == nil {
+			return detection.FileType
+		}
 	}
+	fileType, _ := domain.FileTypeFromExtension(filepath.Ext(filePath))
+	return fileType
 }
 
 // validateDOCXFile validates a .docx file (FR-05 requirement)
 // Uses the consolidated validation function from the document package
 func (a *App) validateDOCXFile(filePath string) error {
-	return document.ValidateDOCX(filePath)
+	return document.ValidateDOCX(filePath, a.config.ResourceLimits())
 }
 
-// validateImageFile validates a JPEG, PNG, or WebP image file (FR-08 requirement)
+// validateImageFile validates a JPEG, PNG, WebP, GIF, BMP or TIFF image file (FR-08 requirement)
+// The file signature (magic bytes) decides the format; the extension is not trusted
 func (a *App) validateImageFile(filePath string, fileType domain.FileType) error {
-	ext := strings.ToLower(filepath.Ext(filePath))
-
-	// Validate extension matches file type
 	switch fileType {
-	case domain.FileTypeJPEG:
-		if ext != ".jpeg" && ext != ".jpg" {
-			return fmt.Errorf("file does not have .jpeg or .jpg extension")
-		}
-	case domain.FileTypePNG:
-		if ext != ".png" {
-			return fmt.Errorf("file does not have .png extension")
-		}
-	case domain.FileTypeWEBP:
-		if ext != ".webp" {
-			return fmt.Errorf("file does not have .webp extension")
-		}
+	case domain.FileTypeJPEG, domain.FileTypePNG, domain.FileTypeWEBP, domain.FileTypeGIF, domain.FileTypeBMP, domain.FileTypeTIFF:
 	default:
 		return fmt.Errorf("unsupported image file type: %s", fileType)
 	}
@@ -724,37 +657,122 @@ func (a *App) validateImageFile(filePath string, fileType domain.FileType) error
 	defer file.Close()
 
 	// Read first few bytes to check file signature
-	signature := make([]byte, 12)
-	if _, err := file.Read(signature); err != nil {
+	signature := make([]byte, 14)
+	n, err := io.ReadFull(file, signature)
+	if err != nil && err != io.ErrUnexpectedEOF {
 		return fmt.Errorf("cannot read file: %w", err)
 	}
 
-	// Validate JPEG signature: FF D8 FF
-	if fileType == domain.FileTypeJPEG {
-		if signature[0] != 0xFF || signature[1] != 0xD8 || signature[2] != 0xFF {
-			return fmt.Errorf("invalid JPEG file: incorrect file signature")
-		}
+	if detected := sniffer.DetectBytes(signature[:n]); detected != fileType {
+		return fmt.Errorf("invalid %s file: incorrect file signature", fileType)
 	}
 
-	// Validate PNG signature: 89 50 4E 47 0D 0A 1A 0A
-	if fileType == domain.FileTypePNG {
-		pngSignature := []byte{0x89, 0x50, 0x4E, 0x47, 0x0D, 0x0A, 0x1A, 0x0A}
-		for i := 0; i < 8; i++ {
-			if signature[i] != pngSignature[i] {
-				return fmt.Errorf("invalid PNG file: incorrect file signature")
-			}
+	return nil
+}
+
+// toConversionResult converts a domain result into the frontend representation
+func toConversionResult(result domain.Result) ConversionResult {
+	converted := ConversionResult{
+		Success:    true,
+		OutputPath: result.OutputPath,
+		Skipped:    result.Skipped,
+		Cached:     result.Cached,
+		Warnings:   result.Warnings,
+	}
+	if !result.Success {
+		converted = failedResult(result.Error, result.Warnings)
+	}
+	converted.Attempts = toAttemptInfos(result.Attempts)
+
+	converted.DurationMs = result.Duration.Milliseconds()
+	converted.InputType = string(result.InputType)
+	converted.InputSize = result.InputSize
+	converted.OutputSize = result.OutputSize
+	converted.Pages = result.Counts.Pages
+	converted.Sheets = result.Counts.Sheets
+	converted.Images = result.Counts.Images
+	for _, engine := range result.Engines {
+		converted.Engines = append(converted.Engines, EngineInfo{Name: engine.Name, Version: engine.Version})
+	}
+	for _, stage := range result.Stages {
+		converted.Stages = append(converted.Stages, StageInfo{
+			Engine:     stage.Engine,
+			Stage:      stage.Stage,
+			DurationMs: stage.Duration.Milliseconds(),
+		})
+	}
+	if encoder := result.Encoder; encoder != nil {
+		converted.Encoder = &EncoderInfo{
+			Format:      string(encoder.Format),
+			Quality:     encoder.Quality,
+			Subsampling: string(encoder.Subsampling),
+			Compression: string(encoder.Compression),
+			Extended:    encoder.Extended,
+		}
+	}
+	for _, warning := range result.Fidelity {
+		converted.Fidelity = append(converted.Fidelity, FidelityInfo{
+			Engine:  warning.Engine,
+			Code:    string(warning.Code),
+			Message: warning.Message,
+		})
+	}
+	return converted
+}
+
+// toAttemptInfos maps the engine attempts of a domain result to the frontend
+func toAttemptInfos(attempts []domain.Attempt) []AttemptInfo {
+	if len(attempts) == 0 {
+		return nil
+	}
+	infos := make([]AttemptInfo, len(attempts))
+	for i, attempt := range attempts {
+		infos[i] = AttemptInfo{
+			Operation:  string(attempt.Operation),
+			Engine:     attempt.Engine,
+			From:       string(attempt.Edge.From),
+			To:         string(attempt.Edge.To),
+			Number:     attempt.Number,
+			DurationMs: attempt.Duration.Milliseconds(),
+		}
+		if attempt.Err != nil {
+			infos[i].Error = attempt.Err.Error()
+			infos[i].Code = string(domain.ErrorCodeOf(attempt.Err))
 		}
 	}
+	return infos
+}
 
-	// Validate WebP signature: RIFF....WEBP
-	if fileType == domain.FileTypeWEBP {
-		// WebP files start with "RIFF" followed by 4 bytes of file size, then "WEBP"
-		if string(signature[0:4]) != "RIFF" || string(signature[8:12]) != "WEBP" {
-			return fmt.Errorf("invalid WebP file: incorrect file signature")
-		}
+// failedResult builds the frontend result of a failed conversion, tagging it
+// with the error's code so the frontend can tell what is worth retrying
+func failedResult(err error, warnings []string) ConversionResult {
+	if err == nil {
+		err = errors.New("Conversion failed")
 	}
+	code := domain.ErrorCodeOf(err)
+	return ConversionResult{
+		Success:   false,
+		Error:     err.Error(),
+		Code:      string(code),
+		Retryable: code.Retryable(),
+		Warnings:  warnings,
+	}
+}
 
-	return nil
+// downloadsOutputPath returns the path in the user's Downloads folder for the converted file
+func (a *App) downloadsOutputPath(fileName, targetFormat string) (string, bool) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", false
+	}
+	downloadsDir := filepath.Join(homeDir, "Downloads")
+	baseName := strings.TrimSuffix(filepath.Base(fileName), filepath.Ext(fileName))
+	formatExt := strings.ToLower(targetFormat)
+	// Normalize JPEG format (handle both "jpg" and "jpeg")
+	if formatExt == "jpg" {
+		formatExt = "jpeg"
+	}
+	return filepath.Join(downloadsDir, baseName+"."+formatExt), true
 }
 
 // generateOutputPath generates an output file path based on input path and target format
@@ -0,0 +1,58 @@
+package gui
+
+import (
+	"context"
+
+	"github.com/eka026/File-Format-Converter/internal/domain"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Events emitted to the frontend while a batch is converted
+const (
+	EventFileStarted  = "conversion:file-started"
+	EventFileFinished = "conversion:file-finished"
+)
+
+// FileEvent is the payload of the per-file batch events
+type FileEvent struct {
+	Index  int               `json:"index"`
+	Path   string            `json:"path"`
+	Result *ConversionResult `json:"result,omitempty"`
+}
+
+// fileEventNotifier forwards domain progress to the wrapped notifier and
+// emits per-file batch events to the frontend through the Wails runtime
+type fileEventNotifier struct {
+	domain.ProgressNotifier
+	ctx func() context.Context
+}
+
+// newFileEventNotifier wraps a progress notifier; ctx returns the Wails
+// application context, which is only available after startup
+func newFileEventNotifier(notifier domain.ProgressNotifier, ctx func() context.Context) *fileEventNotifier {
+	return &fileEventNotifier{
+		ProgressNotifier: notifier,
+		ctx:              ctx,
+	}
+}
+
+// NotifyFileStarted emits EventFileStarted
+func (n *fileEventNotifier) NotifyFileStarted(index int, path string) {
+	n.emit(EventFileStarted, FileEvent{Index: index, Path: path})
+}
+
+// NotifyFileFinished emits EventFileFinished with the file's result
+func (n *fileEventNotifier) NotifyFileFinished(index int, path string, result domain.Result) {
+	converted := toConversionResult(result)
+	n.emit(EventFileFinished, FileEvent{Index: index, Path: path, Result: &converted})
+}
+
+// emit sends an event when running inside the Wails application
+func (n *fileEventNotifier) emit(name string, event FileEvent) {
+	ctx := n.ctx()
+	// runtime.EventsEmit exits the process when given a non-Wails context
+	if ctx == nil || ctx.Value("events") == nil {
+		return
+	}
+	runtime.EventsEmit(ctx, name, event)
+}
@@ -0,0 +1,111 @@
+package gui
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"github.com/eka026/File-Format-Converter/internal/adapters/manifest"
+)
+
+// ManifestPlan lists the conversions a batch manifest describes
+type ManifestPlan struct {
+	Path        string              `json:"path"`
+	ReportPath  string              `json:"reportPath"`
+	Entries     int                 `json:"entries"`
+	Conversions []PlannedConversion `json:"conversions"`
+}
+
+// PlannedConversion is one conversion of a manifest
+type PlannedConversion struct {
+	Input  string `json:"input"`
+	Output string `json:"output"`
+	Format string `json:"format"`
+}
+
+// ManifestRun is the outcome of running a batch manifest
+type ManifestRun struct {
+	ReportPath string             `json:"reportPath"`
+	Succeeded  int                `json:"succeeded"`
+	Skipped    int                `json:"skipped"`
+	Failed     int                `json:"failed"`
+	Results    []ConversionResult `json:"results"`
+}
+
+// ChooseManifest asks the user for a batch manifest and returns its path, or
+// an empty string when the dialog was cancelled
+func (a *App) ChooseManifest() (string, error) {
+	if a.ctx == nil {
+		return "", errors.New("Failed to open dialog: the window is not ready")
+	}
+	return runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Open batch manifest",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "Batch manifests (*.json, *.yaml, *.yml)", Pattern: "*.json;*.yaml;*.yml"},
+		},
+	})
+}
+
+// LoadManifest validates a batch manifest and lists the conversions it
+// would perform
+func (a *App) LoadManifest(path string) (ManifestPlan, error) {
+	m, err := manifest.Load(path)
+	if err != nil {
+		return ManifestPlan{}, fmt.Errorf("Failed to load manifest: %w", err)
+	}
+	jobs, err := m.Plan(time.Now())
+	if err != nil {
+		return ManifestPlan{}, fmt.Errorf("Failed to load manifest: %w", err)
+	}
+
+	plan := ManifestPlan{
+		Path:        m.Path(),
+		ReportPath:  m.ReportPath(),
+		Entries:     len(m.Entries),
+		Conversions: make([]PlannedConversion, len(jobs)),
+	}
+	for i, job := range jobs {
+		plan.Conversions[i] = PlannedConversion{Input: job.Source, Output: job.Target, Format: string(job.Format)}
+	}
+	return plan, nil
+}
+
+// RunManifest converts everything a batch manifest describes as one batch,
+// emitting the same per-file events as BatchConvertFiles, and writes the
+// result report next to the manifest
+func (a *App) RunManifest(path string) (ManifestRun, error) {
+	if a.converterService == nil {
+		if err := a.initializeConverterService(); err != nil {
+			return ManifestRun{}, fmt.Errorf("Failed to initialize converter service: %w", err)
+		}
+	}
+	m, err := manifest.Load(path)
+	if err != nil {
+		return ManifestRun{}, fmt.Errorf("Failed to load manifest: %w", err)
+	}
+
+	started := time.Now()
+	jobs, err := m.Plan(started)
+	if err != nil {
+		return ManifestRun{}, fmt.Errorf("Failed to load manifest: %w", err)
+	}
+	results := manifest.Convert(a.getContext(), a.converterService, jobs)
+	report := m.NewReport(jobs, results, started, time.Now())
+
+	run := ManifestRun{
+		ReportPath: m.ReportPath(),
+		Succeeded:  report.Succeeded,
+		Skipped:    report.Skipped,
+		Failed:     report.Failed,
+		Results:    make([]ConversionResult, len(results)),
+	}
+	for i, result := range results {
+		run.Results[i] = toConversionResult(result)
+	}
+	if err := report.Write(run.ReportPath); err != nil {
+		return run, fmt.Errorf("Failed to write report: %w", err)
+	}
+	return run, nil
+}
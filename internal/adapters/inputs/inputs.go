@@ -0,0 +1,105 @@
+// Package inputs expands the inputs users name, such as files, glob
+// patterns and directories, into the files to convert
+package inputs
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/eka026/File-Format-Converter/internal/domain"
+)
+
+// File is an input file named by the user or found in a directory
+type File struct {
+	Path string
+	// Rel is the path the output takes under an output directory: relative
+	// to the directory the file was found in, or the file's base name
+	Rel string
+}
+
+// Expand resolves inputs into files. Glob patterns are
+// expanded, so they work where the shell does not expand them, and
+// directories contribute their files with a known input extension, including
+// those in subdirectories when recursive is set. Other paths are kept as
+// given so a missing file is reported by its conversion. expanded reports
+// whether any input stood for several files.
+func Expand(args []string, recursive bool) (files []File, expanded bool, err error) {
+	seen := make(map[string]bool)
+	add := func(found ...File) {
+		for _, file := range found {
+			if !seen[file.Path] {
+				seen[file.Path] = true
+				files = append(files, file)
+			}
+		}
+	}
+
+	for _, arg := range args {
+		paths := []string{arg}
+		if IsPattern(arg) {
+			matches, err := filepath.Glob(arg)
+			if err != nil {
+				return nil, false, fmt.Errorf("invalid pattern %q: %w", arg, err)
+			}
+			paths = matches
+			expanded = true
+		}
+
+		for _, path := range paths {
+			info, err := os.Stat(path)
+			if err != nil || !info.IsDir() {
+				add(File{Path: path, Rel: filepath.Base(path)})
+				continue
+			}
+			found, err := directoryFiles(path, recursive)
+			if err != nil {
+				return nil, false, err
+			}
+			add(found...)
+			expanded = true
+		}
+	}
+	return files, expanded, nil
+}
+
+// directoryFiles lists the files in root with a known input extension,
+// skipping hidden files and directories
+func directoryFiles(root string, recursive bool) ([]File, error) {
+	var files []File
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		hidden := strings.HasPrefix(entry.Name(), ".") && path != root
+		if entry.IsDir() {
+			if path != root && (!recursive || hidden) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if hidden {
+			return nil
+		}
+		if _, ok := domain.FileTypeFromExtension(filepath.Ext(path)); !ok {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, File{Path: path, Rel: rel})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading directory %s: %w", root, err)
+	}
+	return files, nil
+}
+
+// IsPattern reports whether path contains glob pattern characters
+func IsPattern(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
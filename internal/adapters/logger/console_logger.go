@@ -17,6 +17,20 @@ const (
 	LogLevelError
 )
 
+// ParseLogLevel returns the level named debug, info or error, and false for
+// any other name
+func ParseLogLevel(name string) (LogLevel, bool) {
+	switch name {
+	case "debug":
+		return LogLevelDebug, true
+	case "info":
+		return LogLevelInfo, true
+	case "error":
+		return LogLevelError, true
+	}
+	return LogLevelInfo, false
+}
+
 // ConsoleLogger is the console logging driven adapter
 type ConsoleLogger struct {
 	level LogLevel
@@ -0,0 +1,286 @@
+// Package manifest runs batch jobs described in a JSON or YAML manifest, so
+// recurring conversions can be kept under version control and rerun as one
+// command.
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/eka026/File-Format-Converter/internal/adapters/dto"
+	"github.com/eka026/File-Format-Converter/internal/domain"
+)
+
+// DefaultNaming is the naming template used when neither the manifest nor
+// the entry sets one: the input's name with the target's extension, under
+// the input's directory relative to a directory input
+const DefaultNaming = "{dir}/{name}.{ext}"
+
+// Manifest describes a batch of conversions. Settings at the top level are
+// defaults for every entry; relative paths are relative to the manifest.
+type Manifest struct {
+	// Output is the directory outputs are written to; empty writes each
+	// output next to its input
+	Output string `json:"output,omitempty"`
+	// Naming is the template of output paths under Output; see Placeholders
+	Naming string `json:"naming,omitempty"`
+	// Options are the conversion options of every entry
+	Options *dto.Options `json:"options,omitempty"`
+	// Report is where the result report is written; empty writes
+	// NAME.report.json next to the manifest
+	Report  string  `json:"report,omitempty"`
+	Entries []Entry `json:"entries"`
+
+	// path is the manifest file, set by Load
+	path string
+}
+
+// Entry is a set of inputs converted to the same targets
+type Entry struct {
+	// Inputs are files, glob patterns and directories
+	Inputs []string `json:"inputs"`
+	// Recursive includes the files in subdirectories of directory inputs
+	Recursive bool     `json:"recursive,omitempty"`
+	To        []string `json:"to"`
+	// Options override the manifest's options field by field
+	Options *dto.Options `json:"options,omitempty"`
+	Output  string       `json:"output,omitempty"`
+	Naming  string       `json:"naming,omitempty"`
+}
+
+// Placeholders are the names a naming template may use
+var Placeholders = map[string]string{
+	"name":   "the input's file name without its extension",
+	"ext":    "the target format's extension, without the dot",
+	"format": "the target format's name in lower case",
+	"dir":    "the input's directory relative to the directory input it was found in; empty otherwise",
+	"date":   "the date of the run as YYYY-MM-DD",
+}
+
+var placeholderPattern = regexp.MustCompile(`\{([a-z]+)\}`)
+
+// Load reads and validates a manifest. Files ending in .yaml or .yml are
+// read as YAML, others as JSON; both have the same fields.
+func Load(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading manifest: %w", err)
+	}
+	manifest, err := Parse(data, filepath.Ext(path))
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+	}
+	if manifest.path, err = filepath.Abs(path); err != nil {
+		return nil, err
+	}
+	if err := manifest.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+	}
+	return manifest, nil
+}
+
+// Parse decodes a manifest in the format named by ext, rejecting unknown
+// fields. Relative paths stay relative to the working directory.
+func Parse(data []byte, ext string) (*Manifest, error) {
+	switch strings.ToLower(ext) {
+	case ".yaml", ".yml":
+		// YAML is decoded through JSON so both formats share the field
+		// names and strictness of the JSON tags
+		var document interface{}
+		if err := yaml.Unmarshal(data, &document); err != nil {
+			return nil, err
+		}
+		var err error
+		if data, err = json.Marshal(document); err != nil {
+			return nil, err
+		}
+	}
+
+	var manifest Manifest
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&manifest); err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+// Path returns the manifest file, or an empty string when it was parsed
+// from memory
+func (m *Manifest) Path() string {
+	return m.path
+}
+
+// Validate checks every entry without touching the filesystem
+func (m *Manifest) Validate() error {
+	if len(m.Entries) == 0 {
+		return errors.New("no entries")
+	}
+	if err := validateNaming(m.Naming, nil); err != nil {
+		return err
+	}
+	for i, entry := range m.Entries {
+		if err := m.validateEntry(entry); err != nil {
+			return fmt.Errorf("entry %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func (m *Manifest) validateEntry(entry Entry) error {
+	if len(entry.Inputs) == 0 {
+		return errors.New("no inputs")
+	}
+	for _, input := range entry.Inputs {
+		if strings.TrimSpace(input) == "" {
+			return errors.New("empty input")
+		}
+	}
+	formats, err := targets(entry.To)
+	if err != nil {
+		return err
+	}
+	if err := validateNaming(firstNonEmpty(entry.Naming, m.Naming), formats); err != nil {
+		return err
+	}
+	opts := m.options(entry)
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// validateNaming checks that a template only uses known placeholders, names
+// each input's output apart from the others and gives the output of each of
+// formats its extension. Plan still checks the paths it expands to, since
+// inputs found in different directories may share a name.
+func validateNaming(naming string, formats []domain.Format) error {
+	if naming == "" {
+		return nil
+	}
+	for _, match := range placeholderPattern.FindAllStringSubmatch(naming, -1) {
+		if _, ok := Placeholders[match[1]]; !ok {
+			return fmt.Errorf("naming %q: unknown placeholder {%s}", naming, match[1])
+		}
+	}
+	if !strings.Contains(naming, "{name}") {
+		return fmt.Errorf("naming %q must contain {name}", naming)
+	}
+	if strings.Contains(naming, "{ext}") || strings.Contains(naming, "{format}") {
+		return nil
+	}
+	if len(formats) > 1 {
+		return fmt.Errorf("naming %q must contain {ext} or {format} to name the outputs of %d target formats apart", naming, len(formats))
+	}
+	// The extension is literal, unless it is built from other placeholders
+	if ext := path.Ext(naming); !strings.Contains(ext, "{") {
+		for _, format := range formats {
+			if !hasExtension(naming, format) {
+				return fmt.Errorf("naming %q does not end in an extension of %s", naming, format)
+			}
+		}
+	}
+	return nil
+}
+
+// hasExtension reports whether the extension of name selects format
+func hasExtension(name string, format domain.Format) bool {
+	extFormat, ok := domain.FormatFromExtension(filepath.Ext(name))
+	return ok && extFormat == format
+}
+
+// targets maps the format names of an entry to output formats
+func targets(names []string) ([]domain.Format, error) {
+	if len(names) == 0 {
+		return nil, errors.New("no target format")
+	}
+	formats := make([]domain.Format, len(names))
+	for i, name := range names {
+		format, ok := domain.FormatFromExtension(strings.TrimSpace(name))
+		if !ok {
+			return nil, fmt.Errorf("unsupported output format %q", name)
+		}
+		formats[i] = format
+	}
+	return formats, nil
+}
+
+// options returns the domain options of an entry, its own fields replacing
+// the manifest's
+func (m *Manifest) options(entry Entry) domain.ConversionOptions {
+	var merged dto.Options
+	if m.Options != nil {
+		merged = *m.Options
+	}
+	if o := entry.Options; o != nil {
+		if o.Quality != 0 {
+			merged.Quality = o.Quality
+		}
+		if o.Width != 0 {
+			merged.Width = o.Width
+		}
+		if o.Height != 0 {
+			merged.Height = o.Height
+		}
+		if o.PageSize != "" {
+			merged.PageSize = o.PageSize
+		}
+		if o.Orientation != "" {
+			merged.Orientation = o.Orientation
+		}
+		if o.Margins != nil {
+			merged.Margins = o.Margins
+		}
+		if o.Sheets != nil {
+			merged.Sheets = o.Sheets
+		}
+		if o.Transforms != nil {
+			merged.Transforms = o.Transforms
+		}
+		if o.Subsampling != "" {
+			merged.Subsampling = o.Subsampling
+		}
+		if o.Compression != "" {
+			merged.Compression = o.Compression
+		}
+		if o.WebPExtended {
+			merged.WebPExtended = true
+		}
+		if o.Metadata != "" {
+			merged.Metadata = o.Metadata
+		}
+		if o.IgnoreOrientation {
+			merged.IgnoreOrientation = true
+		}
+		if o.Collision != "" {
+			merged.Collision = o.Collision
+		}
+	}
+	return merged.ToDomain()
+}
+
+// resolve returns path relative to the manifest's directory
+func (m *Manifest) resolve(path string) string {
+	if path == "" || filepath.IsAbs(path) || m.path == "" {
+		return path
+	}
+	return filepath.Join(filepath.Dir(m.path), path)
+}
+
+// ReportPath returns where the result report is written
+func (m *Manifest) ReportPath() string {
+	if m.Report != "" {
+		return m.resolve(m.Report)
+	}
+	base := strings.TrimSuffix(m.path, filepath.Ext(m.path))
+	return base + ".report.json"
+}
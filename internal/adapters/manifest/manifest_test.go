@@ -0,0 +1,253 @@
+package manifest
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eka026/File-Format-Converter/internal/domain"
+)
+
+// fakeConverter writes every target, failing sources named "bad*"
+type fakeConverter struct {
+	jobs []domain.BatchJob
+}
+
+func (c *fakeConverter) BatchConvertJobs(ctx context.Context, jobs []domain.BatchJob) []domain.Result {
+	c.jobs = append(c.jobs, jobs...)
+	results := make([]domain.Result, len(jobs))
+	for i, job := range jobs {
+		if strings.HasPrefix(filepath.Base(job.Source), "bad") {
+			results[i] = domain.Result{Error: domain.Errorf(domain.ErrorCodeCorruptInput, "corrupt input")}
+			continue
+		}
+		if err := os.WriteFile(job.Target, []byte("converted"), 0644); err != nil {
+			results[i] = domain.Result{Error: err}
+			continue
+		}
+		results[i] = domain.Result{Success: true, OutputPath: job.Target}
+	}
+	return results
+}
+
+// writeFiles creates empty files under dir
+func writeFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+}
+
+// TestParse_YAMLMatchesJSON tests that YAML manifests use the same fields as JSON ones
+func TestParse_YAMLMatchesJSON(t *testing.T) {
+	yamlManifest := `
+output: out
+options:
+  quality: 80
+  pageSize: A4
+entries:
+  - inputs: [scans/*.png]
+    to: [pdf, webp]
+    naming: "{name}-{date}.{ext}"
+`
+	jsonManifest := `{
+		"output": "out",
+		"options": {"quality": 80, "pageSize": "A4"},
+		"entries": [{"inputs": ["scans/*.png"], "to": ["pdf", "webp"], "naming": "{name}-{date}.{ext}"}]
+	}`
+	fromYAML, err := Parse([]byte(yamlManifest), ".yaml")
+	if err != nil {
+		t.Fatalf("Failed to parse YAML: %v", err)
+	}
+	fromJSON, err := Parse([]byte(jsonManifest), ".json")
+	if err != nil {
+		t.Fatalf("Failed to parse JSON: %v", err)
+	}
+	if !reflect.DeepEqual(fromYAML, fromJSON) {
+		t.Errorf("Expected the same manifest, got %+v and %+v", fromYAML, fromJSON)
+	}
+
+	if _, err := Parse([]byte("entries: []\nouptut: out\n"), ".yml"); err == nil {
+		t.Errorf("Expected a misspelled field to be rejected")
+	}
+}
+
+// TestValidate tests that invalid entries are reported with their number
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		want     string
+	}{
+		{"no entries", `{"entries": []}`, "no entries"},
+		{"no inputs", `{"entries": [{"to": ["pdf"]}]}`, "entry 1: no inputs"},
+		{"unknown format", `{"entries": [{"inputs": ["a.png"], "to": ["pdf"]}, {"inputs": ["a.png"], "to": ["xyz"]}]}`, "entry 2: unsupported output format"},
+		{"unknown placeholder", `{"naming": "{name}.{extension}", "entries": [{"inputs": ["a.png"], "to": ["pdf"]}]}`, "unknown placeholder {extension}"},
+		{"naming without name", `{"entries": [{"inputs": ["a.png"], "to": ["pdf"], "naming": "out.{ext}"}]}`, "must contain {name}"},
+		{"naming without ext for several targets", `{"naming": "{name}", "entries": [{"inputs": ["a.png"], "to": ["jpg", "webp"]}]}`, "entry 1: naming \"{name}\" must contain {ext} or {format}"},
+		{"naming with another extension", `{"entries": [{"inputs": ["a.png"], "to": ["pdf"], "naming": "{name}.out"}]}`, "does not end in an extension of PDF"},
+		{"invalid options", `{"entries": [{"inputs": ["a.png"], "to": ["pdf"], "options": {"quality": 200}}]}`, "entry 1:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manifest, err := Parse([]byte(tt.manifest), ".json")
+			if err != nil {
+				t.Fatalf("Failed to parse: %v", err)
+			}
+			if err := manifest.Validate(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+// TestPlan tests that inputs resolve against the manifest and outputs follow the naming template
+func TestPlan(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, "scans/a.png", "scans/nested/b.png", "photos/c.jpg", "photos/d.jpg")
+	path := filepath.Join(dir, "nightly.yaml")
+	content := `
+output: out
+options: {quality: 70}
+entries:
+  - inputs: [scans]
+    recursive: true
+    to: [pdf]
+  - inputs: ["photos/*.jpg"]
+    to: [webp]
+    output: web
+    naming: "{date}/{name}-small.{ext}"
+    options: {width: 320, transforms: ["crop=1:1", {kind: flip, flip: horizontal}]}
+  - inputs: [photos/c.jpg]
+    to: [png]
+    output: ""
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+	manifest, err := Load(path)
+	if err != nil {
+		t.Fatalf("Failed to load manifest: %v", err)
+	}
+
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	jobs, err := manifest.Plan(now)
+	if err != nil {
+		t.Fatalf("Failed to plan: %v", err)
+	}
+	var targets []string
+	for _, job := range jobs {
+		rel, _ := filepath.Rel(dir, job.Target)
+		targets = append(targets, filepath.ToSlash(rel))
+	}
+	want := []string{
+		"out/a.pdf",
+		"out/nested/b.pdf",
+		"web/2026-03-01/c-small.webp",
+		"web/2026-03-01/d-small.webp",
+		"out/c.png",
+	}
+	if !reflect.DeepEqual(targets, want) {
+		t.Errorf("Expected targets %v, got %v", want, targets)
+	}
+	if opts := jobs[2].Options.Image; opts.Quality != 70 || opts.Width != 320 {
+		t.Errorf("Expected entry options to override the manifest's field by field, got %+v", opts)
+	}
+	wantTransforms := []domain.ImageTransform{
+		{Kind: domain.TransformCrop, Aspect: "1:1"},
+		{Kind: domain.TransformFlip, Flip: domain.FlipHorizontal},
+	}
+	if got := jobs[2].Options.Image.Transforms; !reflect.DeepEqual(got, wantTransforms) {
+		t.Errorf("Expected the entry's transforms in either form, got %+v", got)
+	}
+	if manifest.ReportPath() != filepath.Join(dir, "nightly.report.json") {
+		t.Errorf("Expected the report next to the manifest, got %s", manifest.ReportPath())
+	}
+}
+
+// TestPlan_RejectsConflictingTargets tests that planned outputs must have
+// their format's extension and must not be written twice
+func TestPlan_RejectsConflictingTargets(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, "a/photo.png", "b/photo.png")
+	tests := []struct {
+		name     string
+		manifest string
+		want     string
+	}{
+		{"extension from a placeholder", `{"entries": [{"inputs": ["a/photo.png"], "to": ["jpg"], "naming": "{name}.{date}"}]}`, "does not have an extension of JPEG"},
+		{"same name in two directories", `{"output": "out", "naming": "{name}.{ext}", "entries": [{"inputs": ["a", "b"], "to": ["jpg"]}]}`, "is also written by entry 1"},
+		{"same target in two entries", `{"entries": [{"inputs": ["a/photo.png"], "to": ["jpg"]}, {"inputs": ["a/photo.png"], "to": ["jpeg"], "naming": "{name}.jpeg"}]}`, "entry 2: output"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "manifest.json")
+			if err := os.WriteFile(path, []byte(tt.manifest), 0644); err != nil {
+				t.Fatalf("Failed to write manifest: %v", err)
+			}
+			manifest, err := Load(path)
+			if err != nil {
+				t.Fatalf("Failed to load manifest: %v", err)
+			}
+			if _, err := manifest.Plan(time.Now()); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+// TestRun tests that every planned job is converted and the report records each result
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, "in/a.png", "in/bad.png")
+	path := filepath.Join(dir, "job.json")
+	content := `{"output": "out", "entries": [{"inputs": ["in"], "to": ["jpeg", "webp"]}]}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+	manifest, err := Load(path)
+	if err != nil {
+		t.Fatalf("Failed to load manifest: %v", err)
+	}
+
+	converter := &fakeConverter{}
+	report, err := manifest.Run(context.Background(), converter)
+	if err != nil {
+		t.Fatalf("Failed to run: %v", err)
+	}
+	if len(converter.jobs) != 4 || report.Succeeded != 2 || report.Failed != 2 {
+		t.Fatalf("Expected 2 of 4 conversions to succeed, got %+v", report)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "out", "a.webp")); err != nil {
+		t.Errorf("Expected the output under the output directory: %v", err)
+	}
+
+	if err := report.Write(manifest.ReportPath()); err != nil {
+		t.Fatalf("Failed to write report: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "job.report.json"))
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	var written Report
+	if err := json.Unmarshal(data, &written); err != nil {
+		t.Fatalf("Expected a JSON report: %v", err)
+	}
+	if len(written.Results) != 4 || written.Results[0].Entry != 1 || written.Results[0].Format != "JPEG" {
+		t.Errorf("Unexpected report results %+v", written.Results)
+	}
+	if failed := written.Results[2]; failed.Code != string(domain.ErrorCodeCorruptInput) {
+		t.Errorf("Expected the failure code in the report, got %+v", failed)
+	}
+}
@@ -0,0 +1,227 @@
+package manifest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/eka026/File-Format-Converter/internal/adapters/dto"
+	"github.com/eka026/File-Format-Converter/internal/adapters/inputs"
+	"github.com/eka026/File-Format-Converter/internal/domain"
+)
+
+// Converter is the part of domain.ConverterService a manifest runs with
+type Converter interface {
+	BatchConvertJobs(ctx context.Context, jobs []domain.BatchJob) []domain.Result
+}
+
+// Job is a conversion planned from a manifest entry
+type Job struct {
+	// Entry is the number of the entry in the manifest, starting at 1
+	Entry  int
+	Format domain.Format
+	domain.BatchJob
+}
+
+// Report is the machine-readable result of running a manifest
+type Report struct {
+	Manifest  string    `json:"manifest"`
+	Started   time.Time `json:"started"`
+	Finished  time.Time `json:"finished"`
+	Succeeded int       `json:"succeeded"`
+	Skipped   int       `json:"skipped"`
+	Failed    int       `json:"failed"`
+	Results   []Result  `json:"results"`
+}
+
+// Result is the outcome of one planned conversion
+type Result struct {
+	// Entry is the number of the entry in the manifest, starting at 1
+	Entry  int    `json:"entry"`
+	Format string `json:"format"`
+	dto.Result
+}
+
+// Plan expands the inputs of every entry into conversions, in entry order.
+// now fills the {date} placeholder. Outputs whose extension does not select
+// their format, or that two conversions would write, are rejected.
+func (m *Manifest) Plan(now time.Time) ([]Job, error) {
+	var jobs []Job
+	// planned maps each target to the number of the entry that writes it
+	planned := make(map[string]int)
+	for i, entry := range m.Entries {
+		formats, err := targets(entry.To)
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: %w", i+1, err)
+		}
+		paths := make([]string, len(entry.Inputs))
+		for k, input := range entry.Inputs {
+			paths[k] = m.resolve(input)
+		}
+		files, _, err := inputs.Expand(paths, entry.Recursive)
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: %w", i+1, err)
+		}
+
+		output := m.resolve(firstNonEmpty(entry.Output, m.Output))
+		naming := firstNonEmpty(entry.Naming, m.Naming, DefaultNaming)
+		opts := m.options(entry)
+		for _, file := range files {
+			for _, format := range formats {
+				target := targetPath(file, format, output, naming, now)
+				if !hasExtension(target, format) {
+					return nil, fmt.Errorf("entry %d: output %s of %s does not have an extension of %s", i+1, target, file.Path, format)
+				}
+				if entry, ok := planned[target]; ok {
+					return nil, fmt.Errorf("entry %d: output %s of %s is also written by entry %d", i+1, target, file.Path, entry)
+				}
+				planned[target] = i + 1
+				jobs = append(jobs, Job{
+					Entry:  i + 1,
+					Format: format,
+					BatchJob: domain.BatchJob{
+						Source:  file.Path,
+						Target:  target,
+						Options: opts,
+					},
+				})
+			}
+		}
+	}
+	return jobs, nil
+}
+
+// targetPath applies a naming template to an input file. Without an output
+// directory the output is written next to the input.
+func targetPath(file inputs.File, format domain.Format, output, naming string, now time.Time) string {
+	ext := filepath.Ext(file.Rel)
+	dir := filepath.ToSlash(filepath.Dir(file.Rel))
+	if dir == "." || output == "" {
+		dir = ""
+	}
+	name := placeholderPattern.ReplaceAllStringFunc(naming, func(placeholder string) string {
+		switch strings.Trim(placeholder, "{}") {
+		case "name":
+			return strings.TrimSuffix(filepath.Base(file.Rel), ext)
+		case "ext":
+			return strings.TrimPrefix(format.Extension(), ".")
+		case "format":
+			return strings.ToLower(string(format))
+		case "dir":
+			return dir
+		case "date":
+			return now.Format("2006-01-02")
+		}
+		return placeholder
+	})
+	if output == "" {
+		output = filepath.Dir(file.Path)
+	}
+	return filepath.Join(output, filepath.FromSlash(name))
+}
+
+// Run plans the manifest, converts every job in one batch and reports the
+// results. It only fails when the inputs cannot be expanded; failed
+// conversions are recorded in the report.
+func (m *Manifest) Run(ctx context.Context, converter Converter) (*Report, error) {
+	started := time.Now()
+	jobs, err := m.Plan(started)
+	if err != nil {
+		return nil, err
+	}
+	results := Convert(ctx, converter, jobs)
+	return m.NewReport(jobs, results, started, time.Now()), nil
+}
+
+// Convert runs planned jobs as one batch, creating their output directories,
+// and returns their results in the same order
+func Convert(ctx context.Context, converter Converter, jobs []Job) []domain.Result {
+	results := make([]domain.Result, len(jobs))
+	var (
+		batch []domain.BatchJob
+		// owners maps each batch job to its index in jobs
+		owners []int
+	)
+	for i, job := range jobs {
+		if err := os.MkdirAll(filepath.Dir(job.Target), 0755); err != nil {
+			results[i] = domain.Result{Error: domain.Errorf(domain.ErrorCodeOutputNotWritable, "creating output directory: %w", err)}
+			continue
+		}
+		batch = append(batch, job.BatchJob)
+		owners = append(owners, i)
+	}
+	if len(batch) > 0 {
+		for k, result := range converter.BatchConvertJobs(ctx, batch) {
+			results[owners[k]] = result
+		}
+	}
+	return results
+}
+
+// NewReport summarises the results of the planned jobs
+func (m *Manifest) NewReport(jobs []Job, results []domain.Result, started, finished time.Time) *Report {
+	report := &Report{Manifest: m.path, Started: started, Finished: finished, Results: make([]Result, len(jobs))}
+	for i, job := range jobs {
+		result := Result{Entry: job.Entry, Format: string(job.Format), Result: dto.NewResult(job.Source, results[i])}
+		switch {
+		case !result.Success:
+			report.Failed++
+		case result.Skipped:
+			report.Skipped++
+		default:
+			report.Succeeded++
+		}
+		report.Results[i] = result
+	}
+	return report
+}
+
+// Write saves the report as indented JSON, replacing the file atomically so
+// a reader never sees a partial report
+func (r *Report) Write(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFile(path, append(data, '\n')); err != nil {
+		return fmt.Errorf("writing report: %w", err)
+	}
+	return nil
+}
+
+// writeFile writes data to a temporary file next to path and renames it
+// into place
+func writeFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".report-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	// Reports are read by other tools, unlike the private temporary file
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// firstNonEmpty returns the first of values that is not empty
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,192 @@
+package sniffer
+
+// NFR-01 (Data Sovereignty): File type detection reads local files only.
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/eka026/File-Format-Converter/internal/domain"
+)
+
+// headerSize is the number of leading bytes inspected for magic numbers
+const headerSize = 512
+
+// maxContentTypesSize caps how much of [Content_Types].xml is read
+const maxContentTypesSize = 1 << 20
+
+// OOXML main part content types
+const (
+	contentTypeDOCX = "application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"
+	contentTypeXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"
+)
+
+// Sniffer identifies input file types from their content rather than their extension
+type Sniffer struct{}
+
+// NewSniffer creates a new content-based file type detector
+func NewSniffer() domain.FileTypeDetector {
+	return &Sniffer{}
+}
+
+// Detect inspects a file's magic number (and OOXML package manifest for ZIP
+// containers) and compares the result with the type implied by its extension
+func (s *Sniffer) Detect(path string) (domain.FileTypeDetection, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return domain.FileTypeDetection{}, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return domain.FileTypeDetection{}, err
+	}
+	if info.IsDir() {
+		return domain.FileTypeDetection{}, fmt.Errorf("path is a directory, not a file")
+	}
+
+	return DetectReader(file, info.Size(), filepath.Base(path))
+}
+
+// DetectReader identifies the content of r. name is only used for its extension
+// and in warning messages; it may be empty.
+func DetectReader(r io.ReaderAt, size int64, name string) (domain.FileTypeDetection, error) {
+	extensionType, _ := domain.FileTypeFromExtension(filepath.Ext(name))
+	detection := domain.FileTypeDetection{
+		FileType:      extensionType,
+		ExtensionType: extensionType,
+	}
+
+	header := make([]byte, headerSize)
+	n, err := r.ReadAt(header, 0)
+	if err != nil && err != io.EOF {
+		return detection, fmt.Errorf("reading file header: %w", err)
+	}
+	header = header[:n]
+
+	contentType := DetectBytes(header)
+	if isZip(header) {
+		contentType = detectOOXML(r, size)
+	}
+	if contentType == "" && looksLikeHTML(header) {
+		contentType = domain.FileTypeHTML
+	}
+
+	// Unrecognised content keeps the extension-based type; the engine's own
+	// validation will reject it if it is really corrupt
+	if contentType == "" {
+		return detection, nil
+	}
+
+	detection.FileType = contentType
+	if extensionType != "" && extensionType != contentType {
+		detection.Warnings = append(detection.Warnings, fmt.Sprintf(
+			"%s has a %s extension but contains %s data; converting as %s",
+			name, strings.ToLower(filepath.Ext(name)), contentType, contentType))
+	} else if extensionType == "" && name != "" {
+		detection.Warnings = append(detection.Warnings, fmt.Sprintf(
+			"%s has an unrecognised extension; detected %s data from its content", name, contentType))
+	}
+
+	return detection, nil
+}
+
+// DetectBytes identifies image formats from their magic numbers.
+// It returns an empty FileType when the header is not recognised.
+func DetectBytes(header []byte) domain.FileType {
+	switch {
+	case bytes.HasPrefix(header, []byte{0xFF, 0xD8, 0xFF}):
+		// JPEG: FF D8 FF
+		return domain.FileTypeJPEG
+	case bytes.HasPrefix(header, []byte{0x89, 0x50, 0x4E, 0x47, 0x0D, 0x0A, 0x1A, 0x0A}):
+		// PNG: 89 50 4E 47 0D 0A 1A 0A
+		return domain.FileTypePNG
+	case len(header) >= 12 && string(header[0:4]) == "RIFF" && string(header[8:12]) == "WEBP":
+		// WebP: RIFF....WEBP
+		return domain.FileTypeWEBP
+	case bytes.HasPrefix(header, []byte("GIF87a")) || bytes.HasPrefix(header, []byte("GIF89a")):
+		// GIF: GIF87a or GIF89a
+		return domain.FileTypeGIF
+	case len(header) >= 14 && string(header[0:2]) == "BM" && bytes.Equal(header[6:10], []byte{0, 0, 0, 0}):
+		// BMP: BM, the file size, then four reserved zero bytes
+		return domain.FileTypeBMP
+	case bytes.HasPrefix(header, []byte("II*\x00")) || bytes.HasPrefix(header, []byte("MM\x00*")):
+		// TIFF: II*\0 (little-endian) or MM\0* (big-endian)
+		return domain.FileTypeTIFF
+	default:
+		return ""
+	}
+}
+
+// isZip reports whether the header starts with a ZIP local file header
+func isZip(header []byte) bool {
+	return bytes.HasPrefix(header, []byte("PK\x03\x04"))
+}
+
+// contentTypes mirrors the parts of [Content_Types].xml needed for detection
+type contentTypes struct {
+	Overrides []struct {
+		PartName    string `xml:"PartName,attr"`
+		ContentType string `xml:"ContentType,attr"`
+	} `xml:"Override"`
+}
+
+// detectOOXML distinguishes DOCX from XLSX using the package's [Content_Types].xml,
+// falling back to the presence of the main document part
+func detectOOXML(r io.ReaderAt, size int64) domain.FileType {
+	reader, err := zip.NewReader(r, size)
+	if err != nil {
+		return ""
+	}
+
+	parts := make(map[string]bool)
+	for _, file := range reader.File {
+		parts[file.Name] = true
+		if file.Name != "[Content_Types].xml" {
+			continue
+		}
+
+		rc, err := file.Open()
+		if err != nil {
+			continue
+		}
+		var manifest contentTypes
+		err = xml.NewDecoder(io.LimitReader(rc, maxContentTypesSize)).Decode(&manifest)
+		rc.Close()
+		if err != nil {
+			continue
+		}
+
+		for _, override := range manifest.Overrides {
+			switch override.ContentType {
+			case contentTypeDOCX:
+				return domain.FileTypeDOCX
+			case contentTypeXLSX:
+				return domain.FileTypeXLSX
+			}
+		}
+	}
+
+	switch {
+	case parts["word/document.xml"]:
+		return domain.FileTypeDOCX
+	case parts["xl/workbook.xml"]:
+		return domain.FileTypeXLSX
+	default:
+		return ""
+	}
+}
+
+// looksLikeHTML reports whether a text header starts with an HTML document marker
+func looksLikeHTML(header []byte) bool {
+	text := bytes.TrimPrefix(header, []byte{0xEF, 0xBB, 0xBF})
+	text = bytes.ToLower(bytes.TrimSpace(text))
+	return bytes.HasPrefix(text, []byte("<!doctype html")) || bytes.HasPrefix(text, []byte("<html"))
+}
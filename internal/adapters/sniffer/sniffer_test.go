@@ -0,0 +1,142 @@
+package sniffer
+
+import (
+	"archive/zip"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eka026/File-Format-Converter/internal/domain"
+)
+
+// createTempPNG writes a small PNG image under the given file name
+func createTempPNG(t *testing.T, name string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	defer file.Close()
+	if err := png.Encode(file, image.NewRGBA(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatalf("Failed to encode PNG: %v", err)
+	}
+	return path
+}
+
+// createTempOOXML writes a ZIP package whose manifest declares the given main part
+func createTempOOXML(t *testing.T, name, partName, contentType string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	defer file.Close()
+
+	zw := zip.NewWriter(file)
+	w, err := zw.Create("[Content_Types].xml")
+	if err != nil {
+		t.Fatalf("Failed to create manifest: %v", err)
+	}
+	w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>` +
+		`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Override PartName="/` + partName + `" ContentType="` + contentType + `"/></Types>`))
+	if _, err := zw.Create(partName); err != nil {
+		t.Fatalf("Failed to create main part: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Failed to finish ZIP: %v", err)
+	}
+	return path
+}
+
+// TestSniffer_Detect_MatchingExtension tests that correctly named files produce no warnings
+func TestSniffer_Detect_MatchingExtension(t *testing.T) {
+	detection, err := NewSniffer().Detect(createTempPNG(t, "image.png"))
+	if err != nil {
+		t.Fatalf("Expected detection to succeed, got error: %v", err)
+	}
+	if detection.FileType != domain.FileTypePNG {
+		t.Errorf("Expected PNG, got %s", detection.FileType)
+	}
+	if len(detection.Warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", detection.Warnings)
+	}
+}
+
+// TestSniffer_Detect_MislabelledImage tests that a PNG saved as .jpg is detected as PNG
+func TestSniffer_Detect_MislabelledImage(t *testing.T) {
+	detection, err := NewSniffer().Detect(createTempPNG(t, "photo.jpg"))
+	if err != nil {
+		t.Fatalf("Expected detection to succeed, got error: %v", err)
+	}
+	if detection.FileType != domain.FileTypePNG {
+		t.Errorf("Expected PNG, got %s", detection.FileType)
+	}
+	if detection.ExtensionType != domain.FileTypeJPEG || !detection.ExtensionMismatch() {
+		t.Errorf("Expected a JPEG extension mismatch, got %+v", detection)
+	}
+	if len(detection.Warnings) != 1 {
+		t.Errorf("Expected one mismatch warning, got %v", detection.Warnings)
+	}
+}
+
+// TestSniffer_Detect_MislabelledOOXML tests that a spreadsheet saved as .docx is detected as XLSX
+func TestSniffer_Detect_MislabelledOOXML(t *testing.T) {
+	path := createTempOOXML(t, "report.docx", "xl/workbook.xml", contentTypeXLSX)
+
+	detection, err := NewSniffer().Detect(path)
+	if err != nil {
+		t.Fatalf("Expected detection to succeed, got error: %v", err)
+	}
+	if detection.FileType != domain.FileTypeXLSX {
+		t.Errorf("Expected XLSX, got %s", detection.FileType)
+	}
+	if !detection.ExtensionMismatch() {
+		t.Error("Expected an extension mismatch to be reported")
+	}
+}
+
+// TestSniffer_Detect_UnknownContent tests that unrecognised content keeps the extension type
+func TestSniffer_Detect_UnknownContent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.webp")
+	if err := os.WriteFile(path, []byte("plain text"), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	detection, err := NewSniffer().Detect(path)
+	if err != nil {
+		t.Fatalf("Expected detection to succeed, got error: %v", err)
+	}
+	if detection.FileType != domain.FileTypeWEBP || len(detection.Warnings) != 0 {
+		t.Errorf("Expected extension type WEBP with no warnings, got %+v", detection)
+	}
+}
+
+// TestDetectBytes_Images tests the magic numbers of every image format
+func TestDetectBytes_Images(t *testing.T) {
+	tests := []struct {
+		header string
+		want   domain.FileType
+	}{
+		{"\xff\xd8\xff\xe0", domain.FileTypeJPEG},
+		{"\x89PNG\r\n\x1a\n", domain.FileTypePNG},
+		{"RIFF\x24\x00\x00\x00WEBPVP8 ", domain.FileTypeWEBP},
+		{"GIF87a\x10\x00", domain.FileTypeGIF},
+		{"GIF89a\x10\x00", domain.FileTypeGIF},
+		{"BM\x46\x00\x00\x00\x00\x00\x00\x00\x36\x00\x00\x00", domain.FileTypeBMP},
+		{"II*\x00\x08\x00\x00\x00", domain.FileTypeTIFF},
+		{"MM\x00*\x00\x00\x00\x08", domain.FileTypeTIFF},
+		// Text that happens to start like a BMP
+		{"BMW is a car maker", ""},
+		{"GIF", ""},
+	}
+	for _, tt := range tests {
+		if got := DetectBytes([]byte(tt.header)); got != tt.want {
+			t.Errorf("%q: expected %q, got %q", tt.header, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package watch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/eka026/File-Format-Converter/internal/domain"
+)
+
+// Outcomes of a handled file
+const (
+	statusConverted = "converted"
+	statusFailed    = "failed"
+	// statusRetrying is a file whose conversion failed with a transient
+	// error and is tried again on the next scan
+	statusRetrying = "retrying"
+)
+
+// journal records the files a watcher converted but has not yet moved out
+// of the watched folder, so a restart neither converts them again nor
+// forgets a pending retry. A file is identified by its path, size and
+// modification time: a new file dropped under the same name is converted.
+type journal struct {
+	path  string
+	Files map[string]*journalEntry `json:"files"`
+	dirty bool
+}
+
+// journalEntry is the outcome of converting one file
+type journalEntry struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+	Status  string    `json:"status"`
+	// Converted lists the targets that succeeded, which a retry skips
+	Converted []domain.Format `json:"converted,omitempty"`
+	Outputs   []string        `json:"outputs,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	Attempts  int             `json:"attempts"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+}
+
+// targetResult is the result of converting a file to one target
+type targetResult struct {
+	target domain.Format
+	result domain.Result
+}
+
+// converted reports whether the file was already converted to target
+func (e *journalEntry) converted(target domain.Format) bool {
+	for _, done := range e.Converted {
+		if done == target {
+			return true
+		}
+	}
+	return false
+}
+
+// loadJournal reads the journal at path. An empty path keeps the journal in
+// memory only; a missing file starts an empty one.
+func loadJournal(path string) (*journal, error) {
+	j := &journal{path: path, Files: make(map[string]*journalEntry)}
+	if path == "" {
+		return j, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return j, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading watch journal: %w", err)
+	}
+	if err := json.Unmarshal(data, j); err != nil {
+		return nil, fmt.Errorf("invalid watch journal %s: %w", path, err)
+	}
+	if j.Files == nil {
+		j.Files = make(map[string]*journalEntry)
+	}
+	return j, nil
+}
+
+// lookup returns the entry of the file at path, if it is the same file
+func (j *journal) lookup(path string, info os.FileInfo) (*journalEntry, bool) {
+	entry, ok := j.Files[path]
+	if !ok {
+		return nil, false
+	}
+	if entry.Size != info.Size() || !entry.ModTime.Equal(info.ModTime()) {
+		// Another file was dropped under the same name
+		j.remove(path)
+		return nil, false
+	}
+	return entry, true
+}
+
+// record stores the outcome of converting the file at path to the targets
+// it still needed, keeping the targets earlier attempts converted. A file
+// whose failures are all transient is retried until maxAttempts.
+func (j *journal) record(path string, info os.FileInfo, results []targetResult, maxAttempts int) *journalEntry {
+	entry, ok := j.lookup(path, info)
+	if !ok {
+		entry = &journalEntry{Size: info.Size(), ModTime: info.ModTime()}
+		j.Files[path] = entry
+	}
+	entry.Attempts++
+	entry.UpdatedAt = time.Now()
+
+	var failures []string
+	transient := true
+	for _, converted := range results {
+		result := converted.result
+		if result.Success {
+			entry.Converted = append(entry.Converted, converted.target)
+			entry.Outputs = append(entry.Outputs, result.OutputPath)
+			continue
+		}
+		err := result.Error
+		if err == nil {
+			err = errors.New("conversion failed")
+		}
+		failures = append(failures, err.Error())
+		transient = transient && domain.IsRetryable(err)
+	}
+
+	switch {
+	case len(failures) == 0:
+		entry.Status = statusConverted
+		entry.Error = ""
+	case transient && entry.Attempts < maxAttempts:
+		entry.Status = statusRetrying
+		entry.Error = strings.Join(failures, "; ")
+	default:
+		entry.Status = statusFailed
+		entry.Error = strings.Join(failures, "; ")
+	}
+	j.dirty = true
+	return entry
+}
+
+// remove forgets the file at path
+func (j *journal) remove(path string) {
+	if _, ok := j.Files[path]; ok {
+		delete(j.Files, path)
+		j.dirty = true
+	}
+}
+
+// prune forgets files that are no longer in the watched folders
+func (j *journal) prune(present map[string]bool) {
+	for path := range j.Files {
+		if !present[path] {
+			j.remove(path)
+		}
+	}
+}
+
+// save writes the journal if it changed, replacing the file atomically
+func (j *journal) save() error {
+	if j.path == "" || !j.dirty {
+		return nil
+	}
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(j.path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(j.path), ".watch-journal-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), j.path); err != nil {
+		return err
+	}
+	j.dirty = false
+	return nil
+}
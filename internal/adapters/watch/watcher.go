@@ -0,0 +1,395 @@
+// Package watch is the watch-folder adapter: it converts files dropped into
+// directories, such as scanner or export shares, as they arrive.
+package watch
+
+// NFR-01 (Data Sovereignty): Watched folders are read and written locally;
+// files are never sent to external servers.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/eka026/File-Format-Converter/internal/domain"
+)
+
+// Subfolders of a watched directory
+const (
+	// ProcessedDir receives originals once every target was converted
+	ProcessedDir = "processed"
+	// FailedDir receives originals that could not be converted, each with a
+	// .error.txt file explaining why
+	FailedDir = "failed"
+	// ConvertedDir receives the outputs unless the folder sets OutputDir
+	ConvertedDir = "converted"
+)
+
+// Defaults for Config fields left zero
+const (
+	DefaultInterval = 2 * time.Second
+	DefaultSettle   = 5 * time.Second
+	// DefaultMaxAttempts bounds how often a file failing with a transient
+	// error is tried before it is moved to FailedDir
+	DefaultMaxAttempts = 3
+)
+
+// Converter is the part of domain.ConverterService the watcher uses
+type Converter interface {
+	BatchConvertJobs(ctx context.Context, jobs []domain.BatchJob) []domain.Result
+}
+
+// Folder is a watched directory and what its files are converted to
+type Folder struct {
+	// Dir is the directory files are dropped into. Only its top level is
+	// watched; subdirectories are left alone.
+	Dir string
+	// Targets lists the formats each file is converted to
+	Targets []domain.Format
+	// OutputDir receives the outputs; empty uses Dir/converted
+	OutputDir string
+	// Options apply to every conversion. Outputs never overwrite earlier
+	// ones unless Options.Output.Collision says so.
+	Options domain.ConversionOptions
+}
+
+// Config configures a Watcher
+type Config struct {
+	Folders []Folder
+	// Interval is the time between scans of the folders
+	Interval time.Duration
+	// Settle is how long a file's size and modification time must stay
+	// unchanged before it is considered fully written
+	Settle time.Duration
+	// MaxAttempts bounds the tries of a file failing with a transient error
+	MaxAttempts int
+	// StatePath is the journal recording handled files across restarts
+	StatePath string
+}
+
+// Watcher converts the files that appear in its folders with a Converter,
+// then moves the originals to processed/ or failed/. A journal records
+// each handled file so a restart does not convert it again, even when the
+// original could not be moved.
+type Watcher struct {
+	converter Converter
+	logger    domain.Logger
+	config    Config
+	journal   *journal
+	// seen tracks the files in the folders by path, with when they last changed
+	seen map[string]observation
+	now  func() time.Time
+}
+
+// observation is the state of a file at the scan that last saw it change
+type observation struct {
+	size    int64
+	modTime time.Time
+	since   time.Time
+}
+
+// pendingFile is a settled file waiting for conversion
+type pendingFile struct {
+	folder *Folder
+	path   string
+	info   os.FileInfo
+}
+
+// New creates a watcher, loading the journal at config.StatePath
+func New(converter Converter, logger domain.Logger, config Config) (*Watcher, error) {
+	if len(config.Folders) == 0 {
+		return nil, errors.New("no folders to watch")
+	}
+	if config.Interval <= 0 {
+		config.Interval = DefaultInterval
+	}
+	if config.Settle <= 0 {
+		config.Settle = DefaultSettle
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = DefaultMaxAttempts
+	}
+
+	folders := make([]Folder, len(config.Folders))
+	for i, folder := range config.Folders {
+		if len(folder.Targets) == 0 {
+			return nil, fmt.Errorf("folder %s has no target formats", folder.Dir)
+		}
+		dir, err := filepath.Abs(folder.Dir)
+		if err != nil {
+			return nil, err
+		}
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("watched folder %s is not a directory", folder.Dir)
+		}
+		folder.Dir = dir
+		if folder.OutputDir == "" {
+			folder.OutputDir = filepath.Join(dir, ConvertedDir)
+		}
+		// Outputs written to the watched folder would be picked up as inputs
+		outputDir, err := filepath.Abs(folder.OutputDir)
+		if err != nil {
+			return nil, err
+		}
+		if outputDir == dir {
+			return nil, fmt.Errorf("folder %s: the output folder must not be the watched folder", folder.Dir)
+		}
+		folder.OutputDir = outputDir
+		if folder.Options.Output.Collision == "" {
+			folder.Options.Output.Collision = domain.CollisionSuffix
+		}
+		if err := folder.Options.Validate(); err != nil {
+			return nil, fmt.Errorf("folder %s: %w", folder.Dir, err)
+		}
+		folders[i] = folder
+	}
+	config.Folders = folders
+
+	journal, err := loadJournal(config.StatePath)
+	if err != nil {
+		return nil, err
+	}
+	return &Watcher{
+		converter: converter,
+		logger:    logger,
+		config:    config,
+		journal:   journal,
+		seen:      make(map[string]observation),
+		now:       time.Now,
+	}, nil
+}
+
+// Run scans the folders every Interval until ctx is cancelled
+func (w *Watcher) Run(ctx context.Context) error {
+	for _, folder := range w.config.Folders {
+		w.logger.Info(fmt.Sprintf("Watching %s for %s", folder.Dir, formatList(folder.Targets)))
+	}
+	ticker := time.NewTicker(w.config.Interval)
+	defer ticker.Stop()
+	for {
+		w.Scan(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Scan converts the files that have settled since the previous scan
+func (w *Watcher) Scan(ctx context.Context) {
+	var ready []pendingFile
+	present := make(map[string]bool)
+	complete := true
+	for i := range w.config.Folders {
+		folder := &w.config.Folders[i]
+		entries, err := os.ReadDir(folder.Dir)
+		if err != nil {
+			// A share that is briefly unavailable must not lose its journal
+			w.logger.Error(fmt.Sprintf("Cannot read watched folder %s", folder.Dir), err)
+			complete = false
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !isCandidate(entry.Name()) {
+				continue
+			}
+			path := filepath.Join(folder.Dir, entry.Name())
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			present[path] = true
+			if w.settled(path, info) {
+				ready = append(ready, pendingFile{folder: folder, path: path, info: info})
+			}
+		}
+	}
+
+	// Forget files that were removed or moved away by someone else
+	if complete {
+		for path := range w.seen {
+			if !present[path] {
+				delete(w.seen, path)
+			}
+		}
+		w.journal.prune(present)
+	}
+
+	var convert []pendingFile
+	for _, file := range ready {
+		entry, ok := w.journal.lookup(file.path, file.info)
+		switch {
+		case ok && entry.Status != statusRetrying:
+			// Converted before a restart, or the original could not be moved
+			w.finish(file, entry)
+		case readable(file.path):
+			convert = append(convert, file)
+		}
+	}
+	if len(convert) > 0 {
+		w.convert(ctx, convert)
+	}
+	if err := w.journal.save(); err != nil {
+		w.logger.Error("Cannot save the watch journal", err)
+	}
+}
+
+// settled reports whether the file has kept its size and modification time
+// for the settle period, recording it when it is new or has changed
+func (w *Watcher) settled(path string, info os.FileInfo) bool {
+	now := w.now()
+	previous, ok := w.seen[path]
+	if !ok || previous.size != info.Size() || !previous.modTime.Equal(info.ModTime()) {
+		w.seen[path] = observation{size: info.Size(), modTime: info.ModTime(), since: now}
+		return false
+	}
+	return now.Sub(previous.since) >= w.config.Settle
+}
+
+// convert converts each file to its folder's targets in one batch and
+// records the outcome. A file being retried is only converted to the
+// targets that failed before.
+func (w *Watcher) convert(ctx context.Context, files []pendingFile) {
+	var (
+		jobs []domain.BatchJob
+		// owners maps each job to the index of its file, and targets to its format
+		owners  []int
+		targets []domain.Format
+	)
+	for i, file := range files {
+		if err := os.MkdirAll(file.folder.OutputDir, 0755); err != nil {
+			w.logger.Error(fmt.Sprintf("Cannot create output folder %s", file.folder.OutputDir), err)
+			continue
+		}
+		previous, retrying := w.journal.lookup(file.path, file.info)
+		base := strings.TrimSuffix(filepath.Base(file.path), filepath.Ext(file.path))
+		for _, target := range file.folder.Targets {
+			if retrying && previous.converted(target) {
+				continue
+			}
+			jobs = append(jobs, domain.BatchJob{
+				Source:  file.path,
+				Target:  filepath.Join(file.folder.OutputDir, base+target.Extension()),
+				Options: file.folder.Options,
+			})
+			owners = append(owners, i)
+			targets = append(targets, target)
+		}
+	}
+
+	results := w.converter.BatchConvertJobs(ctx, jobs)
+	outcomes := make([][]targetResult, len(files))
+	for j, result := range results {
+		outcomes[owners[j]] = append(outcomes[owners[j]], targetResult{target: targets[j], result: result})
+	}
+
+	// Files interrupted by shutdown are picked up again on the next start
+	if ctx.Err() != nil {
+		return
+	}
+
+	// The journal is saved before any original is moved, so a crash in
+	// between does not convert the file again
+	entries := make([]*journalEntry, len(files))
+	for i, file := range files {
+		if len(outcomes[i]) > 0 {
+			entries[i] = w.journal.record(file.path, file.info, outcomes[i], w.config.MaxAttempts)
+		}
+	}
+	if err := w.journal.save(); err != nil {
+		w.logger.Error("Cannot save the watch journal", err)
+	}
+
+	for i, file := range files {
+		switch {
+		case entries[i] == nil:
+		case entries[i].Status == statusRetrying:
+			w.logger.Error(fmt.Sprintf("Conversion of %s failed, will retry", file.path), errors.New(entries[i].Error))
+		default:
+			w.finish(file, entries[i])
+		}
+	}
+}
+
+// finish moves a handled original to processed/ or failed/
+func (w *Watcher) finish(file pendingFile, entry *journalEntry) {
+	subdir := ProcessedDir
+	if entry.Status == statusFailed {
+		subdir = FailedDir
+	}
+	moved, err := moveToSubdir(file.path, subdir)
+	if err != nil {
+		// The journal keeps the file from being converted again; the move
+		// is retried on the next scan
+		w.logger.Error(fmt.Sprintf("Cannot move %s to %s", file.path, subdir), err)
+		return
+	}
+	delete(w.seen, file.path)
+	w.journal.remove(file.path)
+
+	if entry.Status == statusFailed {
+		if err := os.WriteFile(moved+".error.txt", []byte(entry.Error+"\n"), 0644); err != nil {
+			w.logger.Error("Cannot write the error report", err)
+		}
+		w.logger.Error(fmt.Sprintf("Conversion of %s failed, moved to %s", file.path, moved), errors.New(entry.Error))
+		return
+	}
+	w.logger.Info(fmt.Sprintf("Converted %s to %s", file.path, strings.Join(entry.Outputs, ", ")))
+}
+
+// moveToSubdir moves path into the named subdirectory of its directory,
+// adding a numeric suffix when a file of that name is already there
+func moveToSubdir(path, subdir string) (string, error) {
+	dir := filepath.Join(filepath.Dir(path), subdir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	name := filepath.Base(path)
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	target := filepath.Join(dir, name)
+	for n := 1; ; n++ {
+		if _, err := os.Lstat(target); os.IsNotExist(err) {
+			break
+		}
+		target = filepath.Join(dir, fmt.Sprintf("%s (%d)%s", base, n, ext))
+	}
+	if err := os.Rename(path, target); err != nil {
+		return "", err
+	}
+	return target, nil
+}
+
+// isCandidate reports whether a file name is an input worth converting,
+// skipping hidden files, Office lock files and partial downloads
+func isCandidate(name string) bool {
+	if strings.HasPrefix(name, ".") || strings.HasPrefix(name, "~$") {
+		return false
+	}
+	_, ok := domain.FileTypeFromExtension(filepath.Ext(name))
+	return ok
+}
+
+// readable reports whether the file can be opened; on Windows a file still
+// being written by another program cannot
+func readable(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	file.Close()
+	return true
+}
+
+// formatList joins formats for log messages
+func formatList(formats []domain.Format) string {
+	names := make([]string, len(formats))
+	for i, format := range formats {
+		names[i] = string(format)
+	}
+	return strings.Join(names, ", ")
+}
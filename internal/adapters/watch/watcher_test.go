@@ -0,0 +1,244 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eka026/File-Format-Converter/internal/domain"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(msg string)             {}
+func (nopLogger) Error(msg string, err error) {}
+func (nopLogger) Debug(msg string)            {}
+
+// fakeConverter writes every target unless the source name asks for a
+// failure: "bad" fails permanently, "flaky" fails once with a timeout
+type fakeConverter struct {
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (c *fakeConverter) BatchConvertJobs(ctx context.Context, jobs []domain.BatchJob) []domain.Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	results := make([]domain.Result, len(jobs))
+	for i, job := range jobs {
+		name := filepath.Base(job.Source)
+		c.calls[name]++
+		switch {
+		case strings.HasPrefix(name, "bad"):
+			results[i] = domain.Result{Error: domain.Errorf(domain.ErrorCodeCorruptInput, "corrupt input")}
+		case strings.HasPrefix(name, "flaky") && c.calls[name] == 1:
+			results[i] = domain.Result{Error: domain.Errorf(domain.ErrorCodeTimeout, "engine timed out")}
+		default:
+			if err := os.WriteFile(job.Target, []byte("converted"), 0644); err != nil {
+				results[i] = domain.Result{Error: err}
+				continue
+			}
+			results[i] = domain.Result{Success: true, OutputPath: job.Target}
+		}
+	}
+	return results
+}
+
+// testWatcher is a watcher over a fresh directory with a clock the test advances
+type testWatcher struct {
+	*Watcher
+	converter *fakeConverter
+	clock     time.Time
+}
+
+func newTestWatcher(t *testing.T, dir, statePath string) *testWatcher {
+	t.Helper()
+	converter := &fakeConverter{calls: make(map[string]int)}
+	w, err := New(converter, nopLogger{}, Config{
+		Folders:   []Folder{{Dir: dir, Targets: []domain.Format{domain.FormatJPEG, domain.FormatWEBP}}},
+		Settle:    time.Minute,
+		StatePath: statePath,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create watcher: %v", err)
+	}
+	tw := &testWatcher{Watcher: w, converter: converter, clock: time.Now()}
+	w.now = func() time.Time { return tw.clock }
+	return tw
+}
+
+// scanAfter advances the clock and scans
+func (w *testWatcher) scanAfter(d time.Duration) {
+	w.clock = w.clock.Add(d)
+	w.Scan(context.Background())
+}
+
+func drop(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// TestWatcher_ConvertsSettledFiles tests that a dropped file is converted to every target and moved to processed/
+func TestWatcher_ConvertsSettledFiles(t *testing.T) {
+	dir := t.TempDir()
+	w := newTestWatcher(t, dir, "")
+	drop(t, filepath.Join(dir, "scan.png"), "png")
+	drop(t, filepath.Join(dir, "notes.txt"), "text")
+
+	w.scanAfter(0)
+	if w.converter.calls["scan.png"] != 0 {
+		t.Fatalf("Expected a new file to wait until it settles")
+	}
+	w.scanAfter(time.Minute)
+
+	for _, path := range []string{
+		filepath.Join(dir, ConvertedDir, "scan.jpeg"),
+		filepath.Join(dir, ConvertedDir, "scan.webp"),
+		filepath.Join(dir, ProcessedDir, "scan.png"),
+		filepath.Join(dir, "notes.txt"),
+	} {
+		if !exists(path) {
+			t.Errorf("Expected %s to exist", path)
+		}
+	}
+	if exists(filepath.Join(dir, "scan.png")) {
+		t.Errorf("Expected the original to be moved out of the watched folder")
+	}
+}
+
+// TestWatcher_WaitsForWrites tests that a file still growing is not converted
+func TestWatcher_WaitsForWrites(t *testing.T) {
+	dir := t.TempDir()
+	w := newTestWatcher(t, dir, "")
+	path := filepath.Join(dir, "export.png")
+	drop(t, path, "part")
+
+	w.scanAfter(0)
+	drop(t, path, "partial write")
+	w.scanAfter(time.Minute)
+	if w.converter.calls["export.png"] != 0 {
+		t.Fatalf("Expected a file that changed since the last scan not to be converted")
+	}
+	w.scanAfter(time.Minute)
+	if w.converter.calls["export.png"] == 0 {
+		t.Errorf("Expected the file to be converted once it stopped changing")
+	}
+}
+
+// TestWatcher_MovesFailures tests that failed files go to failed/ with an error report
+func TestWatcher_MovesFailures(t *testing.T) {
+	dir := t.TempDir()
+	w := newTestWatcher(t, dir, "")
+	drop(t, filepath.Join(dir, "bad.png"), "png")
+
+	w.scanAfter(0)
+	w.scanAfter(time.Minute)
+
+	report, err := os.ReadFile(filepath.Join(dir, FailedDir, "bad.png.error.txt"))
+	if err != nil {
+		t.Fatalf("Expected an error report: %v", err)
+	}
+	if !strings.Contains(string(report), "corrupt input") {
+		t.Errorf("Expected the report to explain the failure, got %q", report)
+	}
+	if !exists(filepath.Join(dir, FailedDir, "bad.png")) {
+		t.Errorf("Expected the original in %s", FailedDir)
+	}
+}
+
+// TestWatcher_RetriesTransientFailures tests that a timed out file stays in place and is tried again
+func TestWatcher_RetriesTransientFailures(t *testing.T) {
+	dir := t.TempDir()
+	w := newTestWatcher(t, dir, "")
+	drop(t, filepath.Join(dir, "flaky.png"), "png")
+
+	w.scanAfter(0)
+	w.scanAfter(time.Minute)
+	if !exists(filepath.Join(dir, "flaky.png")) {
+		t.Fatalf("Expected the file to stay for a retry")
+	}
+	w.scanAfter(w.config.Interval)
+	if !exists(filepath.Join(dir, ProcessedDir, "flaky.png")) {
+		t.Errorf("Expected the retry to succeed")
+	}
+}
+
+// TestWatcher_RetriesOnlyFailedTargets tests that a retry does not convert
+// the targets that already succeeded again
+func TestWatcher_RetriesOnlyFailedTargets(t *testing.T) {
+	dir := t.TempDir()
+	w := newTestWatcher(t, dir, "")
+	drop(t, filepath.Join(dir, "flaky.png"), "png")
+
+	// The JPEG target fails once and the WebP one succeeds
+	w.scanAfter(0)
+	w.scanAfter(time.Minute)
+	entry, ok := w.journal.Files[filepath.Join(dir, "flaky.png")]
+	if !ok || entry.Status != statusRetrying {
+		t.Fatalf("Expected the file to wait for a retry, got %+v", entry)
+	}
+	w.scanAfter(w.config.Interval)
+
+	if calls := w.converter.calls["flaky.png"]; calls != 3 {
+		t.Errorf("Expected only the failed target to be converted again, got %d conversions", calls)
+	}
+	if !exists(filepath.Join(dir, ProcessedDir, "flaky.png")) {
+		t.Errorf("Expected the retry to succeed")
+	}
+}
+
+// TestNew_RejectsOutputInWatchedFolder tests that outputs cannot be written
+// where they would be picked up as new inputs
+func TestNew_RejectsOutputInWatchedFolder(t *testing.T) {
+	dir := t.TempDir()
+	for _, outputDir := range []string{dir, dir + string(filepath.Separator) + "."} {
+		_, err := New(&fakeConverter{calls: make(map[string]int)}, nopLogger{}, Config{
+			Folders: []Folder{{Dir: dir, Targets: []domain.Format{domain.FormatPNG}, OutputDir: outputDir}},
+		})
+		if err == nil {
+			t.Errorf("Expected output folder %s to be rejected", outputDir)
+		}
+	}
+}
+
+// TestWatcher_JournalSurvivesRestart tests that a converted file whose move
+// failed is not converted again after a restart
+func TestWatcher_JournalSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	statePath := filepath.Join(t.TempDir(), "watch.json")
+	// A file named like the subfolder makes the move fail
+	blocker := filepath.Join(dir, ProcessedDir)
+	drop(t, blocker, "")
+	drop(t, filepath.Join(dir, "scan.png"), "png")
+
+	w := newTestWatcher(t, dir, statePath)
+	w.scanAfter(0)
+	w.scanAfter(time.Minute)
+	if w.converter.calls["scan.png"] == 0 || !exists(filepath.Join(dir, "scan.png")) {
+		t.Fatalf("Expected the file to be converted but not moved")
+	}
+
+	if err := os.Remove(blocker); err != nil {
+		t.Fatalf("Failed to remove blocker: %v", err)
+	}
+	restarted := newTestWatcher(t, dir, statePath)
+	restarted.scanAfter(0)
+	restarted.scanAfter(time.Minute)
+	if calls := restarted.converter.calls["scan.png"]; calls != 0 {
+		t.Errorf("Expected no conversion after the restart, got %d", calls)
+	}
+	if !exists(filepath.Join(dir, ProcessedDir, "scan.png")) {
+		t.Errorf("Expected the original to be moved after the restart")
+	}
+}
@@ -1,17 +1,295 @@
+// Package config holds the application settings. Load layers them: the
+// defaults, then the config file in the user config directory, then the
+// CONVERTER_* environment variables. Command lines add flags on top with
+// RegisterFlags.
 package config
 
-// Config holds application configuration
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/eka026/File-Format-Converter/internal/domain"
+)
+
+const (
+	// AppDir is the application's directory inside the user config directory
+	AppDir = "file-format-converter"
+	// FileName is the config file inside AppDir
+	FileName = "config.json"
+	// EnvFile names another config file in place of the default one
+	EnvFile = "CONVERTER_CONFIG"
+	// envPrefix starts the environment variable of every setting
+	envPrefix = "CONVERTER_"
+)
+
+// Config holds application configuration. Zero numbers and empty strings
+// select the defaults of the component they configure.
 type Config struct {
-	LogLevel      string
-	WorkerPoolSize int
-	WasmPath      string
+	// LogLevel is debug, info or error
+	LogLevel string `json:"logLevel,omitempty"`
+	// Workers is how many conversions run at once across every batch; 0
+	// uses one per CPU
+	Workers int `json:"workers,omitempty"`
+	// BatchConcurrency is how many files of one batch are converted at once;
+	// 0 uses one per CPU
+	BatchConcurrency int `json:"batchConcurrency,omitempty"`
+	// BrowserPath is the Chrome, Chromium or Edge executable used for PDF
+	// output; empty searches the usual install locations
+	BrowserPath string `json:"browserPath,omitempty"`
+	// BrowserTimeout bounds each attempt of an engine that renders in the
+	// browser, and EngineTimeout each attempt of an in-process engine
+	BrowserTimeout Duration `json:"browserTimeout,omitempty"`
+	EngineTimeout  Duration `json:"engineTimeout,omitempty"`
+	// DefaultQuality is the JPEG quality of conversions that set none; 0
+	// uses the encoder's default
+	DefaultQuality int `json:"defaultQuality,omitempty"`
+	// Collision is what happens when an output exists: overwrite, skip,
+	// suffix or fail. Empty keeps each front end's default.
+	Collision string `json:"collision,omitempty"`
+	// NoCache disables the conversion cache
+	NoCache bool `json:"noCache,omitempty"`
+	// CacheMaxBytes bounds the conversion cache; 0 uses cache.DefaultMaxBytes
+	CacheMaxBytes int64 `json:"cacheMaxBytes,omitempty"`
+	// EngineConcurrency limits how many conversions each named engine runs
+	// at once; engines not listed keep their default
+	EngineConcurrency map[string]int `json:"engineConcurrency,omitempty"`
+	// The resource limits of inputs; 0 keeps the field of
+	// domain.DefaultResourceLimits
+	MaxFileSize       int64   `json:"maxFileSize,omitempty"`
+	MaxImageDimension int     `json:"maxImageDimension,omitempty"`
+	MaxImagePixels    int64   `json:"maxImagePixels,omitempty"`
+	MaxZipEntrySize   int64   `json:"maxZipEntrySize,omitempty"`
+	MaxZipRatio       float64 `json:"maxZipRatio,omitempty"`
+	MaxSheetRows      int     `json:"maxSheetRows,omitempty"`
+	MaxSheetCells     int64   `json:"maxSheetCells,omitempty"`
 }
 
 // DefaultConfig returns default configuration
 func DefaultConfig() *Config {
 	return &Config{
-		LogLevel:      "info",
-		WorkerPoolSize: 0, // 0 means use NumCPU
+		LogLevel:       "info",
+		BrowserTimeout: Duration(domain.DefaultBrowserTimeout),
+		EngineTimeout:  Duration(domain.DefaultEngineTimeout),
+	}
+}
+
+// Dir returns the application's directory inside the user config directory
+func Dir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, AppDir), nil
+}
+
+// Load returns the defaults overlaid with the config file and the
+// environment. The file named by CONVERTER_CONFIG must exist; the default
+// one is optional.
+func Load() (*Config, error) {
+	c := DefaultConfig()
+	path, named := os.LookupEnv(EnvFile)
+	if !named || path == "" {
+		dir, err := Dir()
+		if err != nil {
+			return nil, err
+		}
+		path, named = filepath.Join(dir, FileName), false
+	}
+	if err := c.ReadFile(path); err != nil && (named || !errors.Is(err, os.ErrNotExist)) {
+		return nil, err
+	}
+	if err := c.ApplyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// ReadFile overlays the settings present in a JSON config file
+func (c *Config) ReadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config: %w", err)
 	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(c); err != nil {
+		return fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return nil
+}
+
+// ApplyEnv overlays the settings found by lookup, usually os.LookupEnv.
+// Each setting's variable is its flag name in upper case with a CONVERTER_
+// prefix, such as CONVERTER_BROWSER_TIMEOUT.
+func (c *Config) ApplyEnv(lookup func(string) (string, bool)) error {
+	for _, s := range c.settings() {
+		name := EnvName(s.name)
+		if value, ok := lookup(name); ok {
+			if err := s.value.Set(value); err != nil {
+				return fmt.Errorf("invalid %s: %w", name, err)
+			}
+		}
+	}
+	return nil
 }
 
+// EnvName returns the environment variable of the setting with the given
+// flag name
+func EnvName(flagName string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// RegisterFlags defines a flag for every setting on fs. Flags default to
+// the current values, so parsing overlays only the flags given.
+func (c *Config) RegisterFlags(fs *flag.FlagSet) {
+	for _, s := range c.settings() {
+		fs.Var(s.value, s.name, s.usage)
+	}
+}
+
+// Validate rejects settings out of range
+func (c *Config) Validate() error {
+	switch c.LogLevel {
+	case "debug", "info", "error":
+	default:
+		return fmt.Errorf("invalid log level %q: use debug, info or error", c.LogLevel)
+	}
+	if c.Workers < 0 {
+		return fmt.Errorf("workers must not be negative, got %d", c.Workers)
+	}
+	if c.BatchConcurrency < 0 {
+		return fmt.Errorf("batch concurrency must not be negative, got %d", c.BatchConcurrency)
+	}
+	if c.BrowserTimeout < 0 || c.EngineTimeout < 0 {
+		return errors.New("timeouts must not be negative")
+	}
+	if c.DefaultQuality < 0 || c.DefaultQuality > 100 {
+		return fmt.Errorf("default quality must be between 0 and 100, got %d", c.DefaultQuality)
+	}
+	if err := (domain.ConversionOptions{Output: domain.OutputOptions{Collision: domain.CollisionPolicy(c.Collision)}}).Validate(); err != nil {
+		return err
+	}
+	if c.CacheMaxBytes < 0 {
+		return fmt.Errorf("cache size must not be negative, got %d", c.CacheMaxBytes)
+	}
+	for name, limit := range c.EngineConcurrency {
+		if limit < 0 {
+			return fmt.Errorf("concurrency of engine %s must not be negative, got %d", name, limit)
+		}
+	}
+	if c.MaxFileSize < 0 || c.MaxImageDimension < 0 || c.MaxImagePixels < 0 || c.MaxZipEntrySize < 0 ||
+		c.MaxZipRatio < 0 || c.MaxSheetRows < 0 || c.MaxSheetCells < 0 {
+		return errors.New("resource limits must not be negative")
+	}
+	if c.BrowserPath != "" {
+		info, err := os.Stat(c.BrowserPath)
+		if err != nil {
+			return fmt.Errorf("browser path: %w", err)
+		}
+		if info.IsDir() {
+			return fmt.Errorf("browser path %s is a directory", c.BrowserPath)
+		}
+	}
+	return nil
+}
+
+// ServiceOptions returns the converter service options the settings select
+func (c *Config) ServiceOptions() []domain.ServiceOption {
+	opts := []domain.ServiceOption{
+		domain.WithBatchConcurrency(c.BatchConcurrency),
+		domain.WithDefaultTimeouts(time.Duration(c.BrowserTimeout), time.Duration(c.EngineTimeout)),
+	}
+	if c.Collision != "" {
+		opts = append(opts, domain.WithCollisionPolicy(domain.CollisionPolicy(c.Collision)))
+	}
+	opts = append(opts, domain.WithResourceLimits(c.ResourceLimits()))
+	for _, name := range sortedKeys(c.EngineConcurrency) {
+		opts = append(opts, domain.WithEngineConcurrency(name, c.EngineConcurrency[name]))
+	}
+	return opts
+}
+
+// ResourceLimits returns domain.DefaultResourceLimits with the limits the
+// settings change
+func (c *Config) ResourceLimits() domain.ResourceLimits {
+	limits := domain.DefaultResourceLimits
+	if c.MaxFileSize > 0 {
+		limits.MaxFileSize = c.MaxFileSize
+	}
+	if c.MaxImageDimension > 0 {
+		limits.MaxImageDimension = c.MaxImageDimension
+	}
+	if c.MaxImagePixels > 0 {
+		limits.MaxImagePixels = c.MaxImagePixels
+	}
+	if c.MaxZipEntrySize > 0 {
+		limits.MaxZipEntrySize = c.MaxZipEntrySize
+	}
+	if c.MaxZipRatio > 0 {
+		limits.MaxZipRatio = c.MaxZipRatio
+	}
+	if c.MaxSheetRows > 0 {
+		limits.MaxSheetRows = c.MaxSheetRows
+	}
+	if c.MaxSheetCells > 0 {
+		limits.MaxSheetCells = c.MaxSheetCells
+	}
+	return limits
+}
+
+// EngineSettings returns the engine defaults the settings select
+func (c *Config) EngineSettings() domain.EngineSettings {
+	return domain.EngineSettings{DefaultQuality: c.DefaultQuality}
+}
+
+// setting is a configurable field with its flag
+type setting struct {
+	name  string
+	usage string
+	value flag.Value
+}
+
+// settings lists the fields that environment variables and flags can set
+func (c *Config) settings() []setting {
+	return []setting{
+		{"log-level", "log `level`: debug, info or error", (*stringValue)(&c.LogLevel)},
+		{"workers", "`number` of conversions run at once across batches; 0 uses one per CPU", (*intValue)(&c.Workers)},
+		{"concurrency", "`number` of files of a batch converted at once; 0 uses one per CPU", (*intValue)(&c.BatchConcurrency)},
+		{"browser", "Chrome, Chromium or Edge `executable` for PDF output; found automatically by default", (*stringValue)(&c.BrowserPath)},
+		{"browser-timeout", "time limit of each browser render as a `duration` such as 90s; 0 disables it", &c.BrowserTimeout},
+		{"engine-timeout", "time limit of each in-process engine call as a `duration` such as 5m; 0 disables it", &c.EngineTimeout},
+		{"default-quality", "JPEG `quality` of conversions that set none; 0 uses the encoder's default", (*intValue)(&c.DefaultQuality)},
+		{"collision", "`policy` when an output exists: overwrite, skip, suffix or fail; the command line overwrites and the GUI suffixes by default", (*stringValue)(&c.Collision)},
+		{"no-cache", "do not use the conversion cache", (*boolValue)(&c.NoCache)},
+		{"cache-size", "largest size of the conversion cache in `bytes`; 0 uses the default", (*int64Value)(&c.CacheMaxBytes)},
+		{"engine-concurrency", "conversions each engine runs at once as `limits` such as document=2,image=4; engines not listed keep their default", (*limitsValue)(&c.EngineConcurrency)},
+		{"max-file-size", "largest input in `bytes`; 0 uses the default", (*int64Value)(&c.MaxFileSize)},
+		{"max-image-dimension", "largest image width or height in `pixels`; 0 uses the default", (*intValue)(&c.MaxImageDimension)},
+		{"max-image-pixels", "largest image width times height in `pixels`; 0 uses the default", (*int64Value)(&c.MaxImagePixels)},
+		{"max-zip-entry-size", "largest uncompressed DOCX or XLSX part in `bytes`; 0 uses the default", (*int64Value)(&c.MaxZipEntrySize)},
+		{"max-zip-ratio", "largest compression `ratio` of a DOCX or XLSX part; 0 uses the default", (*float64Value)(&c.MaxZipRatio)},
+		{"max-sheet-rows", "largest `number` of rows read from a worksheet; 0 uses the default", (*intValue)(&c.MaxSheetRows)},
+		{"max-sheet-cells", "largest `number` of cells read from a worksheet; 0 uses the default", (*int64Value)(&c.MaxSheetCells)},
+	}
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
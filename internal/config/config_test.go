@@ -0,0 +1,147 @@
+package config
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eka026/File-Format-Converter/internal/domain"
+)
+
+// TestLoad_Layers tests that the environment overrides the file and flags override both
+func TestLoad_Layers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	content := `{"logLevel": "error", "workers": 2, "defaultQuality": 70, "browserTimeout": "30s", "collision": "skip"}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	t.Setenv(EnvFile, path)
+	t.Setenv("CONVERTER_WORKERS", "4")
+	t.Setenv("CONVERTER_NO_CACHE", "true")
+
+	c, err := Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if c.LogLevel != "error" || c.DefaultQuality != 70 || c.Collision != "skip" {
+		t.Errorf("Expected the file's settings, got %+v", c)
+	}
+	if c.Workers != 4 || !c.NoCache {
+		t.Errorf("Expected the environment to override the file, got %+v", c)
+	}
+	if c.EngineTimeout != DefaultConfig().EngineTimeout {
+		t.Errorf("Expected unset settings to keep their defaults, got %v", c.EngineTimeout)
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	c.RegisterFlags(fs)
+	if err := fs.Parse([]string{"--workers", "8", "--browser-timeout", "1m", "--no-cache=false"}); err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+	if c.Workers != 8 || time.Duration(c.BrowserTimeout) != time.Minute || c.NoCache {
+		t.Errorf("Expected flags to override the environment, got %+v", c)
+	}
+	if c.DefaultQuality != 70 {
+		t.Errorf("Expected flags not given to keep the loaded value, got %d", c.DefaultQuality)
+	}
+}
+
+// TestLoad_Limits tests that resource limits and engine concurrency are read
+// from every layer and that unset limits keep their defaults
+func TestLoad_Limits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	content := `{"maxFileSize": 1048576, "maxSheetRows": 500, "engineConcurrency": {"document": 2, "image": 8}}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	t.Setenv(EnvFile, path)
+	t.Setenv("CONVERTER_MAX_ZIP_RATIO", "50")
+	t.Setenv("CONVERTER_ENGINE_CONCURRENCY", "image=4,spreadsheet=1")
+
+	c, err := Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	c.RegisterFlags(fs)
+	if err := fs.Parse([]string{"--max-sheet-cells", "1000"}); err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+
+	want := domain.DefaultResourceLimits
+	want.MaxFileSize = 1 << 20
+	want.MaxSheetRows = 500
+	want.MaxZipRatio = 50
+	want.MaxSheetCells = 1000
+	if got := c.ResourceLimits(); got != want {
+		t.Errorf("Expected limits %+v, got %+v", want, got)
+	}
+	wantConcurrency := map[string]int{"document": 2, "image": 4, "spreadsheet": 1}
+	if !reflect.DeepEqual(c.EngineConcurrency, wantConcurrency) {
+		t.Errorf("Expected engine concurrency %v, got %v", wantConcurrency, c.EngineConcurrency)
+	}
+}
+
+// TestLoad_Errors tests that a missing named file and invalid values are reported
+func TestLoad_Errors(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+		return path
+	}
+	tests := []struct {
+		name string
+		file string
+		env  map[string]string
+		want string
+	}{
+		{"missing named file", filepath.Join(dir, "missing.json"), nil, "reading config"},
+		{"unknown field", write("unknown.json", `{"wasmPath": "engine.wasm"}`), nil, "unknown field"},
+		{"invalid level", write("level.json", `{"logLevel": "loud"}`), nil, "invalid log level"},
+		{"invalid env number", write("empty.json", `{}`), map[string]string{"CONVERTER_CONCURRENCY": "many"}, "CONVERTER_CONCURRENCY"},
+		{"invalid quality", write("quality.json", `{"defaultQuality": 101}`), nil, "default quality"},
+		{"invalid collision", write("collision.json", `{"collision": "replace"}`), nil, "collision"},
+		{"missing browser", write("browser.json", `{"browserPath": "/nonexistent/chrome"}`), nil, "browser path"},
+		{"negative limit", write("limit.json", `{"maxFileSize": -1}`), nil, "resource limits"},
+		{"negative engine concurrency", write("engine.json", `{"engineConcurrency": {"document": -1}}`), nil, "engine document"},
+		{"invalid engine concurrency", write("empty.json", `{}`), map[string]string{"CONVERTER_ENGINE_CONCURRENCY": "document"}, "name=limit"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvFile, tt.file)
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			if _, err := Load(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+// TestRegisterFlags tests that the flag defaults show the loaded values
+func TestRegisterFlags(t *testing.T) {
+	c := DefaultConfig()
+	c.DefaultQuality = 85
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	c.RegisterFlags(fs)
+
+	if got := fs.Lookup("default-quality").DefValue; got != "85" {
+		t.Errorf("Expected the default to be the loaded value, got %s", got)
+	}
+	if got := fs.Lookup("engine-timeout").DefValue; got != "10m0s" {
+		t.Errorf("Expected the default engine timeout, got %s", got)
+	}
+	if EnvName("browser-timeout") != "CONVERTER_BROWSER_TIMEOUT" {
+		t.Errorf("Unexpected environment variable %s", EnvName("browser-timeout"))
+	}
+}
@@ -0,0 +1,148 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Duration is a time.Duration written as a string such as "5s" in JSON,
+// environment variables and flags
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return fmt.Errorf("duration must be a string such as \"5s\": %w", err)
+	}
+	return d.Set(text)
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// Set parses a duration such as "90s"; it implements flag.Value
+func (d *Duration) Set(text string) error {
+	parsed, err := time.ParseDuration(text)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+// The flag.Value types of the other settings. Unlike the flag package's own,
+// they write straight into the Config field they point at.
+type (
+	stringValue  string
+	intValue     int
+	int64Value   int64
+	float64Value float64
+	boolValue    bool
+	// limitsValue holds per-name limits written as name=limit pairs
+	// separated by commas; each pair set replaces that name's limit
+	limitsValue map[string]int
+)
+
+func (v *stringValue) Set(text string) error {
+	*v = stringValue(text)
+	return nil
+}
+
+func (v *stringValue) String() string {
+	return string(*v)
+}
+
+func (v *intValue) Set(text string) error {
+	n, err := strconv.Atoi(text)
+	if err != nil {
+		return fmt.Errorf("%q is not a number", text)
+	}
+	*v = intValue(n)
+	return nil
+}
+
+func (v *intValue) String() string {
+	return strconv.Itoa(int(*v))
+}
+
+func (v *int64Value) Set(text string) error {
+	n, err := strconv.ParseInt(text, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%q is not a number", text)
+	}
+	*v = int64Value(n)
+	return nil
+}
+
+func (v *int64Value) String() string {
+	return strconv.FormatInt(int64(*v), 10)
+}
+
+func (v *float64Value) Set(text string) error {
+	n, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return fmt.Errorf("%q is not a number", text)
+	}
+	*v = float64Value(n)
+	return nil
+}
+
+func (v *float64Value) String() string {
+	return strconv.FormatFloat(float64(*v), 'g', -1, 64)
+}
+
+func (v *limitsValue) Set(text string) error {
+	if *v == nil {
+		*v = make(limitsValue)
+	}
+	for _, pair := range strings.Split(text, ",") {
+		name, limit, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || name == "" {
+			return fmt.Errorf("%q is not a name=limit pair", pair)
+		}
+		n, err := strconv.Atoi(limit)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", limit)
+		}
+		(*v)[name] = n
+	}
+	return nil
+}
+
+func (v *limitsValue) String() string {
+	if v == nil {
+		return ""
+	}
+	names := sortedKeys(*v)
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + "=" + strconv.Itoa((*v)[name])
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (v *boolValue) Set(text string) error {
+	b, err := strconv.ParseBool(text)
+	if err != nil {
+		return fmt.Errorf("%q is not a boolean", text)
+	}
+	*v = boolValue(b)
+	return nil
+}
+
+func (v *boolValue) String() string {
+	return strconv.FormatBool(bool(*v))
+}
+
+// IsBoolFlag lets the flag be given without a value
+func (v *boolValue) IsBoolFlag() bool {
+	return true
+}
@@ -0,0 +1,160 @@
+package domain
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ConversionCache stores converted outputs under a content key so repeated
+// conversions of the same input with the same engines and options can be
+// served without running the engines again. Each output is stored with
+// opaque metadata the service uses to report the conversion on a hit.
+type ConversionCache interface {
+	// Get copies the cached output for key to dstPath and returns the
+	// metadata stored with it, reporting whether it was found
+	Get(key, dstPath string) ([]byte, bool, error)
+	// Put stores a copy of the file at srcPath and metadata under key
+	Put(key, srcPath string, metadata []byte) error
+}
+
+// WithCache serves repeated conversions from cache. Entries are keyed by the
+// SHA-256 of the input bytes, the name and version of every engine on the
+// route, their option defaults and the conversion options.
+func WithCache(cache ConversionCache) ServiceOption {
+	return func(s *ConverterService) {
+		s.cache = cache
+	}
+}
+
+// cacheKey derives the cache key of converting input along route with opts
+func cacheKey(input io.Reader, route Route, opts ConversionOptions) (string, error) {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, input); err != nil {
+		return "", err
+	}
+	for _, step := range route.Steps {
+		capabilities := step.Engine.Capabilities()
+		fmt.Fprintf(hash, "\x00%s@%s:%s>%s", capabilities.Name, capabilities.Version, step.Edge.From, step.Edge.To)
+		// Configured defaults change the output of options left unset
+		for _, option := range capabilities.Options {
+			fmt.Fprintf(hash, "\x00%s=%s", option.Name, option.Default)
+		}
+	}
+
+	// Output handling does not change the converted bytes
+	opts.Output = OutputOptions{}
+	encoded, err := json.Marshal(opts)
+	if err != nil {
+		return "", err
+	}
+	hash.Write([]byte{0})
+	hash.Write(encoded)
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// fileCacheKey derives the cache key of converting the file at source
+func fileCacheKey(source string, route Route, opts ConversionOptions) (string, error) {
+	file, err := os.Open(source)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	return cacheKey(file, route, opts)
+}
+
+// executeCached produces target from the cache when possible and otherwise
+// runs the route and stores the output. Cache failures never fail a
+// conversion; they are logged and the route runs as if there were no cache.
+func (s *ConverterService) executeCached(ctx context.Context, route Route, source, target string, opts ConversionOptions) (cached bool, err error) {
+	if s.cache == nil {
+		return false, s.executeRoute(ctx, route, source, target, opts)
+	}
+
+	key, err := fileCacheKey(source, route, opts)
+	if err != nil {
+		s.logger.Error("Failed to compute cache key", err)
+		return false, s.executeRoute(ctx, route, source, target, opts)
+	}
+
+	if s.cacheGet(ctx, key, target) {
+		s.logger.Info(fmt.Sprintf("Serving cached conversion for %s", source))
+		return true, nil
+	}
+
+	if err := s.executeRoute(ctx, route, source, target, opts); err != nil {
+		return false, err
+	}
+	s.cachePut(ctx, key, target)
+	return false, nil
+}
+
+// cacheGet copies the cached output for key to target and restores the
+// report of the conversion that produced it, reporting whether it was found
+func (s *ConverterService) cacheGet(ctx context.Context, key, target string) bool {
+	metadata, hit, err := s.cache.Get(key, target)
+	if err != nil {
+		s.logger.Error("Failed to read conversion cache", err)
+		return false
+	}
+	if !hit || len(metadata) == 0 {
+		return hit
+	}
+
+	var report cachedReport
+	if err := json.Unmarshal(metadata, &report); err != nil {
+		// The output is still good; only its report is lost
+		s.logger.Error("Failed to read cached conversion report", err)
+		return true
+	}
+	if log := conversionLogFrom(ctx); log != nil {
+		log.restore(report)
+	}
+	return true
+}
+
+// cachePut stores target under key together with the report collected so
+// far for the conversion that produced it
+func (s *ConverterService) cachePut(ctx context.Context, key, target string) {
+	var metadata []byte
+	if log := conversionLogFrom(ctx); log != nil {
+		encoded, err := json.Marshal(log.snapshot())
+		if err != nil {
+			s.logger.Error("Failed to encode conversion report for cache", err)
+			return
+		}
+		metadata = encoded
+	}
+	if err := s.cache.Put(key, target, metadata); err != nil {
+		s.logger.Error("Failed to store conversion in cache", err)
+	}
+}
+
+// streamCacheKey buffers a stream input so it can be hashed and still be
+// converted afterwards. The key is empty when there is no cache or no route.
+func (s *ConverterService) streamCacheKey(input io.Reader, inputType FileType, format Format, opts ConversionOptions) (io.Reader, string, error) {
+	if s.cache == nil {
+		return input, "", nil
+	}
+	route, err := s.planner.Plan(inputType, format)
+	if err != nil {
+		// ConvertStream reports the missing route
+		return input, "", nil
+	}
+
+	data, err := io.ReadAll(input)
+	if err != nil {
+		return nil, "", withCode(ErrorCodeCorruptInput, fmt.Errorf("reading input: %w", err))
+	}
+	key, err := cacheKey(bytes.NewReader(data), route, opts)
+	if err != nil {
+		s.logger.Error("Failed to compute cache key", err)
+		return bytes.NewReader(data), "", nil
+	}
+	return bytes.NewReader(data), key, nil
+}
@@ -0,0 +1,158 @@
+package domain
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// memoryCache is a ConversionCache holding entries in memory
+type memoryCache struct {
+	mu       sync.Mutex
+	entries  map[string][]byte
+	metadata map[string][]byte
+}
+
+func (c *memoryCache) Get(key, dstPath string) ([]byte, bool, error) {
+	c.mu.Lock()
+	data, ok := c.entries[key]
+	metadata := c.metadata[key]
+	c.mu.Unlock()
+	if !ok {
+		return nil, false, nil
+	}
+	return metadata, true, os.WriteFile(dstPath, data, 0644)
+}
+
+func (c *memoryCache) Put(key, srcPath string, metadata []byte) error {
+	data, err := os.ReadFile(srcPath)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = data
+	if c.metadata == nil {
+		c.metadata = make(map[string][]byte)
+	}
+	c.metadata[key] = metadata
+	return nil
+}
+
+// countingEngine is a prefixEngine that counts its conversions
+type countingEngine struct {
+	prefixStreamEngine
+	calls int32
+}
+
+func (e *countingEngine) Convert(ctx context.Context, input, output string, opts ConversionOptions) error {
+	atomic.AddInt32(&e.calls, 1)
+	return e.prefixEngine.Convert(ctx, input, output, opts)
+}
+
+// TestConverterService_Convert_Cache tests that repeated conversions are served
+// from the cache and that content and option changes miss it
+func TestConverterService_Convert_Cache(t *testing.T) {
+	engine := &countingEngine{prefixStreamEngine: prefixStreamEngine{prefixEngine: *newPrefixDocumentEngine()}}
+	cache := &memoryCache{entries: make(map[string][]byte)}
+	service, source := newOutputTestService(t, engine, WithCache(cache))
+	dir := filepath.Dir(source)
+
+	convert := func(name string, opts ConversionOptions) Result {
+		t.Helper()
+		result := service.Convert(context.Background(), source, filepath.Join(dir, name), opts)
+		if !result.Success {
+			t.Fatalf("Conversion failed: %v", result.Error)
+		}
+		return result
+	}
+
+	if result := convert("first.html", ConversionOptions{}); result.Cached {
+		t.Error("Expected the first conversion to miss the cache")
+	}
+	result := convert("second.html", ConversionOptions{Output: OutputOptions{Collision: CollisionFail}})
+	if !result.Cached {
+		t.Error("Expected the repeated conversion to be served from the cache")
+	}
+	if got := readOutput(t, result.OutputPath); got != "html:docx" {
+		t.Errorf("Expected cached content %q, got %q", "html:docx", got)
+	}
+	if calls := atomic.LoadInt32(&engine.calls); calls != 1 {
+		t.Errorf("Expected the engine to run once, ran %d times", calls)
+	}
+
+	if result := convert("third.html", ConversionOptions{Image: ImageOptions{Quality: 80}}); result.Cached {
+		t.Error("Expected different options to miss the cache")
+	}
+	if err := os.WriteFile(source, []byte("edited"), 0644); err != nil {
+		t.Fatalf("Failed to edit source: %v", err)
+	}
+	result = convert("fourth.html", ConversionOptions{})
+	if result.Cached {
+		t.Error("Expected edited content to miss the cache")
+	}
+	if got := readOutput(t, result.OutputPath); got != "html:edited" {
+		t.Errorf("Expected fresh content %q, got %q", "html:edited", got)
+	}
+}
+
+// TestConverterService_ConvertStreamToFile_Cache tests that stream conversions share the cache
+func TestConverterService_ConvertStreamToFile_Cache(t *testing.T) {
+	engine := &countingEngine{prefixStreamEngine: prefixStreamEngine{prefixEngine: *newPrefixDocumentEngine()}}
+	cache := &memoryCache{entries: make(map[string][]byte)}
+	service, source := newOutputTestService(t, engine, WithCache(cache))
+	dir := filepath.Dir(source)
+
+	if result := service.Convert(context.Background(), source, filepath.Join(dir, "file.html"), ConversionOptions{}); !result.Success {
+		t.Fatalf("Conversion failed: %v", result.Error)
+	}
+	result := service.ConvertStreamToFile(context.Background(), strings.NewReader("docx"), FileTypeDOCX, filepath.Join(dir, "stream.html"), ConversionOptions{})
+	if !result.Success {
+		t.Fatalf("Conversion failed: %v", result.Error)
+	}
+	if !result.Cached {
+		t.Error("Expected the stream conversion to be served from the cache")
+	}
+	if got := readOutput(t, result.OutputPath); got != "html:docx" {
+		t.Errorf("Expected cached content %q, got %q", "html:docx", got)
+	}
+}
+
+// optionEngine is a stubEngine reporting a configured option default
+type optionEngine struct {
+	stubEngine
+	quality string
+}
+
+func (e *optionEngine) Capabilities() Capabilities {
+	capabilities := e.stubEngine.Capabilities()
+	capabilities.Options = []OptionDescriptor{{Name: "quality", Type: "int", Default: e.quality}}
+	return capabilities
+}
+
+// TestCacheKey_OptionDefaults tests that engines configured with different
+// option defaults do not share cache entries
+func TestCacheKey_OptionDefaults(t *testing.T) {
+	key := func(quality string) string {
+		t.Helper()
+		edge := ConversionEdge{From: FileTypePNG, To: FormatJPEG}
+		engine := &optionEngine{stubEngine: stubEngine{name: "image", edges: []ConversionEdge{edge}}, quality: quality}
+		route := Route{Steps: []RouteStep{{Edge: edge, Engine: engine}}}
+		key, err := cacheKey(strings.NewReader("png"), route, ConversionOptions{})
+		if err != nil {
+			t.Fatalf("Failed to compute cache key: %v", err)
+		}
+		return key
+	}
+
+	if key("95") != key("95") {
+		t.Error("Expected equal defaults to give the same key")
+	}
+	if key("95") == key("80") {
+		t.Error("Expected a different default quality to give a different key")
+	}
+}
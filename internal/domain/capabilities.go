@@ -0,0 +1,47 @@
+package domain
+
+// OptionDescriptor describes a conversion option an engine understands
+type OptionDescriptor struct {
+	Name        string
+	Type        string // "int", "float", "bool", "string" or "enum"
+	Description string
+	Default     string   // value used when unset, including configured defaults; part of cache keys
+	Values      []string // allowed values for enum options
+	Formats     []Format // output formats the option applies to; empty means all
+}
+
+// Capabilities describes what a conversion engine can do
+type Capabilities struct {
+	Name string
+	// Version changes whenever the engine may produce different output for
+	// the same input and options; it is part of conversion cache keys
+	Version     string
+	Conversions []ConversionEdge
+	Options     []OptionDescriptor
+}
+
+// InputTypes returns the distinct input file types the engine accepts
+func (c Capabilities) InputTypes() []FileType {
+	seen := make(map[FileType]bool)
+	var types []FileType
+	for _, edge := range c.Conversions {
+		if !seen[edge.From] {
+			seen[edge.From] = true
+			types = append(types, edge.From)
+		}
+	}
+	return types
+}
+
+// OutputFormats returns the distinct output formats the engine produces
+func (c Capabilities) OutputFormats() []Format {
+	seen := make(map[Format]bool)
+	var formats []Format
+	for _, edge := range c.Conversions {
+		if !seen[edge.To] {
+			seen[edge.To] = true
+			formats = append(formats, edge.To)
+		}
+	}
+	return formats
+}
@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// DefaultBrowserConcurrency caps concurrent conversions on engines that render
+// through the headless browser; each one holds a browser page open
+const DefaultBrowserConcurrency = 2
+
+// TaskScheduler runs engine work under per-engine concurrency caps.
+// Engines are identified by their Capabilities().Name; the service runs an
+// engine's browser-backed steps under that name plus "/browser".
+type TaskScheduler interface {
+	// Run executes task once the named engine has a free slot and returns the
+	// task's error. Waiting for a slot is abandoned when ctx is cancelled.
+	Run(ctx context.Context, engine string, task func(ctx context.Context) error) error
+	// SetLimit caps how many tasks of the named engine run at once
+	SetLimit(engine string, limit int)
+}
+
+// engineLimiter is the TaskScheduler used when none is configured; it runs
+// tasks on the calling goroutine, bounded by a semaphore per engine
+type engineLimiter struct {
+	mu    sync.Mutex
+	slots map[string]chan struct{}
+}
+
+// newEngineLimiter creates a limiter with no engine limits set
+func newEngineLimiter() *engineLimiter {
+	return &engineLimiter{
+		slots: make(map[string]chan struct{}),
+	}
+}
+
+// SetLimit replaces the semaphore of the named engine.
+// Tasks already holding a slot of the previous semaphore are unaffected.
+func (l *engineLimiter) SetLimit(engine string, limit int) {
+	if limit <= 0 {
+		limit = runtime.NumCPU()
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.slots[engine] = make(chan struct{}, limit)
+}
+
+// Run blocks until the engine has a free slot or ctx is cancelled, then runs task
+func (l *engineLimiter) Run(ctx context.Context, engine string, task func(ctx context.Context) error) error {
+	slots := l.slotsFor(engine)
+	select {
+	case slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-slots }()
+
+	return task(ctx)
+}
+
+// slotsFor returns the semaphore of an engine, creating one slot per CPU on first use
+func (l *engineLimiter) slotsFor(engine string) chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if slots, ok := l.slots[engine]; ok {
+		return slots
+	}
+	slots := make(chan struct{}, runtime.NumCPU())
+	l.slots[engine] = slots
+	return slots
+}
+
+// browserLaneSuffix is appended to an engine's name to key the scheduler lane
+// of its steps that render in the headless browser
+const browserLaneSuffix = "/browser"
+
+// schedulerLane returns the scheduler key a route step of the named engine
+// runs under. Browser-backed steps get a lane of their own so the browser cap
+// does not hold back the engine's in-process conversions, unless the engine
+// has an explicit limit, which bounds all of its conversions together.
+func (s *ConverterService) schedulerLane(engine string, edge ConversionEdge) string {
+	if _, ok := s.engineLimits[engine]; ok || edge.Cost < EdgeCostBrowser {
+		return engine
+	}
+	return engine + browserLaneSuffix
+}
+
+// setEngineLimits caps the scheduler lanes of an engine: one per CPU for its
+// in-process steps and DefaultBrowserConcurrency for its browser-backed ones
+func (s *ConverterService) setEngineLimits(capabilities Capabilities) {
+	if limit, ok := s.engineLimits[capabilities.Name]; ok {
+		s.scheduler.SetLimit(capabilities.Name, limit)
+		return
+	}
+	s.scheduler.SetLimit(capabilities.Name, runtime.NumCPU())
+	for _, edge := range capabilities.Conversions {
+		if edge.Cost >= EdgeCostBrowser {
+			s.scheduler.SetLimit(capabilities.Name+browserLaneSuffix, DefaultBrowserConcurrency)
+			return
+		}
+	}
+}
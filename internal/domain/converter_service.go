@@ -5,10 +5,15 @@ package domain
 // to external servers. All processing uses local system resources only.
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -16,41 +21,169 @@ import (
 // All operations are performed locally - no external data transmission
 type ConverterService struct {
 	engines          map[FileType]IConverter
+	sources          []EngineSource
+	discovered       []IConverter
+	planner          *RoutePlanner
+	detector         FileTypeDetector
+	scheduler        TaskScheduler
+	engineLimits     map[string]int
+	batchConcurrency int
+	collisionPolicy  CollisionPolicy
+	outputs          *outputReservations
+	cache            ConversionCache
+	interceptors     []Interceptor
+	retryPolicy      RetryPolicy
+	limits           ResourceLimits
+	engineTimeouts   map[string]time.Duration
+	browserTimeout   time.Duration
+	inProcessTimeout time.Duration
+	scratchDir       string
 	logger           Logger
 	progressNotifier ProgressNotifier
 	fileWriter       FileWriter
 }
 
+// ServiceOption configures optional ConverterService behaviour
+type ServiceOption func(*ConverterService)
+
+// WithFileTypeDetector replaces the default extension-based file type detection
+func WithFileTypeDetector(detector FileTypeDetector) ServiceOption {
+	return func(s *ConverterService) {
+		s.detector = detector
+	}
+}
+
+// WithEngineSource adds every converter supplied by source, such as an engine
+// registry, to the ones passed to NewConverterService. Engines passed directly
+// take precedence when both accept the same input type.
+func WithEngineSource(source EngineSource) ServiceOption {
+	return func(s *ConverterService) {
+		if source != nil {
+			s.sources = append(s.sources, source)
+		}
+	}
+}
+
+// WithEngineConcurrency limits how many conversions the named engine runs at once.
+// By default each engine runs DefaultBrowserConcurrency steps that render in
+// the headless browser and one in-process step per CPU.
+func WithEngineConcurrency(name string, limit int) ServiceOption {
+	return func(s *ConverterService) {
+		if limit > 0 {
+			s.engineLimits[name] = limit
+		}
+	}
+}
+
+// WithScheduler runs engine conversions on a shared scheduler instead of the
+// calling goroutine, so the service and the engines draw from one worker pool.
+// The service applies its engine concurrency limits to the scheduler.
+func WithScheduler(scheduler TaskScheduler) ServiceOption {
+	return func(s *ConverterService) {
+		if scheduler != nil {
+			s.scheduler = scheduler
+		}
+	}
+}
+
+// WithBatchConcurrency sets how many files BatchConvert processes at once
+func WithBatchConcurrency(limit int) ServiceOption {
+	return func(s *ConverterService) {
+		if limit > 0 {
+			s.batchConcurrency = limit
+		}
+	}
+}
+
+// WithCollisionPolicy sets the collision policy used when a conversion's
+// options do not choose one. The default is CollisionOverwrite.
+func WithCollisionPolicy(policy CollisionPolicy) ServiceOption {
+	return func(s *ConverterService) {
+		if policy != "" {
+			s.collisionPolicy = policy
+		}
+	}
+}
+
 // NewConverterService creates a new converter service
 func NewConverterService(
 	engines map[FileType]IConverter,
 	logger Logger,
 	progressNotifier ProgressNotifier,
 	fileWriter FileWriter,
+	opts ...ServiceOption,
 ) *ConverterService {
-	return &ConverterService{
+	if engines == nil {
+		engines = make(map[FileType]IConverter)
+	}
+	s := &ConverterService{
 		engines:          engines,
+		planner:          NewRoutePlanner(),
+		detector:         extensionDetector{},
+		scheduler:        newEngineLimiter(),
+		engineLimits:     make(map[string]int),
+		retryPolicy:      DefaultRetryPolicy,
+		limits:           DefaultResourceLimits,
+		engineTimeouts:   make(map[string]time.Duration),
+		browserTimeout:   DefaultBrowserTimeout,
+		inProcessTimeout: DefaultEngineTimeout,
+		batchConcurrency: runtime.NumCPU(),
+		collisionPolicy:  CollisionOverwrite,
+		outputs:          newOutputReservations(),
+		scratchDir:       filepath.Join(os.TempDir(), "file-format-converter", "scratch"),
 		logger:           logger,
 		progressNotifier: progressNotifier,
 		fileWriter:       fileWriter,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	// Logging wraps every other interceptor so it reports the final outcome;
+	// retries repeat the caller's interceptors and each attempt is bounded
+	// by the timeout of its step
+	chain := []Interceptor{LoggingInterceptor(s.logger), retryInterceptor(s.retryPolicy)}
+	chain = append(chain, s.interceptors...)
+	s.interceptors = append(chain, timeoutInterceptor(s.attemptTimeout))
+
+	// Discover engines from the sources; each also serves its input types
+	// that no directly passed engine claims
+	for _, source := range s.sources {
+		for _, engine := range source.Engines() {
+			s.discovered = append(s.discovered, engine)
+			for _, fileType := range engine.Capabilities().InputTypes() {
+				if s.engines[fileType] == nil {
+					s.engines[fileType] = engine
+				}
+			}
+		}
+	}
+
+	// Build the conversion graph from every distinct engine and cap its concurrency
+	for _, engine := range s.distinctEngines() {
+		s.planner.AddEngine(engine)
+		s.setEngineLimits(engine.Capabilities())
+	}
+
+	return s
 }
 
 // Convert performs a single file conversion
-func (s *ConverterService) Convert(ctx context.Context, source, target string) Result {
+// The zero value of opts converts with every engine's defaults
+func (s *ConverterService) Convert(ctx context.Context, source, target string, opts ConversionOptions) Result {
+	ctx, log := withConversionLog(ctx)
+	result := s.convert(ctx, source, target, opts)
+	log.apply(&result)
+	return result
+}
+
+// convert performs a single file conversion, recording what happens in the
+// conversion log carried by ctx
+func (s *ConverterService) convert(ctx context.Context, source, target string, opts ConversionOptions) Result {
 	startTime := time.Now()
 
 	// Check for cancellation before starting
 	if ctx.Err() != nil {
-		err := ctx.Err()
-		s.logger.Error("Conversion cancelled before start", err)
-		s.progressNotifier.NotifyError(err)
-		return Result{
-			Success:    false,
-			OutputPath: "",
-			Error:      err,
-			Duration:   time.Since(startTime),
-		}
+		return s.fail(startTime, "Conversion cancelled before start", contextError(ctx.Err()), nil)
 	}
 
 	s.logger.Info(fmt.Sprintf("Starting conversion: %s -> %s", source, target))
@@ -59,93 +192,77 @@ func (s *ConverterService) Convert(ctx context.Context, source, target string) R
 	// Validate input file
 	validationResult := s.validateInput(ctx, source)
 	if !validationResult.Valid {
-		s.logger.Error("File validation failed", validationResult.Error)
-		s.progressNotifier.NotifyError(validationResult.Error)
-		return Result{
-			Success:    false,
-			OutputPath: "",
-			Error:      validationResult.Error,
-			Duration:   time.Since(startTime),
-		}
+		return s.fail(startTime, "File validation failed", validationResult.Error, nil)
 	}
 
 	// Check for cancellation after validation
 	if ctx.Err() != nil {
-		err := ctx.Err()
-		s.logger.Error("Conversion cancelled after validation", err)
-		s.progressNotifier.NotifyError(err)
-		return Result{
-			Success:    false,
-			OutputPath: "",
-			Error:      err,
-			Duration:   time.Since(startTime),
-		}
+		return s.fail(startTime, "Conversion cancelled after validation", contextError(ctx.Err()), nil)
 	}
 
-	// Detect file type
-	fileType := s.detectFileType(source)
+	// Detect file type from content, reporting mislabelled extensions
+	detection := s.detect(source)
+	fileType := detection.FileType
 	if fileType == "" {
-		err := fmt.Errorf("unsupported file type: %s", source)
-		s.logger.Error("Unsupported file type", err)
-		s.progressNotifier.NotifyError(err)
-		return Result{
-			Success:    false,
-			OutputPath: "",
-			Error:      err,
-			Duration:   time.Since(startTime),
-		}
+		err := Errorf(ErrorCodeUnsupportedFormat, "unsupported file type: %s", source)
+		return s.fail(startTime, "Unsupported file type", err, nil)
+	}
+	for _, warning := range detection.Warnings {
+		s.logger.Info(fmt.Sprintf("Warning: %s", warning))
 	}
 
-	// Select appropriate engine
-	engine := s.selectEngine(fileType)
-	if engine == nil {
-		err := fmt.Errorf("no conversion engine available for file type: %s", fileType)
-		s.logger.Error("Engine not found", err)
-		s.progressNotifier.NotifyError(err)
-		return Result{
-			Success:    false,
-			OutputPath: "",
-			Error:      err,
-			Duration:   time.Since(startTime),
-		}
+	// Plan the conversion route
+	route, err := s.planRoute(fileType, target)
+	if err != nil {
+		return s.fail(startTime, "No conversion route", err, detection.Warnings)
 	}
+	conversionLogFrom(ctx).setRoute(fileType, route)
 
-	// Validate file using engine
-	if err := engine.Validate(ctx, source); err != nil {
-		s.logger.Error("Engine validation failed", err)
-		s.progressNotifier.NotifyError(err)
-		return Result{
-			Success:    false,
-			OutputPath: "",
-			Error:      err,
-			Duration:   time.Since(startTime),
-		}
+	// Validate the options against every engine on the route
+	if err := s.validateOptions(route, opts); err != nil {
+		return s.fail(startTime, "Invalid conversion options", err, detection.Warnings)
+	}
+
+	// Validate file using the engine of the first step
+	if err := s.validateWith(ctx, route.Steps[0].Engine, source); err != nil {
+		return s.fail(startTime, "Engine validation failed", withCode(ErrorCodeCorruptInput, err), detection.Warnings)
 	}
 
 	// Check for cancellation before conversion
 	if ctx.Err() != nil {
-		err := ctx.Err()
-		s.logger.Error("Conversion cancelled before engine conversion", err)
-		s.progressNotifier.NotifyError(err)
-		return Result{
-			Success:    false,
-			OutputPath: "",
-			Error:      err,
+		return s.fail(startTime, "Conversion cancelled before engine conversion", contextError(ctx.Err()), detection.Warnings)
+	}
+
+	// Resolve the output path against the collision policy
+	outputPath, skip, release, err := s.outputs.reserve(target, s.collisionPolicyFor(opts))
+	if err != nil {
+		return s.fail(startTime, "Output path unavailable", err, detection.Warnings)
+	}
+	defer release()
+	if skip {
+		s.logger.Info(fmt.Sprintf("Skipping conversion, output already exists: %s", target))
+		result := Result{
+			Success:    true,
+			OutputPath: target,
+			Error:      nil,
 			Duration:   time.Since(startTime),
+			Warnings:   detection.Warnings,
+			Skipped:    true,
 		}
+		s.progressNotifier.NotifyComplete(result)
+		return result
 	}
 
-	// Perform conversion
+	// Perform conversion into a temporary file that replaces the output only on success
 	s.progressNotifier.NotifyProgress(50, "Converting file...")
-	if err := engine.Convert(ctx, source, target); err != nil {
-		s.logger.Error("Conversion failed", err)
-		s.progressNotifier.NotifyError(err)
-		return Result{
-			Success:    false,
-			OutputPath: "",
-			Error:      err,
-			Duration:   time.Since(startTime),
-		}
+	var cached bool
+	err = writeAtomically(outputPath, func(tempPath string) error {
+		var err error
+		cached, err = s.executeCached(ctx, route, source, tempPath, opts)
+		return err
+	})
+	if err != nil {
+		return s.fail(startTime, "Conversion failed", err, detection.Warnings)
 	}
 
 	duration := time.Since(startTime)
@@ -154,73 +271,332 @@ func (s *ConverterService) Convert(ctx context.Context, source, target string) R
 
 	result := Result{
 		Success:    true,
-		OutputPath: target,
+		OutputPath: outputPath,
 		Error:      nil,
 		Duration:   duration,
+		Warnings:   detection.Warnings,
+		Cached:     cached,
+		InputSize:  fileSize(source),
+		OutputSize: fileSize(outputPath),
 	}
 	s.progressNotifier.NotifyComplete(result)
 
 	return result
 }
 
-// BatchConvert performs batch file conversion
-func (s *ConverterService) BatchConvert(ctx context.Context, files []string, target string) []Result {
-	if len(files) == 0 {
+// ConvertStream converts a document of the given type read from input and
+// writes the result to output in the target format. Engines implementing
+// StreamConverter run entirely in memory; other engines are staged through
+// temporary files in the scratch directory.
+func (s *ConverterService) ConvertStream(ctx context.Context, input io.Reader, inputType FileType, output io.Writer, target Format, opts ConversionOptions) Result {
+	ctx, log := withConversionLog(ctx)
+	result := s.convertStream(ctx, input, inputType, output, target, opts)
+	log.apply(&result)
+	return result
+}
+
+// convertStream performs a stream conversion, recording what happens in the
+// conversion log carried by ctx
+func (s *ConverterService) convertStream(ctx context.Context, input io.Reader, inputType FileType, output io.Writer, target Format, opts ConversionOptions) Result {
+	startTime := time.Now()
+
+	// Check for cancellation before starting
+	if ctx.Err() != nil {
+		return s.fail(startTime, "Conversion cancelled before start", contextError(ctx.Err()), nil)
+	}
+
+	s.logger.Info(fmt.Sprintf("Starting stream conversion: %s -> %s", inputType, target))
+	input = s.limits.LimitReader(input)
+	s.progressNotifier.NotifyProgress(0, "Starting conversion...")
+
+	// Plan the conversion route
+	route, err := s.planner.Plan(inputType, target)
+	if err != nil {
+		return s.fail(startTime, "No conversion route", err, nil)
+	}
+	conversionLogFrom(ctx).setRoute(inputType, route)
+
+	// Validate the options against every engine on the route
+	if err := s.validateOptions(route, opts); err != nil {
+		return s.fail(startTime, "Invalid conversion options", err, nil)
+	}
+
+	// Perform conversion, counting the bytes read and written
+	s.progressNotifier.NotifyProgress(50, "Converting file...")
+	countedInput, countedOutput := &countingReader{r: input}, &countingWriter{w: output}
+	if err := s.executeStreamRoute(ctx, route, countedInput, countedOutput, opts); err != nil {
+		return s.fail(startTime, "Conversion failed", err, nil)
+	}
+
+	duration := time.Since(startTime)
+	s.logger.Info(fmt.Sprintf("Conversion completed successfully in %v", duration))
+	s.progressNotifier.NotifyProgress(100, "Conversion completed")
+
+	result := Result{
+		Success:    true,
+		Duration:   duration,
+		InputSize:  countedInput.n,
+		OutputSize: countedOutput.n,
+	}
+	s.progressNotifier.NotifyComplete(result)
+
+	return result
+}
+
+// ConvertStreamToFile converts a document of the given type read from input
+// into the file at target, whose extension selects the output format. The
+// collision policy is applied to target and the file is written atomically.
+func (s *ConverterService) ConvertStreamToFile(ctx context.Context, input io.Reader, inputType FileType, target string, opts ConversionOptions) Result {
+	ctx, log := withConversionLog(ctx)
+	result := s.convertStreamToFile(ctx, input, inputType, target, opts)
+	log.apply(&result)
+	return result
+}
+
+// convertStreamToFile performs a stream conversion into a file, recording
+// what happens in the conversion log carried by ctx
+func (s *ConverterService) convertStreamToFile(ctx context.Context, input io.Reader, inputType FileType, target string, opts ConversionOptions) Result {
+	startTime := time.Now()
+
+	format, ok := FormatFromExtension(filepath.Ext(target))
+	if !ok {
+		err := Errorf(ErrorCodeUnsupportedFormat, "unsupported output format: %s", target)
+		return s.fail(startTime, "Unsupported output format", err, nil)
+	}
+	if err := opts.Validate(); err != nil {
+		return s.fail(startTime, "Invalid conversion options", withCode(ErrorCodeInvalidOptions, err), nil)
+	}
+
+	// Resolve the output path against the collision policy
+	outputPath, skip, release, err := s.outputs.reserve(target, s.collisionPolicyFor(opts))
+	if err != nil {
+		return s.fail(startTime, "Output path unavailable", err, nil)
+	}
+	defer release()
+	if skip {
+		s.logger.Info(fmt.Sprintf("Skipping conversion, output already exists: %s", target))
+		result := Result{
+			Success:    true,
+			OutputPath: target,
+			Duration:   time.Since(startTime),
+			Skipped:    true,
+		}
+		s.progressNotifier.NotifyComplete(result)
+		return result
+	}
+
+	var result Result
+	countedInput := &countingReader{r: s.limits.LimitReader(input)}
+	err = writeAtomically(outputPath, func(tempPath string) error {
+		input, key, err := s.streamCacheKey(countedInput, inputType, format, opts)
+		if err != nil {
+			return err
+		}
+		if key != "" {
+			if s.cacheGet(ctx, key, tempPath) {
+				s.logger.Info(fmt.Sprintf("Serving cached stream conversion to %s", target))
+				result = Result{Success: true, Cached: true, InputType: inputType}
+				return nil
+			}
+		}
+
+		file, err := os.OpenFile(tempPath, os.O_WRONLY|os.O_TRUNC, 0)
+		if err != nil {
+			return NewError(ErrorCodeOutputNotWritable, err)
+		}
+		result = s.convertStream(ctx, input, inputType, file, format, opts)
+		if closeErr := file.Close(); result.Success && closeErr != nil {
+			return NewError(ErrorCodeOutputNotWritable, closeErr)
+		}
+		if result.Success && key != "" {
+			s.cachePut(ctx, key, tempPath)
+		}
+		return result.Error
+	})
+	if err != nil {
+		return Result{
+			Success:  false,
+			Error:    err,
+			Duration: time.Since(startTime),
+			Warnings: result.Warnings,
+		}
+	}
+
+	result.OutputPath = outputPath
+	result.Duration = time.Since(startTime)
+	result.InputSize = countedInput.n
+	result.OutputSize = fileSize(outputPath)
+	return result
+}
+
+// BatchJob is one conversion of a batch
+type BatchJob struct {
+	// Source is the input file
+	Source string
+	// Target is the output path; its extension selects the output format
+	Target  string
+	Options ConversionOptions
+}
+
+// BatchConvert converts files concurrently, applying the same options to every file.
+// Each output is written next to its input. Results are returned in the order
+// of files. Files not started before ctx is cancelled report the cancellation error.
+func (s *ConverterService) BatchConvert(ctx context.Context, files []string, target string, opts ConversionOptions) []Result {
+	jobs := make([]BatchJob, len(files))
+	for i, file := range files {
+		jobs[i] = BatchJob{Source: file, Target: s.generateOutputPath(file, target), Options: opts}
+	}
+	return s.BatchConvertJobs(ctx, jobs)
+}
+
+// BatchConvertJobs converts jobs concurrently like BatchConvert, letting each
+// job choose its output path and options
+func (s *ConverterService) BatchConvertJobs(ctx context.Context, jobs []BatchJob) []Result {
+	if len(jobs) == 0 {
 		return nil
 	}
 
 	// Check for cancellation before starting
 	if ctx.Err() != nil {
 		s.logger.Error("Batch conversion cancelled before start", ctx.Err())
-		return nil
+		return cancelledResults(ctx, jobs, 0)
 	}
 
-	s.logger.Info(fmt.Sprintf("Starting batch conversion of %d files", len(files)))
-	s.progressNotifier.NotifyProgress(0, fmt.Sprintf("Starting batch conversion of %d files...", len(files)))
+	s.logger.Info(fmt.Sprintf("Starting batch conversion of %d files", len(jobs)))
+	s.progressNotifier.NotifyProgress(0, fmt.Sprintf("Starting batch conversion of %d files...", len(jobs)))
 
-	results := make([]Result, len(files))
-	totalFiles := len(files)
+	results := make([]Result, len(jobs))
+	totalFiles := len(jobs)
+	fileNotifier, _ := s.progressNotifier.(FileProgressNotifier)
 
-	for i, file := range files {
-		// Check for cancellation before each file
-		if ctx.Err() != nil {
-			s.logger.Info(fmt.Sprintf("Batch conversion cancelled after %d of %d files", i, totalFiles))
-			// Return partial results
-			return results[:i]
-		}
+	workers := s.batchConcurrency
+	if workers > totalFiles {
+		workers = totalFiles
+	}
 
-		// Generate output path for each file
-		outputPath := s.generateOutputPath(file, target)
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		completed int
+	)
+	indexes := make(chan int)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				job := jobs[i]
+				if fileNotifier != nil {
+					fileNotifier.NotifyFileStarted(i, job.Source)
+				}
+
+				result := s.Convert(ctx, job.Source, job.Target, job.Options)
+				results[i] = result
+
+				if fileNotifier != nil {
+					fileNotifier.NotifyFileFinished(i, job.Source, result)
+				}
+
+				// Update progress
+				mu.Lock()
+				completed++
+				progress := completed * 100 / totalFiles
+				s.progressNotifier.NotifyProgress(progress, fmt.Sprintf("Converted %d of %d files", completed, totalFiles))
+				mu.Unlock()
+			}
+		}()
+	}
 
-		// Convert single file
-		result := s.Convert(ctx, file, outputPath)
-		results[i] = result
+	dispatched := 0
+dispatch:
+	for i := range jobs {
+		select {
+		case indexes <- i:
+			dispatched++
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(indexes)
+	wg.Wait()
 
-		// Update progress
-		progress := (i + 1) * 100 / totalFiles
-		s.progressNotifier.NotifyProgress(progress, fmt.Sprintf("Converted %d of %d files", i+1, totalFiles))
+	if dispatched < totalFiles {
+		s.logger.Info(fmt.Sprintf("Batch conversion cancelled after starting %d of %d files", dispatched, totalFiles))
+		copy(results[dispatched:], cancelledResults(ctx, jobs, dispatched))
+		return results
 	}
 
-	s.logger.Info(fmt.Sprintf("Batch conversion completed: %d files processed", len(files)))
+	s.logger.Info(fmt.Sprintf("Batch conversion completed: %d files processed", len(jobs)))
 	return results
 }
 
-// GetSupportedFormats returns the list of supported conversion formats
+// cancelledResults returns the results of the jobs from index started on,
+// which were never started because ctx was cancelled
+func cancelledResults(ctx context.Context, jobs []BatchJob, started int) []Result {
+	results := make([]Result, len(jobs)-started)
+	for i := range results {
+		results[i] = Result{
+			Success:    false,
+			OutputPath: "",
+			Error:      contextError(ctx.Err()),
+		}
+	}
+	return results
+}
+
+// GetSupportedFormats returns every output format reachable from at least one registered input type
 func (s *ConverterService) GetSupportedFormats() []Format {
-	// Note: IConverter interface doesn't have GetSupportedOutputTypes method
-	// So we return the known formats based on the engines we have
-	// If engines are registered, we support these formats
-	if len(s.engines) == 0 {
+	reachable := make(map[Format]bool)
+	for _, formats := range s.GetConversionMatrix() {
+		for _, format := range formats {
+			reachable[format] = true
+		}
+	}
+	if len(reachable) == 0 {
 		return nil
 	}
 
-	// Return all supported output formats
-	return []Format{
-		FormatPDF,
-		FormatHTML,
-		FormatPNG,
-		FormatWEBP,
+	formats := make([]Format, 0, len(reachable))
+	for _, format := range knownFormats {
+		if reachable[format] {
+			formats = append(formats, format)
+		}
 	}
+	return formats
+}
+
+// GetConversionMatrix returns, for each accepted input type, the output formats
+// reachable through one or more conversion steps
+func (s *ConverterService) GetConversionMatrix() map[FileType][]Format {
+	matrix := make(map[FileType][]Format)
+	for _, fileType := range knownFileTypes {
+		if !s.planner.Accepts(fileType) {
+			continue
+		}
+
+		reachable := make(map[Format]bool)
+		for _, format := range s.planner.Reachable(fileType) {
+			reachable[format] = true
+		}
+
+		formats := make([]Format, 0, len(reachable))
+		for _, format := range knownFormats {
+			if reachable[format] {
+				formats = append(formats, format)
+			}
+		}
+		matrix[fileType] = formats
+	}
+	return matrix
+}
+
+// GetEngineCapabilities returns the capabilities reported by every registered engine
+func (s *ConverterService) GetEngineCapabilities() []Capabilities {
+	var capabilities []Capabilities
+	for _, engine := range s.distinctEngines() {
+		capabilities = append(capabilities, engine.Capabilities())
+	}
+	return capabilities
 }
 
 // ValidateFile validates if a file can be converted
@@ -230,7 +606,7 @@ func (s *ConverterService) ValidateFile(ctx context.Context, file string) Valida
 		return ValidationResult{
 			Valid:   false,
 			Message: "Validation cancelled",
-			Error:   ctx.Err(),
+			Error:   contextError(ctx.Err()),
 		}
 	}
 
@@ -239,7 +615,16 @@ func (s *ConverterService) ValidateFile(ctx context.Context, file string) Valida
 		return ValidationResult{
 			Valid:   false,
 			Message: "File does not exist",
-			Error:   fmt.Errorf("file does not exist: %s", file),
+			Error:   Errorf(ErrorCodeInputNotFound, "file does not exist: %s", file),
+		}
+	}
+
+	// Reject oversized inputs before any engine reads them
+	if err := s.checkFileSize(file); err != nil {
+		return ValidationResult{
+			Valid:   false,
+			Message: "File too large",
+			Error:   err,
 		}
 	}
 
@@ -249,7 +634,7 @@ func (s *ConverterService) ValidateFile(ctx context.Context, file string) Valida
 		return ValidationResult{
 			Valid:   false,
 			Message: "Unsupported file type",
-			Error:   fmt.Errorf("unsupported file type: %s", file),
+			Error:   Errorf(ErrorCodeUnsupportedFormat, "unsupported file type: %s", file),
 		}
 	}
 
@@ -259,16 +644,16 @@ func (s *ConverterService) ValidateFile(ctx context.Context, file string) Valida
 		return ValidationResult{
 			Valid:   false,
 			Message: "No conversion engine available for this file type",
-			Error:   fmt.Errorf("no conversion engine available for file type: %s", fileType),
+			Error:   Errorf(ErrorCodeEngineFailed, "no conversion engine available for file type: %s", fileType),
 		}
 	}
 
 	// Validate using engine
-	if err := engine.Validate(ctx, file); err != nil {
+	if err := s.validateWith(ctx, engine, file); err != nil {
 		return ValidationResult{
 			Valid:   false,
 			Message: "File validation failed",
-			Error:   err,
+			Error:   withCode(ErrorCodeCorruptInput, err),
 		}
 	}
 
@@ -279,6 +664,249 @@ func (s *ConverterService) ValidateFile(ctx context.Context, file string) Valida
 	}
 }
 
+// fail logs a failed conversion, notifies the progress listener and builds its result
+func (s *ConverterService) fail(startTime time.Time, msg string, err error, warnings []string) Result {
+	s.logger.Error(msg, err)
+	s.progressNotifier.NotifyError(err)
+	return Result{
+		Success:    false,
+		OutputPath: "",
+		Error:      err,
+		Duration:   time.Since(startTime),
+		Warnings:   warnings,
+	}
+}
+
+// collisionPolicyFor returns the collision policy chosen by opts or the service default
+func (s *ConverterService) collisionPolicyFor(opts ConversionOptions) CollisionPolicy {
+	if opts.Output.Collision != "" {
+		return opts.Output.Collision
+	}
+	return s.collisionPolicy
+}
+
+// planRoute finds the cheapest conversion route from the input type to the target path's format
+func (s *ConverterService) planRoute(fileType FileType, target string) (Route, error) {
+	format, ok := FormatFromExtension(filepath.Ext(target))
+	if !ok {
+		return Route{}, Errorf(ErrorCodeUnsupportedFormat, "unsupported output format: %s", target)
+	}
+	return s.planner.Plan(fileType, format)
+}
+
+// validateOptions checks option ranges and lets every engine on the route
+// reject settings that do not apply to its step
+func (s *ConverterService) validateOptions(route Route, opts ConversionOptions) error {
+	if err := opts.Validate(); err != nil {
+		return withCode(ErrorCodeInvalidOptions, err)
+	}
+	for _, step := range route.Steps {
+		validator, ok := step.Engine.(OptionsValidator)
+		if !ok {
+			continue
+		}
+		if err := validator.ValidateOptions(opts, step.Edge.To); err != nil {
+			return withCode(ErrorCodeInvalidOptions, err)
+		}
+	}
+	return nil
+}
+
+// executeRoute runs each step of a route in order, chaining intermediate
+// artifacts through a scratch directory that is removed afterwards
+func (s *ConverterService) executeRoute(ctx context.Context, route Route, source, target string, opts ConversionOptions) error {
+	if len(route.Steps) == 1 {
+		return s.convertStep(ctx, route.Steps[0], source, target, opts)
+	}
+
+	if err := os.MkdirAll(s.scratchDir, 0755); err != nil {
+		return Errorf(ErrorCodeOutputNotWritable, "creating scratch directory: %w", err)
+	}
+	workDir, err := os.MkdirTemp(s.scratchDir, "route-*")
+	if err != nil {
+		return Errorf(ErrorCodeOutputNotWritable, "creating scratch directory: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	baseName := strings.TrimSuffix(filepath.Base(source), filepath.Ext(source))
+	input := source
+	for i, step := range route.Steps {
+		// Check for cancellation between steps
+		if ctx.Err() != nil {
+			return contextError(ctx.Err())
+		}
+
+		output := target
+		if i < len(route.Steps)-1 {
+			output = filepath.Join(workDir, fmt.Sprintf("%s.step%d%s", baseName, i+1, step.Edge.To.Extension()))
+		}
+
+		if err := s.convertStep(ctx, step, input, output, opts); err != nil {
+			return fmt.Errorf("converting %s to %s: %w", step.Edge.From, step.Edge.To, err)
+		}
+		input = output
+	}
+
+	return nil
+}
+
+// executeStreamRoute runs each step of a route in order, buffering
+// intermediate artifacts in memory
+func (s *ConverterService) executeStreamRoute(ctx context.Context, route Route, input io.Reader, output io.Writer, opts ConversionOptions) error {
+	for i, step := range route.Steps {
+		// Check for cancellation between steps
+		if ctx.Err() != nil {
+			return contextError(ctx.Err())
+		}
+
+		// The last step writes straight to the caller's writer
+		var stepOutput io.Writer = output
+		var buffer *bytes.Buffer
+		if i < len(route.Steps)-1 {
+			buffer = new(bytes.Buffer)
+			stepOutput = buffer
+		}
+
+		if err := s.convertStreamStep(ctx, step, input, stepOutput, opts); err != nil {
+			if len(route.Steps) == 1 {
+				return err
+			}
+			return fmt.Errorf("converting %s to %s: %w", step.Edge.From, step.Edge.To, err)
+		}
+		if buffer != nil {
+			input = buffer
+		}
+	}
+
+	return nil
+}
+
+// convertStreamStep runs one route step on streams, staging the data through
+// the scratch directory when the engine only works with file paths. When
+// retries are enabled the input is buffered so every attempt reads all of
+// it, and the output only reaches the caller's writer from a successful one.
+func (s *ConverterService) convertStreamStep(ctx context.Context, step RouteStep, input io.Reader, output io.Writer, opts ConversionOptions) error {
+	name := step.Engine.Capabilities().Name
+	call := Call{Operation: OperationConvertStream, Engine: name, Edge: step.Edge, Options: opts}
+	if s.retryPolicy.MaxAttempts < 2 {
+		return s.scheduler.Run(ctx, s.schedulerLane(name, step.Edge), func(ctx context.Context) error {
+			return s.intercept(ctx, call, func(ctx context.Context) error {
+				return s.streamStep(ctx, step, input, output, opts)
+			})
+		})
+	}
+
+	data, err := io.ReadAll(input)
+	if err != nil {
+		return withCode(ErrorCodeCorruptInput, fmt.Errorf("reading input: %w", err))
+	}
+	var attemptOutput bytes.Buffer
+	err = s.scheduler.Run(ctx, s.schedulerLane(name, step.Edge), func(ctx context.Context) error {
+		return s.intercept(ctx, call, func(ctx context.Context) error {
+			attemptOutput.Reset()
+			return s.streamStep(ctx, step, bytes.NewReader(data), &attemptOutput, opts)
+		})
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := attemptOutput.WriteTo(output); err != nil {
+		return Errorf(ErrorCodeOutputNotWritable, "writing output: %w", err)
+	}
+	return nil
+}
+
+// streamStep performs a stream route step once the engine has a free slot
+func (s *ConverterService) streamStep(ctx context.Context, step RouteStep, input io.Reader, output io.Writer, opts ConversionOptions) error {
+	engine := step.Engine
+	if resolver, ok := engine.(EngineResolver); ok {
+		resolved, err := resolver.ResolveEngine()
+		if err != nil {
+			return withCode(ErrorCodeEngineUnavailable, err)
+		}
+		engine = resolved
+	}
+	if streamer, ok := engine.(StreamConverter); ok {
+		return streamer.ConvertStream(ctx, input, output, step.Edge.To, opts)
+	}
+
+	if err := os.MkdirAll(s.scratchDir, 0755); err != nil {
+		return Errorf(ErrorCodeOutputNotWritable, "creating scratch directory: %w", err)
+	}
+	workDir, err := os.MkdirTemp(s.scratchDir, "stream-*")
+	if err != nil {
+		return Errorf(ErrorCodeOutputNotWritable, "creating scratch directory: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	inputExt := "." + strings.ToLower(string(step.Edge.From))
+	if extensions := step.Edge.From.Extensions(); len(extensions) > 0 {
+		inputExt = extensions[0]
+	}
+	inputPath := filepath.Join(workDir, "input"+inputExt)
+	outputPath := filepath.Join(workDir, "output"+step.Edge.To.Extension())
+
+	inputFile, err := os.Create(inputPath)
+	if err != nil {
+		return Errorf(ErrorCodeOutputNotWritable, "staging input: %w", err)
+	}
+	_, err = io.Copy(inputFile, input)
+	if closeErr := inputFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return Errorf(ErrorCodeOutputNotWritable, "staging input: %w", err)
+	}
+
+	if err := engine.Convert(ctx, inputPath, outputPath, opts); err != nil {
+		return err
+	}
+
+	outputFile, err := os.Open(outputPath)
+	if err != nil {
+		return fmt.Errorf("reading staged output: %w", err)
+	}
+	defer outputFile.Close()
+	if _, err := io.Copy(output, outputFile); err != nil {
+		return Errorf(ErrorCodeOutputNotWritable, "writing output: %w", err)
+	}
+	return nil
+}
+
+// convertStep runs one engine conversion through the scheduler, which bounds
+// how many steps of its cost each engine runs at once, and the interceptor chain
+func (s *ConverterService) convertStep(ctx context.Context, step RouteStep, input, output string, opts ConversionOptions) error {
+	name := step.Engine.Capabilities().Name
+	call := Call{Operation: OperationConvert, Engine: name, Edge: step.Edge, Input: input, Output: output, Options: opts}
+	return s.scheduler.Run(ctx, s.schedulerLane(name, step.Edge), func(ctx context.Context) error {
+		return s.intercept(ctx, call, func(ctx context.Context) error {
+			return step.Engine.Convert(ctx, input, output, opts)
+		})
+	})
+}
+
+// distinctEngines returns each registered engine once: directly passed
+// engines in input type order, then discovered engines in source order
+func (s *ConverterService) distinctEngines() []IConverter {
+	seen := make(map[IConverter]bool)
+	var engines []IConverter
+	for _, fileType := range knownFileTypes {
+		engine := s.engines[fileType]
+		if engine == nil || seen[engine] {
+			continue
+		}
+		seen[engine] = true
+		engines = append(engines, engine)
+	}
+	for _, engine := range s.discovered {
+		if !seen[engine] {
+			seen[engine] = true
+			engines = append(engines, engine)
+		}
+	}
+	return engines
+}
+
 // selectEngine selects the appropriate conversion engine for a file type
 func (s *ConverterService) selectEngine(fileType FileType) IConverter {
 	engine, exists := s.engines[fileType]
@@ -295,7 +923,7 @@ func (s *ConverterService) validateInput(ctx context.Context, file string) Valid
 		return ValidationResult{
 			Valid:   false,
 			Message: "Validation cancelled",
-			Error:   ctx.Err(),
+			Error:   contextError(ctx.Err()),
 		}
 	}
 
@@ -304,7 +932,16 @@ func (s *ConverterService) validateInput(ctx context.Context, file string) Valid
 		return ValidationResult{
 			Valid:   false,
 			Message: "File does not exist",
-			Error:   fmt.Errorf("file does not exist: %s", file),
+			Error:   Errorf(ErrorCodeInputNotFound, "file does not exist: %s", file),
+		}
+	}
+
+	// Reject oversized inputs before any engine reads them
+	if err := s.checkFileSize(file); err != nil {
+		return ValidationResult{
+			Valid:   false,
+			Message: "File too large",
+			Error:   err,
 		}
 	}
 
@@ -314,7 +951,7 @@ func (s *ConverterService) validateInput(ctx context.Context, file string) Valid
 		return ValidationResult{
 			Valid:   false,
 			Message: "Unsupported file type",
-			Error:   fmt.Errorf("unsupported file type: %s", file),
+			Error:   Errorf(ErrorCodeUnsupportedFormat, "unsupported file type: %s", file),
 		}
 	}
 
@@ -324,7 +961,7 @@ func (s *ConverterService) validateInput(ctx context.Context, file string) Valid
 		return ValidationResult{
 			Valid:   false,
 			Message: "No conversion engine available for this file type",
-			Error:   fmt.Errorf("no conversion engine available for file type: %s", fileType),
+			Error:   Errorf(ErrorCodeEngineFailed, "no conversion engine available for file type: %s", fileType),
 		}
 	}
 
@@ -335,23 +972,30 @@ func (s *ConverterService) validateInput(ctx context.Context, file string) Valid
 	}
 }
 
-// detectFileType detects the file type from the file extension
+// checkFileSize checks the size of an input file against the resource limits.
+// Files that cannot be inspected pass, as opening them reports the problem.
+func (s *ConverterService) checkFileSize(file string) error {
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil
+	}
+	return s.limits.CheckFileSize(info.Size())
+}
+
+// detectFileType detects the file type used to route a file
 func (s *ConverterService) detectFileType(filePath string) FileType {
-	ext := strings.ToLower(filepath.Ext(filePath))
-	switch ext {
-	case ".docx":
-		return FileTypeDOCX
-	case ".xlsx":
-		return FileTypeXLSX
-	case ".jpeg", ".jpg":
-		return FileTypeJPEG
-	case ".png":
-		return FileTypePNG
-	case ".webp":
-		return FileTypeWEBP
-	default:
-		return ""
+	return s.detect(filePath).FileType
+}
+
+// detect runs the configured detector, falling back to the file extension
+// when the content cannot be inspected
+func (s *ConverterService) detect(filePath string) FileTypeDetection {
+	detection, err := s.detector.Detect(filePath)
+	if err != nil {
+		s.logger.Debug(fmt.Sprintf("Content detection failed for %s, using extension: %v", filePath, err))
+		detection, _ = extensionDetector{}.Detect(filePath)
 	}
+	return detection
 }
 
 // generateOutputPath generates an output file path based on input path and target format
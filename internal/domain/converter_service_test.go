@@ -0,0 +1,367 @@
+package domain
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// nopLogger discards all log output
+type nopLogger struct{}
+
+func (nopLogger) Info(msg string)             {}
+func (nopLogger) Error(msg string, err error) {}
+func (nopLogger) Debug(msg string)            {}
+
+// nopNotifier discards all progress notifications
+type nopNotifier struct{}
+
+func (nopNotifier) NotifyProgress(pct int, msg string) {}
+func (nopNotifier) NotifyComplete(result Result)       {}
+func (nopNotifier) NotifyError(err error)              {}
+
+// existsWriter is a FileWriter that reports every path as existing
+type existsWriter struct{}
+
+func (existsWriter) Write(path string, data []byte) error { return nil }
+func (existsWriter) Read(path string) ([]byte, error)     { return nil, nil }
+func (existsWriter) Exists(path string) bool              { return true }
+
+// recordingNotifier records per-file batch events
+type recordingNotifier struct {
+	nopNotifier
+	mu       sync.Mutex
+	started  int
+	finished map[int]string
+}
+
+func (n *recordingNotifier) NotifyFileStarted(index int, path string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.started++
+}
+
+func (n *recordingNotifier) NotifyFileFinished(index int, path string, result Result) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.finished[index] = path
+}
+
+// slowEngine records how many conversions run at the same time
+type slowEngine struct {
+	stubEngine
+	running int32
+	peak    int32
+}
+
+func (e *slowEngine) Convert(ctx context.Context, input, output string, opts ConversionOptions) error {
+	running := atomic.AddInt32(&e.running, 1)
+	defer atomic.AddInt32(&e.running, -1)
+	for {
+		peak := atomic.LoadInt32(&e.peak)
+		if running <= peak || atomic.CompareAndSwapInt32(&e.peak, peak, running) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	return nil
+}
+
+// prefixEngine converts by prepending a marker to its input; it only works with file paths
+type prefixEngine struct {
+	stubEngine
+	prefix string
+}
+
+func (e *prefixEngine) Convert(ctx context.Context, input, output string, opts ConversionOptions) error {
+	data, err := os.ReadFile(input)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(output, append([]byte(e.prefix), data...), 0644)
+}
+
+// prefixStreamEngine is a prefixEngine that also converts streams in memory
+type prefixStreamEngine struct {
+	prefixEngine
+}
+
+func (e *prefixStreamEngine) ConvertStream(ctx context.Context, input io.Reader, output io.Writer, format Format, opts ConversionOptions) error {
+	if _, err := io.WriteString(output, e.prefix); err != nil {
+		return err
+	}
+	_, err := io.Copy(output, input)
+	return err
+}
+
+// TestConverterService_GetConversionMatrix tests that the matrix reflects registered engines
+func TestConverterService_GetConversionMatrix(t *testing.T) {
+	document := &stubEngine{name: "document", edges: []ConversionEdge{
+		{From: FileTypeDOCX, To: FormatHTML, Cost: EdgeCostInProcess},
+	}}
+	html := &stubEngine{name: "html", edges: []ConversionEdge{
+		{From: FileTypeHTML, To: FormatPDF, Cost: EdgeCostBrowser},
+	}}
+	service := NewConverterService(
+		map[FileType]IConverter{FileTypeDOCX: document, FileTypeHTML: html},
+		nil, nil, nil,
+	)
+
+	matrix := service.GetConversionMatrix()
+	if got := matrix[FileTypeDOCX]; len(got) != 2 || got[0] != FormatPDF || got[1] != FormatHTML {
+		t.Errorf("Expected DOCX to reach [PDF HTML], got %v", got)
+	}
+	if _, ok := matrix[FileTypeJPEG]; ok {
+		t.Error("Expected no entry for JPEG without an image engine")
+	}
+
+	formats := service.GetSupportedFormats()
+	if len(formats) != 2 {
+		t.Errorf("Expected 2 supported formats, got %v", formats)
+	}
+}
+
+// TestConverterService_ConvertStream tests a multi-hop stream conversion that mixes
+// an in-memory engine with one that has to be staged through temporary files
+func TestConverterService_ConvertStream(t *testing.T) {
+	document := &prefixStreamEngine{prefixEngine{
+		stubEngine: stubEngine{name: "document", edges: []ConversionEdge{
+			{From: FileTypeDOCX, To: FormatHTML, Cost: EdgeCostInProcess},
+		}},
+		prefix: "html:",
+	}}
+	html := &prefixEngine{
+		stubEngine: stubEngine{name: "html", edges: []ConversionEdge{
+			{From: FileTypeHTML, To: FormatPDF, Cost: EdgeCostBrowser},
+		}},
+		prefix: "pdf:",
+	}
+	service := NewConverterService(
+		map[FileType]IConverter{FileTypeDOCX: document, FileTypeHTML: html},
+		nopLogger{}, nopNotifier{}, nil,
+	)
+	service.scratchDir = t.TempDir()
+
+	var output bytes.Buffer
+	result := service.ConvertStream(context.Background(), strings.NewReader("docx"), FileTypeDOCX, &output, FormatPDF, ConversionOptions{})
+	if !result.Success {
+		t.Fatalf("Expected stream conversion to succeed, got error: %v", result.Error)
+	}
+	if got := output.String(); got != "pdf:html:docx" {
+		t.Errorf("Expected output %q, got %q", "pdf:html:docx", got)
+	}
+
+	entries, err := os.ReadDir(service.scratchDir)
+	if err != nil {
+		t.Fatalf("Failed to read scratch directory: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Expected staged files to be removed, found %d entries", len(entries))
+	}
+}
+
+// TestConverterService_ConvertStream_NoRoute tests that unreachable formats fail without writing output
+func TestConverterService_ConvertStream_NoRoute(t *testing.T) {
+	service := NewConverterService(map[FileType]IConverter{}, nopLogger{}, nopNotifier{}, nil)
+
+	var output bytes.Buffer
+	result := service.ConvertStream(context.Background(), strings.NewReader("png"), FileTypePNG, &output, FormatPDF, ConversionOptions{})
+	if result.Success || result.Error == nil {
+		t.Error("Expected an error for an unreachable format")
+	}
+	if !errors.Is(result.Error, ErrUnsupportedFormat) {
+		t.Errorf("Expected ErrUnsupportedFormat, got %v", result.Error)
+	}
+	if output.Len() != 0 {
+		t.Errorf("Expected no output, got %d bytes", output.Len())
+	}
+}
+
+// TestConverterService_Convert_InvalidOptions tests that out-of-range options are rejected before converting
+func TestConverterService_Convert_InvalidOptions(t *testing.T) {
+	document := &prefixEngine{
+		stubEngine: stubEngine{name: "document", edges: []ConversionEdge{
+			{From: FileTypeDOCX, To: FormatHTML, Cost: EdgeCostInProcess},
+		}},
+		prefix: "html:",
+	}
+	service := NewConverterService(map[FileType]IConverter{FileTypeDOCX: document}, nopLogger{}, nopNotifier{}, nil)
+
+	var output bytes.Buffer
+	opts := ConversionOptions{Page: PageOptions{Size: "Tabloid"}}
+	result := service.ConvertStream(context.Background(), strings.NewReader("docx"), FileTypeDOCX, &output, FormatHTML, opts)
+	if result.Success || result.Error == nil {
+		t.Error("Expected an unknown page size to be rejected")
+	}
+	if code := ErrorCodeOf(result.Error); code != ErrorCodeInvalidOptions {
+		t.Errorf("Expected error code %s, got %s", ErrorCodeInvalidOptions, code)
+	}
+	if output.Len() != 0 {
+		t.Errorf("Expected no output, got %d bytes", output.Len())
+	}
+}
+
+// TestConverterService_BatchConvert_Concurrency tests that batches run in parallel within
+// the engine's concurrency limit, keep result order and emit per-file events
+func TestConverterService_BatchConvert_Concurrency(t *testing.T) {
+	engine := &slowEngine{stubEngine: stubEngine{name: "slow", edges: []ConversionEdge{
+		{From: FileTypeDOCX, To: FormatHTML, Cost: EdgeCostInProcess},
+	}}}
+	notifier := &recordingNotifier{finished: make(map[int]string)}
+	service := NewConverterService(
+		map[FileType]IConverter{FileTypeDOCX: engine},
+		nopLogger{}, notifier, existsWriter{},
+		WithBatchConcurrency(4),
+		WithEngineConcurrency("slow", 2),
+	)
+
+	dir := t.TempDir()
+	files := make([]string, 8)
+	for i := range files {
+		files[i] = filepath.Join(dir, fmt.Sprintf("doc%d.docx", i))
+	}
+	results := service.BatchConvert(context.Background(), files, "html", ConversionOptions{})
+
+	if len(results) != len(files) {
+		t.Fatalf("Expected %d results, got %d", len(files), len(results))
+	}
+	for i, result := range results {
+		if !result.Success {
+			t.Errorf("File %d failed: %v", i, result.Error)
+		}
+		if want := filepath.Join(dir, fmt.Sprintf("doc%d.html", i)); result.OutputPath != want {
+			t.Errorf("Expected result %d for %s, got %s", i, want, result.OutputPath)
+		}
+	}
+	if peak := atomic.LoadInt32(&engine.peak); peak > 2 {
+		t.Errorf("Expected at most 2 concurrent conversions, got %d", peak)
+	} else if peak < 2 {
+		t.Errorf("Expected conversions to overlap, peak concurrency was %d", peak)
+	}
+	if notifier.started != len(files) || len(notifier.finished) != len(files) {
+		t.Errorf("Expected %d start and finish events, got %d and %d", len(files), notifier.started, len(notifier.finished))
+	}
+}
+
+// recordingScheduler runs tasks inline and records lane limits and the lane of each task
+type recordingScheduler struct {
+	mu     sync.Mutex
+	limits map[string]int
+	lanes  []string
+}
+
+func (r *recordingScheduler) Run(ctx context.Context, engine string, task func(ctx context.Context) error) error {
+	r.mu.Lock()
+	r.lanes = append(r.lanes, engine)
+	r.mu.Unlock()
+	return task(ctx)
+}
+
+func (r *recordingScheduler) SetLimit(engine string, limit int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.limits[engine] = limit
+}
+
+// TestConverterService_SchedulerLanes tests that in-process steps of an engine with
+// browser-backed steps are not held to the browser concurrency cap
+func TestConverterService_SchedulerLanes(t *testing.T) {
+	engine := &prefixEngine{
+		stubEngine: stubEngine{name: "document", edges: []ConversionEdge{
+			{From: FileTypeDOCX, To: FormatHTML, Cost: EdgeCostInProcess},
+			{From: FileTypeDOCX, To: FormatPDF, Cost: EdgeCostBrowser},
+		}},
+		prefix: "out:",
+	}
+	scheduler := &recordingScheduler{limits: make(map[string]int)}
+	service, source := newOutputTestService(t, engine, WithScheduler(scheduler))
+
+	if got := scheduler.limits["document"]; got != runtime.NumCPU() {
+		t.Errorf("Expected the in-process lane to allow %d conversions, got %d", runtime.NumCPU(), got)
+	}
+	if got := scheduler.limits["document/browser"]; got != DefaultBrowserConcurrency {
+		t.Errorf("Expected the browser lane to allow %d conversions, got %d", DefaultBrowserConcurrency, got)
+	}
+
+	dir := filepath.Dir(source)
+	for _, output := range []string{"report.html", "report.pdf"} {
+		if result := service.Convert(context.Background(), source, filepath.Join(dir, output), ConversionOptions{}); !result.Success {
+			t.Fatalf("Failed to convert to %s: %v", output, result.Error)
+		}
+	}
+	if len(scheduler.lanes) != 2 || scheduler.lanes[0] != "document" || scheduler.lanes[1] != "document/browser" {
+		t.Errorf("Expected the steps to run in lanes [document document/browser], got %v", scheduler.lanes)
+	}
+
+	// An explicit limit bounds all of the engine's conversions in one lane
+	scheduler = &recordingScheduler{limits: make(map[string]int)}
+	service, source = newOutputTestService(t, engine, WithScheduler(scheduler), WithEngineConcurrency("document", 3))
+	if result := service.Convert(context.Background(), source, filepath.Join(filepath.Dir(source), "report.pdf"), ConversionOptions{}); !result.Success {
+		t.Fatalf("Failed to convert to PDF: %v", result.Error)
+	}
+	if _, ok := scheduler.limits["document/browser"]; ok || scheduler.limits["document"] != 3 {
+		t.Errorf("Expected a single lane limited to 3, got %v", scheduler.limits)
+	}
+	if len(scheduler.lanes) != 1 || scheduler.lanes[0] != "document" {
+		t.Errorf("Expected the step to run in lane document, got %v", scheduler.lanes)
+	}
+}
+
+// TestConverterService_BatchConvertJobs_CancelledBeforeStart tests that a
+// cancelled batch still reports one cancellation result per job, in order
+func TestConverterService_BatchConvertJobs_CancelledBeforeStart(t *testing.T) {
+	service, source := newOutputTestService(t, newPrefixDocumentEngine())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := service.BatchConvertJobs(ctx, []BatchJob{
+		{Source: source, Target: filepath.Join(t.TempDir(), "a.html")},
+		{Source: source, Target: filepath.Join(t.TempDir(), "b.html")},
+	})
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	for i, result := range results {
+		if result.Success || ErrorCodeOf(result.Error) != ErrorCodeCancelled {
+			t.Errorf("Expected job %d to report the cancellation, got %+v", i, result)
+		}
+	}
+}
+
+// TestConverterService_BatchConvertJobs tests that each job is written to its own target with its own options
+func TestConverterService_BatchConvertJobs(t *testing.T) {
+	service, source := newOutputTestService(t, newPrefixDocumentEngine())
+	outDir := filepath.Join(filepath.Dir(source), "out")
+	if err := os.Mkdir(outDir, 0755); err != nil {
+		t.Fatalf("Failed to create output directory: %v", err)
+	}
+	existing := filepath.Join(outDir, "b.html")
+	if err := os.WriteFile(existing, []byte("keep"), 0644); err != nil {
+		t.Fatalf("Failed to create existing output: %v", err)
+	}
+
+	results := service.BatchConvertJobs(context.Background(), []BatchJob{
+		{Source: source, Target: filepath.Join(outDir, "a.html")},
+		{Source: source, Target: existing, Options: ConversionOptions{Output: OutputOptions{Collision: CollisionSkip}}},
+	})
+
+	if len(results) != 2 || !results[0].Success || !results[1].Success {
+		t.Fatalf("Expected both jobs to succeed, got %+v", results)
+	}
+	if got := readOutput(t, filepath.Join(outDir, "a.html")); got != "html:docx" {
+		t.Errorf("Expected the first job's output at its target, got %q", got)
+	}
+	if !results[1].Skipped || readOutput(t, existing) != "keep" {
+		t.Errorf("Expected the second job to be skipped by its own collision policy")
+	}
+}
@@ -0,0 +1,27 @@
+package domain
+
+import "path/filepath"
+
+// FileTypeDetection describes the type detected for an input file
+type FileTypeDetection struct {
+	// FileType is the type used for routing; detectors prefer the content over the extension
+	FileType FileType
+	// ExtensionType is the type implied by the file extension, empty if unknown
+	ExtensionType FileType
+	// Warnings reports inconsistencies such as a mislabelled extension
+	Warnings []string
+}
+
+// ExtensionMismatch reports whether the content disagrees with the file extension
+func (d FileTypeDetection) ExtensionMismatch() bool {
+	return d.ExtensionType != "" && d.FileType != d.ExtensionType
+}
+
+// extensionDetector is the fallback detector that trusts file extensions
+type extensionDetector struct{}
+
+// Detect maps the file extension to a file type
+func (extensionDetector) Detect(path string) (FileTypeDetection, error) {
+	fileType, _ := FileTypeFromExtension(filepath.Ext(path))
+	return FileTypeDetection{FileType: fileType, ExtensionType: fileType}, nil
+}
@@ -0,0 +1,157 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrorCode is a stable, machine-readable identifier for a class of
+// conversion failure. Codes are part of the frontend contract and must not
+// be renamed.
+type ErrorCode string
+
+const (
+	ErrorCodeUnsupportedFormat ErrorCode = "unsupported_format"
+	ErrorCodeCorruptInput      ErrorCode = "corrupt_input"
+	ErrorCodeInputNotFound     ErrorCode = "input_not_found"
+	ErrorCodeInvalidOptions    ErrorCode = "invalid_options"
+	ErrorCodeEngineUnavailable ErrorCode = "engine_unavailable"
+	ErrorCodeEngineFailed      ErrorCode = "engine_failed"
+	ErrorCodeBrowserMissing    ErrorCode = "browser_missing"
+	ErrorCodeCancelled         ErrorCode = "cancelled"
+	ErrorCodeTimeout           ErrorCode = "timeout"
+	ErrorCodeOutputNotWritable ErrorCode = "output_not_writable"
+	ErrorCodeOutputExists      ErrorCode = "output_exists"
+	ErrorCodeResourceLimit     ErrorCode = "resource_limit_exceeded"
+	// ErrorCodeUnknown classifies errors that carry no code
+	ErrorCodeUnknown ErrorCode = "conversion_failed"
+)
+
+// Sentinel errors for each error class; match them with errors.Is
+var (
+	ErrUnsupportedFormat = errors.New("unsupported format")
+	ErrCorruptInput      = errors.New("corrupt input")
+	ErrInputNotFound     = errors.New("input file not found")
+	ErrInvalidOptions    = errors.New("invalid conversion options")
+	ErrEngineUnavailable = errors.New("conversion engine unavailable")
+	ErrEngineFailed      = errors.New("conversion engine failed")
+	ErrBrowserMissing    = errors.New("headless browser not available")
+	ErrCancelled         = errors.New("conversion cancelled")
+	ErrTimeout           = errors.New("conversion timed out")
+	ErrOutputNotWritable = errors.New("output not writable")
+	ErrOutputExists      = errors.New("output file already exists")
+	ErrResourceLimit     = errors.New("resource limit exceeded")
+)
+
+// errorClasses maps each code to its sentinel and whether retrying the same
+// request can succeed without the user changing anything
+var errorClasses = map[ErrorCode]struct {
+	sentinel  error
+	retryable bool
+}{
+	ErrorCodeUnsupportedFormat: {ErrUnsupportedFormat, false},
+	ErrorCodeCorruptInput:      {ErrCorruptInput, false},
+	ErrorCodeInputNotFound:     {ErrInputNotFound, false},
+	ErrorCodeInvalidOptions:    {ErrInvalidOptions, false},
+	ErrorCodeEngineUnavailable: {ErrEngineUnavailable, true},
+	ErrorCodeEngineFailed:      {ErrEngineFailed, false},
+	ErrorCodeBrowserMissing:    {ErrBrowserMissing, false},
+	ErrorCodeCancelled:         {ErrCancelled, false},
+	ErrorCodeTimeout:           {ErrTimeout, true},
+	ErrorCodeOutputNotWritable: {ErrOutputNotWritable, false},
+	ErrorCodeOutputExists:      {ErrOutputExists, false},
+	ErrorCodeResourceLimit:     {ErrResourceLimit, false},
+}
+
+// Retryable reports whether a failure with this code may succeed when retried unchanged
+func (c ErrorCode) Retryable() bool {
+	return errorClasses[c].retryable
+}
+
+// ConversionError is a conversion failure tagged with its error code.
+// It matches its class sentinel with errors.Is and unwraps to the cause.
+type ConversionError struct {
+	Code ErrorCode
+	Err  error
+}
+
+// NewError tags err with an error code; it returns nil for a nil err
+func NewError(code ErrorCode, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ConversionError{Code: code, Err: err}
+}
+
+// Errorf formats an error message and tags it with an error code
+func Errorf(code ErrorCode, format string, args ...any) error {
+	return &ConversionError{Code: code, Err: fmt.Errorf(format, args...)}
+}
+
+// Error returns the message of the underlying cause
+func (e *ConversionError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying cause
+func (e *ConversionError) Unwrap() error {
+	return e.Err
+}
+
+// Is matches the sentinel error of the code's class
+func (e *ConversionError) Is(target error) bool {
+	class, ok := errorClasses[e.Code]
+	return ok && target == class.sentinel
+}
+
+// ErrorCodeOf classifies an error. Tagged errors report their own code;
+// sentinels, context errors and missing files are recognised when wrapped
+// with %w. Anything else is ErrorCodeUnknown.
+func ErrorCodeOf(err error) ErrorCode {
+	if err == nil {
+		return ""
+	}
+
+	var conversionErr *ConversionError
+	if errors.As(err, &conversionErr) {
+		return conversionErr.Code
+	}
+	for code, class := range errorClasses {
+		if errors.Is(err, class.sentinel) {
+			return code
+		}
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorCodeTimeout
+	case errors.Is(err, context.Canceled):
+		return ErrorCodeCancelled
+	case errors.Is(err, os.ErrNotExist):
+		return ErrorCodeInputNotFound
+	}
+	return ErrorCodeUnknown
+}
+
+// IsRetryable reports whether the failure may succeed when retried unchanged
+func IsRetryable(err error) bool {
+	return ErrorCodeOf(err).Retryable()
+}
+
+// withCode tags err with code unless it is already classified
+func withCode(code ErrorCode, err error) error {
+	if err == nil || ErrorCodeOf(err) != ErrorCodeUnknown {
+		return err
+	}
+	return NewError(code, err)
+}
+
+// contextError tags a context error as a cancellation or timeout
+func contextError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return NewError(ErrorCodeTimeout, err)
+	}
+	return NewError(ErrorCodeCancelled, err)
+}
@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+)
+
+// TestErrorCodeOf tests that tagged errors, sentinels and standard library errors are classified
+func TestErrorCodeOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ErrorCode
+	}{
+		{"nil", nil, ""},
+		{"tagged", Errorf(ErrorCodeCorruptInput, "parsing docx: %w", errors.New("zip: not a valid zip file")), ErrorCodeCorruptInput},
+		{"wrapped tagged", fmt.Errorf("converting DOCX to HTML: %w", NewError(ErrorCodeBrowserMissing, errors.New("no browser"))), ErrorCodeBrowserMissing},
+		{"wrapped sentinel", fmt.Errorf("decoding: %w", ErrResourceLimit), ErrorCodeResourceLimit},
+		{"deadline", fmt.Errorf("printing: %w", context.DeadlineExceeded), ErrorCodeTimeout},
+		{"canceled", context.Canceled, ErrorCodeCancelled},
+		{"missing file", &os.PathError{Op: "open", Path: "in.png", Err: os.ErrNotExist}, ErrorCodeInputNotFound},
+		{"untagged", errors.New("something went wrong"), ErrorCodeUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ErrorCodeOf(tt.err); got != tt.want {
+				t.Errorf("ErrorCodeOf(%v) = %s, want %s", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestConversionError_IsAs tests that tagged errors match their sentinel and keep their cause
+func TestConversionError_IsAs(t *testing.T) {
+	cause := errors.New("permission denied")
+	err := fmt.Errorf("converting PNG to JPEG: %w", NewError(ErrorCodeOutputNotWritable, cause))
+
+	if !errors.Is(err, ErrOutputNotWritable) {
+		t.Error("Expected the error to match ErrOutputNotWritable")
+	}
+	if errors.Is(err, ErrCorruptInput) {
+		t.Error("Expected the error not to match another class")
+	}
+	if !errors.Is(err, cause) {
+		t.Error("Expected the error to unwrap to its cause")
+	}
+
+	var conversionErr *ConversionError
+	if !errors.As(err, &conversionErr) || conversionErr.Code != ErrorCodeOutputNotWritable {
+		t.Fatalf("Expected a *ConversionError with code %s, got %v", ErrorCodeOutputNotWritable, err)
+	}
+	if conversionErr.Error() != cause.Error() {
+		t.Errorf("Expected the cause's message, got %q", conversionErr.Error())
+	}
+}
+
+// TestIsRetryable tests which error classes are reported as retryable
+func TestIsRetryable(t *testing.T) {
+	if !IsRetryable(contextError(context.DeadlineExceeded)) {
+		t.Error("Expected timeouts to be retryable")
+	}
+	if !IsRetryable(Errorf(ErrorCodeEngineUnavailable, "browser target crashed")) {
+		t.Error("Expected an unavailable engine to be retryable")
+	}
+	if IsRetryable(Errorf(ErrorCodeEngineFailed, "creating document engine")) {
+		t.Error("Expected a failed engine not to be retryable")
+	}
+	if IsRetryable(contextError(context.Canceled)) {
+		t.Error("Expected cancellations not to be retryable")
+	}
+	if IsRetryable(Errorf(ErrorCodeCorruptInput, "bad zip")) {
+		t.Error("Expected corrupt input not to be retryable")
+	}
+	if IsRetryable(errors.New("unknown")) {
+		t.Error("Expected unclassified errors not to be retryable")
+	}
+}
+
+// TestConverterService_Convert_Cancelled tests that cancellation is reported as ErrCancelled
+func TestConverterService_Convert_Cancelled(t *testing.T) {
+	service := NewConverterService(map[FileType]IConverter{}, nopLogger{}, nopNotifier{}, existsWriter{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result := service.Convert(ctx, "/tmp/in.docx", "/tmp/out.html", ConversionOptions{})
+	if !errors.Is(result.Error, ErrCancelled) || !errors.Is(result.Error, context.Canceled) {
+		t.Errorf("Expected ErrCancelled wrapping context.Canceled, got %v", result.Error)
+	}
+}
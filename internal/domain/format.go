@@ -1,5 +1,7 @@
 package domain
 
+import "strings"
+
 // Format represents output file formats
 type Format string
 
@@ -7,7 +9,11 @@ const (
 	FormatPDF  Format = "PDF"
 	FormatHTML Format = "HTML"
 	FormatPNG  Format = "PNG"
+	FormatJPEG Format = "JPEG"
 	FormatWEBP Format = "WEBP"
+	FormatGIF  Format = "GIF"
+	FormatBMP  Format = "BMP"
+	FormatTIFF Format = "TIFF"
 )
 
 // FileType represents input file types
@@ -16,8 +22,83 @@ type FileType string
 const (
 	FileTypeXLSX FileType = "XLSX"
 	FileTypeDOCX FileType = "DOCX"
+	FileTypeHTML FileType = "HTML"
 	FileTypeJPEG FileType = "JPEG"
 	FileTypePNG  FileType = "PNG"
 	FileTypeWEBP FileType = "WEBP"
+	FileTypeGIF  FileType = "GIF"
+	FileTypeBMP  FileType = "BMP"
+	FileTypeTIFF FileType = "TIFF"
 )
 
+// knownFormats lists every output format in presentation order
+var knownFormats = []Format{FormatPDF, FormatHTML, FormatPNG, FormatJPEG, FormatWEBP, FormatGIF, FormatBMP, FormatTIFF}
+
+// knownFileTypes lists every input file type in presentation order
+var knownFileTypes = []FileType{FileTypeDOCX, FileTypeXLSX, FileTypeHTML, FileTypeJPEG, FileTypePNG, FileTypeWEBP, FileTypeGIF, FileTypeBMP, FileTypeTIFF}
+
+// fileTypeExtensions maps input file types to the extensions they are saved with
+var fileTypeExtensions = map[FileType][]string{
+	FileTypeDOCX: {".docx"},
+	FileTypeXLSX: {".xlsx"},
+	FileTypeHTML: {".html", ".htm"},
+	FileTypeJPEG: {".jpeg", ".jpg"},
+	FileTypePNG:  {".png"},
+	FileTypeWEBP: {".webp"},
+	FileTypeGIF:  {".gif"},
+	FileTypeBMP:  {".bmp"},
+	FileTypeTIFF: {".tiff", ".tif"},
+}
+
+// Extensions returns the file extensions (with leading dot) used by the file type
+func (t FileType) Extensions() []string {
+	return fileTypeExtensions[t]
+}
+
+// FileTypeFromExtension maps a file extension (with or without leading dot) to an input file type
+func FileTypeFromExtension(ext string) (FileType, bool) {
+	ext = "." + strings.TrimPrefix(strings.ToLower(ext), ".")
+	for _, fileType := range knownFileTypes {
+		for _, candidate := range fileTypeExtensions[fileType] {
+			if candidate == ext {
+				return fileType, true
+			}
+		}
+	}
+	return "", false
+}
+
+// AsFileType returns the input file type produced by this output format,
+// so the output of one conversion step can feed the next one
+func (f Format) AsFileType() FileType {
+	return FileType(f)
+}
+
+// Extension returns the canonical file extension (with leading dot) for the format
+func (f Format) Extension() string {
+	return "." + strings.ToLower(string(f))
+}
+
+// FormatFromExtension maps a file extension (with or without leading dot) to an output format
+func FormatFromExtension(ext string) (Format, bool) {
+	switch strings.TrimPrefix(strings.ToLower(ext), ".") {
+	case "pdf":
+		return FormatPDF, true
+	case "html", "htm":
+		return FormatHTML, true
+	case "png":
+		return FormatPNG, true
+	case "jpeg", "jpg":
+		return FormatJPEG, true
+	case "webp":
+		return FormatWEBP, true
+	case "gif":
+		return FormatGIF, true
+	case "bmp":
+		return FormatBMP, true
+	case "tiff", "tif":
+		return FormatTIFF, true
+	default:
+		return "", false
+	}
+}
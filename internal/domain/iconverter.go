@@ -1,12 +1,42 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"io"
+)
 
 // IConverter defines the contract for conversion operations
 type IConverter interface {
-	// Convert performs the conversion from input to output
-	Convert(ctx context.Context, input, output string) error
+	// Convert performs the conversion from input to output.
+	// The zero value of opts selects the converter's defaults.
+	Convert(ctx context.Context, input, output string, opts ConversionOptions) error
 
 	// Validate checks if the input file is valid for this converter
 	Validate(ctx context.Context, file string) error
+
+	// Capabilities describes the conversions and options this converter supports
+	Capabilities() Capabilities
+}
+
+// StreamConverter is implemented by converters that can convert without
+// touching the filesystem. Converters that do not implement it are staged
+// through temporary files by ConverterService.ConvertStream.
+type StreamConverter interface {
+	// ConvertStream reads the input document from input and writes it to output in the given format
+	ConvertStream(ctx context.Context, input io.Reader, output io.Writer, format Format, opts ConversionOptions) error
+}
+
+// EngineResolver is implemented by converters that stand in for another
+// converter, such as registry entries constructed on first use. The service
+// resolves them before checking for optional interfaces like StreamConverter.
+type EngineResolver interface {
+	// ResolveEngine returns the converter performing the conversions
+	ResolveEngine() (IConverter, error)
+}
+
+// EngineSource supplies converters to a ConverterService, such as a registry
+// of engines contributed by several packages
+type EngineSource interface {
+	// Engines returns the converters to route conversions through
+	Engines() []IConverter
 }
@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Operation identifies the kind of engine call passing through the interceptor chain
+type Operation string
+
+const (
+	// OperationValidate is an IConverter.Validate call
+	OperationValidate Operation = "validate"
+	// OperationConvert is an IConverter.Convert call for one route step
+	OperationConvert Operation = "convert"
+	// OperationConvertStream is a stream conversion of one route step
+	OperationConvertStream Operation = "convert_stream"
+)
+
+// Call describes an engine call to interceptors
+type Call struct {
+	Operation Operation
+	// Engine is the name of the engine handling the call
+	Engine string
+	// Edge is the conversion step; it is zero for validation
+	Edge ConversionEdge
+	// Input and Output are file paths; they are empty for streams
+	Input  string
+	Output string
+	// Options are the conversion options; they are zero for validation
+	Options ConversionOptions
+}
+
+// Interceptor wraps engine calls with cross-cutting behaviour. It must call
+// next to continue the chain, and may replace the context passed on, inspect
+// or replace the error, or skip the call entirely by not calling next.
+type Interceptor func(ctx context.Context, call Call, next func(ctx context.Context) error) error
+
+// WithInterceptors wraps every engine call in the given interceptors. The
+// first interceptor is the outermost; the service's logging interceptor
+// wraps them all. Repeated options append to the chain.
+func WithInterceptors(interceptors ...Interceptor) ServiceOption {
+	return func(s *ConverterService) {
+		for _, interceptor := range interceptors {
+			if interceptor != nil {
+				s.interceptors = append(s.interceptors, interceptor)
+			}
+		}
+	}
+}
+
+// TimingInterceptor reports how long each engine call took and how it ended
+func TimingInterceptor(observe func(call Call, elapsed time.Duration, err error)) Interceptor {
+	return func(ctx context.Context, call Call, next func(ctx context.Context) error) error {
+		start := time.Now()
+		err := next(ctx)
+		observe(call, time.Since(start), err)
+		return err
+	}
+}
+
+// LoggingInterceptor logs the outcome and duration of each engine call:
+// successes at debug level and failures at error level
+func LoggingInterceptor(logger Logger) Interceptor {
+	return TimingInterceptor(func(call Call, elapsed time.Duration, err error) {
+		if err != nil {
+			logger.Error(fmt.Sprintf("Engine %s %s failed after %v", call.Engine, call.describe(), elapsed), err)
+			return
+		}
+		logger.Debug(fmt.Sprintf("Engine %s %s took %v", call.Engine, call.describe(), elapsed))
+	})
+}
+
+// describe returns a short description of the call for log messages
+func (c Call) describe() string {
+	switch c.Operation {
+	case OperationValidate:
+		return fmt.Sprintf("validation of %s", c.Input)
+	case OperationConvertStream:
+		return fmt.Sprintf("stream conversion %s -> %s", c.Edge.From, c.Edge.To)
+	default:
+		return fmt.Sprintf("conversion %s -> %s", c.Edge.From, c.Edge.To)
+	}
+}
+
+// intercept runs fn through the interceptor chain, passing the service's
+// resource limits on to the engine and attributing its reports to it
+func (s *ConverterService) intercept(ctx context.Context, call Call, fn func(ctx context.Context) error) error {
+	ctx = withEngineName(ContextWithResourceLimits(ctx, s.limits), call.Engine)
+	handler := fn
+	for i := len(s.interceptors) - 1; i >= 0; i-- {
+		interceptor, next := s.interceptors[i], handler
+		handler = func(ctx context.Context) error {
+			return interceptor(ctx, call, next)
+		}
+	}
+	return handler(ctx)
+}
+
+// validateWith validates file with engine through the interceptor chain
+func (s *ConverterService) validateWith(ctx context.Context, engine IConverter, file string) error {
+	call := Call{Operation: OperationValidate, Engine: engine.Capabilities().Name, Input: file}
+	return s.intercept(ctx, call, func(ctx context.Context) error {
+		return engine.Validate(ctx, file)
+	})
+}
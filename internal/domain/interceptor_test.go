@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestConverterService_Interceptors_Order tests that interceptors wrap engine calls in registration order
+func TestConverterService_Interceptors_Order(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+	record := func(name string) Interceptor {
+		return func(ctx context.Context, call Call, next func(ctx context.Context) error) error {
+			mu.Lock()
+			events = append(events, name+">"+string(call.Operation))
+			mu.Unlock()
+			err := next(ctx)
+			mu.Lock()
+			events = append(events, name+"<"+string(call.Operation))
+			mu.Unlock()
+			return err
+		}
+	}
+
+	service, source := newOutputTestService(t, newPrefixDocumentEngine(), WithInterceptors(record("outer"), record("inner")))
+	result := service.Convert(context.Background(), source, filepath.Join(filepath.Dir(source), "report.html"), ConversionOptions{})
+	if !result.Success {
+		t.Fatalf("Conversion failed: %v", result.Error)
+	}
+
+	want := []string{
+		"outer>validate", "inner>validate", "inner<validate", "outer<validate",
+		"outer>convert", "inner>convert", "inner<convert", "outer<convert",
+	}
+	if strings.Join(events, " ") != strings.Join(want, " ") {
+		t.Errorf("Expected events %v, got %v", want, events)
+	}
+}
+
+// TestConverterService_Interceptors_ShortCircuit tests that an interceptor can reject a call without running the engine
+func TestConverterService_Interceptors_ShortCircuit(t *testing.T) {
+	engine := &countingEngine{prefixStreamEngine: prefixStreamEngine{prefixEngine: *newPrefixDocumentEngine()}}
+	reject := func(ctx context.Context, call Call, next func(ctx context.Context) error) error {
+		if call.Operation == OperationConvert {
+			return Errorf(ErrorCodeResourceLimit, "input too large")
+		}
+		return next(ctx)
+	}
+	service, source := newOutputTestService(t, engine, WithInterceptors(reject))
+
+	result := service.Convert(context.Background(), source, filepath.Join(filepath.Dir(source), "report.html"), ConversionOptions{})
+	if !errors.Is(result.Error, ErrResourceLimit) {
+		t.Fatalf("Expected ErrResourceLimit, got %v", result.Error)
+	}
+	if calls := atomic.LoadInt32(&engine.calls); calls != 0 {
+		t.Errorf("Expected the engine not to run, ran %d times", calls)
+	}
+}
+
+// TestTimingInterceptor tests that the timing interceptor observes every engine call with its outcome
+func TestTimingInterceptor(t *testing.T) {
+	var mu sync.Mutex
+	observed := make(map[Operation]error)
+	timing := TimingInterceptor(func(call Call, elapsed time.Duration, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if call.Engine != "document" {
+			t.Errorf("Expected the document engine, got %q", call.Engine)
+		}
+		observed[call.Operation] = err
+	})
+	engine := &failingEngine{stubEngine: stubEngine{name: "document", edges: []ConversionEdge{
+		{From: FileTypeDOCX, To: FormatHTML, Cost: EdgeCostInProcess},
+	}}}
+	service, source := newOutputTestService(t, engine, WithInterceptors(timing))
+
+	service.Convert(context.Background(), source, filepath.Join(filepath.Dir(source), "report.html"), ConversionOptions{})
+	if err, ok := observed[OperationValidate]; !ok || err != nil {
+		t.Errorf("Expected a successful validation to be observed, got %v (observed %v)", err, ok)
+	}
+	if err := observed[OperationConvert]; err == nil || err.Error() != "engine crashed" {
+		t.Errorf("Expected the engine failure to be observed, got %v", err)
+	}
+}
@@ -21,3 +21,14 @@ type ProgressNotifier interface {
 	NotifyError(err error)
 }
 
+// FileProgressNotifier is optionally implemented by a ProgressNotifier that
+// wants per-file events while BatchConvert processes files concurrently
+type FileProgressNotifier interface {
+	NotifyFileStarted(index int, path string)
+	NotifyFileFinished(index int, path string, result Result)
+}
+
+// FileTypeDetector identifies the real type of an input file
+type FileTypeDetector interface {
+	Detect(path string) (FileTypeDetection, error)
+}
@@ -0,0 +1,201 @@
+package domain
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+)
+
+// ResourceLimits bound the input an engine accepts before it decodes it, so
+// a small crafted file cannot exhaust memory. A zero field disables that limit.
+type ResourceLimits struct {
+	// MaxFileSize is the largest input, in bytes, read from a file or stream
+	MaxFileSize int64
+	// MaxImageDimension is the largest width or height of an image in pixels
+	MaxImageDimension int
+	// MaxImagePixels is the largest width times height of an image
+	MaxImagePixels int64
+	// MaxZipEntrySize is the largest uncompressed size of one entry of an
+	// OOXML (DOCX, XLSX) archive
+	MaxZipEntrySize int64
+	// MaxZipRatio is the largest uncompressed to compressed size ratio of an
+	// archive entry; entries smaller than zipRatioMinSize are not checked
+	MaxZipRatio float64
+	// MaxSheetRows is the largest number of rows read from one worksheet
+	MaxSheetRows int
+	// MaxSheetCells is the largest number of cells read from one worksheet
+	MaxSheetCells int64
+}
+
+// DefaultResourceLimits accept any realistic document while rejecting
+// decompression bombs
+var DefaultResourceLimits = ResourceLimits{
+	MaxFileSize:       256 << 20,
+	MaxImageDimension: 32768,
+	MaxImagePixels:    100_000_000,
+	MaxZipEntrySize:   256 << 20,
+	MaxZipRatio:       200,
+	MaxSheetRows:      100_000,
+	MaxSheetCells:     2_000_000,
+}
+
+// zipRatioMinSize keeps small, highly compressible entries such as empty
+// XML parts from tripping the ratio limit
+const zipRatioMinSize = 1 << 20
+
+// LimitError reports which resource limit an input exceeded. It is wrapped
+// in a ConversionError with ErrorCodeResourceLimit.
+type LimitError struct {
+	// Limit names the ResourceLimits field that was exceeded
+	Limit string
+	// Subject is what was measured, such as an archive entry or sheet name
+	Subject string
+	Value   int64
+	Max     int64
+}
+
+func (e *LimitError) Error() string {
+	switch e.Limit {
+	case "MaxFileSize":
+		return fmt.Sprintf("input of %d bytes or more exceeds the %d byte limit", e.Value, e.Max)
+	case "MaxImageDimension":
+		return fmt.Sprintf("image %s of %d pixels exceeds the %d pixel limit", e.Subject, e.Value, e.Max)
+	case "MaxImagePixels":
+		return fmt.Sprintf("image of %d pixels exceeds the %d pixel limit", e.Value, e.Max)
+	case "MaxZipEntrySize":
+		return fmt.Sprintf("archive entry %s of %d bytes exceeds the %d byte limit", e.Subject, e.Value, e.Max)
+	case "MaxZipRatio":
+		return fmt.Sprintf("archive entry %s compressed %d:1 exceeds the %d:1 limit", e.Subject, e.Value, e.Max)
+	case "MaxSheetRows":
+		return fmt.Sprintf("sheet %s has more rows than the %d row limit", e.Subject, e.Max)
+	case "MaxSheetCells":
+		return fmt.Sprintf("sheet %s has more cells than the %d cell limit", e.Subject, e.Max)
+	default:
+		return fmt.Sprintf("%s %d exceeds the limit of %d", e.Limit, e.Value, e.Max)
+	}
+}
+
+// limitExceeded returns the typed error for an exceeded limit
+func limitExceeded(limit, subject string, value, max int64) error {
+	return NewError(ErrorCodeResourceLimit, &LimitError{Limit: limit, Subject: subject, Value: value, Max: max})
+}
+
+// WithResourceLimits replaces DefaultResourceLimits for the service and the
+// engines it calls
+func WithResourceLimits(limits ResourceLimits) ServiceOption {
+	return func(s *ConverterService) {
+		s.limits = limits
+	}
+}
+
+type resourceLimitsKey struct{}
+
+// ContextWithResourceLimits returns a context carrying limits for the engines
+// called with it
+func ContextWithResourceLimits(ctx context.Context, limits ResourceLimits) context.Context {
+	return context.WithValue(ctx, resourceLimitsKey{}, limits)
+}
+
+// ResourceLimitsFromContext returns the limits carried by ctx, or
+// DefaultResourceLimits when it carries none
+func ResourceLimitsFromContext(ctx context.Context) ResourceLimits {
+	if limits, ok := ctx.Value(resourceLimitsKey{}).(ResourceLimits); ok {
+		return limits
+	}
+	return DefaultResourceLimits
+}
+
+// CheckFileSize rejects inputs larger than MaxFileSize
+func (l ResourceLimits) CheckFileSize(size int64) error {
+	if l.MaxFileSize > 0 && size > l.MaxFileSize {
+		return limitExceeded("MaxFileSize", "", size, l.MaxFileSize)
+	}
+	return nil
+}
+
+// CheckImageSize rejects images whose dimensions exceed MaxImageDimension or
+// MaxImagePixels
+func (l ResourceLimits) CheckImageSize(width, height int) error {
+	if l.MaxImageDimension > 0 {
+		if width > l.MaxImageDimension {
+			return limitExceeded("MaxImageDimension", "width", int64(width), int64(l.MaxImageDimension))
+		}
+		if height > l.MaxImageDimension {
+			return limitExceeded("MaxImageDimension", "height", int64(height), int64(l.MaxImageDimension))
+		}
+	}
+	if pixels := int64(width) * int64(height); l.MaxImagePixels > 0 && pixels > l.MaxImagePixels {
+		return limitExceeded("MaxImagePixels", "", pixels, l.MaxImagePixels)
+	}
+	return nil
+}
+
+// CheckZip rejects archives with an entry larger than MaxZipEntrySize or
+// compressed more than MaxZipRatio. The sizes come from the archive's
+// directory; archive/zip fails reads that go past the declared size.
+func (l ResourceLimits) CheckZip(files []*zip.File) error {
+	for _, file := range files {
+		size := file.UncompressedSize64
+		if l.MaxZipEntrySize > 0 && size > uint64(l.MaxZipEntrySize) {
+			return limitExceeded("MaxZipEntrySize", file.Name, int64(size), l.MaxZipEntrySize)
+		}
+		if l.MaxZipRatio <= 0 || size < zipRatioMinSize {
+			continue
+		}
+		compressed := file.CompressedSize64
+		if compressed == 0 {
+			compressed = 1
+		}
+		if ratio := float64(size) / float64(compressed); ratio > l.MaxZipRatio {
+			return limitExceeded("MaxZipRatio", file.Name, int64(ratio), int64(l.MaxZipRatio))
+		}
+	}
+	return nil
+}
+
+// CheckSheet rejects worksheets with more than MaxSheetRows rows or
+// MaxSheetCells cells
+func (l ResourceLimits) CheckSheet(name string, rows int, cells int64) error {
+	if l.MaxSheetRows > 0 && rows > l.MaxSheetRows {
+		return limitExceeded("MaxSheetRows", name, int64(rows), int64(l.MaxSheetRows))
+	}
+	if l.MaxSheetCells > 0 && cells > l.MaxSheetCells {
+		return limitExceeded("MaxSheetCells", name, cells, l.MaxSheetCells)
+	}
+	return nil
+}
+
+// LimitReader returns a reader failing with a resource limit error once more
+// than MaxFileSize bytes are read from r
+func (l ResourceLimits) LimitReader(r io.Reader) io.Reader {
+	if l.MaxFileSize <= 0 {
+		return r
+	}
+	return &limitedReader{r: r, remaining: l.MaxFileSize, max: l.MaxFileSize}
+}
+
+// limitedReader is an io.LimitedReader that reports reading past the limit
+// instead of ending the stream early
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+	max       int64
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	if r.remaining < 0 {
+		return 0, limitExceeded("MaxFileSize", "", r.max+1, r.max)
+	}
+	// Read one byte past the limit to tell a stream that ends exactly at the
+	// limit from one that goes on
+	if int64(len(p)) > r.remaining+1 {
+		p = p[:r.remaining+1]
+	}
+	n, err := r.r.Read(p)
+	r.remaining -= int64(n)
+	if r.remaining < 0 {
+		return n + int(r.remaining), limitExceeded("MaxFileSize", "", r.max+1, r.max)
+	}
+	return n, err
+}
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+// TestResourceLimits_LimitReader tests that reading past MaxFileSize fails while reading up to it succeeds
+func TestResourceLimits_LimitReader(t *testing.T) {
+	limits := ResourceLimits{MaxFileSize: 4}
+
+	data, err := io.ReadAll(limits.LimitReader(bytes.NewReader([]byte("docx"))))
+	if err != nil || string(data) != "docx" {
+		t.Errorf("Expected input at the limit to be read, got %q, %v", data, err)
+	}
+
+	_, err = io.ReadAll(limits.LimitReader(bytes.NewReader([]byte("docx!"))))
+	var limitErr *LimitError
+	if !errors.Is(err, ErrResourceLimit) || !errors.As(err, &limitErr) || limitErr.Limit != "MaxFileSize" {
+		t.Errorf("Expected a MaxFileSize limit error, got %v", err)
+	}
+}
+
+// TestConverterService_Convert_FileSizeLimit tests that oversized inputs are rejected before any engine runs
+func TestConverterService_Convert_FileSizeLimit(t *testing.T) {
+	engine := &countingEngine{prefixStreamEngine: prefixStreamEngine{prefixEngine: *newPrefixDocumentEngine()}}
+	service, source := newOutputTestService(t, engine, WithResourceLimits(ResourceLimits{MaxFileSize: 3}))
+
+	result := service.Convert(context.Background(), source, filepath.Join(filepath.Dir(source), "report.html"), ConversionOptions{})
+	if ErrorCodeOf(result.Error) != ErrorCodeResourceLimit {
+		t.Fatalf("Expected a resource limit error, got %v", result.Error)
+	}
+	if calls := atomic.LoadInt32(&engine.calls); calls != 0 {
+		t.Errorf("Expected the engine not to run, ran %d times", calls)
+	}
+}
+
+// TestConverterService_ConvertStream_FileSizeLimit tests that oversized streams fail with a resource limit error
+func TestConverterService_ConvertStream_FileSizeLimit(t *testing.T) {
+	service, _ := newOutputTestService(t, newPrefixDocumentEngine(), WithResourceLimits(ResourceLimits{MaxFileSize: 3}))
+
+	var output bytes.Buffer
+	result := service.ConvertStream(context.Background(), bytes.NewReader([]byte("docx")), FileTypeDOCX, &output, FormatHTML, ConversionOptions{})
+	if ErrorCodeOf(result.Error) != ErrorCodeResourceLimit {
+		t.Fatalf("Expected a resource limit error, got %v", result.Error)
+	}
+}
+
+// TestConverterService_PassesLimitsToEngines tests that engines see the service's limits
+func TestConverterService_PassesLimitsToEngines(t *testing.T) {
+	limits := ResourceLimits{MaxImagePixels: 42}
+	var seen ResourceLimits
+	observe := func(ctx context.Context, call Call, next func(ctx context.Context) error) error {
+		seen = ResourceLimitsFromContext(ctx)
+		return next(ctx)
+	}
+	service, source := newOutputTestService(t, newPrefixDocumentEngine(), WithResourceLimits(limits), WithInterceptors(observe))
+
+	result := service.Convert(context.Background(), source, filepath.Join(filepath.Dir(source), "report.html"), ConversionOptions{})
+	if !result.Success {
+		t.Fatalf("Conversion failed: %v", result.Error)
+	}
+	if seen != limits {
+		t.Errorf("Expected engines to see %+v, got %+v", limits, seen)
+	}
+}
@@ -0,0 +1,247 @@
+package domain
+
+import "fmt"
+
+// ConversionOptions carries per-conversion settings to the engines.
+// The zero value selects every engine's defaults.
+type ConversionOptions struct {
+	Image  ImageOptions
+	Page   PageOptions
+	Sheet  SheetOptions
+	Output OutputOptions
+}
+
+// EngineSettings are configured defaults engines apply to conversions that
+// leave the matching option unset. The zero value keeps each engine's own.
+type EngineSettings struct {
+	// DefaultQuality is the JPEG quality of conversions that set none; 0
+	// uses the encoder's default
+	DefaultQuality int
+}
+
+// ImageOptions controls image encoding and transforms
+type ImageOptions struct {
+	// Quality is the JPEG encoding quality (1-100); 0 uses the encoder
+	// default. For WebP output it selects lossy encoding at that quality; 0
+	// encodes losslessly.
+	Quality int
+	// Width and Height bound the output size in pixels, preserving the aspect
+	// ratio. 0 leaves that dimension unconstrained.
+	Width  int
+	Height int
+	// Transforms are applied in order after decoding, before the Width and
+	// Height bound
+	Transforms []ImageTransform
+	// Subsampling is the chroma subsampling of JPEG output; empty uses 4:2:0
+	Subsampling ChromaSubsampling
+	// Compression is the PNG compression level; empty uses CompressionDefault
+	Compression CompressionLevel
+	// WebPExtended writes WebP output in the extended (VP8X) container
+	// instead of the simple one
+	WebPExtended bool
+	// Metadata is what output keeps of the input's EXIF, XMP and colour
+	// profile; empty uses MetadataStrip
+	Metadata MetadataPolicy
+	// IgnoreOrientation keeps the pixels as stored instead of turning them
+	// upright as the input's EXIF orientation says
+	IgnoreOrientation bool
+}
+
+// MetadataPolicy is what image output keeps of the input's metadata. Pixels
+// turned upright have their EXIF orientation reset, whatever is kept.
+type MetadataPolicy string
+
+const (
+	// MetadataStrip drops all metadata, including GPS positions and the
+	// colour profile
+	MetadataStrip MetadataPolicy = "strip"
+	// MetadataKeep copies the EXIF, XMP and colour profile
+	MetadataKeep MetadataPolicy = "keep"
+	// MetadataCopyright keeps only the EXIF copyright notice and the colour
+	// profile
+	MetadataCopyright MetadataPolicy = "copyright"
+)
+
+// ChromaSubsampling is the resolution of the colour channels of JPEG output
+// relative to its brightness channel. Less colour resolution gives smaller
+// files; 4:4:4 keeps sharp coloured edges such as text and line art.
+type ChromaSubsampling string
+
+const (
+	Subsampling444 ChromaSubsampling = "4:4:4"
+	Subsampling422 ChromaSubsampling = "4:2:2"
+	Subsampling420 ChromaSubsampling = "4:2:0"
+)
+
+// CompressionLevel trades PNG encoding time for file size. Every level is
+// lossless.
+type CompressionLevel string
+
+const (
+	CompressionDefault CompressionLevel = "default"
+	CompressionNone    CompressionLevel = "none"
+	CompressionFast    CompressionLevel = "fast"
+	CompressionBest    CompressionLevel = "best"
+)
+
+// PageOptions controls the layout of paginated (PDF) output
+type PageOptions struct {
+	// Size is the paper size; empty uses the browser default (Letter)
+	Size PageSize
+	// Orientation is portrait or landscape; empty means portrait
+	Orientation Orientation
+	// Margins overrides the browser's default margins when set
+	Margins *Margins
+}
+
+// SheetOptions controls which worksheets of a workbook are converted
+type SheetOptions struct {
+	// Sheets lists worksheet names to include; empty includes every sheet
+	Sheets []string
+}
+
+// OutputOptions controls how converted files are written
+type OutputOptions struct {
+	// Collision decides what happens when the output path already exists;
+	// empty uses the service default (see WithCollisionPolicy)
+	Collision CollisionPolicy
+}
+
+// PageSize identifies a standard paper size
+type PageSize string
+
+const (
+	PageSizeA3     PageSize = "A3"
+	PageSizeA4     PageSize = "A4"
+	PageSizeA5     PageSize = "A5"
+	PageSizeLetter PageSize = "Letter"
+	PageSizeLegal  PageSize = "Legal"
+)
+
+// pageSizeInches maps paper sizes to portrait width and height in inches
+var pageSizeInches = map[PageSize][2]float64{
+	PageSizeA3:     {11.69, 16.54},
+	PageSizeA4:     {8.27, 11.69},
+	PageSizeA5:     {5.83, 8.27},
+	PageSizeLetter: {8.5, 11},
+	PageSizeLegal:  {8.5, 14},
+}
+
+// Dimensions returns the portrait width and height of the paper size in inches
+func (p PageSize) Dimensions() (width, height float64, ok bool) {
+	size, ok := pageSizeInches[p]
+	return size[0], size[1], ok
+}
+
+// Orientation is the page orientation of paginated output
+type Orientation string
+
+const (
+	OrientationPortrait  Orientation = "portrait"
+	OrientationLandscape Orientation = "landscape"
+)
+
+// Margins are page margins in inches
+type Margins struct {
+	Top    float64
+	Right  float64
+	Bottom float64
+	Left   float64
+}
+
+// OptionsValidator is implemented by converters that consume ConversionOptions
+// and can reject settings that do not apply to a conversion step
+type OptionsValidator interface {
+	// ValidateOptions checks the options for a conversion producing the given format
+	ValidateOptions(opts ConversionOptions, format Format) error
+}
+
+// Validate checks that every option value is within its allowed range
+func (o ConversionOptions) Validate() error {
+	if o.Image.Quality < 0 || o.Image.Quality > 100 {
+		return fmt.Errorf("invalid quality %d: must be between 1 and 100", o.Image.Quality)
+	}
+	if o.Image.Width < 0 || o.Image.Height < 0 {
+		return fmt.Errorf("invalid resize %dx%d: dimensions must not be negative", o.Image.Width, o.Image.Height)
+	}
+	switch o.Image.Subsampling {
+	case "", Subsampling444, Subsampling422, Subsampling420:
+	default:
+		return fmt.Errorf("invalid chroma subsampling %q: use 4:4:4, 4:2:2 or 4:2:0", o.Image.Subsampling)
+	}
+	switch o.Image.Compression {
+	case "", CompressionDefault, CompressionNone, CompressionFast, CompressionBest:
+	default:
+		return fmt.Errorf("invalid compression level %q: use default, none, fast or best", o.Image.Compression)
+	}
+	switch o.Image.Metadata {
+	case "", MetadataStrip, MetadataKeep, MetadataCopyright:
+	default:
+		return fmt.Errorf("invalid metadata policy %q: use strip, keep or copyright", o.Image.Metadata)
+	}
+	for i, transform := range o.Image.Transforms {
+		if err := transform.Validate(); err != nil {
+			return fmt.Errorf("transform %d: %w", i+1, err)
+		}
+	}
+
+	if o.Page.Size != "" {
+		if _, _, ok := o.Page.Size.Dimensions(); !ok {
+			return fmt.Errorf("invalid page size: %s", o.Page.Size)
+		}
+	}
+	switch o.Page.Orientation {
+	case "", OrientationPortrait, OrientationLandscape:
+	default:
+		return fmt.Errorf("invalid orientation: %s", o.Page.Orientation)
+	}
+	if m := o.Page.Margins; m != nil && (m.Top < 0 || m.Right < 0 || m.Bottom < 0 || m.Left < 0) {
+		return fmt.Errorf("invalid margins: values must not be negative")
+	}
+
+	switch o.Output.Collision {
+	case "", CollisionOverwrite, CollisionSkip, CollisionSuffix, CollisionFail:
+	default:
+		return fmt.Errorf("invalid collision policy: %s", o.Output.Collision)
+	}
+
+	for _, sheet := range o.Sheet.Sheets {
+		if sheet == "" {
+			return fmt.Errorf("invalid sheet selection: sheet names must not be empty")
+		}
+	}
+
+	return nil
+}
+
+// PageOptionDescriptors describes the page layout options shared by every
+// engine that produces PDF output
+func PageOptionDescriptors() []OptionDescriptor {
+	descriptors := []OptionDescriptor{
+		{
+			Name:        "pageSize",
+			Type:        "enum",
+			Description: "Paper size",
+			Default:     string(PageSizeLetter),
+			Values:      []string{string(PageSizeA3), string(PageSizeA4), string(PageSizeA5), string(PageSizeLetter), string(PageSizeLegal)},
+			Formats:     []Format{FormatPDF},
+		},
+		{
+			Name:        "orientation",
+			Type:        "enum",
+			Description: "Page orientation",
+			Default:     string(OrientationPortrait),
+			Values:      []string{string(OrientationPortrait), string(OrientationLandscape)},
+			Formats:     []Format{FormatPDF},
+		},
+	}
+	for _, side := range []string{"Top", "Right", "Bottom", "Left"} {
+		descriptors = append(descriptors, OptionDescriptor{
+			Name:        "margin" + side,
+			Type:        "float",
+			Description: side + " page margin in inches",
+			Formats:     []Format{FormatPDF},
+		})
+	}
+	return descriptors
+}
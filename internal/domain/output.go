@@ -0,0 +1,128 @@
+package domain
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// CollisionPolicy decides what happens when a conversion's output path already exists
+type CollisionPolicy string
+
+const (
+	// CollisionOverwrite replaces the existing file once the conversion succeeds
+	CollisionOverwrite CollisionPolicy = "overwrite"
+	// CollisionSkip leaves the existing file untouched and reports the conversion as skipped
+	CollisionSkip CollisionPolicy = "skip"
+	// CollisionSuffix writes to the first free name of the form "name (1).ext"
+	CollisionSuffix CollisionPolicy = "suffix"
+	// CollisionFail fails the conversion with ErrOutputExists
+	CollisionFail CollisionPolicy = "fail"
+)
+
+// maxSuffix bounds the search for a free "name (n).ext" path
+const maxSuffix = 10000
+
+// outputReservations tracks output paths claimed by in-flight conversions so
+// concurrent conversions resolving the same name do not pick the same file
+type outputReservations struct {
+	mu    sync.Mutex
+	paths map[string]bool
+}
+
+// newOutputReservations creates an empty reservation set
+func newOutputReservations() *outputReservations {
+	return &outputReservations{paths: make(map[string]bool)}
+}
+
+// reserve applies the collision policy to target and claims the resulting path.
+// skip is set when the policy leaves an existing file in place; otherwise the
+// returned release function must be called once the output is committed or abandoned.
+func (r *outputReservations) reserve(target string, policy CollisionPolicy) (path string, skip bool, release func(), err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	taken := func(path string) bool {
+		if r.paths[path] {
+			return true
+		}
+		_, err := os.Lstat(path)
+		return err == nil
+	}
+
+	path = target
+	if taken(target) {
+		switch policy {
+		case CollisionSkip:
+			return target, true, func() {}, nil
+		case CollisionFail:
+			return "", false, nil, Errorf(ErrorCodeOutputExists, "output file already exists: %s", target)
+		case CollisionSuffix:
+			path, err = suffixedPath(target, taken)
+			if err != nil {
+				return "", false, nil, err
+			}
+		}
+		// CollisionOverwrite keeps the target; the commit replaces it
+	}
+
+	r.paths[path] = true
+	return path, false, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(r.paths, path)
+	}, nil
+}
+
+// suffixedPath returns the first "name (n).ext" variant of target that is not taken
+func suffixedPath(target string, taken func(string) bool) (string, error) {
+	ext := filepath.Ext(target)
+	base := strings.TrimSuffix(target, ext)
+	for n := 1; n <= maxSuffix; n++ {
+		candidate := fmt.Sprintf("%s (%d)%s", base, n, ext)
+		if !taken(candidate) {
+			return candidate, nil
+		}
+	}
+	return "", Errorf(ErrorCodeOutputExists, "no free output name for %s", target)
+}
+
+// writeAtomically produces path by letting write fill a temporary file in the
+// same directory and renaming it into place, so readers never observe a
+// partial file and a failed conversion leaves any existing file untouched.
+// The temporary file keeps path's extension because engines choose the
+// output format from it.
+func writeAtomically(path string, write func(tempPath string) error) error {
+	dir, name := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+	ext := filepath.Ext(name)
+	temp, err := os.CreateTemp(dir, "."+strings.TrimSuffix(name, ext)+".*.partial"+ext)
+	if err != nil {
+		return NewError(ErrorCodeOutputNotWritable, err)
+	}
+	tempPath := temp.Name()
+	if err := temp.Close(); err != nil {
+		os.Remove(tempPath)
+		return NewError(ErrorCodeOutputNotWritable, err)
+	}
+
+	if err := write(tempPath); err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+
+	// CreateTemp uses 0600; outputs are shared like any user document
+	if err := os.Chmod(tempPath, 0644); err != nil {
+		os.Remove(tempPath)
+		return NewError(ErrorCodeOutputNotWritable, err)
+	}
+	if err := os.Rename(tempPath, path); err != nil {
+		os.Remove(tempPath)
+		return NewError(ErrorCodeOutputNotWritable, err)
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// failingEngine writes partial output and then fails
+type failingEngine struct {
+	stubEngine
+}
+
+func (e *failingEngine) Convert(ctx context.Context, input, output string, opts ConversionOptions) error {
+	if err := os.WriteFile(output, []byte("partial"), 0644); err != nil {
+		return err
+	}
+	return errors.New("engine crashed")
+}
+
+// newOutputTestService creates a service converting DOCX to HTML with engine,
+// and a source document in a fresh directory
+func newOutputTestService(t *testing.T, engine IConverter, opts ...ServiceOption) (*ConverterService, string) {
+	t.Helper()
+	dir := t.TempDir()
+	source := filepath.Join(dir, "report.docx")
+	if err := os.WriteFile(source, []byte("docx"), 0644); err != nil {
+		t.Fatalf("Failed to create source: %v", err)
+	}
+	service := NewConverterService(map[FileType]IConverter{FileTypeDOCX: engine}, nopLogger{}, nopNotifier{}, existsWriter{}, opts...)
+	return service, source
+}
+
+// newPrefixDocumentEngine returns an engine converting DOCX to HTML by prefixing "html:"
+func newPrefixDocumentEngine() *prefixEngine {
+	return &prefixEngine{
+		stubEngine: stubEngine{name: "document", edges: []ConversionEdge{
+			{From: FileTypeDOCX, To: FormatHTML, Cost: EdgeCostInProcess},
+		}},
+		prefix: "html:",
+	}
+}
+
+// readOutput returns the content of a file, failing the test when it cannot be read
+func readOutput(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+// TestConverterService_Convert_CollisionPolicies tests each collision policy against an existing output
+func TestConverterService_Convert_CollisionPolicies(t *testing.T) {
+	tests := []struct {
+		policy      CollisionPolicy
+		wantPath    string
+		wantContent string
+		wantSkipped bool
+		wantErr     error
+	}{
+		{policy: CollisionOverwrite, wantPath: "report.html", wantContent: "html:docx"},
+		{policy: CollisionSkip, wantPath: "report.html", wantContent: "existing", wantSkipped: true},
+		{policy: CollisionSuffix, wantPath: "report (1).html", wantContent: "html:docx"},
+		{policy: CollisionFail, wantErr: ErrOutputExists},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			service, source := newOutputTestService(t, newPrefixDocumentEngine())
+			dir := filepath.Dir(source)
+			target := filepath.Join(dir, "report.html")
+			if err := os.WriteFile(target, []byte("existing"), 0644); err != nil {
+				t.Fatalf("Failed to create existing output: %v", err)
+			}
+
+			opts := ConversionOptions{Output: OutputOptions{Collision: tt.policy}}
+			result := service.Convert(context.Background(), source, target, opts)
+			if tt.wantErr != nil {
+				if !errors.Is(result.Error, tt.wantErr) {
+					t.Fatalf("Expected %v, got %v", tt.wantErr, result.Error)
+				}
+				if got := readOutput(t, target); got != "existing" {
+					t.Errorf("Expected the existing output to be kept, got %q", got)
+				}
+				return
+			}
+
+			if !result.Success {
+				t.Fatalf("Conversion failed: %v", result.Error)
+			}
+			if want := filepath.Join(dir, tt.wantPath); result.OutputPath != want {
+				t.Errorf("Expected output %s, got %s", want, result.OutputPath)
+			}
+			if result.Skipped != tt.wantSkipped {
+				t.Errorf("Expected Skipped=%v, got %v", tt.wantSkipped, result.Skipped)
+			}
+			if got := readOutput(t, result.OutputPath); got != tt.wantContent {
+				t.Errorf("Expected output content %q, got %q", tt.wantContent, got)
+			}
+			if tt.policy == CollisionSuffix {
+				if got := readOutput(t, target); got != "existing" {
+					t.Errorf("Expected the existing output to be kept, got %q", got)
+				}
+			}
+		})
+	}
+}
+
+// TestConverterService_Convert_SuffixIncrements tests that repeated conversions pick the next free suffix
+func TestConverterService_Convert_SuffixIncrements(t *testing.T) {
+	service, source := newOutputTestService(t, newPrefixDocumentEngine(), WithCollisionPolicy(CollisionSuffix))
+	dir := filepath.Dir(source)
+	target := filepath.Join(dir, "report.html")
+
+	for _, want := range []string{"report.html", "report (1).html", "report (2).html"} {
+		result := service.Convert(context.Background(), source, target, ConversionOptions{})
+		if !result.Success {
+			t.Fatalf("Conversion failed: %v", result.Error)
+		}
+		if result.OutputPath != filepath.Join(dir, want) {
+			t.Errorf("Expected output %s, got %s", want, filepath.Base(result.OutputPath))
+		}
+	}
+}
+
+// TestConverterService_Convert_AtomicWrite tests that a failed conversion neither
+// replaces an existing output nor leaves partial files behind
+func TestConverterService_Convert_AtomicWrite(t *testing.T) {
+	engine := &failingEngine{stubEngine: stubEngine{name: "document", edges: []ConversionEdge{
+		{From: FileTypeDOCX, To: FormatHTML, Cost: EdgeCostInProcess},
+	}}}
+	service, source := newOutputTestService(t, engine)
+	dir := filepath.Dir(source)
+	target := filepath.Join(dir, "report.html")
+	if err := os.WriteFile(target, []byte("existing"), 0644); err != nil {
+		t.Fatalf("Failed to create existing output: %v", err)
+	}
+
+	result := service.Convert(context.Background(), source, target, ConversionOptions{})
+	if result.Success {
+		t.Fatal("Expected the conversion to fail")
+	}
+	if got := readOutput(t, target); got != "existing" {
+		t.Errorf("Expected the existing output to survive a failed conversion, got %q", got)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to list output directory: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("Expected only the source and existing output, found %d entries", len(entries))
+	}
+}
+
+// TestConverterService_ConvertStreamToFile tests stream conversions into a file with a collision policy
+func TestConverterService_ConvertStreamToFile(t *testing.T) {
+	engine := &prefixStreamEngine{prefixEngine: *newPrefixDocumentEngine()}
+	service, source := newOutputTestService(t, engine)
+	dir := filepath.Dir(source)
+	target := filepath.Join(dir, "report.html")
+	if err := os.WriteFile(target, []byte("existing"), 0644); err != nil {
+		t.Fatalf("Failed to create existing output: %v", err)
+	}
+
+	input, err := os.Open(source)
+	if err != nil {
+		t.Fatalf("Failed to open source: %v", err)
+	}
+	defer input.Close()
+
+	opts := ConversionOptions{Output: OutputOptions{Collision: CollisionSuffix}}
+	result := service.ConvertStreamToFile(context.Background(), input, FileTypeDOCX, target, opts)
+	if !result.Success {
+		t.Fatalf("Conversion failed: %v", result.Error)
+	}
+	if want := filepath.Join(dir, "report (1).html"); result.OutputPath != want {
+		t.Errorf("Expected output %s, got %s", want, result.OutputPath)
+	}
+	if got := readOutput(t, result.OutputPath); got != "html:docx" {
+		t.Errorf("Expected converted content, got %q", got)
+	}
+	info, err := os.Stat(result.OutputPath)
+	if err != nil {
+		t.Fatalf("Failed to stat output: %v", err)
+	}
+	if info.Mode().Perm() != 0644 {
+		t.Errorf("Expected output permissions 0644, got %v", info.Mode().Perm())
+	}
+}
@@ -0,0 +1,322 @@
+package domain
+
+import (
+	"context"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// EngineRef identifies an engine of a conversion route
+type EngineRef struct {
+	Name    string
+	Version string
+}
+
+// ContentCounts describe how much content a conversion handled. Zero means
+// the count does not apply or was not reported.
+type ContentCounts struct {
+	// Pages is the number of pages in PDF output
+	Pages int
+	// Sheets is the number of worksheets rendered
+	Sheets int
+	// Images is the number of images in the input
+	Images int
+}
+
+// EncoderSettings are the settings an image output was encoded with, with
+// the defaults resolved, so the output can be reproduced. Only the fields of
+// Format apply.
+type EncoderSettings struct {
+	Format Format
+	// Quality is the JPEG quality, or the quality of lossy WebP output
+	Quality int
+	// Subsampling is the JPEG chroma subsampling
+	Subsampling ChromaSubsampling
+	// Compression is the PNG compression level
+	Compression CompressionLevel
+	// Extended is set for WebP output in the extended container
+	Extended bool
+}
+
+// StageTiming is the time an engine spent in one stage of a conversion,
+// such as parsing, rendering or printing to PDF
+type StageTiming struct {
+	Engine   string
+	Stage    string
+	Duration time.Duration
+}
+
+// FidelityCode is a stable identifier for content an engine could not
+// carry over to the output
+type FidelityCode string
+
+const (
+	FidelityImagesDropped         FidelityCode = "images_dropped"
+	FidelityChartsDropped         FidelityCode = "charts_dropped"
+	FidelityCommentsDropped       FidelityCode = "comments_dropped"
+	FidelityNotesDropped          FidelityCode = "notes_dropped"
+	FidelityHeadersFootersDropped FidelityCode = "headers_footers_dropped"
+	FidelityConditionalFormatting FidelityCode = "conditional_formatting_ignored"
+	FidelityTransparencyFlattened FidelityCode = "transparency_flattened"
+)
+
+// FidelityWarning reports content an engine skipped or approximated
+type FidelityWarning struct {
+	Engine  string
+	Code    FidelityCode
+	Message string
+}
+
+// Stage names reported by the built-in engines
+const (
+	StageDecode     = "decode"
+	StageTransform  = "transform"
+	StageEncode     = "encode"
+	StageParse      = "parse"
+	StageRender     = "render"
+	StagePDF        = "pdf"
+	StageScreenshot = "screenshot"
+)
+
+// RecordStage reports the time the current engine spent in a stage. Engines
+// call it with the context they were given; outside a conversion it does nothing.
+func RecordStage(ctx context.Context, stage string, elapsed time.Duration) {
+	timing := StageTiming{Engine: engineNameFrom(ctx), Stage: stage, Duration: elapsed}
+	if log := conversionLogFrom(ctx); log != nil {
+		log.recordStage(timing)
+	}
+	if observe, ok := ctx.Value(stageObserverKey{}).(func(StageTiming)); ok {
+		observe(timing)
+	}
+}
+
+// WithStageObserver returns a context whose conversions call observe as each
+// stage finishes, so a caller can report progress while a conversion runs.
+// observe may be called from several goroutines.
+func WithStageObserver(ctx context.Context, observe func(StageTiming)) context.Context {
+	return context.WithValue(ctx, stageObserverKey{}, observe)
+}
+
+// StartStage starts timing a stage and returns the function that records it
+func StartStage(ctx context.Context, stage string) func() {
+	start := time.Now()
+	return func() {
+		RecordStage(ctx, stage, time.Since(start))
+	}
+}
+
+// RecordCounts reports content counts; non-zero fields replace earlier reports
+func RecordCounts(ctx context.Context, counts ContentCounts) {
+	if log := conversionLogFrom(ctx); log != nil {
+		log.mu.Lock()
+		defer log.mu.Unlock()
+		if counts.Pages != 0 {
+			log.counts.Pages = counts.Pages
+		}
+		if counts.Sheets != 0 {
+			log.counts.Sheets = counts.Sheets
+		}
+		if counts.Images != 0 {
+			log.counts.Images = counts.Images
+		}
+	}
+}
+
+// RecordEncoderSettings reports the settings an image output was encoded
+// with, replacing earlier reports
+func RecordEncoderSettings(ctx context.Context, settings EncoderSettings) {
+	if log := conversionLogFrom(ctx); log != nil {
+		log.mu.Lock()
+		defer log.mu.Unlock()
+		log.encoder = &settings
+	}
+}
+
+// RecordFidelityWarning reports content the current engine skipped or
+// approximated. Each code is reported once per engine.
+func RecordFidelityWarning(ctx context.Context, code FidelityCode, message string) {
+	if log := conversionLogFrom(ctx); log != nil {
+		engine := engineNameFrom(ctx)
+		log.mu.Lock()
+		defer log.mu.Unlock()
+		for _, warning := range log.fidelity {
+			if warning.Engine == engine && warning.Code == code {
+				return
+			}
+		}
+		log.fidelity = append(log.fidelity, FidelityWarning{Engine: engine, Code: code, Message: message})
+	}
+}
+
+// conversionLog collects what happens during one conversion: the engines on
+// its route, their attempts and what they report
+type conversionLog struct {
+	mu        sync.Mutex
+	inputType FileType
+	engines   []EngineRef
+	attempts  []Attempt
+	stages    []StageTiming
+	counts    ContentCounts
+	fidelity  []FidelityWarning
+	encoder   *EncoderSettings
+}
+
+type conversionLogKey struct{}
+
+type stageObserverKey struct{}
+
+type engineNameKey struct{}
+
+// withConversionLog returns a context carrying a conversion log, reusing the
+// one already carried by ctx so nested conversions share it
+func withConversionLog(ctx context.Context) (context.Context, *conversionLog) {
+	if log := conversionLogFrom(ctx); log != nil {
+		return ctx, log
+	}
+	log := &conversionLog{}
+	return context.WithValue(ctx, conversionLogKey{}, log), log
+}
+
+// conversionLogFrom returns the conversion log carried by ctx, or nil
+func conversionLogFrom(ctx context.Context) *conversionLog {
+	log, _ := ctx.Value(conversionLogKey{}).(*conversionLog)
+	return log
+}
+
+// withEngineName returns a context attributing reports to the named engine
+func withEngineName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, engineNameKey{}, name)
+}
+
+// engineNameFrom returns the engine a report made with ctx is attributed to
+func engineNameFrom(ctx context.Context) string {
+	name, _ := ctx.Value(engineNameKey{}).(string)
+	return name
+}
+
+// setRoute records the input type and engines of the planned route
+func (l *conversionLog) setRoute(inputType FileType, route Route) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inputType = inputType
+	l.engines = l.engines[:0]
+	for _, step := range route.Steps {
+		capabilities := step.Engine.Capabilities()
+		l.engines = append(l.engines, EngineRef{Name: capabilities.Name, Version: capabilities.Version})
+	}
+}
+
+// recordStage adds a stage timing; a retried stage replaces the timing of
+// the failed attempt
+func (l *conversionLog) recordStage(timing StageTiming) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, existing := range l.stages {
+		if existing.Engine == timing.Engine && existing.Stage == timing.Stage {
+			l.stages[i].Duration = timing.Duration
+			return
+		}
+	}
+	l.stages = append(l.stages, timing)
+}
+
+// apply copies the collected data into result
+func (l *conversionLog) apply(result *Result) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if result.InputType == "" {
+		result.InputType = l.inputType
+	}
+	result.Engines = copyOrNil(l.engines)
+	result.Attempts = copyOrNil(l.attempts)
+	result.Stages = copyOrNil(l.stages)
+	result.Counts = l.counts
+	result.Fidelity = copyOrNil(l.fidelity)
+	if l.encoder != nil {
+		encoder := *l.encoder
+		result.Encoder = &encoder
+	}
+}
+
+// cachedReport is the part of a conversion report stored with a cached
+// output, so a cache hit reports what the conversion that produced it did
+type cachedReport struct {
+	Engines  []EngineRef       `json:"engines,omitempty"`
+	Stages   []StageTiming     `json:"stages,omitempty"`
+	Counts   ContentCounts     `json:"counts"`
+	Fidelity []FidelityWarning `json:"fidelity,omitempty"`
+	Encoder  *EncoderSettings  `json:"encoder,omitempty"`
+}
+
+// snapshot returns the report to store with the cached output
+func (l *conversionLog) snapshot() cachedReport {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	report := cachedReport{
+		Engines:  copyOrNil(l.engines),
+		Stages:   copyOrNil(l.stages),
+		Counts:   l.counts,
+		Fidelity: copyOrNil(l.fidelity),
+	}
+	if l.encoder != nil {
+		encoder := *l.encoder
+		report.Encoder = &encoder
+	}
+	return report
+}
+
+// restore replaces the collected data with a report read from the cache
+func (l *conversionLog) restore(report cachedReport) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(report.Engines) > 0 {
+		l.engines = report.Engines
+	}
+	l.stages = report.Stages
+	l.counts = report.Counts
+	l.fidelity = report.Fidelity
+	l.encoder = report.Encoder
+}
+
+// copyOrNil returns a copy of items, or nil when there are none
+func copyOrNil[T any](items []T) []T {
+	if len(items) == 0 {
+		return nil
+	}
+	return append([]T(nil), items...)
+}
+
+// fileSize returns the size of the file at path, or 0 when it cannot be read
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
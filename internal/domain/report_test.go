@@ -0,0 +1,129 @@
+package domain
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// reportingEngine is a prefixStreamEngine that reports a stage, counts, a
+// fidelity warning and encoder settings on every call
+type reportingEngine struct {
+	prefixStreamEngine
+}
+
+func (e *reportingEngine) report(ctx context.Context) {
+	RecordStage(ctx, StageRender, time.Millisecond)
+	RecordCounts(ctx, ContentCounts{Images: 2})
+	RecordFidelityWarning(ctx, FidelityImagesDropped, "2 images were dropped")
+	RecordFidelityWarning(ctx, FidelityImagesDropped, "reported twice")
+	RecordEncoderSettings(ctx, EncoderSettings{Format: FormatJPEG, Quality: 90})
+	RecordEncoderSettings(ctx, EncoderSettings{Format: FormatJPEG, Quality: 80, Subsampling: Subsampling444})
+}
+
+func (e *reportingEngine) Convert(ctx context.Context, input, output string, opts ConversionOptions) error {
+	e.report(ctx)
+	return e.prefixStreamEngine.Convert(ctx, input, output, opts)
+}
+
+func (e *reportingEngine) ConvertStream(ctx context.Context, input io.Reader, output io.Writer, format Format, opts ConversionOptions) error {
+	e.report(ctx)
+	return e.prefixStreamEngine.ConvertStream(ctx, input, output, format, opts)
+}
+
+// checkReport fails the test when result lacks what reportingEngine reported
+func checkReport(t *testing.T, result Result) {
+	t.Helper()
+	if result.InputType != FileTypeDOCX {
+		t.Errorf("Expected input type %s, got %q", FileTypeDOCX, result.InputType)
+	}
+	if result.InputSize != int64(len("docx")) || result.OutputSize != int64(len("html:docx")) {
+		t.Errorf("Expected sizes 4 -> 9, got %d -> %d", result.InputSize, result.OutputSize)
+	}
+	if len(result.Engines) != 1 || result.Engines[0].Name != "document" {
+		t.Errorf("Expected the document engine on the route, got %+v", result.Engines)
+	}
+	if len(result.Stages) != 1 || result.Stages[0] != (StageTiming{Engine: "document", Stage: StageRender, Duration: time.Millisecond}) {
+		t.Errorf("Expected one render stage attributed to the engine, got %+v", result.Stages)
+	}
+	if result.Counts.Images != 2 {
+		t.Errorf("Expected 2 images, got %+v", result.Counts)
+	}
+	if len(result.Fidelity) != 1 || result.Fidelity[0].Code != FidelityImagesDropped || result.Fidelity[0].Engine != "document" {
+		t.Errorf("Expected one images_dropped warning, got %+v", result.Fidelity)
+	}
+	if want := (EncoderSettings{Format: FormatJPEG, Quality: 80, Subsampling: Subsampling444}); result.Encoder == nil || *result.Encoder != want {
+		t.Errorf("Expected the last encoder settings reported, got %+v", result.Encoder)
+	}
+}
+
+// newReportingEngine returns a reportingEngine converting DOCX to HTML
+func newReportingEngine() *reportingEngine {
+	return &reportingEngine{prefixStreamEngine: prefixStreamEngine{prefixEngine: *newPrefixDocumentEngine()}}
+}
+
+// TestConverterService_Convert_Report tests that file conversions report sizes, engines, stages and fidelity warnings
+func TestConverterService_Convert_Report(t *testing.T) {
+	service, source := newOutputTestService(t, newReportingEngine())
+
+	result := service.Convert(context.Background(), source, filepath.Join(filepath.Dir(source), "report.html"), ConversionOptions{})
+	if !result.Success {
+		t.Fatalf("Conversion failed: %v", result.Error)
+	}
+	checkReport(t, result)
+}
+
+// TestConverterService_ConvertStream_Report tests that stream conversions report the same metadata
+func TestConverterService_ConvertStream_Report(t *testing.T) {
+	service, _ := newOutputTestService(t, newReportingEngine())
+
+	var output bytes.Buffer
+	result := service.ConvertStream(context.Background(), bytes.NewReader([]byte("docx")), FileTypeDOCX, &output, FormatHTML, ConversionOptions{})
+	if !result.Success {
+		t.Fatalf("Conversion failed: %v", result.Error)
+	}
+	checkReport(t, result)
+}
+
+// TestConverterService_Report_CacheHit tests that cache hits report what the
+// conversion that produced the cached output reported
+func TestConverterService_Report_CacheHit(t *testing.T) {
+	cache := &memoryCache{entries: make(map[string][]byte)}
+	service, source := newOutputTestService(t, newReportingEngine(), WithCache(cache))
+	dir := filepath.Dir(source)
+
+	if result := service.Convert(context.Background(), source, filepath.Join(dir, "first.html"), ConversionOptions{}); !result.Success {
+		t.Fatalf("Conversion failed: %v", result.Error)
+	}
+	result := service.Convert(context.Background(), source, filepath.Join(dir, "second.html"), ConversionOptions{})
+	if !result.Success || !result.Cached {
+		t.Fatalf("Expected a cached conversion, got success=%v cached=%v err=%v", result.Success, result.Cached, result.Error)
+	}
+	checkReport(t, result)
+
+	result = service.ConvertStreamToFile(context.Background(), bytes.NewReader([]byte("docx")), FileTypeDOCX, filepath.Join(dir, "stream.html"), ConversionOptions{})
+	if !result.Success || !result.Cached {
+		t.Fatalf("Expected a cached stream conversion, got success=%v cached=%v err=%v", result.Success, result.Cached, result.Error)
+	}
+	checkReport(t, result)
+}
+
+// TestWithStageObserver tests that stages are observed as the engine records them
+func TestWithStageObserver(t *testing.T) {
+	service, source := newOutputTestService(t, newReportingEngine())
+
+	var observed []StageTiming
+	ctx := WithStageObserver(context.Background(), func(timing StageTiming) {
+		observed = append(observed, timing)
+	})
+	result := service.Convert(ctx, source, filepath.Join(filepath.Dir(source), "observed.html"), ConversionOptions{})
+	if !result.Success {
+		t.Fatalf("Conversion failed: %v", result.Error)
+	}
+	if len(observed) != 1 || observed[0].Engine != "document" || observed[0].Stage != StageRender {
+		t.Errorf("Expected the render stage to be observed, got %+v", observed)
+	}
+}
@@ -8,6 +8,33 @@ type Result struct {
 	OutputPath string
 	Error      error
 	Duration   time.Duration
+	// Warnings lists non-fatal issues noticed during conversion
+	Warnings []string
+	// Skipped is set when the output already existed and the collision
+	// policy left it untouched; OutputPath is the existing file
+	Skipped bool
+	// Cached is set when the output was served from the conversion cache
+	// instead of running the engines
+	Cached bool
+	// Attempts lists every engine call made, in order, including failed
+	// attempts that were retried
+	Attempts []Attempt
+	// InputType is the detected type of the input
+	InputType FileType
+	// InputSize and OutputSize are in bytes; zero when unknown
+	InputSize  int64
+	OutputSize int64
+	// Engines are the engines of the conversion route, in order
+	Engines []EngineRef
+	// Counts describe the converted content, as reported by the engines
+	Counts ContentCounts
+	// Stages are the engines' timings of their conversion stages
+	Stages []StageTiming
+	// Fidelity lists content the engines skipped or approximated
+	Fidelity []FidelityWarning
+	// Encoder holds the settings the image output was encoded with; nil when
+	// the engines encoded no image
+	Encoder *EncoderSettings
 }
 
 // ValidationResult represents the result of file validation
@@ -16,4 +43,3 @@ type ValidationResult struct {
 	Message string
 	Error   error
 }
-
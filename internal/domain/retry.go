@@ -0,0 +1,178 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	// DefaultBrowserTimeout bounds each attempt of a step that drives the headless browser
+	DefaultBrowserTimeout = 2 * time.Minute
+	// DefaultEngineTimeout bounds each attempt of an in-process step
+	DefaultEngineTimeout = 10 * time.Minute
+)
+
+// RetryPolicy decides how often engine calls failing with a transient error
+// are attempted. Only failures whose error code is retryable are retried,
+// and never once the conversion's own context is done.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts including the first; values
+	// below 2 disable retries
+	MaxAttempts int
+	// InitialBackoff is the wait before the second attempt
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between attempts
+	MaxBackoff time.Duration
+	// Multiplier grows the wait after each attempt; values below 1 keep it constant
+	Multiplier float64
+}
+
+// DefaultRetryPolicy retries transient failures twice with exponential backoff
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 250 * time.Millisecond,
+	MaxBackoff:     2 * time.Second,
+	Multiplier:     2,
+}
+
+// Attempt records one engine call made during a conversion
+type Attempt struct {
+	Operation Operation
+	Engine    string
+	// Edge is the conversion step; it is zero for validation
+	Edge ConversionEdge
+	// Number counts the attempts of this call, starting at 1
+	Number   int
+	Duration time.Duration
+	// Err is nil when the attempt succeeded
+	Err error
+}
+
+// WithRetryPolicy replaces DefaultRetryPolicy for engine calls
+func WithRetryPolicy(policy RetryPolicy) ServiceOption {
+	return func(s *ConverterService) {
+		s.retryPolicy = policy
+	}
+}
+
+// WithEngineTimeout bounds each attempt of the named engine. A zero timeout
+// disables the bound. By default steps rendering in the headless browser get
+// DefaultBrowserTimeout and in-process steps DefaultEngineTimeout.
+func WithEngineTimeout(name string, timeout time.Duration) ServiceOption {
+	return func(s *ConverterService) {
+		if timeout >= 0 {
+			s.engineTimeouts[name] = timeout
+		}
+	}
+}
+
+// WithDefaultTimeouts replaces DefaultBrowserTimeout and DefaultEngineTimeout
+// for the engines WithEngineTimeout does not name. A zero timeout disables
+// the bound.
+func WithDefaultTimeouts(browser, inProcess time.Duration) ServiceOption {
+	return func(s *ConverterService) {
+		if browser >= 0 {
+			s.browserTimeout = browser
+		}
+		if inProcess >= 0 {
+			s.inProcessTimeout = inProcess
+		}
+	}
+}
+
+// attemptTimeout picks the bound of each attempt of call: the engine's own
+// timeout when one is set, otherwise the default for the cost of the step
+func (s *ConverterService) attemptTimeout(call Call) time.Duration {
+	if timeout, ok := s.engineTimeouts[call.Engine]; ok {
+		return timeout
+	}
+	if call.Edge.Cost >= EdgeCostBrowser {
+		return s.browserTimeout
+	}
+	return s.inProcessTimeout
+}
+
+// backoff returns the wait after the given failed attempt
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	wait := p.InitialBackoff
+	for i := 1; i < attempt; i++ {
+		if p.Multiplier > 1 {
+			wait = time.Duration(float64(wait) * p.Multiplier)
+		}
+		if p.MaxBackoff > 0 && wait >= p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+	if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+	return wait
+}
+
+// shouldRetry reports whether a failed attempt is worth repeating
+func (p RetryPolicy) shouldRetry(ctx context.Context, attempt int, err error) bool {
+	if attempt >= p.MaxAttempts || ctx.Err() != nil {
+		return false
+	}
+	return IsRetryable(err)
+}
+
+// retryInterceptor repeats calls failing with a transient error and records
+// every attempt in the conversion's attempt log
+func retryInterceptor(policy RetryPolicy) Interceptor {
+	return func(ctx context.Context, call Call, next func(ctx context.Context) error) error {
+		for attempt := 1; ; attempt++ {
+			start := time.Now()
+			err := next(ctx)
+			recordAttempt(ctx, Attempt{
+				Operation: call.Operation,
+				Engine:    call.Engine,
+				Edge:      call.Edge,
+				Number:    attempt,
+				Duration:  time.Since(start),
+				Err:       err,
+			})
+			if err == nil || !policy.shouldRetry(ctx, attempt, err) {
+				return err
+			}
+
+			timer := time.NewTimer(policy.backoff(attempt))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+		}
+	}
+}
+
+// timeoutInterceptor bounds each attempt with the timeout of its call
+func timeoutInterceptor(timeoutFor func(call Call) time.Duration) Interceptor {
+	return func(ctx context.Context, call Call, next func(ctx context.Context) error) error {
+		timeout := timeoutFor(call)
+		if timeout <= 0 {
+			return next(ctx)
+		}
+		attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		err := next(attemptCtx)
+		// Only the attempt deadline is reported here; the caller's own
+		// cancellation or deadline passes through unchanged
+		if err != nil && ctx.Err() == nil && errors.Is(attemptCtx.Err(), context.DeadlineExceeded) {
+			return Errorf(ErrorCodeTimeout, "%s engine timed out after %v: %w", call.Engine, timeout, err)
+		}
+		return err
+	}
+}
+
+// recordAttempt adds an attempt to the conversion log carried by ctx, if any
+func recordAttempt(ctx context.Context, attempt Attempt) {
+	if log := conversionLogFrom(ctx); log != nil {
+		log.mu.Lock()
+		defer log.mu.Unlock()
+		log.attempts = append(log.attempts, attempt)
+	}
+}
@@ -0,0 +1,235 @@
+package domain
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyEngine fails its first failures conversions with err before converting
+type flakyEngine struct {
+	prefixStreamEngine
+	failures int32
+	err      error
+	calls    int32
+}
+
+func (e *flakyEngine) Convert(ctx context.Context, input, output string, opts ConversionOptions) error {
+	if atomic.AddInt32(&e.calls, 1) <= e.failures {
+		return e.err
+	}
+	return e.prefixStreamEngine.Convert(ctx, input, output, opts)
+}
+
+func (e *flakyEngine) ConvertStream(ctx context.Context, input io.Reader, output io.Writer, format Format, opts ConversionOptions) error {
+	// Consume part of the input and write partial output before failing, as a
+	// crashing engine would
+	if atomic.AddInt32(&e.calls, 1) <= e.failures {
+		buf := make([]byte, 2)
+		input.Read(buf)
+		output.Write([]byte("partial"))
+		return e.err
+	}
+	return e.prefixStreamEngine.ConvertStream(ctx, input, output, format, opts)
+}
+
+// hangingEngine blocks every conversion until its context is done
+type hangingEngine struct {
+	stubEngine
+}
+
+func (e *hangingEngine) Convert(ctx context.Context, input, output string, opts ConversionOptions) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// newFlakyEngine returns a DOCX to HTML engine failing failures times with err
+func newFlakyEngine(failures int32, err error) *flakyEngine {
+	return &flakyEngine{
+		prefixStreamEngine: prefixStreamEngine{prefixEngine: *newPrefixDocumentEngine()},
+		failures:           failures,
+		err:                err,
+	}
+}
+
+// fastRetries retries up to attempts times without a noticeable backoff
+func fastRetries(attempts int) ServiceOption {
+	return WithRetryPolicy(RetryPolicy{MaxAttempts: attempts, InitialBackoff: time.Millisecond})
+}
+
+// TestConverterService_RetriesTransientFailures tests that transient engine failures are retried and recorded
+func TestConverterService_RetriesTransientFailures(t *testing.T) {
+	engine := newFlakyEngine(2, Errorf(ErrorCodeEngineUnavailable, "browser target crashed"))
+	service, source := newOutputTestService(t, engine, fastRetries(3))
+
+	result := service.Convert(context.Background(), source, filepath.Join(filepath.Dir(source), "report.html"), ConversionOptions{})
+	if !result.Success {
+		t.Fatalf("Conversion failed: %v", result.Error)
+	}
+	if got := readOutput(t, result.OutputPath); got != "html:docx" {
+		t.Errorf("Expected the successful attempt's output, got %q", got)
+	}
+
+	var converts []Attempt
+	for _, attempt := range result.Attempts {
+		if attempt.Operation == OperationConvert {
+			converts = append(converts, attempt)
+		}
+	}
+	if len(converts) != 3 {
+		t.Fatalf("Expected 3 conversion attempts, got %d", len(converts))
+	}
+	for i, attempt := range converts {
+		if attempt.Number != i+1 || attempt.Engine != "document" {
+			t.Errorf("Unexpected attempt %d: %+v", i, attempt)
+		}
+		if failed := attempt.Err != nil; failed != (i < 2) {
+			t.Errorf("Attempt %d: unexpected error %v", i+1, attempt.Err)
+		}
+	}
+}
+
+// TestConverterService_GivesUpAfterMaxAttempts tests that retries stop at the policy's limit
+func TestConverterService_GivesUpAfterMaxAttempts(t *testing.T) {
+	engine := newFlakyEngine(5, Errorf(ErrorCodeEngineUnavailable, "browser disconnected"))
+	service, source := newOutputTestService(t, engine, fastRetries(2))
+
+	result := service.Convert(context.Background(), source, filepath.Join(filepath.Dir(source), "report.html"), ConversionOptions{})
+	if !errors.Is(result.Error, ErrEngineUnavailable) {
+		t.Fatalf("Expected ErrEngineUnavailable, got %v", result.Error)
+	}
+	if calls := atomic.LoadInt32(&engine.calls); calls != 2 {
+		t.Errorf("Expected 2 attempts, got %d", calls)
+	}
+}
+
+// TestConverterService_DoesNotRetryPermanentFailures tests that non-retryable failures run once
+func TestConverterService_DoesNotRetryPermanentFailures(t *testing.T) {
+	engine := newFlakyEngine(1, Errorf(ErrorCodeCorruptInput, "broken document"))
+	service, source := newOutputTestService(t, engine, fastRetries(3))
+
+	result := service.Convert(context.Background(), source, filepath.Join(filepath.Dir(source), "report.html"), ConversionOptions{})
+	if !errors.Is(result.Error, ErrCorruptInput) {
+		t.Fatalf("Expected ErrCorruptInput, got %v", result.Error)
+	}
+	if calls := atomic.LoadInt32(&engine.calls); calls != 1 {
+		t.Errorf("Expected a single attempt, got %d", calls)
+	}
+}
+
+// TestConverterService_RetriesStreamConversions tests that a retried stream step replays its whole input
+func TestConverterService_RetriesStreamConversions(t *testing.T) {
+	engine := newFlakyEngine(1, Errorf(ErrorCodeEngineUnavailable, "browser target crashed"))
+	service, _ := newOutputTestService(t, engine, fastRetries(2))
+
+	var output bytes.Buffer
+	result := service.ConvertStream(context.Background(), bytes.NewReader([]byte("docx")), FileTypeDOCX, &output, FormatHTML, ConversionOptions{})
+	if !result.Success {
+		t.Fatalf("Conversion failed: %v", result.Error)
+	}
+	if output.String() != "html:docx" {
+		t.Errorf("Expected only the successful attempt's output, got %q", output.String())
+	}
+	if len(result.Attempts) != 2 {
+		t.Errorf("Expected 2 attempts, got %d", len(result.Attempts))
+	}
+}
+
+// TestConverterService_EngineTimeout tests that a hanging engine is stopped by its timeout
+func TestConverterService_EngineTimeout(t *testing.T) {
+	engine := &hangingEngine{stubEngine: stubEngine{name: "document", edges: []ConversionEdge{
+		{From: FileTypeDOCX, To: FormatHTML, Cost: EdgeCostInProcess},
+	}}}
+	service, source := newOutputTestService(t, engine, fastRetries(2), WithEngineTimeout("document", 20*time.Millisecond))
+
+	result := service.Convert(context.Background(), source, filepath.Join(filepath.Dir(source), "report.html"), ConversionOptions{})
+	if !errors.Is(result.Error, ErrTimeout) {
+		t.Fatalf("Expected ErrTimeout, got %v", result.Error)
+	}
+	var converts int
+	for _, attempt := range result.Attempts {
+		if attempt.Operation == OperationConvert {
+			converts++
+			if !errors.Is(attempt.Err, ErrTimeout) {
+				t.Errorf("Expected the attempt to time out, got %v", attempt.Err)
+			}
+		}
+	}
+	if converts != 2 {
+		t.Errorf("Expected the timed out attempt to be retried once, got %d attempts", converts)
+	}
+}
+
+// TestConverterService_DefaultTimeouts tests that the configured default bounds engines without their own timeout
+func TestConverterService_DefaultTimeouts(t *testing.T) {
+	engine := &hangingEngine{stubEngine: stubEngine{name: "document", edges: []ConversionEdge{
+		{From: FileTypeDOCX, To: FormatHTML, Cost: EdgeCostInProcess},
+	}}}
+	service, source := newOutputTestService(t, engine, fastRetries(1), WithDefaultTimeouts(time.Hour, 20*time.Millisecond))
+
+	result := service.Convert(context.Background(), source, filepath.Join(filepath.Dir(source), "report.html"), ConversionOptions{})
+	if !errors.Is(result.Error, ErrTimeout) {
+		t.Fatalf("Expected ErrTimeout, got %v", result.Error)
+	}
+}
+
+// TestConverterService_DefaultTimeouts_ByStepCost tests that each step gets the
+// default timeout of its own cost, not the costliest step of its engine
+func TestConverterService_DefaultTimeouts_ByStepCost(t *testing.T) {
+	engine := &hangingEngine{stubEngine: stubEngine{name: "document", edges: []ConversionEdge{
+		{From: FileTypeDOCX, To: FormatHTML, Cost: EdgeCostInProcess},
+		{From: FileTypeDOCX, To: FormatPDF, Cost: EdgeCostBrowser},
+	}}}
+
+	service, source := newOutputTestService(t, engine, fastRetries(1), WithDefaultTimeouts(time.Hour, 20*time.Millisecond))
+	result := service.Convert(context.Background(), source, filepath.Join(filepath.Dir(source), "report.html"), ConversionOptions{})
+	if !errors.Is(result.Error, ErrTimeout) {
+		t.Errorf("Expected the in-process step to get the in-process timeout, got %v", result.Error)
+	}
+
+	service, source = newOutputTestService(t, engine, fastRetries(1), WithDefaultTimeouts(20*time.Millisecond, time.Hour))
+	result = service.Convert(context.Background(), source, filepath.Join(filepath.Dir(source), "report.pdf"), ConversionOptions{})
+	if !errors.Is(result.Error, ErrTimeout) {
+		t.Errorf("Expected the browser step to get the browser timeout, got %v", result.Error)
+	}
+}
+
+// TestConverterService_CancellationIsNotRetried tests that cancelling the conversion stops retries
+func TestConverterService_CancellationIsNotRetried(t *testing.T) {
+	engine := &hangingEngine{stubEngine: stubEngine{name: "document", edges: []ConversionEdge{
+		{From: FileTypeDOCX, To: FormatHTML, Cost: EdgeCostInProcess},
+	}}}
+	service, source := newOutputTestService(t, engine, fastRetries(3))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	result := service.Convert(ctx, source, filepath.Join(filepath.Dir(source), "report.html"), ConversionOptions{})
+	if result.Error == nil {
+		t.Fatal("Expected the conversion to fail")
+	}
+	var converts int
+	for _, attempt := range result.Attempts {
+		if attempt.Operation == OperationConvert {
+			converts++
+		}
+	}
+	if converts != 1 {
+		t.Errorf("Expected a single attempt, got %d", converts)
+	}
+}
+
+// TestRetryPolicy_Backoff tests exponential growth capped at MaxBackoff
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond, Multiplier: 2}
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond}
+	for i, expected := range want {
+		if got := policy.backoff(i + 1); got != expected {
+			t.Errorf("Attempt %d: expected %v, got %v", i+1, expected, got)
+		}
+	}
+}
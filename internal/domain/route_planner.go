@@ -0,0 +1,167 @@
+package domain
+
+const (
+	// EdgeCostInProcess is the cost of a conversion step performed entirely in Go
+	EdgeCostInProcess = 1
+	// EdgeCostBrowser is the cost of a conversion step that drives the headless browser
+	EdgeCostBrowser = 2
+)
+
+// ConversionEdge describes a single conversion step an engine can perform
+type ConversionEdge struct {
+	From FileType
+	To   Format
+	// Cost is a relative weight used to pick the cheapest route; see EdgeCostInProcess
+	Cost int
+}
+
+// RouteStep is a single hop of a conversion route, bound to the engine that performs it
+type RouteStep struct {
+	Edge   ConversionEdge
+	Engine IConverter
+}
+
+// Route is an ordered chain of conversion steps from an input type to an output format
+type Route struct {
+	Steps []RouteStep
+	Cost  int
+}
+
+// RoutePlanner builds a conversion graph from engine edges and finds the cheapest
+// path between an input file type and an output format
+type RoutePlanner struct {
+	steps map[FileType][]RouteStep
+}
+
+// NewRoutePlanner creates an empty route planner
+func NewRoutePlanner() *RoutePlanner {
+	return &RoutePlanner{
+		steps: make(map[FileType][]RouteStep),
+	}
+}
+
+// AddEngine registers every conversion edge declared by the engine
+func (p *RoutePlanner) AddEngine(engine IConverter) {
+	for _, edge := range engine.Capabilities().Conversions {
+		p.AddEdge(engine, edge)
+	}
+}
+
+// AddEdge registers a single conversion edge performed by the given engine
+func (p *RoutePlanner) AddEdge(engine IConverter, edge ConversionEdge) {
+	if edge.Cost <= 0 {
+		edge.Cost = EdgeCostInProcess
+	}
+	p.steps[edge.From] = append(p.steps[edge.From], RouteStep{Edge: edge, Engine: engine})
+}
+
+// Accepts reports whether at least one engine accepts the given input type
+func (p *RoutePlanner) Accepts(fileType FileType) bool {
+	return len(p.steps[fileType]) > 0
+}
+
+// Plan finds the cheapest route from an input file type to an output format.
+// Ties are broken by the number of steps, then by engine registration order.
+func (p *RoutePlanner) Plan(from FileType, to Format) (Route, error) {
+	target := to.AsFileType()
+
+	// Same-type conversions (e.g. re-encoding a PNG) are only served by direct edges
+	if from == target {
+		var best *RouteStep
+		for i := range p.steps[from] {
+			step := &p.steps[from][i]
+			if step.Edge.To == to && (best == nil || step.Edge.Cost < best.Edge.Cost) {
+				best = step
+			}
+		}
+		if best == nil {
+			return Route{}, Errorf(ErrorCodeUnsupportedFormat, "no conversion route from %s to %s", from, to)
+		}
+		return Route{Steps: []RouteStep{*best}, Cost: best.Edge.Cost}, nil
+	}
+
+	type node struct {
+		cost  int
+		hops  int
+		via   *RouteStep
+		prev  FileType
+		final bool
+	}
+	nodes := map[FileType]*node{from: {}}
+	// order keeps discovery order so that ties resolve deterministically
+	order := []FileType{from}
+
+	for {
+		// Pick the cheapest node that has not been finalised yet
+		var current FileType
+		var best *node
+		for _, fileType := range order {
+			n := nodes[fileType]
+			if n.final {
+				continue
+			}
+			if best == nil || n.cost < best.cost || (n.cost == best.cost && n.hops < best.hops) {
+				current, best = fileType, n
+			}
+		}
+		if best == nil {
+			return Route{}, Errorf(ErrorCodeUnsupportedFormat, "no conversion route from %s to %s", from, to)
+		}
+		best.final = true
+
+		if current == target {
+			// Walk the predecessor chain back to the input type
+			steps := make([]RouteStep, best.hops)
+			for ft, i := target, best.hops-1; i >= 0; i-- {
+				n := nodes[ft]
+				steps[i] = *n.via
+				ft = n.prev
+			}
+			return Route{Steps: steps, Cost: best.cost}, nil
+		}
+
+		for i := range p.steps[current] {
+			step := &p.steps[current][i]
+			next := step.Edge.To.AsFileType()
+			cost := best.cost + step.Edge.Cost
+			hops := best.hops + 1
+
+			existing, seen := nodes[next]
+			if seen && existing.final {
+				continue
+			}
+			if !seen {
+				order = append(order, next)
+			}
+			if !seen || cost < existing.cost || (cost == existing.cost && hops < existing.hops) {
+				nodes[next] = &node{cost: cost, hops: hops, via: step, prev: current}
+			}
+		}
+	}
+}
+
+// Reachable returns every output format reachable from the given input type
+func (p *RoutePlanner) Reachable(from FileType) []Format {
+	visited := map[FileType]bool{from: true}
+	seen := make(map[Format]bool)
+	queue := []FileType{from}
+	var formats []Format
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, step := range p.steps[current] {
+			if !seen[step.Edge.To] {
+				seen[step.Edge.To] = true
+				formats = append(formats, step.Edge.To)
+			}
+			next := step.Edge.To.AsFileType()
+			if !visited[next] {
+				visited[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	return formats
+}
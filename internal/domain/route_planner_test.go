@@ -0,0 +1,118 @@
+package domain
+
+import (
+	"context"
+	"testing"
+)
+
+// stubEngine is a minimal IConverter that only declares conversion edges
+type stubEngine struct {
+	name  string
+	edges []ConversionEdge
+}
+
+func (e *stubEngine) Convert(ctx context.Context, input, output string, opts ConversionOptions) error {
+	return nil
+}
+
+func (e *stubEngine) Validate(ctx context.Context, file string) error { return nil }
+
+func (e *stubEngine) Capabilities() Capabilities {
+	return Capabilities{Name: e.name, Conversions: e.edges}
+}
+
+// newTestPlanner builds a planner that mirrors the built-in engine graph
+func newTestPlanner() *RoutePlanner {
+	planner := NewRoutePlanner()
+	planner.AddEngine(&stubEngine{name: "document", edges: []ConversionEdge{
+		{From: FileTypeDOCX, To: FormatHTML, Cost: EdgeCostInProcess},
+		{From: FileTypeDOCX, To: FormatPDF, Cost: EdgeCostBrowser},
+	}})
+	planner.AddEngine(&stubEngine{name: "spreadsheet", edges: []ConversionEdge{
+		{From: FileTypeXLSX, To: FormatHTML, Cost: EdgeCostInProcess},
+	}})
+	planner.AddEngine(&stubEngine{name: "html", edges: []ConversionEdge{
+		{From: FileTypeHTML, To: FormatPDF, Cost: EdgeCostBrowser},
+		{From: FileTypeHTML, To: FormatPNG, Cost: EdgeCostBrowser},
+	}})
+	planner.AddEngine(&stubEngine{name: "image", edges: []ConversionEdge{
+		{From: FileTypePNG, To: FormatPNG, Cost: EdgeCostInProcess},
+		{From: FileTypePNG, To: FormatJPEG, Cost: EdgeCostInProcess},
+	}})
+	return planner
+}
+
+// routeFormats returns the output format of every step in a route
+func routeFormats(route Route) []Format {
+	formats := make([]Format, len(route.Steps))
+	for i, step := range route.Steps {
+		formats[i] = step.Edge.To
+	}
+	return formats
+}
+
+// TestRoutePlanner_PrefersDirectEdge tests that a cheaper direct edge wins over a multi-hop route
+func TestRoutePlanner_PrefersDirectEdge(t *testing.T) {
+	route, err := newTestPlanner().Plan(FileTypeDOCX, FormatPDF)
+	if err != nil {
+		t.Fatalf("Expected a route from DOCX to PDF, got error: %v", err)
+	}
+
+	if got := routeFormats(route); len(got) != 1 || got[0] != FormatPDF {
+		t.Errorf("Expected direct DOCX -> PDF route, got %v", got)
+	}
+}
+
+// TestRoutePlanner_ChainsIntermediateFormats tests multi-hop planning through HTML
+func TestRoutePlanner_ChainsIntermediateFormats(t *testing.T) {
+	route, err := newTestPlanner().Plan(FileTypeXLSX, FormatJPEG)
+	if err != nil {
+		t.Fatalf("Expected a route from XLSX to JPEG, got error: %v", err)
+	}
+
+	want := []Format{FormatHTML, FormatPNG, FormatJPEG}
+	got := routeFormats(route)
+	if len(got) != len(want) {
+		t.Fatalf("Expected route %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Expected route %v, got %v", want, got)
+		}
+	}
+	if route.Cost != EdgeCostInProcess+EdgeCostBrowser+EdgeCostInProcess {
+		t.Errorf("Unexpected route cost: %d", route.Cost)
+	}
+}
+
+// TestRoutePlanner_SameTypeConversion tests that re-encoding uses a direct self edge
+func TestRoutePlanner_SameTypeConversion(t *testing.T) {
+	route, err := newTestPlanner().Plan(FileTypePNG, FormatPNG)
+	if err != nil {
+		t.Fatalf("Expected a route from PNG to PNG, got error: %v", err)
+	}
+	if len(route.Steps) != 1 {
+		t.Errorf("Expected a single step, got %d", len(route.Steps))
+	}
+}
+
+// TestRoutePlanner_NoRoute tests that unreachable formats are reported as errors
+func TestRoutePlanner_NoRoute(t *testing.T) {
+	if _, err := newTestPlanner().Plan(FileTypePNG, FormatPDF); err == nil {
+		t.Error("Expected an error for an unreachable format, got a route")
+	}
+}
+
+// TestRoutePlanner_Reachable tests that reachable formats include multi-hop targets
+func TestRoutePlanner_Reachable(t *testing.T) {
+	reachable := make(map[Format]bool)
+	for _, format := range newTestPlanner().Reachable(FileTypeDOCX) {
+		reachable[format] = true
+	}
+
+	for _, format := range []Format{FormatHTML, FormatPDF, FormatPNG, FormatJPEG} {
+		if !reachable[format] {
+			t.Errorf("Expected %s to be reachable from DOCX", format)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// TransformKind names a step of the image transform pipeline
+type TransformKind string
+
+const (
+	TransformResize TransformKind = "resize"
+	TransformCrop   TransformKind = "crop"
+	TransformRotate TransformKind = "rotate"
+	TransformFlip   TransformKind = "flip"
+)
+
+// ResizeMode decides how a resize treats the aspect ratio
+type ResizeMode string
+
+const (
+	// ResizeFit scales the image down to fit inside the box, keeping its
+	// aspect ratio; images that already fit are unchanged
+	ResizeFit ResizeMode = "fit"
+	// ResizeFill scales the image to cover the box and crops the overflow
+	// around the centre
+	ResizeFill ResizeMode = "fill"
+	// ResizeExact scales the image to the box, stretching it if needed. A
+	// zero width or height follows the aspect ratio.
+	ResizeExact ResizeMode = "exact"
+)
+
+// ResampleFilter is the filter used to resample pixels when resizing
+type ResampleFilter string
+
+const (
+	FilterLanczos    ResampleFilter = "lanczos"
+	FilterCatmullRom ResampleFilter = "catmullrom"
+	FilterLinear     ResampleFilter = "linear"
+	FilterBox        ResampleFilter = "box"
+	FilterNearest    ResampleFilter = "nearest"
+)
+
+// FlipDirection is the axis an image is mirrored across
+type FlipDirection string
+
+const (
+	FlipHorizontal FlipDirection = "horizontal"
+	FlipVertical   FlipDirection = "vertical"
+)
+
+// ImageTransform is one step of the image transform pipeline. Kind selects
+// the step; the other fields are its parameters.
+type ImageTransform struct {
+	Kind TransformKind
+	// Width and Height are the resize box, or the size of the crop box, in
+	// pixels
+	Width  int
+	Height int
+	// Mode and Filter apply to resize; empty uses ResizeFit and FilterLanczos
+	Mode   ResizeMode
+	Filter ResampleFilter
+	// X and Y are the top-left corner of the crop box, unless Centered
+	// places the box around the centre of the image
+	X        int
+	Y        int
+	Centered bool
+	// Aspect crops the largest centred box of a width:height ratio such as
+	// "16:9" instead of a box
+	Aspect string
+	// Angle is the clockwise rotation in degrees. Angles other than
+	// multiples of 90 leave transparent corners.
+	Angle float64
+	// Flip is the axis of a flip
+	Flip FlipDirection
+}
+
+// Validate checks the parameters of the transform
+func (t ImageTransform) Validate() error {
+	switch t.Kind {
+	case TransformResize:
+		if t.Width < 0 || t.Height < 0 || (t.Width == 0 && t.Height == 0) {
+			return fmt.Errorf("invalid resize %dx%d: give a positive width, height or both", t.Width, t.Height)
+		}
+		switch t.Mode {
+		case "", ResizeFit, ResizeExact:
+		case ResizeFill:
+			if t.Width == 0 || t.Height == 0 {
+				return fmt.Errorf("invalid resize %dx%d: fill needs both a width and a height", t.Width, t.Height)
+			}
+		default:
+			return fmt.Errorf("invalid resize mode %q: use fit, fill or exact", t.Mode)
+		}
+		switch t.Filter {
+		case "", FilterLanczos, FilterCatmullRom, FilterLinear, FilterBox, FilterNearest:
+		default:
+			return fmt.Errorf("invalid resample filter %q: use lanczos, catmullrom, linear, box or nearest", t.Filter)
+		}
+	case TransformCrop:
+		if t.Aspect != "" {
+			if t.Width != 0 || t.Height != 0 || t.X != 0 || t.Y != 0 || t.Centered {
+				return fmt.Errorf("invalid crop: give either a box or an aspect ratio")
+			}
+			if _, _, err := ParseAspect(t.Aspect); err != nil {
+				return err
+			}
+			return nil
+		}
+		if t.Width <= 0 || t.Height <= 0 || t.X < 0 || t.Y < 0 {
+			return fmt.Errorf("invalid crop box %dx%d+%d+%d: the size must be positive and the corner not negative", t.Width, t.Height, t.X, t.Y)
+		}
+	case TransformRotate:
+		if math.IsNaN(t.Angle) || math.IsInf(t.Angle, 0) {
+			return fmt.Errorf("invalid rotation angle %v", t.Angle)
+		}
+	case TransformFlip:
+		switch t.Flip {
+		case FlipHorizontal, FlipVertical:
+		default:
+			return fmt.Errorf("invalid flip %q: use horizontal or vertical", t.Flip)
+		}
+	default:
+		return fmt.Errorf("unknown transform %q: use resize, crop, rotate or flip", t.Kind)
+	}
+	return nil
+}
+
+// ParseAspect parses an aspect ratio written as width:height, such as "16:9"
+func ParseAspect(aspect string) (width, height float64, err error) {
+	w, h, ok := strings.Cut(aspect, ":")
+	if ok {
+		width, err = strconv.ParseFloat(w, 64)
+		if err == nil {
+			height, err = strconv.ParseFloat(h, 64)
+		}
+	}
+	if !ok || err != nil || !(width > 0) || !(height > 0) || math.IsInf(width, 0) || math.IsInf(height, 0) {
+		return 0, 0, fmt.Errorf("invalid aspect ratio %q: use width:height, such as 16:9", aspect)
+	}
+	return width, height, nil
+}
+
+// ParseImageTransform parses the short form of a transform used on the
+// command line:
+//
+//	resize=WxH[:MODE][:FILTER]  resize=800x600:fill, resize=1200x, resize=x480:exact:nearest
+//	crop=WxH[+X+Y]              crop=400x300+10+20; without a corner the box is centred
+//	crop=W:H                    crop=16:9, the largest centred box of that ratio
+//	rotate=DEGREES              rotate=90, clockwise
+//	flip=horizontal|vertical    flip=h, flip=v
+func ParseImageTransform(spec string) (ImageTransform, error) {
+	kind, value, ok := strings.Cut(strings.TrimSpace(spec), "=")
+	if !ok || value == "" {
+		return ImageTransform{}, fmt.Errorf("invalid transform %q: use NAME=VALUE, such as rotate=90", spec)
+	}
+	t := ImageTransform{Kind: TransformKind(strings.ToLower(kind))}
+	var err error
+	switch t.Kind {
+	case TransformResize:
+		parts := strings.Split(value, ":")
+		if t.Width, t.Height, err = parseSize(parts[0], true); err != nil {
+			return ImageTransform{}, fmt.Errorf("invalid transform %q: %w", spec, err)
+		}
+		for _, part := range parts[1:] {
+			switch part := strings.ToLower(part); part {
+			case string(ResizeFit), string(ResizeFill), string(ResizeExact):
+				t.Mode = ResizeMode(part)
+			default:
+				t.Filter = ResampleFilter(part)
+			}
+		}
+	case TransformCrop:
+		if strings.Contains(value, ":") {
+			t.Aspect = value
+			break
+		}
+		size, corner, hasCorner := strings.Cut(value, "+")
+		if t.Width, t.Height, err = parseSize(size, false); err != nil {
+			return ImageTransform{}, fmt.Errorf("invalid transform %q: %w", spec, err)
+		}
+		if !hasCorner {
+			t.Centered = true
+			break
+		}
+		x, y, ok := strings.Cut(corner, "+")
+		if t.X, err = strconv.Atoi(x); err == nil && ok {
+			t.Y, err = strconv.Atoi(y)
+		}
+		if err != nil || !ok {
+			return ImageTransform{}, fmt.Errorf("invalid transform %q: the crop corner must be +X+Y", spec)
+		}
+	case TransformRotate:
+		if t.Angle, err = strconv.ParseFloat(value, 64); err != nil {
+			return ImageTransform{}, fmt.Errorf("invalid transform %q: the angle must be a number of degrees", spec)
+		}
+	case TransformFlip:
+		switch strings.ToLower(value) {
+		case "h", string(FlipHorizontal):
+			t.Flip = FlipHorizontal
+		case "v", string(FlipVertical):
+			t.Flip = FlipVertical
+		default:
+			t.Flip = FlipDirection(value)
+		}
+	}
+	if err := t.Validate(); err != nil {
+		return ImageTransform{}, err
+	}
+	return t, nil
+}
+
+// parseSize parses WxH; with optional set either side may be empty
+func parseSize(size string, optional bool) (width, height int, err error) {
+	w, h, ok := strings.Cut(strings.ToLower(size), "x")
+	if !ok {
+		return 0, 0, fmt.Errorf("the size must be WIDTHxHEIGHT")
+	}
+	parse := func(text string) (int, error) {
+		if text == "" && optional {
+			return 0, nil
+		}
+		return strconv.Atoi(text)
+	}
+	if width, err = parse(w); err == nil {
+		height, err = parse(h)
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("the size must be WIDTHxHEIGHT in pixels")
+	}
+	return width, height, nil
+}
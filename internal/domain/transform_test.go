@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestParseImageTransform tests the short forms of every transform
+func TestParseImageTransform(t *testing.T) {
+	tests := []struct {
+		spec string
+		want ImageTransform
+	}{
+		{"resize=800x600", ImageTransform{Kind: TransformResize, Width: 800, Height: 600}},
+		{"resize=1200x", ImageTransform{Kind: TransformResize, Width: 1200}},
+		{"resize=x480:exact:nearest", ImageTransform{Kind: TransformResize, Height: 480, Mode: ResizeExact, Filter: FilterNearest}},
+		{"resize=320x240:CatmullRom:fill", ImageTransform{Kind: TransformResize, Width: 320, Height: 240, Mode: ResizeFill, Filter: FilterCatmullRom}},
+		{"crop=400x300+10+20", ImageTransform{Kind: TransformCrop, Width: 400, Height: 300, X: 10, Y: 20}},
+		{"crop=400x300", ImageTransform{Kind: TransformCrop, Width: 400, Height: 300, Centered: true}},
+		{"crop=16:9", ImageTransform{Kind: TransformCrop, Aspect: "16:9"}},
+		{"rotate=-12.5", ImageTransform{Kind: TransformRotate, Angle: -12.5}},
+		{"flip=h", ImageTransform{Kind: TransformFlip, Flip: FlipHorizontal}},
+		{"flip=vertical", ImageTransform{Kind: TransformFlip, Flip: FlipVertical}},
+	}
+	for _, tt := range tests {
+		got, err := ParseImageTransform(tt.spec)
+		if err != nil {
+			t.Errorf("Failed to parse %q: %v", tt.spec, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Expected %q to parse as %+v, got %+v", tt.spec, tt.want, got)
+		}
+	}
+}
+
+// TestParseImageTransform_Invalid tests that malformed transforms are rejected with a reason
+func TestParseImageTransform_Invalid(t *testing.T) {
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{"rotate", "NAME=VALUE"},
+		{"blur=2", "unknown transform"},
+		{"resize=x", "positive width"},
+		{"resize=800:fill", "WIDTHxHEIGHT"},
+		{"resize=800x:fill", "fill needs both"},
+		{"resize=800x600:bicubic", "resample filter"},
+		{"crop=0x300+0+0", "invalid crop box"},
+		{"crop=400x300+10", "+X+Y"},
+		{"crop=16:0", "aspect ratio"},
+		{"rotate=right", "degrees"},
+		{"flip=diagonal", "invalid flip"},
+	}
+	for _, tt := range tests {
+		if _, err := ParseImageTransform(tt.spec); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Expected %q to fail with %q, got %v", tt.spec, tt.want, err)
+		}
+	}
+}
+
+// TestConversionOptions_ValidateTransforms tests that invalid transforms are reported with their position
+func TestConversionOptions_ValidateTransforms(t *testing.T) {
+	opts := ConversionOptions{Image: ImageOptions{Transforms: []ImageTransform{
+		{Kind: TransformRotate, Angle: 90},
+		{Kind: TransformCrop, Aspect: "4:3", X: 10},
+	}}}
+	if err := opts.Validate(); err == nil || !strings.Contains(err.Error(), "transform 2:") {
+		t.Errorf("Expected the second transform to be rejected, got %v", err)
+	}
+}
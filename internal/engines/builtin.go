@@ -0,0 +1,81 @@
+package engines
+
+import (
+	"github.com/eka026/File-Format-Converter/internal/adapters/browser"
+	"github.com/eka026/File-Format-Converter/internal/domain"
+	"github.com/eka026/File-Format-Converter/internal/engines/document"
+	"github.com/eka026/File-Format-Converter/internal/engines/html"
+	"github.com/eka026/File-Format-Converter/internal/engines/image"
+	"github.com/eka026/File-Format-Converter/internal/engines/spreadsheet"
+	"github.com/eka026/File-Format-Converter/internal/scheduler"
+)
+
+// BuiltinDeps are the shared resources the built-in engines are constructed with
+type BuiltinDeps struct {
+	// Scheduler runs batch work; nil uses scheduler.Default()
+	Scheduler *scheduler.Scheduler
+	// Browser returns the shared headless browser, starting it if needed
+	Browser func() (*browser.HeadlessBrowser, error)
+	// Settings are the configured engine defaults; the zero value keeps
+	// each engine's own
+	Settings domain.EngineSettings
+}
+
+// RegisterBuiltins registers the engines shipped with the application at
+// PriorityBuiltin. Engines are only constructed, and the browser only
+// started, once a conversion needs them.
+func RegisterBuiltins(r *Registry, deps BuiltinDeps) error {
+	// The document and spreadsheet engines produce HTML without a browser and
+	// only start it for PDF output, reporting there why it is unavailable
+	registrations := []Registration{
+		{
+			Name:         "spreadsheet",
+			Capabilities: spreadsheet.NewSpreadsheetEngine(deps.Scheduler, nil, nil).Capabilities(),
+			Factory: func() (domain.IConverter, error) {
+				var pdfGenerator *spreadsheet.PDFGenerator
+				if deps.Browser != nil {
+					pdfGenerator = spreadsheet.NewPDFGenerator(deps.Browser)
+				}
+				return spreadsheet.NewSpreadsheetEngine(deps.Scheduler, spreadsheet.NewHTMLRenderer(), pdfGenerator), nil
+			},
+		},
+		{
+			Name:         "document",
+			Capabilities: document.NewDocumentEngine(deps.Scheduler, nil).Capabilities(),
+			Factory: func() (domain.IConverter, error) {
+				return document.NewDocumentEngine(deps.Scheduler, deps.Browser), nil
+			},
+		},
+		{
+			Name:         "image",
+			Capabilities: image.NewImageEngine(deps.Scheduler, deps.Settings).Capabilities(),
+			Factory: func() (domain.IConverter, error) {
+				return image.NewImageEngine(deps.Scheduler, deps.Settings), nil
+			},
+		},
+		{
+			// Every HTML conversion renders in the browser, so the engine is
+			// only constructed once one is available
+			Name:         "html",
+			Capabilities: html.NewHTMLEngine(nil).Capabilities(),
+			Factory: func() (domain.IConverter, error) {
+				if deps.Browser == nil {
+					return nil, domain.Errorf(domain.ErrorCodeBrowserMissing, "headless browser not available")
+				}
+				b, err := deps.Browser()
+				if err != nil {
+					return nil, err
+				}
+				return html.NewHTMLEngine(b), nil
+			},
+		},
+	}
+
+	for _, registration := range registrations {
+		registration.Priority = PriorityBuiltin
+		if err := r.Register(registration); err != nil {
+			return err
+		}
+	}
+	return nil
+}
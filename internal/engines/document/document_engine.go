@@ -2,13 +2,14 @@ package document
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
-	"sync"
 
-	"github.com/eka026/File-Format-Converter/internal/domain"
 	"github.com/eka026/File-Format-Converter/internal/adapters/browser"
-	"github.com/eka026/File-Format-Converter/internal/engines/image"
+	"github.com/eka026/File-Format-Converter/internal/domain"
+	"github.com/eka026/File-Format-Converter/internal/scheduler"
 )
 
 // DocumentEngine implements IConverter for document conversions using pure Go
@@ -16,24 +17,39 @@ import (
 type DocumentEngine struct {
 	parser       *DocxParser
 	htmlRenderer *HTMLRenderer
-	pdfGenerator *browser.HeadlessBrowser
-	workerPool   *image.WorkerPool
+	// launchBrowser returns the headless browser that renders PDF output,
+	// starting it if needed
+	launchBrowser func() (*browser.HeadlessBrowser, error)
+	scheduler     *scheduler.Scheduler
 }
 
 // NewDocumentEngine creates a new document conversion engine
 // Uses pure Go DOCX parsing (no WASM, no CGO dependencies)
-func NewDocumentEngine(pdfGenerator *browser.HeadlessBrowser) domain.IConverter {
+// Batch conversions run on the given scheduler; nil uses scheduler.Default()
+// launchBrowser is only called for PDF output; nil reports the browser missing
+func NewDocumentEngine(sched *scheduler.Scheduler, launchBrowser func() (*browser.HeadlessBrowser, error)) domain.IConverter {
+	if sched == nil {
+		sched = scheduler.Default()
+	}
 	return &DocumentEngine{
-		parser:       NewDocxParser(),
-		htmlRenderer: NewHTMLRenderer(),
-		pdfGenerator: pdfGenerator,
-		workerPool:   image.NewWorkerPool(),
+		parser:        NewDocxParser(),
+		htmlRenderer:  NewHTMLRenderer(),
+		launchBrowser: launchBrowser,
+		scheduler:     sched,
 	}
 }
 
+// pdfGenerator returns the browser that renders PDF output, starting it if needed
+func (e *DocumentEngine) pdfGenerator() (*browser.HeadlessBrowser, error) {
+	if e.launchBrowser == nil {
+		return nil, domain.Errorf(domain.ErrorCodeBrowserMissing, "pdf generator not available")
+	}
+	return e.launchBrowser()
+}
+
 // Convert converts a DOCX file to the specified output format
 // Input and output are file paths (matches domain.IConverter interface)
-func (e *DocumentEngine) Convert(ctx context.Context, input, output string) error {
+func (e *DocumentEngine) Convert(ctx context.Context, input, output string, opts domain.ConversionOptions) error {
 	// Check for cancellation before starting
 	if ctx.Err() != nil {
 		return ctx.Err()
@@ -45,42 +61,122 @@ func (e *DocumentEngine) Convert(ctx context.Context, input, output string) erro
 		return fmt.Errorf("reading docx file: %w", err)
 	}
 
-	// Check for cancellation after reading
+	// Parse and render to HTML
+	htmlContent, err := e.renderHTML(ctx, docxData)
+	if err != nil {
+		return err
+	}
+
+	// Determine output format from file extension
+	outputExt := getFileExtension(output)
+	if outputExt == ".html" || outputExt == ".htm" {
+		// Write HTML directly
+		if err := os.WriteFile(output, []byte(htmlContent), 0644); err != nil {
+			return domain.NewError(domain.ErrorCodeOutputNotWritable, err)
+		}
+		return nil
+	}
+
+	// For PDF, use headless browser
+	if outputExt == ".pdf" {
+		pdfGenerator, err := e.pdfGenerator()
+		if err != nil {
+			return err
+		}
+		// Use the provided context instead of Background()
+		return pdfGenerator.GeneratePDFFromHTML(ctx, htmlContent, output, opts.Page)
+	}
+
+	return domain.Errorf(domain.ErrorCodeUnsupportedFormat, "unsupported output format: %s", outputExt)
+}
+
+// ConvertStream converts DOCX data read from input to HTML or PDF written to output
+func (e *DocumentEngine) ConvertStream(ctx context.Context, input io.Reader, output io.Writer, format domain.Format, opts domain.ConversionOptions) error {
+	// Check for cancellation before starting
 	if ctx.Err() != nil {
 		return ctx.Err()
 	}
 
+	// DOCX is a ZIP archive, so the whole document is needed before parsing
+	docxData, err := io.ReadAll(input)
+	if err != nil {
+		return fmt.Errorf("reading docx data: %w", err)
+	}
+
+	// Parse and render to HTML
+	htmlContent, err := e.renderHTML(ctx, docxData)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case domain.FormatHTML:
+		_, err := io.WriteString(output, htmlContent)
+		return err
+	case domain.FormatPDF:
+		pdfGenerator, err := e.pdfGenerator()
+		if err != nil {
+			return err
+		}
+		pdf, err := pdfGenerator.GeneratePDFFromHTMLBytes(ctx, htmlContent, opts.Page)
+		if err != nil {
+			return err
+		}
+		_, err = output.Write(pdf)
+		return err
+	default:
+		return domain.Errorf(domain.ErrorCodeUnsupportedFormat, "unsupported output format: %s", format)
+	}
+}
+
+// renderHTML parses DOCX data and renders it as an HTML document
+func (e *DocumentEngine) renderHTML(ctx context.Context, docxData []byte) (string, error) {
+	// Check for cancellation after reading
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+
 	// Parse DOCX file
-	doc, err := e.parser.Parse(docxData)
+	parsed := domain.StartStage(ctx, domain.StageParse)
+	doc, err := e.parser.WithLimits(domain.ResourceLimitsFromContext(ctx)).Parse(docxData)
+	parsed()
+	if errors.Is(err, domain.ErrResourceLimit) {
+		return "", err
+	}
 	if err != nil {
-		return fmt.Errorf("parsing docx: %w", err)
+		return "", domain.Errorf(domain.ErrorCodeCorruptInput, "parsing docx: %w", err)
 	}
 
 	// Check for cancellation after parsing
 	if ctx.Err() != nil {
-		return ctx.Err()
+		return "", ctx.Err()
 	}
 
+	reportOmitted(ctx, doc.Omitted)
+
 	// Convert to HTML
-	htmlContent := e.htmlRenderer.Render(doc)
+	defer domain.StartStage(ctx, domain.StageRender)()
+	return e.htmlRenderer.Render(doc), nil
+}
 
-	// Determine output format from file extension
-	outputExt := getFileExtension(output)
-	if outputExt == ".html" || outputExt == ".htm" {
-		// Write HTML directly
-		return os.WriteFile(output, []byte(htmlContent), 0644)
+// reportOmitted reports the document content the HTML renderer cannot reproduce
+func reportOmitted(ctx context.Context, omitted OmittedContent) {
+	domain.RecordCounts(ctx, domain.ContentCounts{Images: omitted.Images})
+	if omitted.Images > 0 {
+		domain.RecordFidelityWarning(ctx, domain.FidelityImagesDropped,
+			fmt.Sprintf("%d embedded images, charts or shapes were dropped", omitted.Images))
 	}
-
-	// For PDF, use headless browser
-	if outputExt == ".pdf" {
-		if e.pdfGenerator == nil {
-			return fmt.Errorf("pdf generator not available")
-		}
-		// Use the provided context instead of Background()
-		return e.pdfGenerator.GeneratePDFFromHTML(ctx, htmlContent, output)
+	if omitted.Notes > 0 {
+		domain.RecordFidelityWarning(ctx, domain.FidelityNotesDropped,
+			fmt.Sprintf("%d footnotes or endnotes were dropped", omitted.Notes))
+	}
+	if omitted.Comments > 0 {
+		domain.RecordFidelityWarning(ctx, domain.FidelityCommentsDropped,
+			fmt.Sprintf("%d comments were dropped", omitted.Comments))
+	}
+	if omitted.HeadersFooters > 0 {
+		domain.RecordFidelityWarning(ctx, domain.FidelityHeadersFootersDropped, "page headers and footers were dropped")
 	}
-
-	return fmt.Errorf("unsupported output format: %s", outputExt)
 }
 
 // getFileExtension extracts file extension in lowercase
@@ -115,7 +211,22 @@ func (e *DocumentEngine) Validate(ctx context.Context, file string) error {
 	if ctx.Err() != nil {
 		return ctx.Err()
 	}
-	return ValidateDOCX(file)
+	return ValidateDOCX(file, domain.ResourceLimitsFromContext(ctx))
+}
+
+// Capabilities reports the conversions this engine can perform.
+// DOCX → PDF renders through the browser directly, so it is priced as a single
+// browser step rather than DOCX → HTML followed by a separate HTML → PDF hop.
+func (e *DocumentEngine) Capabilities() domain.Capabilities {
+	return domain.Capabilities{
+		Name:    "document",
+		Version: "1",
+		Conversions: []domain.ConversionEdge{
+			{From: domain.FileTypeDOCX, To: domain.FormatHTML, Cost: domain.EdgeCostInProcess},
+			{From: domain.FileTypeDOCX, To: domain.FormatPDF, Cost: domain.EdgeCostBrowser},
+		},
+		Options: domain.PageOptionDescriptors(),
+	}
 }
 
 // BatchConversionTask represents a single conversion task in a batch
@@ -123,6 +234,7 @@ type BatchConversionTask struct {
 	InputPath  string
 	OutputPath string
 	Index      int
+	Options    domain.ConversionOptions
 }
 
 // BatchConversionResult represents the result of a batch conversion task
@@ -131,43 +243,32 @@ type BatchConversionResult struct {
 	Error error
 }
 
-// BatchConvert processes multiple document conversions in parallel using the worker pool
-// It takes a slice of input/output path pairs and processes them concurrently
-// utilizing all available CPU cores through the worker pool
-func (e *DocumentEngine) BatchConvert(tasks []BatchConversionTask) []BatchConversionResult {
+// BatchConvert processes multiple document conversions in parallel on the shared scheduler
+// It takes a slice of input/output path pairs and processes them concurrently,
+// bounded by the scheduler's worker pool and the document engine's concurrency limit
+func (e *DocumentEngine) BatchConvert(ctx context.Context, tasks []BatchConversionTask) []BatchConversionResult {
 	if len(tasks) == 0 {
 		return nil
 	}
 
-	results := make([]BatchConversionResult, len(tasks))
-	var wg sync.WaitGroup
-	var mu sync.Mutex
-
-	// Submit all tasks to the worker pool
-	for _, task := range tasks {
-		wg.Add(1)
+	// Submit all tasks before waiting so they are queued together
+	// Cancelling ctx fails the tasks not yet started and is passed on to
+	// the running ones
+	handles := make([]*scheduler.Handle, len(tasks))
+	for i, task := range tasks {
 		task := task // Capture loop variable
-
-		e.workerPool.Submit(func() {
-			defer wg.Done()
-
-			// Perform the conversion with background context
-			// Note: For batch operations, we use background context as cancellation
-			// should be handled at the batch level, not individual task level
-			err := e.Convert(context.Background(), task.InputPath, task.OutputPath)
-
-			// Store result thread-safely
-			mu.Lock()
-			results[task.Index] = BatchConversionResult{
-				Index: task.Index,
-				Error: err,
-			}
-			mu.Unlock()
+		handles[i] = e.scheduler.Submit(ctx, "document", scheduler.PriorityNormal, func(ctx context.Context) error {
+			return e.Convert(ctx, task.InputPath, task.OutputPath, task.Options)
 		})
 	}
 
-	// Wait for all conversions to complete
-	wg.Wait()
+	results := make([]BatchConversionResult, len(tasks))
+	for i, task := range tasks {
+		results[task.Index] = BatchConversionResult{
+			Index: task.Index,
+			Error: handles[i].Wait(),
+		}
+	}
 
 	return results
 }
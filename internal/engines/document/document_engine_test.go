@@ -4,11 +4,14 @@ import (
 	"archive/zip"
 	"bytes"
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/eka026/File-Format-Converter/internal/adapters/browser"
+	"github.com/eka026/File-Format-Converter/internal/domain"
+	"github.com/eka026/File-Format-Converter/internal/scheduler"
 )
 
 // TestDocumentEngine_Validate_ValidDOCX tests FR-05: The system shall accept valid .docx files as input
@@ -16,7 +19,7 @@ func TestDocumentEngine_Validate_ValidDOCX(t *testing.T) {
 	tmpFile := createTempDOCXFile(t)
 	defer os.Remove(tmpFile)
 
-	engine := NewDocumentEngine(nil).(*DocumentEngine)
+	engine := NewDocumentEngine(nil, nil).(*DocumentEngine)
 	ctx := context.Background()
 
 	err := engine.Validate(ctx, tmpFile)
@@ -34,7 +37,7 @@ func TestDocumentEngine_Validate_InvalidFile(t *testing.T) {
 	}
 	defer os.Remove(tmpFile)
 
-	engine := NewDocumentEngine(nil).(*DocumentEngine)
+	engine := NewDocumentEngine(nil, nil).(*DocumentEngine)
 	ctx := context.Background()
 
 	err := engine.Validate(ctx, tmpFile)
@@ -50,7 +53,7 @@ func TestDocumentEngine_ParseDOCX(t *testing.T) {
 	tmpFile := createTempDOCXFile(t)
 	defer os.Remove(tmpFile)
 
-	engine := NewDocumentEngine(nil).(*DocumentEngine)
+	engine := NewDocumentEngine(nil, nil).(*DocumentEngine)
 	parser := engine.parser
 
 	// Read DOCX file
@@ -81,7 +84,7 @@ func TestDocumentEngine_RenderToHTML(t *testing.T) {
 	tmpFile := createTempDOCXFile(t)
 	defer os.Remove(tmpFile)
 
-	engine := NewDocumentEngine(nil).(*DocumentEngine)
+	engine := NewDocumentEngine(nil, nil).(*DocumentEngine)
 	parser := engine.parser
 	renderer := engine.htmlRenderer
 
@@ -120,7 +123,7 @@ func TestDocumentEngine_Convert_ToPDF(t *testing.T) {
 	defer os.Remove(tmpFile)
 
 	// Test HTML rendering separately (PDF generation requires browser)
-	engine := NewDocumentEngine(nil).(*DocumentEngine)
+	engine := NewDocumentEngine(nil, nil).(*DocumentEngine)
 	parser := engine.parser
 	renderer := engine.htmlRenderer
 
@@ -147,6 +150,60 @@ func TestDocumentEngine_Convert_ToPDF(t *testing.T) {
 	}
 }
 
+// TestDocumentEngine_Convert_BrowserOnlyForPDF tests that the browser is only
+// started for PDF output and that its launch error is reported from there
+func TestDocumentEngine_Convert_BrowserOnlyForPDF(t *testing.T) {
+	launches := 0
+	launchErr := domain.Errorf(domain.ErrorCodeBrowserMissing, "no local browser found")
+	engine := NewDocumentEngine(nil, func() (*browser.HeadlessBrowser, error) {
+		launches++
+		return nil, launchErr
+	})
+	input := createTempDOCXFile(t)
+
+	if err := engine.Convert(context.Background(), input, filepath.Join(t.TempDir(), "out.html"), domain.ConversionOptions{}); err != nil {
+		t.Fatalf("Expected HTML output without a browser, got %v", err)
+	}
+	if launches != 0 {
+		t.Errorf("Expected HTML output not to start the browser, got %d launches", launches)
+	}
+
+	err := engine.Convert(context.Background(), input, filepath.Join(t.TempDir(), "out.pdf"), domain.ConversionOptions{})
+	if !errors.Is(err, launchErr) {
+		t.Errorf("Expected the launch error for PDF output, got %v", err)
+	}
+	if launches != 1 {
+		t.Errorf("Expected PDF output to start the browser once, got %d launches", launches)
+	}
+}
+
+// TestDocumentEngine_BatchConvert_SharedScheduler tests that batch conversions run on the injected scheduler
+func TestDocumentEngine_BatchConvert_SharedScheduler(t *testing.T) {
+	sched := scheduler.New(2)
+	defer sched.Close()
+
+	tasks := make([]BatchConversionTask, 3)
+	for i := range tasks {
+		tasks[i] = BatchConversionTask{
+			InputPath:  createTempDOCXFile(t),
+			OutputPath: filepath.Join(t.TempDir(), "output.html"),
+			Index:      i,
+		}
+	}
+
+	engine := NewDocumentEngine(sched, nil).(*DocumentEngine)
+	for i, result := range engine.BatchConvert(context.Background(), tasks) {
+		if result.Error != nil {
+			t.Errorf("Conversion %d failed: %v", i, result.Error)
+		}
+	}
+
+	metrics := sched.Metrics()
+	if metrics.Completed != uint64(len(tasks)) || metrics.Queued != 0 || metrics.Running != 0 {
+		t.Errorf("Expected %d completed tasks on the shared scheduler, got %+v", len(tasks), metrics)
+	}
+}
+
 // TestDocumentEngine_Convert_WithRealBrowser tests FR-07 with actual browser (integration test)
 func TestDocumentEngine_Convert_WithRealBrowser(t *testing.T) {
 	if testing.Short() {
@@ -154,11 +211,11 @@ func TestDocumentEngine_Convert_WithRealBrowser(t *testing.T) {
 	}
 
 	// This test requires Chrome/Chromium to be installed
-	browser, err := browser.NewHeadlessBrowser()
+	headless, err := browser.NewHeadlessBrowser(nil)
 	if err != nil {
 		t.Skipf("Skipping test: browser not available: %v", err)
 	}
-	defer browser.Close()
+	defer headless.Close()
 
 	tmpFile := createTempDOCXFile(t)
 	defer os.Remove(tmpFile)
@@ -166,10 +223,10 @@ func TestDocumentEngine_Convert_WithRealBrowser(t *testing.T) {
 	outputFile := filepath.Join(t.TempDir(), "output.pdf")
 	defer os.Remove(outputFile)
 
-	engine := NewDocumentEngine(browser).(*DocumentEngine)
+	engine := NewDocumentEngine(nil, func() (*browser.HeadlessBrowser, error) { return headless, nil }).(*DocumentEngine)
 
 	ctx := context.Background()
-	err = engine.Convert(ctx, tmpFile, outputFile)
+	err = engine.Convert(ctx, tmpFile, outputFile, domain.ConversionOptions{})
 	if err != nil {
 		t.Fatalf("FR-07: Conversion to PDF failed: %v", err)
 	}
@@ -202,16 +259,16 @@ func TestDocumentEngine_Convert_PreservesStructure(t *testing.T) {
 	outputFile := filepath.Join(t.TempDir(), "output.pdf")
 	defer os.Remove(outputFile)
 
-	browser, err := browser.NewHeadlessBrowser()
+	headless, err := browser.NewHeadlessBrowser(nil)
 	if err != nil {
 		t.Skipf("Skipping test: browser not available: %v", err)
 	}
-	defer browser.Close()
+	defer headless.Close()
 
-	engine := NewDocumentEngine(browser).(*DocumentEngine)
+	engine := NewDocumentEngine(nil, func() (*browser.HeadlessBrowser, error) { return headless, nil }).(*DocumentEngine)
 
 	ctx := context.Background()
-	err = engine.Convert(ctx, tmpFile, outputFile)
+	err = engine.Convert(ctx, tmpFile, outputFile, domain.ConversionOptions{})
 	if err != nil {
 		t.Fatalf("FR-07: Conversion failed: %v", err)
 	}
@@ -225,6 +282,58 @@ func TestDocumentEngine_Convert_PreservesStructure(t *testing.T) {
 // Helper functions
 
 // createTempDOCXFile creates a minimal valid DOCX file for testing
+
+// TestDocxParser_Parse_OmittedContent tests that content the renderer drops is counted
+func TestDocxParser_Parse_OmittedContent(t *testing.T) {
+	documentXML := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+<w:body>
+<w:p><w:r><w:drawing/></w:r><w:r><w:pict/></w:r></w:p>
+<w:p><w:r><w:t>Text</w:t></w:r><w:r><w:footnoteReference w:id="1"/></w:r><w:r><w:commentReference w:id="0"/></w:r></w:p>
+<w:sectPr><w:headerReference w:type="default"/><w:footerReference w:type="default"/></w:sectPr>
+</w:body>
+</w:document>`
+
+	doc, err := NewDocxParser().parseDocumentXML([]byte(documentXML))
+	if err != nil {
+		t.Fatalf("Failed to parse document.xml: %v", err)
+	}
+	expected := OmittedContent{Images: 2, Notes: 1, Comments: 1, HeadersFooters: 2}
+	if doc.Omitted != expected {
+		t.Errorf("Expected omitted content %+v, got %+v", expected, doc.Omitted)
+	}
+}
+// TestDocumentEngine_Validate_ZipBomb tests that highly compressed archive entries are rejected before decompression
+func TestDocumentEngine_Validate_ZipBomb(t *testing.T) {
+	buf := new(bytes.Buffer)
+	zipWriter := zip.NewWriter(buf)
+	for name, size := range map[string]int{"[Content_Types].xml": 0, "word/document.xml": 8 << 20} {
+		w, err := zipWriter.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+		if _, err := w.Write(bytes.Repeat([]byte(" "), size)); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	if err := zipWriter.Close(); err != nil {
+		t.Fatalf("Failed to close zip writer: %v", err)
+	}
+	tmpFile := filepath.Join(t.TempDir(), "bomb.docx")
+	if err := os.WriteFile(tmpFile, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("Failed to write DOCX file: %v", err)
+	}
+
+	engine := NewDocumentEngine(nil, nil)
+	if err := engine.Validate(context.Background(), tmpFile); !errors.Is(err, domain.ErrResourceLimit) {
+		t.Errorf("Expected ErrResourceLimit for a zip bomb, got %v", err)
+	}
+	err := engine.Convert(context.Background(), tmpFile, filepath.Join(t.TempDir(), "out.html"), domain.ConversionOptions{})
+	if !errors.Is(err, domain.ErrResourceLimit) {
+		t.Errorf("Expected Convert to reject the zip bomb, got %v", err)
+	}
+}
+
 func createTempDOCXFile(t *testing.T) string {
 	tmpFile := filepath.Join(t.TempDir(), "test.docx")
 
@@ -2,10 +2,9 @@ package document
 
 import (
 	"archive/zip"
-	"fmt"
 	"os"
-	"path/filepath"
-	"strings"
+
+	"github.com/eka026/File-Format-Converter/internal/domain"
 )
 
 // readDOCX reads a DOCX file and returns its content
@@ -13,46 +12,52 @@ func readDOCX(filePath string) ([]byte, error) {
 	return os.ReadFile(filePath)
 }
 
-// ValidateDOCX validates that a file is a valid DOCX file
+// ValidateDOCX validates that a file is a valid DOCX file within limits
 // This function consolidates all DOCX validation logic in one place
-func ValidateDOCX(filePath string) error {
+func ValidateDOCX(filePath string, limits domain.ResourceLimits) error {
 	// Check if file exists
 	fileInfo, err := os.Stat(filePath)
 	if err != nil {
-		return fmt.Errorf("file does not exist: %w", err)
+		return domain.Errorf(domain.ErrorCodeInputNotFound, "file does not exist: %w", err)
 	}
 
 	// Check if it's a regular file (not a directory)
 	if fileInfo.IsDir() {
-		return fmt.Errorf("path is a directory, not a file")
-	}
-
-	// Check file extension
-	ext := strings.ToLower(filepath.Ext(filePath))
-	if ext != ".docx" {
-		return fmt.Errorf("invalid file extension: expected .docx, got %s", ext)
+		return domain.Errorf(domain.ErrorCodeInputNotFound, "path is a directory, not a file")
 	}
 
 	// Validate DOCX file structure (DOCX files are ZIP archives)
 	// Open the file as a ZIP archive to verify it's a valid DOCX
 	reader, err := zip.OpenReader(filePath)
 	if err != nil {
-		return fmt.Errorf("invalid DOCX file structure: %w", err)
+		return domain.Errorf(domain.ErrorCodeCorruptInput, "invalid DOCX file structure: %w", err)
 	}
 	defer reader.Close()
 
+	// Reject decompression bombs before any entry is read
+	if err := limits.CheckZip(reader.File); err != nil {
+		return err
+	}
+
 	// Check for required DOCX structure files
-	// A valid DOCX must contain at least [Content_Types].xml
+	// A valid DOCX must contain [Content_Types].xml and the main document part.
+	// The extension is not checked: mislabelled files are routed here by content.
 	hasContentTypes := false
+	hasDocument := false
 	for _, file := range reader.File {
-		if file.Name == "[Content_Types].xml" {
+		switch file.Name {
+		case "[Content_Types].xml":
 			hasContentTypes = true
-			break
+		case "word/document.xml":
+			hasDocument = true
 		}
 	}
 
 	if !hasContentTypes {
-		return fmt.Errorf("invalid DOCX file: missing required [Content_Types].xml")
+		return domain.Errorf(domain.ErrorCodeCorruptInput, "invalid DOCX file: missing required [Content_Types].xml")
+	}
+	if !hasDocument {
+		return domain.Errorf(domain.ErrorCodeCorruptInput, "invalid DOCX file: missing required word/document.xml")
 	}
 
 	return nil
@@ -7,11 +7,23 @@ import (
 	"fmt"
 	"io"
 	"strings"
+
+	"github.com/eka026/File-Format-Converter/internal/domain"
 )
 
 // DocxDocument represents a parsed DOCX document
 type DocxDocument struct {
 	Elements []DocumentElement
+	// Omitted counts content found in the document that is not parsed
+	Omitted OmittedContent
+}
+
+// OmittedContent counts document content the parser skips
+type OmittedContent struct {
+	Images         int
+	Notes          int
+	Comments       int
+	HeadersFooters int
 }
 
 // DocumentElement represents any element in the document (paragraph, list, table)
@@ -84,19 +96,31 @@ type TableCell struct {
 }
 
 // DocxParser parses DOCX files
-type DocxParser struct{}
+type DocxParser struct {
+	limits domain.ResourceLimits
+}
 
-// NewDocxParser creates a new DOCX parser
+// NewDocxParser creates a new DOCX parser bounded by domain.DefaultResourceLimits
 func NewDocxParser() *DocxParser {
-	return &DocxParser{}
+	return &DocxParser{limits: domain.DefaultResourceLimits}
+}
+
+// WithLimits returns a parser bounded by limits instead
+func (p *DocxParser) WithLimits(limits domain.ResourceLimits) *DocxParser {
+	return &DocxParser{limits: limits}
 }
 
 // Parse parses a DOCX file from bytes
+// Archives whose entries exceed the parser's limits are rejected before any
+// entry is decompressed
 func (p *DocxParser) Parse(data []byte) (*DocxDocument, error) {
 	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
 	if err != nil {
 		return nil, fmt.Errorf("opening docx as zip: %w", err)
 	}
+	if err := p.limits.CheckZip(reader.File); err != nil {
+		return nil, err
+	}
 
 	// Find and read the main document XML
 	var docXML []byte
@@ -294,6 +318,17 @@ func (p *DocxParser) parseDocumentXML(xmlData []byte) (*DocxDocument, error) {
 				if inRun {
 					currentText.WriteString("\n")
 				}
+
+			// Content the renderer cannot reproduce is counted so the engine
+			// can report it
+			case "drawing", "pict": // Images, charts and shapes
+				doc.Omitted.Images++
+			case "footnoteReference", "endnoteReference":
+				doc.Omitted.Notes++
+			case "commentReference":
+				doc.Omitted.Comments++
+			case "headerReference", "footerReference":
+				doc.Omitted.HeadersFooters++
 			}
 
 		case xml.EndElement:
@@ -0,0 +1,126 @@
+package html
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/eka026/File-Format-Converter/internal/adapters/browser"
+	"github.com/eka026/File-Format-Converter/internal/domain"
+)
+
+// HTMLEngine implements IConverter for HTML documents using the headless browser.
+// It is the shared last hop of multi-step routes such as DOCX → HTML → PNG.
+type HTMLEngine struct {
+	browser *browser.HeadlessBrowser
+}
+
+// NewHTMLEngine creates a new HTML rendering engine
+func NewHTMLEngine(browser *browser.HeadlessBrowser) domain.IConverter {
+	return &HTMLEngine{
+		browser: browser,
+	}
+}
+
+// Convert renders an HTML file to PDF or PNG depending on the output extension
+func (e *HTMLEngine) Convert(ctx context.Context, input, output string, opts domain.ConversionOptions) error {
+	// Check for cancellation before starting
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	htmlData, err := os.ReadFile(input)
+	if err != nil {
+		return fmt.Errorf("reading html file: %w", err)
+	}
+
+	if e.browser == nil {
+		return domain.Errorf(domain.ErrorCodeBrowserMissing, "headless browser not available")
+	}
+
+	outputExt := strings.ToLower(filepath.Ext(output))
+	switch outputExt {
+	case ".pdf":
+		return e.browser.GeneratePDFFromHTML(ctx, string(htmlData), output, opts.Page)
+	case ".png":
+		return e.browser.GenerateScreenshotFromHTML(ctx, string(htmlData), output)
+	default:
+		return domain.Errorf(domain.ErrorCodeUnsupportedFormat, "unsupported output format: %s", outputExt)
+	}
+}
+
+// ConvertStream renders HTML read from input to PDF or PNG written to output
+func (e *HTMLEngine) ConvertStream(ctx context.Context, input io.Reader, output io.Writer, format domain.Format, opts domain.ConversionOptions) error {
+	// Check for cancellation before starting
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	htmlData, err := io.ReadAll(input)
+	if err != nil {
+		return fmt.Errorf("reading html data: %w", err)
+	}
+
+	if e.browser == nil {
+		return domain.Errorf(domain.ErrorCodeBrowserMissing, "headless browser not available")
+	}
+
+	var rendered []byte
+	switch format {
+	case domain.FormatPDF:
+		rendered, err = e.browser.GeneratePDFFromHTMLBytes(ctx, string(htmlData), opts.Page)
+	case domain.FormatPNG:
+		rendered, err = e.browser.GenerateScreenshotFromHTMLBytes(ctx, string(htmlData))
+	default:
+		return domain.Errorf(domain.ErrorCodeUnsupportedFormat, "unsupported output format: %s", format)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = output.Write(rendered)
+	return err
+}
+
+// Validate checks that the input is a readable UTF-8 text file
+func (e *HTMLEngine) Validate(ctx context.Context, file string) error {
+	// Check for cancellation
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	fileInfo, err := os.Stat(file)
+	if err != nil {
+		return domain.Errorf(domain.ErrorCodeInputNotFound, "file does not exist: %w", err)
+	}
+	if fileInfo.IsDir() {
+		return domain.Errorf(domain.ErrorCodeInputNotFound, "path is a directory, not a file")
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("cannot read file: %w", err)
+	}
+	if !utf8.Valid(data) {
+		return domain.Errorf(domain.ErrorCodeCorruptInput, "invalid HTML file: content is not valid UTF-8 text")
+	}
+
+	return nil
+}
+
+// Capabilities reports the conversions this engine can perform
+func (e *HTMLEngine) Capabilities() domain.Capabilities {
+	return domain.Capabilities{
+		Name:    "html",
+		Version: "1",
+		Conversions: []domain.ConversionEdge{
+			{From: domain.FileTypeHTML, To: domain.FormatPDF, Cost: domain.EdgeCostBrowser},
+			{From: domain.FileTypeHTML, To: domain.FormatPNG, Cost: domain.EdgeCostBrowser},
+		},
+		Options: domain.PageOptionDescriptors(),
+	}
+}
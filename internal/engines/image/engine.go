@@ -1,89 +1,316 @@
 package image
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"image"
+	"image/png"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
-	"sync"
 
 	"github.com/HugoSmits86/nativewebp"
 	"github.com/disintegration/imaging"
 	"github.com/eka026/File-Format-Converter/internal/domain"
-	"golang.org/x/image/webp"
+	"github.com/eka026/File-Format-Converter/internal/scheduler"
+	"golang.org/x/image/bmp"
+	"golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp" // registers the WebP decoder with image.Decode
 )
 
 // ImageEngine implements IConverter for image format conversions
 type ImageEngine struct {
-	workerPool *WorkerPool
+	scheduler *scheduler.Scheduler
+	// quality is the JPEG quality of conversions that set none
+	quality int
 }
 
 // NewImageEngine creates a new image conversion engine
-func NewImageEngine(workerPool *WorkerPool) domain.IConverter {
+// Batch conversions run on the given scheduler; nil uses scheduler.Default()
+// settings set the default JPEG quality; the zero value uses the encoder's default
+func NewImageEngine(sched *scheduler.Scheduler, settings domain.EngineSettings) domain.IConverter {
+	if sched == nil {
+		sched = scheduler.Default()
+	}
 	return &ImageEngine{
-		workerPool: workerPool,
+		scheduler: sched,
+		quality:   settings.DefaultQuality,
 	}
 }
 
 // Convert converts an image from one format to another
-func (e *ImageEngine) Convert(ctx context.Context, input, output string) error {
+func (e *ImageEngine) Convert(ctx context.Context, input, output string, opts domain.ConversionOptions) error {
 	// Check for cancellation before starting
 	if ctx.Err() != nil {
 		return ctx.Err()
 	}
 
-	// Load image - handle WebP input specially
-	var img image.Image
-	var err error
+	// Load image - the decoder is chosen from the file content rather than
+	// its extension, so mislabelled files (e.g. a PNG saved as .jpg) still decode.
+	// WebP, BMP and TIFF decoding is provided by golang.org/x/image's
+	// registered formats.
+	decoded := domain.StartStage(ctx, domain.StageDecode)
+	img, meta, err := open(ctx, input)
+	decoded()
+	if err != nil {
+		return err
+	}
+
+	// Check for cancellation after loading
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	// Determine output format from file extension
+	format, ok := domain.FormatFromExtension(filepath.Ext(output))
+	if !ok {
+		return domain.Errorf(domain.ErrorCodeUnsupportedFormat, "unsupported output format: %s", strings.ToLower(filepath.Ext(output)))
+	}
+
+	// Transform before creating the output, so a rejected step leaves no file
+	img, err = applyTransforms(ctx, orient(img, meta, opts.Image), opts.Image.Transforms)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(output)
+	if err != nil {
+		return domain.NewError(domain.ErrorCodeOutputNotWritable, err)
+	}
+	if err := e.encode(ctx, file, img, meta, format, opts.Image); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// ConvertStream decodes an image from input and encodes it to output in the given format
+func (e *ImageEngine) ConvertStream(ctx context.Context, input io.Reader, output io.Writer, format domain.Format, opts domain.ConversionOptions) error {
+	// Check for cancellation before starting
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	decoded := domain.StartStage(ctx, domain.StageDecode)
+	img, meta, err := decode(ctx, input)
+	decoded()
+	if err != nil {
+		return err
+	}
 
-	inputExt := strings.ToLower(filepath.Ext(input))
-	if inputExt == ".webp" {
-		// Use golang.org/x/image/webp for WebP decoding
-		file, err := os.Open(input)
-		if err != nil {
+	// Check for cancellation after decoding
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	img, err = applyTransforms(ctx, orient(img, meta, opts.Image), opts.Image.Transforms)
+	if err != nil {
+		return err
+	}
+	return e.encode(ctx, output, img, meta, format, opts.Image)
+}
+
+// encode resizes img as requested and writes it to w in the given format
+// with the metadata of the input that opts keeps, reporting the image and any
+// transparency lost to JPEG
+func (e *ImageEngine) encode(ctx context.Context, w io.Writer, img image.Image, meta imageMetadata, format domain.Format, opts domain.ImageOptions) error {
+	domain.RecordCounts(ctx, domain.ContentCounts{Images: 1})
+	if opaque, ok := img.(interface{ Opaque() bool }); ok && format == domain.FormatJPEG && !opaque.Opaque() {
+		domain.RecordFidelityWarning(ctx, domain.FidelityTransparencyFlattened,
+			"transparent pixels were flattened, as JPEG has no transparency")
+	}
+
+	settings := e.encoderSettings(format, opts)
+	meta = meta.filter(opts.Metadata, !opts.IgnoreOrientation)
+	defer domain.StartStage(ctx, domain.StageEncode)()
+	if meta.empty() {
+		if err := encode(w, resize(img, opts), settings); err != nil {
 			return err
 		}
-		defer file.Close()
-		img, err = webp.Decode(file)
-		if err != nil {
+	} else {
+		// Metadata is spliced into the encoded file, so it is buffered first
+		var encoded bytes.Buffer
+		if err := encode(&encoded, resize(img, opts), settings); err != nil {
 			return err
 		}
-	} else {
-		// Use imaging library for other formats
-		img, err = imaging.Open(input)
-		if err != nil {
+		if _, err := w.Write(embedMetadata(encoded.Bytes(), format, meta)); err != nil {
 			return err
 		}
 	}
+	domain.RecordEncoderSettings(ctx, settings)
+	return nil
+}
 
-	// Check for cancellation after loading
-	if ctx.Err() != nil {
-		return ctx.Err()
+// orient turns img upright as its EXIF orientation says, unless opts ignore it
+func orient(img image.Image, meta imageMetadata, opts domain.ImageOptions) image.Image {
+	if opts.IgnoreOrientation {
+		return img
 	}
+	return autoOrient(img, meta.orientation())
+}
 
-	// Determine output format from file extension
-	outputExt := strings.ToLower(filepath.Ext(output))
+// defaultQuality returns the JPEG quality used when the options set none
+func (e *ImageEngine) defaultQuality() int {
+	if e.quality == 0 {
+		return defaultJPEGQuality
+	}
+	return e.quality
+}
 
-	switch outputExt {
-	case ".webp":
-		// Use nativewebp for WebP encoding (lossless)
-		file, err := os.Create(output)
-		if err != nil {
-			return err
+// encoderSettings resolves the encoder settings of format from opts, filling
+// in the defaults
+func (e *ImageEngine) encoderSettings(format domain.Format, opts domain.ImageOptions) domain.EncoderSettings {
+	settings := domain.EncoderSettings{Format: format}
+	switch format {
+	case domain.FormatJPEG:
+		settings.Quality = opts.Quality
+		if settings.Quality == 0 {
+			settings.Quality = e.defaultQuality()
+		}
+		settings.Subsampling = opts.Subsampling
+		if settings.Subsampling == "" {
+			settings.Subsampling = domain.Subsampling420
+		}
+	case domain.FormatPNG:
+		settings.Compression = opts.Compression
+		if settings.Compression == "" {
+			settings.Compression = domain.CompressionDefault
+		}
+	case domain.FormatWEBP:
+		// A quality selects lossy encoding
+		settings.Quality = opts.Quality
+		settings.Extended = opts.WebPExtended
+	}
+	return settings
+}
+
+// ValidateOptions rejects image options that the output format cannot honour
+func (e *ImageEngine) ValidateOptions(opts domain.ConversionOptions, format domain.Format) error {
+	if opts.Image.Quality != 0 && format != domain.FormatJPEG && format != domain.FormatWEBP {
+		return domain.Errorf(domain.ErrorCodeInvalidOptions, "quality is only supported for JPEG and WebP output, not %s", format)
+	}
+	if opts.Image.Subsampling != "" && format != domain.FormatJPEG {
+		return domain.Errorf(domain.ErrorCodeInvalidOptions, "chroma subsampling is only supported for JPEG output, not %s", format)
+	}
+	if opts.Image.Compression != "" && format != domain.FormatPNG {
+		return domain.Errorf(domain.ErrorCodeInvalidOptions, "compression level is only supported for PNG output, not %s", format)
+	}
+	if opts.Image.WebPExtended && format != domain.FormatWEBP {
+		return domain.Errorf(domain.ErrorCodeInvalidOptions, "the extended container is only supported for WebP output, not %s", format)
+	}
+	if opts.Image.Metadata != "" && opts.Image.Metadata != domain.MetadataStrip && !carriesMetadata(format) {
+		return domain.Errorf(domain.ErrorCodeInvalidOptions, "metadata can only be kept in JPEG, PNG and WebP output, not %s", format)
+	}
+	return nil
+}
+
+// resize shrinks img to fit the requested bounds, preserving the aspect ratio.
+// Images that already fit are returned unchanged.
+func resize(img image.Image, opts domain.ImageOptions) image.Image {
+	if opts.Width <= 0 && opts.Height <= 0 {
+		return img
+	}
+
+	// An unconstrained dimension is bounded by the image's own size
+	width, height := opts.Width, opts.Height
+	if width <= 0 {
+		width = img.Bounds().Dx()
+	}
+	if height <= 0 {
+		height = img.Bounds().Dy()
+	}
+	return imaging.Fit(img, width, height, imaging.Lanczos)
+}
+
+// encode writes img to w with the given encoder settings
+func encode(w io.Writer, img image.Image, settings domain.EncoderSettings) error {
+	switch settings.Format {
+	case domain.FormatWEBP:
+		if settings.Quality > 0 {
+			return encodeWebPLossy(w, img, settings.Quality, settings.Extended)
 		}
-		defer file.Close()
-		return nativewebp.Encode(file, img, nil)
-	case ".jpeg", ".jpg":
-		// JPEG format - imaging.Save will handle this automatically
-		return imaging.Save(imaging.Clone(img), output)
-	case ".png":
-		// PNG format - imaging.Save will handle this automatically
-		return imaging.Save(imaging.Clone(img), output)
+		// Use nativewebp for WebP encoding (lossless)
+		return nativewebp.Encode(w, img, &nativewebp.Options{UseExtendedFormat: settings.Extended})
+	case domain.FormatJPEG:
+		return encodeJPEG(w, img, settings.Quality, settings.Subsampling)
+	case domain.FormatPNG:
+		encoder := png.Encoder{CompressionLevel: pngCompressionLevels[settings.Compression]}
+		return encoder.Encode(w, img)
+	case domain.FormatGIF:
+		return encodeGIF(w, img)
+	case domain.FormatBMP:
+		return bmp.Encode(w, img)
+	case domain.FormatTIFF:
+		// Lossless, and compressed well for photos by the predictor
+		return tiff.Encode(w, img, &tiff.Options{Compression: tiff.Deflate, Predictor: true})
 	default:
-		// Use imaging library for other formats (auto-detects from extension)
-		return imaging.Save(imaging.Clone(img), output)
+		return domain.Errorf(domain.ErrorCodeUnsupportedFormat, "unsupported output format: %s", settings.Format)
+	}
+}
+
+// pngCompressionLevels maps compression levels to image/png's
+var pngCompressionLevels = map[domain.CompressionLevel]png.CompressionLevel{
+	domain.CompressionDefault: png.DefaultCompression,
+	domain.CompressionNone:    png.NoCompression,
+	domain.CompressionFast:    png.BestSpeed,
+	domain.CompressionBest:    png.BestCompression,
+}
+
+// open decodes the image file at path within the resource limits carried by ctx
+func open(ctx context.Context, path string) (image.Image, imageMetadata, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, imageMetadata{}, decodeError(err)
 	}
+	defer file.Close()
+	return decode(ctx, file)
+}
+
+// decode reads the image dimensions from the header and checks them against
+// the resource limits carried by ctx before decoding the pixels and reading
+// the metadata
+func decode(ctx context.Context, r io.Reader) (image.Image, imageMetadata, error) {
+	var header bytes.Buffer
+	config, _, err := image.DecodeConfig(io.TeeReader(r, &header))
+	if err != nil {
+		return nil, imageMetadata{}, decodeError(err)
+	}
+	if err := domain.ResourceLimitsFromContext(ctx).CheckImageSize(config.Width, config.Height); err != nil {
+		return nil, imageMetadata{}, err
+	}
+
+	// Metadata may follow the image data, so the whole file is kept
+	data, err := io.ReadAll(io.MultiReader(&header, r))
+	if err != nil {
+		return nil, imageMetadata{}, decodeError(err)
+	}
+	img, err := imaging.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, imageMetadata{}, decodeError(err)
+	}
+	return img, readMetadata(data), nil
+}
+
+// decodeError classifies a failure to open or decode an input image.
+// Files that cannot be read keep their I/O error, as do already classified
+// failures such as an exceeded size limit; anything else is corrupt input.
+func decodeError(err error) error {
+	var conversionErr *domain.ConversionError
+	if errors.As(err, &conversionErr) {
+		return err
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return domain.NewError(domain.ErrorCodeInputNotFound, err)
+	}
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return err
+	}
+	return domain.NewError(domain.ErrorCodeCorruptInput, err)
 }
 
 // Validate checks if the input file is a valid image
@@ -93,21 +320,39 @@ func (e *ImageEngine) Validate(ctx context.Context, file string) error {
 		return ctx.Err()
 	}
 
-	// Handle WebP files specially
-	ext := strings.ToLower(filepath.Ext(file))
-	if ext == ".webp" {
-		f, err := os.Open(file)
-		if err != nil {
-			return err
+	// Decode using the content-sniffing decoder (covers JPEG, PNG, WebP, GIF,
+	// BMP and TIFF)
+	_, _, err := open(ctx, file)
+	return err
+}
+
+// Capabilities reports every image-to-image conversion this engine can perform
+func (e *ImageEngine) Capabilities() domain.Capabilities {
+	inputs := []domain.FileType{domain.FileTypeJPEG, domain.FileTypePNG, domain.FileTypeWEBP, domain.FileTypeGIF, domain.FileTypeBMP, domain.FileTypeTIFF}
+	outputs := []domain.Format{domain.FormatPNG, domain.FormatJPEG, domain.FormatWEBP, domain.FormatGIF, domain.FormatBMP, domain.FormatTIFF}
+
+	edges := make([]domain.ConversionEdge, 0, len(inputs)*len(outputs))
+	for _, from := range inputs {
+		for _, to := range outputs {
+			edges = append(edges, domain.ConversionEdge{From: from, To: to, Cost: domain.EdgeCostInProcess})
 		}
-		defer f.Close()
-		_, err = webp.Decode(f)
-		return err
 	}
-
-	// Use imaging library for other formats
-	_, err := imaging.Open(file)
-	return err
+	return domain.Capabilities{
+		Name:        "image",
+		Version:     "3",
+		Conversions: edges,
+		Options: []domain.OptionDescriptor{
+			{Name: "quality", Type: "int", Description: "JPEG quality (1-100); for WebP, encodes lossily at that quality instead of losslessly", Default: fmt.Sprintf("%d for JPEG; lossless for WebP", e.defaultQuality()), Formats: []domain.Format{domain.FormatJPEG, domain.FormatWEBP}},
+			{Name: "width", Type: "int", Description: "Maximum output width in pixels (0 keeps the original)", Default: "0"},
+			{Name: "height", Type: "int", Description: "Maximum output height in pixels (0 keeps the original)", Default: "0"},
+			{Name: "subsampling", Type: "enum", Description: "JPEG chroma subsampling", Default: string(domain.Subsampling420), Values: []string{string(domain.Subsampling444), string(domain.Subsampling422), string(domain.Subsampling420)}, Formats: []domain.Format{domain.FormatJPEG}},
+			{Name: "compression", Type: "enum", Description: "PNG compression level", Default: string(domain.CompressionDefault), Values: []string{string(domain.CompressionDefault), string(domain.CompressionNone), string(domain.CompressionFast), string(domain.CompressionBest)}, Formats: []domain.Format{domain.FormatPNG}},
+			{Name: "webpExtended", Type: "bool", Description: "Write WebP in the extended (VP8X) container", Default: "false", Formats: []domain.Format{domain.FormatWEBP}},
+			{Name: "metadata", Type: "enum", Description: "EXIF, XMP and ICC metadata to carry over: strip all of it, keep all of it, or keep only the copyright and colour profile", Default: string(domain.MetadataStrip), Values: []string{string(domain.MetadataStrip), string(domain.MetadataKeep), string(domain.MetadataCopyright)}, Formats: []domain.Format{domain.FormatJPEG, domain.FormatPNG, domain.FormatWEBP}},
+			{Name: "ignoreOrientation", Type: "bool", Description: "Keep the stored pixel orientation instead of turning the image upright as its EXIF orientation says", Default: "false"},
+			{Name: "transforms", Type: "string", Description: "Resize, crop, rotate and flip steps applied in order before the width and height bound, such as rotate=90 or resize=800x600:fill"},
+		},
+	}
 }
 
 // BatchConversionTask represents a single conversion task in a batch
@@ -115,6 +360,7 @@ type BatchConversionTask struct {
 	InputPath  string
 	OutputPath string
 	Index      int
+	Options    domain.ConversionOptions
 }
 
 // BatchConversionResult represents the result of a batch conversion task
@@ -123,51 +369,33 @@ type BatchConversionResult struct {
 	Error error
 }
 
-// BatchConvert processes multiple image conversions in parallel using the worker pool
-// It takes a slice of input/output path pairs and processes them concurrently
-// utilizing all available CPU cores through the worker pool
-func (e *ImageEngine) BatchConvert(tasks []BatchConversionTask) []BatchConversionResult {
+// BatchConvert processes multiple image conversions in parallel on the shared scheduler
+// It takes a slice of input/output path pairs and processes them concurrently,
+// bounded by the scheduler's worker pool and the image engine's concurrency limit
+func (e *ImageEngine) BatchConvert(ctx context.Context, tasks []BatchConversionTask) []BatchConversionResult {
 	if len(tasks) == 0 {
 		return nil
 	}
 
-	results := make([]BatchConversionResult, len(tasks))
-	var wg sync.WaitGroup
-	var mu sync.Mutex
-
-	// Submit all tasks to the worker pool
-	for _, task := range tasks {
-		wg.Add(1)
+	// Submit all tasks before waiting so they are queued together
+	// Cancelling ctx fails the tasks not yet started and is passed on to
+	// the running ones
+	handles := make([]*scheduler.Handle, len(tasks))
+	for i, task := range tasks {
 		task := task // Capture loop variable
-
-		e.workerPool.Submit(func() {
-			defer wg.Done()
-
-			// Perform the conversion with background context
-			// Note: For batch operations, we use background context as cancellation
-			// should be handled at the batch level, not individual task level
-			err := e.Convert(context.Background(), task.InputPath, task.OutputPath)
-
-			// Store result thread-safely
-			mu.Lock()
-			results[task.Index] = BatchConversionResult{
-				Index: task.Index,
-				Error: err,
-			}
-			mu.Unlock()
+		handles[i] = e.scheduler.Submit(ctx, "image", scheduler.PriorityNormal, func(ctx context.Context) error {
+			return e.Convert(ctx, task.InputPath, task.OutputPath, task.Options)
 		})
 	}
 
-	// Wait for all conversions to complete
-	wg.Wait()
+	results := make([]BatchConversionResult, len(tasks))
+	for i, task := range tasks {
+		results[task.Index] = BatchConversionResult{
+			Index: task.Index,
+			Error: handles[i].Wait(),
+		}
+	}
 
 	return results
 }
 
-// Close closes the worker pool to prevent goroutine leaks
-func (e *ImageEngine) Close() {
-	if e.workerPool != nil {
-		e.workerPool.Close()
-	}
-}
-
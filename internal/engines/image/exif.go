@@ -0,0 +1,116 @@
+package image
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// EXIF tags of the first image file directory that conversions read
+const (
+	exifTagOrientation = 0x0112
+	exifTagCopyright   = 0x8298
+)
+
+// EXIF value types
+const (
+	exifTypeASCII = 2
+	exifTypeShort = 3
+)
+
+// exifEntry finds tag in the first image file directory of TIFF-structured
+// EXIF data, returning the byte order and the offset of its 12-byte entry
+func exifEntry(exif []byte, tag uint16) (binary.ByteOrder, int, bool) {
+	if len(exif) < 8 {
+		return nil, 0, false
+	}
+	var order binary.ByteOrder
+	switch string(exif[:4]) {
+	case "II*\x00":
+		order = binary.LittleEndian
+	case "MM\x00*":
+		order = binary.BigEndian
+	default:
+		return nil, 0, false
+	}
+
+	ifd := int(order.Uint32(exif[4:]))
+	if ifd < 8 || ifd+2 > len(exif) {
+		return nil, 0, false
+	}
+	count := int(order.Uint16(exif[ifd:]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + 12*i
+		if entry+12 > len(exif) {
+			break
+		}
+		if order.Uint16(exif[entry:]) == tag {
+			return order, entry, true
+		}
+	}
+	return nil, 0, false
+}
+
+// exifOrientation returns the orientation (1-8) of EXIF data, or 1 when it
+// has none
+func exifOrientation(exif []byte) int {
+	order, entry, ok := exifEntry(exif, exifTagOrientation)
+	if !ok || order.Uint16(exif[entry+2:]) != exifTypeShort {
+		return 1
+	}
+	if orientation := int(order.Uint16(exif[entry+8:])); orientation >= 1 && orientation <= 8 {
+		return orientation
+	}
+	return 1
+}
+
+// resetEXIFOrientation returns a copy of EXIF data with the orientation set
+// to upright
+func resetEXIFOrientation(exif []byte) []byte {
+	order, entry, ok := exifEntry(exif, exifTagOrientation)
+	if !ok || order.Uint16(exif[entry+2:]) != exifTypeShort {
+		return exif
+	}
+	reset := bytes.Clone(exif)
+	order.PutUint16(reset[entry+8:], 1)
+	return reset
+}
+
+// exifCopyright returns the copyright notice of EXIF data, or "" when it has
+// none
+func exifCopyright(exif []byte) string {
+	order, entry, ok := exifEntry(exif, exifTagCopyright)
+	if !ok || order.Uint16(exif[entry+2:]) != exifTypeASCII {
+		return ""
+	}
+	// Values of up to 4 bytes are stored in the entry instead of an offset
+	length := int(order.Uint32(exif[entry+4:]))
+	value := entry + 8
+	if length > 4 {
+		value = int(order.Uint32(exif[entry+8:]))
+	}
+	if length < 0 || value < 0 || value+length > len(exif) {
+		return ""
+	}
+	notice, _, _ := bytes.Cut(exif[value:value+length], []byte{0})
+	return string(bytes.TrimSpace(notice))
+}
+
+// copyrightEXIF returns EXIF data holding nothing but a copyright notice
+func copyrightEXIF(notice string) []byte {
+	value := append([]byte(notice), 0)
+	exif := []byte("II*\x00")
+	exif = binary.LittleEndian.AppendUint32(exif, 8)
+	exif = binary.LittleEndian.AppendUint16(exif, 1)
+	exif = binary.LittleEndian.AppendUint16(exif, exifTagCopyright)
+	exif = binary.LittleEndian.AppendUint16(exif, exifTypeASCII)
+	exif = binary.LittleEndian.AppendUint32(exif, uint32(len(value)))
+	if len(value) <= 4 {
+		exif = append(exif, make([]byte, 4)...)
+		copy(exif[len(exif)-4:], value)
+		return binary.LittleEndian.AppendUint32(exif, 0)
+	}
+	// The value follows the directory and its next directory offset
+	exif = binary.LittleEndian.AppendUint32(exif, uint32(len(exif)+8))
+	exif = binary.LittleEndian.AppendUint32(exif, 0)
+	return append(exif, value...)
+}
@@ -0,0 +1,29 @@
+package image
+
+import (
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"io"
+)
+
+// encodeGIF writes img as a single-frame GIF. Paletted images, such as
+// decoded GIFs, keep their palette; others are dithered to the Plan 9
+// palette, or to the web-safe one and a transparent entry when they have
+// transparent pixels.
+func encodeGIF(w io.Writer, img image.Image) error {
+	if paletted, ok := img.(*image.Paletted); ok && len(paletted.Palette) <= 256 {
+		return gif.Encode(w, paletted, nil)
+	}
+
+	colors := palette.Plan9
+	if opaque, ok := img.(interface{ Opaque() bool }); ok && !opaque.Opaque() {
+		colors = append(color.Palette{color.Transparent}, palette.WebSafe...)
+	}
+	bounds := img.Bounds()
+	paletted := image.NewPaletted(bounds, colors)
+	draw.FloydSteinberg.Draw(paletted, bounds, img, bounds.Min)
+	return gif.Encode(w, paletted, nil)
+}
@@ -3,6 +3,7 @@ package image
 import (
 	"github.com/eka026/File-Format-Converter/internal/domain"
 	"github.com/eka026/File-Format-Converter/internal/ports"
+	"github.com/eka026/File-Format-Converter/internal/scheduler"
 )
 
 // ImageEngineLegacy implements ports.IConverter for image format conversions (legacy/unused)
@@ -10,17 +11,17 @@ import (
 // The active implementation is in engine.go which implements domain.IConverter
 type ImageEngineLegacy struct {
 	workerCount int
-	workerPool  *WorkerPool
+	scheduler   *scheduler.Scheduler
 }
 
 // NewImageEngineLegacy creates a new image conversion engine (legacy/unused)
 func NewImageEngineLegacy(
 	workerCount int,
-	workerPool *WorkerPool,
+	sched *scheduler.Scheduler,
 ) ports.IConverter {
 	return &ImageEngineLegacy{
 		workerCount: workerCount,
-		workerPool:  workerPool,
+		scheduler:   sched,
 	}
 }
 
@@ -54,8 +55,8 @@ func (e *ImageEngineLegacy) BatchProcess(images [][]byte) [][]byte {
 	return nil
 }
 
-// initWorkerPool initializes the worker pool
-func (e *ImageEngineLegacy) initWorkerPool() *WorkerPool {
+// initScheduler initializes the scheduler
+func (e *ImageEngineLegacy) initScheduler() *scheduler.Scheduler {
 	// Implementation will be added
 	return nil
 }
@@ -1,11 +1,19 @@
 package image
 
 import (
+	"bytes"
 	"context"
+	"encoding/binary"
+	"errors"
 	"image"
 	"image/color"
+	"image/draw"
+	"image/gif"
 	"image/jpeg"
 	"image/png"
+	"io"
+	"math"
+	"math/rand"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -13,6 +21,9 @@ import (
 	"testing"
 
 	"github.com/disintegration/imaging"
+	"github.com/eka026/File-Format-Converter/internal/domain"
+	"github.com/eka026/File-Format-Converter/internal/scheduler"
+	"golang.org/x/image/webp"
 )
 
 // TestImageEngine_Validate_JPEG tests FR-08: The system shall accept common image formats (JPEG) as input
@@ -59,6 +70,15 @@ func TestImageEngine_Validate_InvalidFile(t *testing.T) {
 	if err == nil {
 		t.Error("FR-08: Expected invalid file to be rejected, but validation passed")
 	}
+	if !errors.Is(err, domain.ErrCorruptInput) {
+		t.Errorf("Expected domain.ErrCorruptInput, got %v", err)
+	}
+
+	// A missing file is reported separately from a corrupt one
+	err = engine.Validate(ctx, filepath.Join(t.TempDir(), "missing.png"))
+	if !errors.Is(err, domain.ErrInputNotFound) {
+		t.Errorf("Expected domain.ErrInputNotFound, got %v", err)
+	}
 }
 
 
@@ -73,7 +93,7 @@ func TestImageEngine_Convert_ToPNG(t *testing.T) {
 	engine := createTestImageEngine(t)
 	ctx := context.Background()
 
-	err := engine.Convert(ctx, tmpFile, outputFile)
+	err := engine.Convert(ctx, tmpFile, outputFile, domain.ConversionOptions{})
 	if err != nil {
 		t.Fatalf("FR-09: Conversion to PNG failed: %v", err)
 	}
@@ -101,7 +121,7 @@ func TestImageEngine_Convert_ToJPEG(t *testing.T) {
 	engine := createTestImageEngine(t)
 	ctx := context.Background()
 
-	err := engine.Convert(ctx, tmpFile, outputFile)
+	err := engine.Convert(ctx, tmpFile, outputFile, domain.ConversionOptions{})
 	if err != nil {
 		t.Fatalf("FR-09: Conversion to JPEG failed: %v", err)
 	}
@@ -118,6 +138,615 @@ func TestImageEngine_Convert_ToJPEG(t *testing.T) {
 	}
 }
 
+// TestImageEngine_Convert_DefaultQuality tests that the configured quality applies to JPEG conversions that set none
+func TestImageEngine_Convert_DefaultQuality(t *testing.T) {
+	input := createTempPNGFile(t)
+	dir := t.TempDir()
+	convert := func(engine domain.IConverter, name string, opts domain.ConversionOptions) int64 {
+		output := filepath.Join(dir, name)
+		if err := engine.Convert(context.Background(), input, output, opts); err != nil {
+			t.Fatalf("Conversion failed: %v", err)
+		}
+		info, err := os.Stat(output)
+		if err != nil {
+			t.Fatalf("Failed to stat output: %v", err)
+		}
+		return info.Size()
+	}
+
+	engine := NewImageEngine(nil, domain.EngineSettings{DefaultQuality: 10})
+	low := convert(engine, "low.jpeg", domain.ConversionOptions{})
+	high := convert(engine, "high.jpeg", domain.ConversionOptions{Image: domain.ImageOptions{Quality: 95}})
+	if low >= high {
+		t.Errorf("Expected the default quality of 10 to give a smaller file than quality 95, got %d and %d bytes", low, high)
+	}
+}
+
+// TestImageEngine_Capabilities_DefaultQuality tests that the quality
+// descriptor reports the configured JPEG default and lossless WebP
+func TestImageEngine_Capabilities_DefaultQuality(t *testing.T) {
+	for _, tt := range []struct {
+		quality int
+		want    string
+	}{{0, "95 for JPEG; lossless for WebP"}, {80, "80 for JPEG; lossless for WebP"}} {
+		engine := NewImageEngine(nil, domain.EngineSettings{DefaultQuality: tt.quality})
+		var got string
+		for _, option := range engine.Capabilities().Options {
+			if option.Name == "quality" {
+				got = option.Default
+			}
+		}
+		if got != tt.want {
+			t.Errorf("DefaultQuality %d: expected quality default %q, got %q", tt.quality, tt.want, got)
+		}
+	}
+}
+
+// TestImageEngine_ConvertStream_ToJPEG tests in-memory conversion without touching the filesystem
+func TestImageEngine_ConvertStream_ToJPEG(t *testing.T) {
+	var input bytes.Buffer
+	if err := png.Encode(&input, image.NewRGBA(image.Rect(0, 0, 32, 32))); err != nil {
+		t.Fatalf("Failed to encode test PNG: %v", err)
+	}
+
+	engine := createTestImageEngine(t)
+	var output bytes.Buffer
+	if err := engine.ConvertStream(context.Background(), &input, &output, domain.FormatJPEG, domain.ConversionOptions{}); err != nil {
+		t.Fatalf("Stream conversion to JPEG failed: %v", err)
+	}
+
+	if _, err := jpeg.Decode(&output); err != nil {
+		t.Errorf("Stream output is not a valid JPEG: %v", err)
+	}
+}
+
+// TestImageEngine_Convert_PixelLimit tests that images above the pixel limit are rejected before decoding
+func TestImageEngine_Convert_PixelLimit(t *testing.T) {
+	engine := createTestImageEngine(t)
+	input := createTempPNGFile(t)
+	output := filepath.Join(t.TempDir(), "output.jpg")
+	ctx := domain.ContextWithResourceLimits(context.Background(), domain.ResourceLimits{MaxImagePixels: 100 * 99})
+
+	err := engine.Convert(ctx, input, output, domain.ConversionOptions{})
+	var limitErr *domain.LimitError
+	if !errors.Is(err, domain.ErrResourceLimit) || !errors.As(err, &limitErr) || limitErr.Limit != "MaxImagePixels" {
+		t.Fatalf("Expected a MaxImagePixels limit error, got %v", err)
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Error("Expected no output for a rejected image")
+	}
+
+	data, err := os.ReadFile(input)
+	if err != nil {
+		t.Fatalf("Failed to read test image: %v", err)
+	}
+	ctx = domain.ContextWithResourceLimits(context.Background(), domain.ResourceLimits{MaxImageDimension: 64})
+	if err := engine.ConvertStream(ctx, bytes.NewReader(data), io.Discard, domain.FormatJPEG, domain.ConversionOptions{}); !errors.Is(err, domain.ErrResourceLimit) {
+		t.Errorf("Expected the stream to exceed MaxImageDimension, got %v", err)
+	}
+}
+
+// TestImageEngine_Convert_Transforms tests that transforms run in order before the width and height bound
+func TestImageEngine_Convert_Transforms(t *testing.T) {
+	engine := createTestImageEngine(t)
+	input := createTempPNGFile(t)
+
+	tests := []struct {
+		name          string
+		opts          domain.ImageOptions
+		width, height int
+	}{
+		{"aspect crop", domain.ImageOptions{Transforms: []domain.ImageTransform{{Kind: domain.TransformCrop, Aspect: "16:9"}}}, 100, 56},
+		{"crop then rotate", domain.ImageOptions{Transforms: []domain.ImageTransform{
+			{Kind: domain.TransformCrop, Width: 40, Height: 20, X: 10, Y: 10},
+			{Kind: domain.TransformRotate, Angle: 90},
+		}}, 20, 40},
+		{"fill", domain.ImageOptions{Transforms: []domain.ImageTransform{{Kind: domain.TransformResize, Width: 50, Height: 20, Mode: domain.ResizeFill}}}, 50, 20},
+		{"exact", domain.ImageOptions{Transforms: []domain.ImageTransform{{Kind: domain.TransformResize, Width: 300, Mode: domain.ResizeExact, Filter: domain.FilterNearest}}}, 300, 300},
+		{"fit never enlarges", domain.ImageOptions{Transforms: []domain.ImageTransform{{Kind: domain.TransformResize, Width: 300}}}, 100, 100},
+		{"free rotation", domain.ImageOptions{Transforms: []domain.ImageTransform{{Kind: domain.TransformRotate, Angle: 45}}}, 141, 141},
+		{"bounded after", domain.ImageOptions{Width: 50, Transforms: []domain.ImageTransform{{Kind: domain.TransformCrop, Aspect: "16:9"}}}, 50, 28},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := filepath.Join(t.TempDir(), "output.png")
+			if err := engine.Convert(context.Background(), input, output, domain.ConversionOptions{Image: tt.opts}); err != nil {
+				t.Fatalf("Conversion failed: %v", err)
+			}
+			img, err := imaging.Open(output)
+			if err != nil {
+				t.Fatalf("Failed to open output: %v", err)
+			}
+			if got := img.Bounds().Size(); got.X != tt.width || got.Y != tt.height {
+				t.Errorf("Expected %dx%d, got %dx%d", tt.width, tt.height, got.X, got.Y)
+			}
+		})
+	}
+}
+
+// TestImageEngine_Convert_RotateAndFlipPixels tests the direction of rotations and flips
+func TestImageEngine_Convert_RotateAndFlipPixels(t *testing.T) {
+	engine := createTestImageEngine(t)
+	input := createTempPNGFile(t)
+
+	tests := []struct {
+		transform domain.ImageTransform
+		want      color.NRGBA // the output's top-left pixel
+	}{
+		{domain.ImageTransform{Kind: domain.TransformRotate, Angle: 90}, color.NRGBA{0, 99, 128, 255}},
+		{domain.ImageTransform{Kind: domain.TransformRotate, Angle: -90}, color.NRGBA{99, 0, 128, 255}},
+		{domain.ImageTransform{Kind: domain.TransformFlip, Flip: domain.FlipHorizontal}, color.NRGBA{99, 0, 128, 255}},
+		{domain.ImageTransform{Kind: domain.TransformFlip, Flip: domain.FlipVertical}, color.NRGBA{0, 99, 128, 255}},
+	}
+	for _, tt := range tests {
+		output := filepath.Join(t.TempDir(), "output.png")
+		opts := domain.ConversionOptions{Image: domain.ImageOptions{Transforms: []domain.ImageTransform{tt.transform}}}
+		if err := engine.Convert(context.Background(), input, output, opts); err != nil {
+			t.Fatalf("Conversion failed: %v", err)
+		}
+		img, err := imaging.Open(output)
+		if err != nil {
+			t.Fatalf("Failed to open output: %v", err)
+		}
+		if got := color.NRGBAModel.Convert(img.At(0, 0)); got != tt.want {
+			t.Errorf("%+v: expected the top-left pixel %v, got %v", tt.transform, tt.want, got)
+		}
+	}
+}
+
+// TestImageEngine_Convert_TransformErrors tests that a crop outside the image and oversized results are rejected
+func TestImageEngine_Convert_TransformErrors(t *testing.T) {
+	engine := createTestImageEngine(t)
+	input := createTempPNGFile(t)
+	output := filepath.Join(t.TempDir(), "output.png")
+
+	opts := domain.ConversionOptions{Image: domain.ImageOptions{Transforms: []domain.ImageTransform{
+		{Kind: domain.TransformCrop, Width: 10, Height: 10, X: 100, Y: 0},
+	}}}
+	if err := engine.Convert(context.Background(), input, output, opts); !errors.Is(err, domain.ErrInvalidOptions) {
+		t.Errorf("Expected a crop outside the image to be invalid, got %v", err)
+	}
+
+	ctx := domain.ContextWithResourceLimits(context.Background(), domain.ResourceLimits{MaxImageDimension: 200})
+	opts = domain.ConversionOptions{Image: domain.ImageOptions{Transforms: []domain.ImageTransform{
+		{Kind: domain.TransformResize, Width: 400, Height: 400, Mode: domain.ResizeExact},
+	}}}
+	if err := engine.Convert(ctx, input, output, opts); !errors.Is(err, domain.ErrResourceLimit) {
+		t.Errorf("Expected the resize to exceed MaxImageDimension, got %v", err)
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Error("Expected no output for a rejected transform")
+	}
+}
+
+// TestImageEngine_BatchConvert_Transforms tests that every task applies its own transforms
+func TestImageEngine_BatchConvert_Transforms(t *testing.T) {
+	input := createTempPNGFile(t)
+	dir := t.TempDir()
+	tasks := []BatchConversionTask{
+		{InputPath: input, OutputPath: filepath.Join(dir, "wide.png"), Index: 0, Options: domain.ConversionOptions{Image: domain.ImageOptions{
+			Transforms: []domain.ImageTransform{{Kind: domain.TransformCrop, Aspect: "2:1"}},
+		}}},
+		{InputPath: input, OutputPath: filepath.Join(dir, "tall.jpeg"), Index: 1, Options: domain.ConversionOptions{Image: domain.ImageOptions{
+			Transforms: []domain.ImageTransform{{Kind: domain.TransformCrop, Aspect: "2:1"}, {Kind: domain.TransformRotate, Angle: 270}},
+		}}},
+	}
+
+	results := createTestImageEngine(t).BatchConvert(context.Background(), tasks)
+	want := []image.Point{{100, 50}, {50, 100}}
+	for i, result := range results {
+		if result.Error != nil {
+			t.Fatalf("Task %d failed: %v", i, result.Error)
+		}
+		img, err := imaging.Open(tasks[i].OutputPath)
+		if err != nil {
+			t.Fatalf("Failed to open output: %v", err)
+		}
+		if got := img.Bounds().Size(); got != want[i] {
+			t.Errorf("Task %d: expected %v, got %v", i, want[i], got)
+		}
+	}
+}
+
+// TestImageEngine_Convert_Subsampling tests that JPEG output uses the requested chroma subsampling
+func TestImageEngine_Convert_Subsampling(t *testing.T) {
+	engine := createTestImageEngine(t)
+	input := createTempPNGFile(t)
+	source, err := imaging.Open(input)
+	if err != nil {
+		t.Fatalf("Failed to open test image: %v", err)
+	}
+
+	tests := []struct {
+		subsampling domain.ChromaSubsampling
+		want        image.YCbCrSubsampleRatio
+	}{
+		{"", image.YCbCrSubsampleRatio420},
+		{domain.Subsampling420, image.YCbCrSubsampleRatio420},
+		{domain.Subsampling422, image.YCbCrSubsampleRatio422},
+		{domain.Subsampling444, image.YCbCrSubsampleRatio444},
+	}
+	for _, tt := range tests {
+		output := filepath.Join(t.TempDir(), "output.jpg")
+		opts := domain.ConversionOptions{Image: domain.ImageOptions{Quality: 90, Subsampling: tt.subsampling}}
+		if err := engine.Convert(context.Background(), input, output, opts); err != nil {
+			t.Fatalf("Conversion with %q failed: %v", tt.subsampling, err)
+		}
+		file, err := os.Open(output)
+		if err != nil {
+			t.Fatalf("Failed to open output: %v", err)
+		}
+		img, err := jpeg.Decode(file)
+		file.Close()
+		if err != nil {
+			t.Fatalf("Failed to decode %q output: %v", tt.subsampling, err)
+		}
+		ycbcr, ok := img.(*image.YCbCr)
+		if !ok || ycbcr.SubsampleRatio != tt.want {
+			t.Errorf("Expected %q to decode as %v, got %T", tt.subsampling, tt.want, img)
+			continue
+		}
+		if psnr := imagePSNR(source, img); psnr < 35 {
+			t.Errorf("Expected %q output close to the source, got a PSNR of %.1f dB", tt.subsampling, psnr)
+		}
+	}
+}
+
+// TestImageEngine_Convert_EncoderOptions tests the PNG compression level and WebP container options
+func TestImageEngine_Convert_EncoderOptions(t *testing.T) {
+	engine := createTestImageEngine(t)
+	input := createTempPNGFile(t)
+	convert := func(name string, opts domain.ImageOptions) []byte {
+		t.Helper()
+		output := filepath.Join(t.TempDir(), name)
+		if err := engine.Convert(context.Background(), input, output, domain.ConversionOptions{Image: opts}); err != nil {
+			t.Fatalf("Conversion to %s failed: %v", name, err)
+		}
+		data, err := os.ReadFile(output)
+		if err != nil {
+			t.Fatalf("Failed to read output: %v", err)
+		}
+		return data
+	}
+
+	stored := convert("stored.png", domain.ImageOptions{Compression: domain.CompressionNone})
+	best := convert("best.png", domain.ImageOptions{Compression: domain.CompressionBest})
+	if len(stored) <= len(best) {
+		t.Errorf("Expected uncompressed PNG to be larger than the best compression, got %d and %d bytes", len(stored), len(best))
+	}
+
+	if chunk := string(convert("simple.webp", domain.ImageOptions{})[12:16]); chunk != "VP8L" {
+		t.Errorf("Expected the simple WebP container, got chunk %q", chunk)
+	}
+	if chunk := string(convert("extended.webp", domain.ImageOptions{WebPExtended: true})[12:16]); chunk != "VP8X" {
+		t.Errorf("Expected the extended WebP container, got chunk %q", chunk)
+	}
+}
+
+// TestImageEngine_ValidateOptions tests that encoder settings of another format are rejected
+func TestImageEngine_ValidateOptions(t *testing.T) {
+	engine := createTestImageEngine(t)
+	tests := []struct {
+		opts   domain.ImageOptions
+		format domain.Format
+		valid  bool
+	}{
+		{domain.ImageOptions{Quality: 80, Subsampling: domain.Subsampling444}, domain.FormatJPEG, true},
+		{domain.ImageOptions{Subsampling: domain.Subsampling444}, domain.FormatPNG, false},
+		{domain.ImageOptions{Compression: domain.CompressionBest}, domain.FormatPNG, true},
+		{domain.ImageOptions{Compression: domain.CompressionBest}, domain.FormatWEBP, false},
+		{domain.ImageOptions{WebPExtended: true}, domain.FormatWEBP, true},
+		{domain.ImageOptions{WebPExtended: true}, domain.FormatJPEG, false},
+		{domain.ImageOptions{Quality: 80, WebPExtended: true}, domain.FormatWEBP, true},
+		{domain.ImageOptions{Quality: 80}, domain.FormatPNG, false},
+		{domain.ImageOptions{Metadata: domain.MetadataKeep}, domain.FormatPNG, true},
+		{domain.ImageOptions{Metadata: domain.MetadataStrip}, domain.FormatGIF, true},
+		{domain.ImageOptions{Metadata: domain.MetadataCopyright}, domain.FormatTIFF, false},
+		{domain.ImageOptions{Quality: 80}, domain.FormatBMP, false},
+	}
+	for _, tt := range tests {
+		err := engine.ValidateOptions(domain.ConversionOptions{Image: tt.opts}, tt.format)
+		if valid := err == nil; valid != tt.valid {
+			t.Errorf("%+v to %s: expected valid=%v, got %v", tt.opts, tt.format, tt.valid, err)
+		}
+		if err != nil && !errors.Is(err, domain.ErrInvalidOptions) {
+			t.Errorf("Expected domain.ErrInvalidOptions, got %v", err)
+		}
+	}
+}
+
+// TestImageEngine_Convert_LossyWebP tests that a WebP quality selects lossy
+// encoding, which is smaller than lossless for photos and close to the source
+func TestImageEngine_Convert_LossyWebP(t *testing.T) {
+	engine := createTestImageEngine(t)
+	input := createPhotoPNGFile(t, 256, 192)
+	source, err := imaging.Open(input)
+	if err != nil {
+		t.Fatalf("Failed to open test image: %v", err)
+	}
+	convert := func(quality int) (image.Image, int) {
+		t.Helper()
+		output := filepath.Join(t.TempDir(), "output.webp")
+		opts := domain.ConversionOptions{Image: domain.ImageOptions{Quality: quality}}
+		if err := engine.Convert(context.Background(), input, output, opts); err != nil {
+			t.Fatalf("Conversion at quality %d failed: %v", quality, err)
+		}
+		info, err := os.Stat(output)
+		if err != nil {
+			t.Fatalf("Failed to stat output: %v", err)
+		}
+		return decodeWebPFile(t, output), int(info.Size())
+	}
+
+	lossless, losslessSize := convert(0)
+	if psnr := imagePSNR(source, lossless); !math.IsInf(psnr, 1) {
+		t.Errorf("Expected lossless WebP to match the source exactly, got a PSNR of %.1f dB", psnr)
+	}
+	previousSize, previousPSNR := 0, 0.0
+	for _, quality := range []int{30, 75, 95} {
+		lossy, size := convert(quality)
+		psnr := imagePSNR(source, lossy)
+		if size >= losslessSize/2 {
+			t.Errorf("Expected quality %d to be under half the %d bytes of lossless WebP, got %d bytes", quality, losslessSize, size)
+		}
+		if psnr < 30 {
+			t.Errorf("Expected quality %d output close to the source, got a PSNR of %.1f dB", quality, psnr)
+		}
+		if size <= previousSize || psnr <= previousPSNR {
+			t.Errorf("Expected quality %d to be larger and closer to the source than lower qualities, got %d bytes at %.1f dB", quality, size, psnr)
+		}
+		previousSize, previousPSNR = size, psnr
+	}
+}
+
+// TestImageEngine_Convert_LossyWebPTransparency tests that lossy WebP keeps
+// the alpha channel in the extended container
+func TestImageEngine_Convert_LossyWebPTransparency(t *testing.T) {
+	engine := createTestImageEngine(t)
+	img := image.NewNRGBA(image.Rect(0, 0, 37, 21))
+	for y := 0; y < 21; y++ {
+		for x := 0; x < 37; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{uint8(7 * x), uint8(12 * y), 200, uint8(x * 255 / 36)})
+		}
+	}
+	input := filepath.Join(t.TempDir(), "alpha.png")
+	if err := imaging.Save(img, input); err != nil {
+		t.Fatalf("Failed to save test image: %v", err)
+	}
+
+	output := filepath.Join(t.TempDir(), "alpha.webp")
+	opts := domain.ConversionOptions{Image: domain.ImageOptions{Quality: 90}}
+	if err := engine.Convert(context.Background(), input, output, opts); err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	if chunk := string(data[12:16]); chunk != "VP8X" {
+		t.Errorf("Expected the extended WebP container, got chunk %q", chunk)
+	}
+	decoded := decodeWebPFile(t, output)
+	for _, p := range []image.Point{{0, 0}, {18, 10}, {36, 20}} {
+		if got, want := color.NRGBAModel.Convert(decoded.At(p.X, p.Y)).(color.NRGBA).A, img.NRGBAAt(p.X, p.Y).A; got != want {
+			t.Errorf("Expected alpha %d at %v, got %d", want, p, got)
+		}
+	}
+}
+
+// TestImageEngine_EncoderSettings_WebP tests that WebP output records the
+// quality of lossy encoding and none for lossless
+func TestImageEngine_EncoderSettings_WebP(t *testing.T) {
+	engine := NewImageEngine(nil, domain.EngineSettings{DefaultQuality: 70}).(*ImageEngine)
+	tests := []struct {
+		opts domain.ImageOptions
+		want domain.EncoderSettings
+	}{
+		{domain.ImageOptions{}, domain.EncoderSettings{Format: domain.FormatWEBP}},
+		{domain.ImageOptions{Quality: 60, WebPExtended: true}, domain.EncoderSettings{Format: domain.FormatWEBP, Quality: 60, Extended: true}},
+	}
+	for _, tt := range tests {
+		if got := engine.encoderSettings(domain.FormatWEBP, tt.opts); got != tt.want {
+			t.Errorf("%+v: expected %+v, got %+v", tt.opts, tt.want, got)
+		}
+	}
+}
+
+// TestImageEngine_Convert_AutoOrient tests that images are turned upright
+// as their EXIF orientation says unless the orientation is ignored
+func TestImageEngine_Convert_AutoOrient(t *testing.T) {
+	engine := createTestImageEngine(t)
+	// Stored landscape with a red top-left corner, to be shown turned a
+	// quarter clockwise
+	input := createMetadataJPEGFile(t, imageMetadata{exif: createTestEXIF(6, "")})
+
+	output := filepath.Join(t.TempDir(), "upright.png")
+	if err := engine.Convert(context.Background(), input, output, domain.ConversionOptions{}); err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	upright, err := imaging.Open(output)
+	if err != nil {
+		t.Fatalf("Failed to open output: %v", err)
+	}
+	if size := upright.Bounds().Size(); size != image.Pt(20, 40) {
+		t.Errorf("Expected the upright image to be 20x40, got %v", size)
+	}
+	if r, g, b, _ := upright.At(17, 2).RGBA(); r>>8 < 200 || g>>8 > 60 || b>>8 > 60 {
+		t.Errorf("Expected the red corner at the top right, got %v", upright.At(17, 2))
+	}
+
+	output = filepath.Join(t.TempDir(), "stored.png")
+	opts := domain.ConversionOptions{Image: domain.ImageOptions{IgnoreOrientation: true}}
+	if err := engine.Convert(context.Background(), input, output, opts); err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	stored, err := imaging.Open(output)
+	if err != nil {
+		t.Fatalf("Failed to open output: %v", err)
+	}
+	if size := stored.Bounds().Size(); size != image.Pt(40, 20) {
+		t.Errorf("Expected the stored 40x20 image with the orientation ignored, got %v", size)
+	}
+}
+
+// TestImageEngine_Convert_MetadataPolicy tests that every output format
+// strips, keeps or keeps only the copyright and colour profile of the input's
+// metadata
+func TestImageEngine_Convert_MetadataPolicy(t *testing.T) {
+	engine := createTestImageEngine(t)
+	icc := make([]byte, 70000)
+	rand.New(rand.NewSource(1)).Read(icc)
+	xmp := []byte(`<x:xmpmeta xmlns:x="adobe:ns:meta/"><dc:creator>Ada</dc:creator></x:xmpmeta>`)
+	input := createMetadataJPEGFile(t, imageMetadata{exif: createTestEXIF(6, "(c) Ada Lovelace"), xmp: xmp, icc: icc})
+
+	outputs := []struct {
+		name    string
+		quality int
+	}{{"out.jpg", 0}, {"out.png", 0}, {"out.webp", 0}, {"lossy.webp", 80}}
+	for _, out := range outputs {
+		for _, policy := range []domain.MetadataPolicy{"", domain.MetadataStrip, domain.MetadataKeep, domain.MetadataCopyright} {
+			output := filepath.Join(t.TempDir(), out.name)
+			opts := domain.ConversionOptions{Image: domain.ImageOptions{Quality: out.quality, Metadata: policy}}
+			if err := engine.Convert(context.Background(), input, output, opts); err != nil {
+				t.Fatalf("%s with %q: conversion failed: %v", out.name, policy, err)
+			}
+			data, err := os.ReadFile(output)
+			if err != nil {
+				t.Fatalf("Failed to read output: %v", err)
+			}
+			if _, _, err := image.Decode(bytes.NewReader(data)); err != nil {
+				t.Errorf("%s with %q: expected the output to decode, got %v", out.name, policy, err)
+			}
+
+			meta := readMetadata(data)
+			_, _, hasGPS := exifEntry(meta.exif, 0x8825)
+			switch policy {
+			case domain.MetadataKeep:
+				if meta.orientation() != 1 || exifCopyright(meta.exif) != "(c) Ada Lovelace" || !hasGPS {
+					t.Errorf("%s with keep: expected the EXIF with its orientation reset, got orientation %d, copyright %q and GPS %v",
+						out.name, meta.orientation(), exifCopyright(meta.exif), hasGPS)
+				}
+				if !bytes.Equal(meta.xmp, xmp) || !bytes.Equal(meta.icc, icc) {
+					t.Errorf("%s with keep: expected the XMP and the %d-byte colour profile, got %d and %d bytes", out.name, len(icc), len(meta.xmp), len(meta.icc))
+				}
+			case domain.MetadataCopyright:
+				if exifCopyright(meta.exif) != "(c) Ada Lovelace" || hasGPS || meta.xmp != nil {
+					t.Errorf("%s with copyright: expected only the copyright notice, got copyright %q, GPS %v and %d bytes of XMP",
+						out.name, exifCopyright(meta.exif), hasGPS, len(meta.xmp))
+				}
+				if !bytes.Equal(meta.icc, icc) {
+					t.Errorf("%s with copyright: expected the %d-byte colour profile, got %d bytes", out.name, len(icc), len(meta.icc))
+				}
+			default:
+				if !meta.empty() {
+					t.Errorf("%s with %q: expected no metadata, got %d bytes of EXIF, %d of XMP and %d of ICC",
+						out.name, policy, len(meta.exif), len(meta.xmp), len(meta.icc))
+				}
+			}
+		}
+	}
+}
+
+// TestCopyrightEXIF tests that notices of any length read back
+func TestCopyrightEXIF(t *testing.T) {
+	for _, notice := range []string{"c", "(c) Ada Lovelace, 1843"} {
+		if got := exifCopyright(copyrightEXIF(notice)); got != notice {
+			t.Errorf("Expected copyright %q to read back, got %q", notice, got)
+		}
+	}
+}
+
+// TestImageEngine_Convert_GIFBMPTIFF tests that GIF, BMP and TIFF are read
+// and written, with BMP and TIFF keeping every pixel
+func TestImageEngine_Convert_GIFBMPTIFF(t *testing.T) {
+	engine := createTestImageEngine(t)
+	input := createPhotoPNGFile(t, 64, 48)
+	source, err := imaging.Open(input)
+	if err != nil {
+		t.Fatalf("Failed to open test image: %v", err)
+	}
+
+	for _, ext := range []string{"gif", "bmp", "tiff", "tif"} {
+		output := filepath.Join(t.TempDir(), "photo."+ext)
+		if err := engine.Convert(context.Background(), input, output, domain.ConversionOptions{}); err != nil {
+			t.Fatalf("Conversion to %s failed: %v", ext, err)
+		}
+		if err := engine.Validate(context.Background(), output); err != nil {
+			t.Errorf("Expected %s output to validate, got %v", ext, err)
+		}
+
+		// Converting back reads the format as an input
+		back := filepath.Join(t.TempDir(), "back.png")
+		if err := engine.Convert(context.Background(), output, back, domain.ConversionOptions{}); err != nil {
+			t.Fatalf("Conversion from %s failed: %v", ext, err)
+		}
+		decoded, err := imaging.Open(back)
+		if err != nil {
+			t.Fatalf("Failed to open output: %v", err)
+		}
+		psnr := imagePSNR(source, decoded)
+		switch {
+		case ext == "gif" && psnr < 20:
+			t.Errorf("Expected the dithered GIF close to the source, got a PSNR of %.1f dB", psnr)
+		case ext != "gif" && !math.IsInf(psnr, 1):
+			t.Errorf("Expected %s to match the source exactly, got a PSNR of %.1f dB", ext, psnr)
+		}
+	}
+}
+
+// TestImageEngine_Convert_GIFTransparency tests that GIF output keeps
+// transparent pixels and GIF input keeps its palette
+func TestImageEngine_Convert_GIFTransparency(t *testing.T) {
+	engine := createTestImageEngine(t)
+	img := image.NewNRGBA(image.Rect(0, 0, 16, 16))
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 8; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{255, 0, 0, 255})
+		}
+	}
+	input := filepath.Join(t.TempDir(), "half.png")
+	if err := imaging.Save(img, input); err != nil {
+		t.Fatalf("Failed to save test image: %v", err)
+	}
+
+	output := filepath.Join(t.TempDir(), "half.gif")
+	if err := engine.Convert(context.Background(), input, output, domain.ConversionOptions{}); err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	file, err := os.Open(output)
+	if err != nil {
+		t.Fatalf("Failed to open output: %v", err)
+	}
+	defer file.Close()
+	decoded, err := gif.Decode(file)
+	if err != nil {
+		t.Fatalf("Failed to decode GIF: %v", err)
+	}
+	if _, _, _, a := decoded.At(12, 8).RGBA(); a != 0 {
+		t.Errorf("Expected a transparent pixel, got alpha %d", a)
+	}
+	if r, g, b, a := decoded.At(3, 8).RGBA(); r>>8 != 255 || g != 0 || b != 0 || a>>8 != 255 {
+		t.Errorf("Expected an opaque red pixel, got %v", decoded.At(3, 8))
+	}
+
+	// GIF to GIF keeps the palette, so the pixels do not change
+	again := filepath.Join(t.TempDir(), "again.gif")
+	if err := engine.Convert(context.Background(), output, again, domain.ConversionOptions{}); err != nil {
+		t.Fatalf("Conversion failed: %v", err)
+	}
+	first, err := imaging.Open(output)
+	if err != nil {
+		t.Fatalf("Failed to open output: %v", err)
+	}
+	second, err := imaging.Open(again)
+	if err != nil {
+		t.Fatalf("Failed to open output: %v", err)
+	}
+	if psnr := imagePSNR(first, second); !math.IsInf(psnr, 1) {
+		t.Errorf("Expected GIF to GIF to keep every pixel, got a PSNR of %.1f dB", psnr)
+	}
+}
+
 // TestImageEngine_BatchConvert_ParallelProcessing tests FR-10: The system shall utilize parallel processing (worker pools) to handle batch image conversions
 func TestImageEngine_BatchConvert_ParallelProcessing(t *testing.T) {
 	// Create multiple test images
@@ -144,10 +773,9 @@ func TestImageEngine_BatchConvert_ParallelProcessing(t *testing.T) {
 	}
 
 	engine := createTestImageEngine(t)
-	defer engine.Close()
 
 	// Measure time to verify parallel processing
-	results := engine.BatchConvert(tasks)
+	results := engine.BatchConvert(context.Background(), tasks)
 
 	// Verify all conversions completed
 	if len(results) != numImages {
@@ -198,7 +826,6 @@ func TestImageEngine_BatchConvert_WorkerPoolUtilization(t *testing.T) {
 	}
 
 	engine := createTestImageEngine(t)
-	defer engine.Close()
 
 	// Track concurrent executions
 	var maxConcurrent int
@@ -219,7 +846,7 @@ func TestImageEngine_BatchConvert_WorkerPoolUtilization(t *testing.T) {
 			mu.Unlock()
 
 			// Perform conversion
-			err := engine.Convert(context.Background(), tasks[idx].InputPath, tasks[idx].OutputPath)
+			err := engine.Convert(context.Background(), tasks[idx].InputPath, tasks[idx].OutputPath, domain.ConversionOptions{})
 
 			mu.Lock()
 			currentConcurrent--
@@ -242,9 +869,37 @@ func TestImageEngine_BatchConvert_WorkerPoolUtilization(t *testing.T) {
 	}
 }
 
+// TestImageEngine_BatchConvert_SharedScheduler tests that batch conversions run on the injected scheduler
+func TestImageEngine_BatchConvert_SharedScheduler(t *testing.T) {
+	sched := scheduler.New(2)
+	defer sched.Close()
+	sched.SetLimit("image", 1)
+
+	tasks := make([]BatchConversionTask, 3)
+	for i := range tasks {
+		tasks[i] = BatchConversionTask{
+			InputPath:  createTempPNGFile(t),
+			OutputPath: filepath.Join(t.TempDir(), "output.jpg"),
+			Index:      i,
+		}
+	}
+
+	engine := NewImageEngine(sched, domain.EngineSettings{}).(*ImageEngine)
+	for i, result := range engine.BatchConvert(context.Background(), tasks) {
+		if result.Error != nil {
+			t.Errorf("Conversion %d failed: %v", i, result.Error)
+		}
+	}
+
+	metrics := sched.Metrics()
+	if metrics.Completed != uint64(len(tasks)) || metrics.Queued != 0 || metrics.Running != 0 {
+		t.Errorf("Expected %d completed tasks on the shared scheduler, got %+v", len(tasks), metrics)
+	}
+}
+
 // TestImageEngine_BatchConvert_AllFormats tests batch conversion with different target formats
 func TestImageEngine_BatchConvert_AllFormats(t *testing.T) {
-	formats := []string{"png", "jpeg"}
+	formats := []string{"png", "jpeg", "webp", "gif", "bmp", "tiff"}
 	tasks := make([]BatchConversionTask, len(formats))
 	inputFile := createTempJPEGFile(t)
 	defer os.Remove(inputFile)
@@ -261,9 +916,8 @@ func TestImageEngine_BatchConvert_AllFormats(t *testing.T) {
 	}
 
 	engine := createTestImageEngine(t)
-	defer engine.Close()
 
-	results := engine.BatchConvert(tasks)
+	results := engine.BatchConvert(context.Background(), tasks)
 
 	// Verify all conversions succeeded
 	for i, result := range results {
@@ -275,10 +929,30 @@ func TestImageEngine_BatchConvert_AllFormats(t *testing.T) {
 
 // Helper functions
 
+// imagePSNR returns the peak signal-to-noise ratio of b against a over the
+// RGB channels, in decibels
+func imagePSNR(a, b image.Image) float64 {
+	var squared float64
+	bounds := a.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			ca := color.NRGBAModel.Convert(a.At(x, y)).(color.NRGBA)
+			cb := color.NRGBAModel.Convert(b.At(x, y)).(color.NRGBA)
+			for _, d := range []float64{float64(ca.R) - float64(cb.R), float64(ca.G) - float64(cb.G), float64(ca.B) - float64(cb.B)} {
+				squared += d * d
+			}
+		}
+	}
+	mse := squared / float64(3*bounds.Dx()*bounds.Dy())
+	if mse == 0 {
+		return math.Inf(1)
+	}
+	return 10 * math.Log10(255*255/mse)
+}
+
 // createTestImageEngine creates an ImageEngine instance for testing
 func createTestImageEngine(t *testing.T) *ImageEngine {
-	workerPool := NewWorkerPool()
-	return NewImageEngine(workerPool).(*ImageEngine)
+	return NewImageEngine(nil, domain.EngineSettings{}).(*ImageEngine)
 }
 
 // createTempJPEGFile creates a temporary JPEG file for testing
@@ -307,6 +981,62 @@ func createTempJPEGFile(t *testing.T) string {
 	return tmpFile
 }
 
+// createMetadataJPEGFile creates a 40x20 JPEG with a red top-left corner
+// and the given metadata
+func createMetadataJPEGFile(t *testing.T, meta imageMetadata) string {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 40; x++ {
+			img.Set(x, y, color.RGBA{40, 90, 160, 255})
+		}
+	}
+	draw.Draw(img, image.Rect(0, 0, 8, 8), image.NewUniform(color.RGBA{255, 0, 0, 255}), image.Point{}, draw.Src)
+
+	var encoded bytes.Buffer
+	if err := jpeg.Encode(&encoded, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatalf("Failed to encode JPEG: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "photo.jpg")
+	if err := os.WriteFile(path, embedJPEGMetadata(encoded.Bytes(), meta), 0o644); err != nil {
+		t.Fatalf("Failed to write test JPEG file: %v", err)
+	}
+	return path
+}
+
+// createTestEXIF builds big-endian EXIF data, as cameras often write, with
+// an orientation, a copyright notice when one is given and a GPS directory
+// pointer
+func createTestEXIF(orientation int, copyright string) []byte {
+	order := binary.BigEndian
+	value := append([]byte(copyright), 0)
+	entries := 2
+	if copyright != "" {
+		entries = 3
+	}
+	exif := order.AppendUint32([]byte("MM\x00*"), 8)
+	exif = order.AppendUint16(exif, uint16(entries))
+	exif = order.AppendUint16(exif, exifTagOrientation)
+	exif = order.AppendUint16(exif, exifTypeShort)
+	exif = order.AppendUint32(exif, 1)
+	exif = order.AppendUint32(exif, uint32(orientation)<<16)
+	if copyright != "" {
+		exif = order.AppendUint16(exif, exifTagCopyright)
+		exif = order.AppendUint16(exif, exifTypeASCII)
+		exif = order.AppendUint32(exif, uint32(len(value)))
+		exif = order.AppendUint32(exif, uint32(10+12*entries+4))
+	}
+	// GPSInfo, a LONG offset
+	exif = order.AppendUint16(exif, 0x8825)
+	exif = order.AppendUint16(exif, 4)
+	exif = order.AppendUint32(exif, 1)
+	exif = order.AppendUint32(exif, 0)
+	exif = order.AppendUint32(exif, 0)
+	if copyright != "" {
+		exif = append(exif, value...)
+	}
+	return exif
+}
+
 // createTempPNGFile creates a temporary PNG file for testing
 func createTempPNGFile(t *testing.T) string {
 	tmpFile := filepath.Join(t.TempDir(), "test.png")
@@ -333,4 +1063,76 @@ func createTempPNGFile(t *testing.T) string {
 	return tmpFile
 }
 
+// decodeWebPFile decodes a WebP file. Lossy WebP holds studio-range
+// Y'CbCr, which is converted to RGB as libwebp does rather than with the
+// full range of image.YCbCr.
+func decodeWebPFile(t *testing.T, path string) image.Image {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open output: %v", err)
+	}
+	defer file.Close()
+	img, err := webp.Decode(file)
+	if err != nil {
+		t.Fatalf("Failed to decode WebP: %v", err)
+	}
+
+	var ycbcr *image.YCbCr
+	var alpha *image.NYCbCrA
+	switch decoded := img.(type) {
+	case *image.YCbCr:
+		ycbcr = decoded
+	case *image.NYCbCrA:
+		ycbcr, alpha = &decoded.YCbCr, decoded
+	default:
+		return img
+	}
+	bounds := ycbcr.Bounds()
+	rgba := image.NewNRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			luma := 1.164 * (float64(ycbcr.Y[ycbcr.YOffset(x, y)]) - 16)
+			cb := float64(ycbcr.Cb[ycbcr.COffset(x, y)]) - 128
+			cr := float64(ycbcr.Cr[ycbcr.COffset(x, y)]) - 128
+			c := color.NRGBA{
+				R: clampByte(luma + 1.596*cr),
+				G: clampByte(luma - 0.813*cr - 0.391*cb),
+				B: clampByte(luma + 2.018*cb),
+				A: 255,
+			}
+			if alpha != nil {
+				c.A = alpha.A[alpha.AOffset(x, y)]
+			}
+			rgba.SetNRGBA(x, y, c)
+		}
+	}
+	return rgba
+}
+
+func clampByte(v float64) uint8 {
+	return uint8(math.Max(0, math.Min(255, math.Round(v))))
+}
 
+// createPhotoPNGFile creates a PNG of smooth gradients with sensor-like
+// noise, which lossless encoders compress poorly
+func createPhotoPNGFile(t *testing.T, width, height int) string {
+	tmpFile := filepath.Join(t.TempDir(), "photo.png")
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	noise := rand.New(rand.NewSource(1))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			fx, fy := float64(x), float64(y)
+			img.SetNRGBA(x, y, color.NRGBA{
+				R: clampByte(128 + 100*math.Sin(fx/17)*math.Cos(fy/23) + 3*noise.NormFloat64()),
+				G: clampByte(128 + 90*math.Sin((fx+fy)/31) + 3*noise.NormFloat64()),
+				B: clampByte(128 + 80*math.Cos(fy/13) + 3*noise.NormFloat64()),
+				A: 255,
+			})
+		}
+	}
+	if err := imaging.Save(img, tmpFile); err != nil {
+		t.Fatalf("Failed to create test PNG file: %v", err)
+	}
+	return tmpFile
+}
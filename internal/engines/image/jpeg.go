@@ -0,0 +1,402 @@
+package image
+
+import (
+	"bufio"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"io"
+	"math"
+
+	"github.com/eka026/File-Format-Converter/internal/domain"
+)
+
+// defaultJPEGQuality is the quality of JPEG output when neither the options
+// nor the configuration set one, as used by imaging
+const defaultJPEGQuality = 95
+
+// encodeJPEG writes img as a baseline JPEG with the given chroma
+// subsampling. 4:2:0 and grayscale images use the standard library encoder;
+// 4:4:4 and 4:2:2, which it cannot write, use jpegWriter.
+func encodeJPEG(w io.Writer, img image.Image, quality int, subsampling domain.ChromaSubsampling) error {
+	if _, gray := img.(*image.Gray); gray || subsampling == domain.Subsampling420 {
+		// As imaging does, skip the alpha conversion of opaque NRGBA images
+		if nrgba, ok := img.(*image.NRGBA); ok && nrgba.Opaque() {
+			img = &image.RGBA{Pix: nrgba.Pix, Stride: nrgba.Stride, Rect: nrgba.Rect}
+		}
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
+	}
+
+	// Like image/jpeg, encode the alpha-premultiplied colour, so transparent
+	// pixels become black
+	bounds := img.Bounds()
+	rgba, ok := img.(*image.RGBA)
+	if !ok {
+		rgba = image.NewRGBA(bounds)
+		draw.Draw(rgba, bounds, img, bounds.Min, draw.Src)
+	}
+	horizontal, vertical := 1, 1
+	if subsampling == domain.Subsampling422 {
+		horizontal = 2
+	}
+	jw := &jpegWriter{w: bufio.NewWriter(w), horizontal: horizontal, vertical: vertical}
+	jw.writeImage(rgba, quality)
+	if jw.err == nil {
+		jw.err = jw.w.Flush()
+	}
+	return jw.err
+}
+
+// Zig-zag order of the coefficients of an 8x8 block
+var zigzag = [64]int{
+	0, 1, 8, 16, 9, 2, 3, 10,
+	17, 24, 32, 25, 18, 11, 4, 5,
+	12, 19, 26, 33, 40, 48, 41, 34,
+	27, 20, 13, 6, 7, 14, 21, 28,
+	35, 42, 49, 56, 57, 50, 43, 36,
+	29, 22, 15, 23, 30, 37, 44, 51,
+	58, 59, 52, 45, 38, 31, 39, 46,
+	53, 60, 61, 54, 47, 55, 62, 63,
+}
+
+// Base quantization tables of JPEG Annex K, in natural order
+var baseQuant = [2][64]int{
+	{
+		16, 11, 10, 16, 24, 40, 51, 61,
+		12, 12, 14, 19, 26, 58, 60, 55,
+		14, 13, 16, 24, 40, 57, 69, 56,
+		14, 17, 22, 29, 51, 87, 80, 62,
+		18, 22, 37, 56, 68, 109, 103, 77,
+		24, 35, 55, 64, 81, 104, 113, 92,
+		49, 64, 78, 87, 103, 121, 120, 101,
+		72, 92, 95, 98, 112, 100, 103, 99,
+	},
+	{
+		17, 18, 24, 47, 99, 99, 99, 99,
+		18, 21, 26, 66, 99, 99, 99, 99,
+		24, 26, 56, 99, 99, 99, 99, 99,
+		47, 66, 99, 99, 99, 99, 99, 99,
+		99, 99, 99, 99, 99, 99, 99, 99,
+		99, 99, 99, 99, 99, 99, 99, 99,
+		99, 99, 99, 99, 99, 99, 99, 99,
+		99, 99, 99, 99, 99, 99, 99, 99,
+	},
+}
+
+// huffmanSpec is a Huffman table as stored in a DHT segment: the number of
+// codes of each length from 1 to 16 bits, then the symbols in code order
+type huffmanSpec struct {
+	counts  [16]byte
+	symbols []byte
+}
+
+// The standard Huffman tables of JPEG Annex K: luminance DC, luminance AC,
+// chrominance DC and chrominance AC
+var huffmanSpecs = [4]huffmanSpec{
+	{
+		[16]byte{0, 1, 5, 1, 1, 1, 1, 1, 1, 0, 0, 0, 0, 0, 0, 0},
+		[]byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11},
+	},
+	{
+		[16]byte{0, 2, 1, 3, 3, 2, 4, 3, 5, 5, 4, 4, 0, 0, 1, 125},
+		[]byte{
+			0x01, 0x02, 0x03, 0x00, 0x04, 0x11, 0x05, 0x12,
+			0x21, 0x31, 0x41, 0x06, 0x13, 0x51, 0x61, 0x07,
+			0x22, 0x71, 0x14, 0x32, 0x81, 0x91, 0xa1, 0x08,
+			0x23, 0x42, 0xb1, 0xc1, 0x15, 0x52, 0xd1, 0xf0,
+			0x24, 0x33, 0x62, 0x72, 0x82, 0x09, 0x0a, 0x16,
+			0x17, 0x18, 0x19, 0x1a, 0x25, 0x26, 0x27, 0x28,
+			0x29, 0x2a, 0x34, 0x35, 0x36, 0x37, 0x38, 0x39,
+			0x3a, 0x43, 0x44, 0x45, 0x46, 0x47, 0x48, 0x49,
+			0x4a, 0x53, 0x54, 0x55, 0x56, 0x57, 0x58, 0x59,
+			0x5a, 0x63, 0x64, 0x65, 0x66, 0x67, 0x68, 0x69,
+			0x6a, 0x73, 0x74, 0x75, 0x76, 0x77, 0x78, 0x79,
+			0x7a, 0x83, 0x84, 0x85, 0x86, 0x87, 0x88, 0x89,
+			0x8a, 0x92, 0x93, 0x94, 0x95, 0x96, 0x97, 0x98,
+			0x99, 0x9a, 0xa2, 0xa3, 0xa4, 0xa5, 0xa6, 0xa7,
+			0xa8, 0xa9, 0xaa, 0xb2, 0xb3, 0xb4, 0xb5, 0xb6,
+			0xb7, 0xb8, 0xb9, 0xba, 0xc2, 0xc3, 0xc4, 0xc5,
+			0xc6, 0xc7, 0xc8, 0xc9, 0xca, 0xd2, 0xd3, 0xd4,
+			0xd5, 0xd6, 0xd7, 0xd8, 0xd9, 0xda, 0xe1, 0xe2,
+			0xe3, 0xe4, 0xe5, 0xe6, 0xe7, 0xe8, 0xe9, 0xea,
+			0xf1, 0xf2, 0xf3, 0xf4, 0xf5, 0xf6, 0xf7, 0xf8,
+			0xf9, 0xfa,
+		},
+	},
+	{
+		[16]byte{0, 3, 1, 1, 1, 1, 1, 1, 1, 1, 1, 0, 0, 0, 0, 0},
+		[]byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11},
+	},
+	{
+		[16]byte{0, 2, 1, 2, 4, 4, 3, 4, 7, 5, 4, 4, 0, 1, 2, 119},
+		[]byte{
+			0x00, 0x01, 0x02, 0x03, 0x11, 0x04, 0x05, 0x21,
+			0x31, 0x06, 0x12, 0x41, 0x51, 0x07, 0x61, 0x71,
+			0x13, 0x22, 0x32, 0x81, 0x08, 0x14, 0x42, 0x91,
+			0xa1, 0xb1, 0xc1, 0x09, 0x23, 0x33, 0x52, 0xf0,
+			0x15, 0x62, 0x72, 0xd1, 0x0a, 0x16, 0x24, 0x34,
+			0xe1, 0x25, 0xf1, 0x17, 0x18, 0x19, 0x1a, 0x26,
+			0x27, 0x28, 0x29, 0x2a, 0x35, 0x36, 0x37, 0x38,
+			0x39, 0x3a, 0x43, 0x44, 0x45, 0x46, 0x47, 0x48,
+			0x49, 0x4a, 0x53, 0x54, 0x55, 0x56, 0x57, 0x58,
+			0x59, 0x5a, 0x63, 0x64, 0x65, 0x66, 0x67, 0x68,
+			0x69, 0x6a, 0x73, 0x74, 0x75, 0x76, 0x77, 0x78,
+			0x79, 0x7a, 0x82, 0x83, 0x84, 0x85, 0x86, 0x87,
+			0x88, 0x89, 0x8a, 0x92, 0x93, 0x94, 0x95, 0x96,
+			0x97, 0x98, 0x99, 0x9a, 0xa2, 0xa3, 0xa4, 0xa5,
+			0xa6, 0xa7, 0xa8, 0xa9, 0xaa, 0xb2, 0xb3, 0xb4,
+			0xb5, 0xb6, 0xb7, 0xb8, 0xb9, 0xba, 0xc2, 0xc3,
+			0xc4, 0xc5, 0xc6, 0xc7, 0xc8, 0xc9, 0xca, 0xd2,
+			0xd3, 0xd4, 0xd5, 0xd6, 0xd7, 0xd8, 0xd9, 0xda,
+			0xe2, 0xe3, 0xe4, 0xe5, 0xe6, 0xe7, 0xe8, 0xe9,
+			0xea, 0xf2, 0xf3, 0xf4, 0xf5, 0xf6, 0xf7, 0xf8,
+			0xf9, 0xfa,
+		},
+	},
+}
+
+// huffmanCode is the code of a symbol and its length in bits
+type huffmanCode struct {
+	code   uint32
+	length uint8
+}
+
+// huffmanCodes holds the canonical codes of each standard table, indexed
+// by symbol
+var huffmanCodes = func() (codes [4][256]huffmanCode) {
+	for i, spec := range huffmanSpecs {
+		code, k := uint32(0), 0
+		for length, count := range spec.counts {
+			for j := 0; j < int(count); j++ {
+				codes[i][spec.symbols[k]] = huffmanCode{code: code, length: uint8(length + 1)}
+				code++
+				k++
+			}
+			code <<= 1
+		}
+	}
+	return codes
+}()
+
+// dctCos[u][x] is the DCT basis cos((2x+1)uπ/16), scaled by the
+// normalisation factor of u
+var dctCos = func() (table [8][8]float64) {
+	for u := 0; u < 8; u++ {
+		scale := 0.5
+		if u == 0 {
+			scale = 1 / (2 * math.Sqrt2)
+		}
+		for x := 0; x < 8; x++ {
+			table[u][x] = scale * math.Cos(float64(2*x+1)*float64(u)*math.Pi/16)
+		}
+	}
+	return table
+}()
+
+// jpegWriter writes a baseline JPEG whose luma is sampled horizontal x
+// vertical times as often as the chroma. The first write error is kept in
+// err and ends the output.
+type jpegWriter struct {
+	w                    *bufio.Writer
+	err                  error
+	horizontal, vertical int
+	quant                [2][64]int // in zig-zag order
+	bits                 uint32
+	nBits                uint
+}
+
+// writeImage writes the markers and scan of img
+func (jw *jpegWriter) writeImage(img *image.RGBA, quality int) {
+	jw.setQuality(quality)
+	jw.write([]byte{0xFF, 0xD8})
+	jw.writeDQT()
+	jw.writeSOF0(img.Bounds().Size())
+	jw.writeDHT()
+	jw.writeSOS(img)
+	jw.write([]byte{0xFF, 0xD9})
+}
+
+// setQuality scales the base quantization tables as libjpeg does
+func (jw *jpegWriter) setQuality(quality int) {
+	quality = min(max(quality, 1), 100)
+	scale := 200 - 2*quality
+	if quality < 50 {
+		scale = 5000 / quality
+	}
+	for table := range baseQuant {
+		for i, natural := range zigzag {
+			jw.quant[table][i] = min(max((baseQuant[table][natural]*scale+50)/100, 1), 255)
+		}
+	}
+}
+
+func (jw *jpegWriter) write(p []byte) {
+	if jw.err == nil {
+		_, jw.err = jw.w.Write(p)
+	}
+}
+
+// writeMarker writes a marker segment header for a payload of length bytes
+func (jw *jpegWriter) writeMarker(marker byte, length int) {
+	jw.write([]byte{0xFF, marker, byte((length + 2) >> 8), byte(length + 2)})
+}
+
+func (jw *jpegWriter) writeDQT() {
+	jw.writeMarker(0xDB, 2*65)
+	for table := range jw.quant {
+		jw.write([]byte{byte(table)})
+		for _, q := range jw.quant[table] {
+			jw.write([]byte{byte(q)})
+		}
+	}
+}
+
+func (jw *jpegWriter) writeSOF0(size image.Point) {
+	jw.writeMarker(0xC0, 6+3*3)
+	jw.write([]byte{8, byte(size.Y >> 8), byte(size.Y), byte(size.X >> 8), byte(size.X), 3})
+	// Component ID, sampling factors and quantization table of Y, Cb and Cr
+	jw.write([]byte{1, byte(jw.horizontal<<4 | jw.vertical), 0})
+	jw.write([]byte{2, 0x11, 1})
+	jw.write([]byte{3, 0x11, 1})
+}
+
+func (jw *jpegWriter) writeDHT() {
+	length := 0
+	for _, spec := range huffmanSpecs {
+		length += 1 + 16 + len(spec.symbols)
+	}
+	jw.writeMarker(0xC4, length)
+	// Table class and ID: DC 0, AC 0, DC 1, AC 1
+	for i, class := range []byte{0x00, 0x10, 0x01, 0x11} {
+		jw.write([]byte{class})
+		jw.write(huffmanSpecs[i].counts[:])
+		jw.write(huffmanSpecs[i].symbols)
+	}
+}
+
+// writeSOS writes the scan header and the entropy-coded MCUs of img
+func (jw *jpegWriter) writeSOS(img *image.RGBA) {
+	jw.writeMarker(0xDA, 1+3*2+3)
+	jw.write([]byte{3, 1, 0x00, 2, 0x11, 3, 0x11, 0, 63, 0})
+
+	bounds := img.Bounds()
+	mcuWidth, mcuHeight := 8*jw.horizontal, 8*jw.vertical
+	var y, cb, cr [64]float64
+	var previousDC [3]int
+	for my := bounds.Min.Y; my < bounds.Max.Y; my += mcuHeight {
+		for mx := bounds.Min.X; mx < bounds.Max.X; mx += mcuWidth {
+			var cbSum, crSum [64]float64
+			for by := 0; by < jw.vertical; by++ {
+				for bx := 0; bx < jw.horizontal; bx++ {
+					for i := 0; i < 64; i++ {
+						// Pixels past the edge repeat the last row and column
+						px := min(mx+8*bx+i%8, bounds.Max.X-1)
+						py := min(my+8*by+i/8, bounds.Max.Y-1)
+						offset := img.PixOffset(px, py)
+						r, g, b := img.Pix[offset], img.Pix[offset+1], img.Pix[offset+2]
+						yy, u, v := color.RGBToYCbCr(r, g, b)
+						y[i] = float64(yy)
+						// Each chroma sample averages the luma samples it covers
+						c := (8*by+i/8)/jw.vertical*8 + (8*bx+i%8)/jw.horizontal
+						cbSum[c] += float64(u)
+						crSum[c] += float64(v)
+					}
+					previousDC[0] = jw.writeBlock(&y, 0, previousDC[0])
+				}
+			}
+			samples := float64(jw.horizontal * jw.vertical)
+			for i := range cb {
+				cb[i] = cbSum[i] / samples
+				cr[i] = crSum[i] / samples
+			}
+			previousDC[1] = jw.writeBlock(&cb, 1, previousDC[1])
+			previousDC[2] = jw.writeBlock(&cr, 1, previousDC[2])
+		}
+	}
+	// Pad the last byte with 1 bits
+	jw.emit(0x7F, 7)
+}
+
+// writeBlock transforms, quantizes and Huffman-codes one 8x8 block of
+// samples using table 0 (luma) or 1 (chroma), and returns its DC
+// coefficient for the next block's prediction
+func (jw *jpegWriter) writeBlock(block *[64]float64, table int, previousDC int) int {
+	var rows [64]float64
+	for v := 0; v < 8; v++ {
+		for u := 0; u < 8; u++ {
+			var sum float64
+			for x := 0; x < 8; x++ {
+				sum += (block[v*8+x] - 128) * dctCos[u][x]
+			}
+			rows[v*8+u] = sum
+		}
+	}
+	var coefficients [64]int
+	for i, natural := range zigzag {
+		u, v := natural%8, natural/8
+		var sum float64
+		for y := 0; y < 8; y++ {
+			sum += rows[y*8+u] * dctCos[v][y]
+		}
+		coefficients[i] = int(math.Round(sum / float64(jw.quant[table][i])))
+	}
+
+	dc, ac := &huffmanCodes[2*table], &huffmanCodes[2*table+1]
+	jw.emitValue(dc, 0, coefficients[0]-previousDC)
+	run := 0
+	for _, c := range coefficients[1:] {
+		if c == 0 {
+			run++
+			continue
+		}
+		for ; run > 15; run -= 16 {
+			jw.emitCode(ac, 0xF0)
+		}
+		jw.emitValue(ac, run, c)
+		run = 0
+	}
+	if run > 0 {
+		jw.emitCode(ac, 0x00)
+	}
+	return coefficients[0]
+}
+
+// emitValue writes the Huffman code of a run of zeros and the size of
+// value, followed by value's bits
+func (jw *jpegWriter) emitValue(codes *[256]huffmanCode, run, value int) {
+	magnitude := value
+	if value < 0 {
+		magnitude = -value
+		value--
+	}
+	size := 0
+	for magnitude > 0 {
+		size++
+		magnitude >>= 1
+	}
+	jw.emitCode(codes, byte(run<<4|size))
+	if size > 0 {
+		jw.emit(uint32(value)&(1<<size-1), uint(size))
+	}
+}
+
+func (jw *jpegWriter) emitCode(codes *[256]huffmanCode, symbol byte) {
+	code := codes[symbol]
+	jw.emit(code.code, uint(code.length))
+}
+
+// emit appends the low n bits of bits to the entropy-coded data, stuffing a
+// zero byte after every 0xFF
+func (jw *jpegWriter) emit(bits uint32, n uint) {
+	jw.bits = jw.bits<<n | bits
+	jw.nBits += n
+	for jw.nBits >= 8 {
+		b := byte(jw.bits >> (jw.nBits - 8))
+		jw.nBits -= 8
+		jw.write([]byte{b})
+		if b == 0xFF {
+			jw.write([]byte{0})
+		}
+	}
+}
@@ -0,0 +1,377 @@
+package image
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"sort"
+
+	"github.com/eka026/File-Format-Converter/internal/domain"
+)
+
+// imageMetadata is the metadata of an image file that conversions can carry
+// over: TIFF-structured EXIF, an XMP packet and an ICC colour profile
+type imageMetadata struct {
+	exif, xmp, icc []byte
+}
+
+// Signatures that introduce metadata in JPEG segments and PNG chunks
+var (
+	jpegEXIFHeader = []byte("Exif\x00\x00")
+	jpegXMPHeader  = []byte("http://ns.adobe.com/xap/1.0/\x00")
+	jpegICCHeader  = []byte("ICC_PROFILE\x00")
+	pngSignature   = []byte("\x89PNG\r\n\x1a\n")
+	pngXMPKeyword  = "XML:com.adobe.xmp"
+)
+
+// jpegMaxSegment is the largest payload of a JPEG marker segment
+const jpegMaxSegment = 65533
+
+// maxInflatedMetadata bounds the size of compressed PNG metadata once
+// inflated
+const maxInflatedMetadata = 16 << 20
+
+// readMetadata extracts the metadata of a JPEG, PNG or WebP file. Damaged
+// or unknown metadata is skipped, as the pixels still decode.
+func readMetadata(data []byte) imageMetadata {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xff, 0xd8}):
+		return readJPEGMetadata(data)
+	case bytes.HasPrefix(data, pngSignature):
+		return readPNGMetadata(data)
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return readWebPMetadata(data)
+	}
+	return imageMetadata{}
+}
+
+// orientation returns the EXIF orientation (1-8)
+func (m imageMetadata) orientation() int {
+	return exifOrientation(m.exif)
+}
+
+// filter returns the metadata that policy keeps. When the pixels were turned
+// upright, kept EXIF says so.
+func (m imageMetadata) filter(policy domain.MetadataPolicy, oriented bool) imageMetadata {
+	switch policy {
+	case domain.MetadataKeep:
+		if oriented && m.exif != nil {
+			m.exif = resetEXIFOrientation(m.exif)
+		}
+		return m
+	case domain.MetadataCopyright:
+		kept := imageMetadata{icc: m.icc}
+		if notice := exifCopyright(m.exif); notice != "" {
+			kept.exif = copyrightEXIF(notice)
+		}
+		return kept
+	default:
+		return imageMetadata{}
+	}
+}
+
+// empty reports whether there is no metadata to write
+func (m imageMetadata) empty() bool {
+	return len(m.exif) == 0 && len(m.xmp) == 0 && len(m.icc) == 0
+}
+
+// carriesMetadata reports whether output of format can hold metadata
+func carriesMetadata(format domain.Format) bool {
+	return format == domain.FormatJPEG || format == domain.FormatPNG || format == domain.FormatWEBP
+}
+
+// embedMetadata adds m to an encoded image of format
+func embedMetadata(data []byte, format domain.Format, m imageMetadata) []byte {
+	switch format {
+	case domain.FormatJPEG:
+		return embedJPEGMetadata(data, m)
+	case domain.FormatPNG:
+		return embedPNGMetadata(data, m)
+	case domain.FormatWEBP:
+		return embedWebPMetadata(data, m)
+	}
+	return data
+}
+
+// readJPEGMetadata reads the APP1 EXIF and XMP segments and the APP2 ICC
+// profile, which may be split over several segments
+func readJPEGMetadata(data []byte) imageMetadata {
+	var m imageMetadata
+	var iccChunks [][]byte
+	for i := 2; i+4 <= len(data) && data[i] == 0xff; {
+		marker := data[i+1]
+		switch {
+		case marker == 0xff:
+			// Fill byte
+			i++
+			continue
+		case marker >= 0xd0 && marker <= 0xd7 || marker == 0x01:
+			// Markers without a segment
+			i += 2
+			continue
+		case marker == 0xda || marker == 0xd9:
+			// The entropy-coded image data follows
+			i = len(data)
+			continue
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if length < 2 || i+2+length > len(data) {
+			break
+		}
+		payload := data[i+4 : i+2+length]
+		switch {
+		case marker == 0xe1 && bytes.HasPrefix(payload, jpegEXIFHeader):
+			m.exif = payload[len(jpegEXIFHeader):]
+		case marker == 0xe1 && bytes.HasPrefix(payload, jpegXMPHeader):
+			m.xmp = payload[len(jpegXMPHeader):]
+		case marker == 0xe2 && bytes.HasPrefix(payload, jpegICCHeader) && len(payload) >= len(jpegICCHeader)+2:
+			// Each chunk starts with its sequence number and the chunk count
+			iccChunks = append(iccChunks, payload[len(jpegICCHeader):])
+		}
+		i += 2 + length
+	}
+	sort.SliceStable(iccChunks, func(a, b int) bool { return iccChunks[a][0] < iccChunks[b][0] })
+	for _, chunk := range iccChunks {
+		m.icc = append(m.icc, chunk[2:]...)
+	}
+	return m
+}
+
+// embedJPEGMetadata inserts metadata segments after the start of image
+// marker. EXIF and XMP too large for one segment are dropped.
+func embedJPEGMetadata(data []byte, m imageMetadata) []byte {
+	var segments []byte
+	segment := func(marker byte, parts ...[]byte) {
+		length := 2
+		for _, part := range parts {
+			length += len(part)
+		}
+		segments = append(segments, 0xff, marker, byte(length>>8), byte(length))
+		for _, part := range parts {
+			segments = append(segments, part...)
+		}
+	}
+	if len(m.exif) > 0 && len(jpegEXIFHeader)+len(m.exif) <= jpegMaxSegment {
+		segment(0xe1, jpegEXIFHeader, m.exif)
+	}
+	if len(m.xmp) > 0 && len(jpegXMPHeader)+len(m.xmp) <= jpegMaxSegment {
+		segment(0xe1, jpegXMPHeader, m.xmp)
+	}
+	const iccChunkSize = jpegMaxSegment - 14
+	if count := (len(m.icc) + iccChunkSize - 1) / iccChunkSize; count > 0 && count <= 255 {
+		for i := 0; i < count; i++ {
+			chunk := m.icc[i*iccChunkSize : min((i+1)*iccChunkSize, len(m.icc))]
+			segment(0xe2, jpegICCHeader, []byte{byte(i + 1), byte(count)}, chunk)
+		}
+	}
+
+	embedded := make([]byte, 0, len(data)+len(segments))
+	embedded = append(embedded, data[:2]...)
+	embedded = append(embedded, segments...)
+	return append(embedded, data[2:]...)
+}
+
+// readPNGMetadata reads the eXIf, iCCP and XMP iTXt chunks
+func readPNGMetadata(data []byte) imageMetadata {
+	var m imageMetadata
+	for i := len(pngSignature); i+12 <= len(data); {
+		length := int(binary.BigEndian.Uint32(data[i:]))
+		kind := string(data[i+4 : i+8])
+		if length < 0 || length > len(data)-i-12 {
+			break
+		}
+		chunk := data[i+8 : i+8+length]
+		switch kind {
+		case "eXIf":
+			m.exif = chunk
+		case "iCCP":
+			// A profile name, the compression method and the zlib stream
+			if _, profile, ok := bytes.Cut(chunk, []byte{0}); ok && len(profile) > 1 {
+				m.icc = inflate(profile[1:])
+			}
+		case "iTXt":
+			m.xmp = pngXMP(chunk, m.xmp)
+		case "IEND":
+			return m
+		}
+		i += 12 + length
+	}
+	return m
+}
+
+// pngXMP returns the XMP packet of an iTXt chunk, or xmp when the chunk
+// holds other text
+func pngXMP(chunk, xmp []byte) []byte {
+	keyword, rest, ok := bytes.Cut(chunk, []byte{0})
+	if !ok || string(keyword) != pngXMPKeyword || len(rest) < 2 {
+		return xmp
+	}
+	compressed := rest[0] == 1
+	// Skip the compression method, the language tag and the translated keyword
+	_, rest, _ = bytes.Cut(rest[2:], []byte{0})
+	_, text, ok := bytes.Cut(rest, []byte{0})
+	if !ok {
+		return xmp
+	}
+	if compressed {
+		return inflate(text)
+	}
+	return text
+}
+
+// inflate decompresses a zlib stream, or returns nil when it is damaged
+func inflate(data []byte) []byte {
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil
+	}
+	defer r.Close()
+	inflated, err := io.ReadAll(io.LimitReader(r, maxInflatedMetadata))
+	if err != nil {
+		return nil
+	}
+	return inflated
+}
+
+// embedPNGMetadata inserts metadata chunks after the header chunk, which is
+// always first
+func embedPNGMetadata(data []byte, m imageMetadata) []byte {
+	var chunks []byte
+	chunk := func(kind string, parts ...[]byte) {
+		var body []byte
+		for _, part := range parts {
+			body = append(body, part...)
+		}
+		chunks = binary.BigEndian.AppendUint32(chunks, uint32(len(body)))
+		start := len(chunks)
+		chunks = append(chunks, kind...)
+		chunks = append(chunks, body...)
+		chunks = binary.BigEndian.AppendUint32(chunks, crc32.ChecksumIEEE(chunks[start:]))
+	}
+	if len(m.icc) > 0 {
+		var compressed bytes.Buffer
+		w := zlib.NewWriter(&compressed)
+		w.Write(m.icc)
+		w.Close()
+		chunk("iCCP", []byte("ICC profile\x00\x00"), compressed.Bytes())
+	}
+	if len(m.exif) > 0 {
+		chunk("eXIf", m.exif)
+	}
+	if len(m.xmp) > 0 {
+		// Uncompressed, with no language tag or translated keyword
+		chunk("iTXt", []byte(pngXMPKeyword+"\x00\x00\x00\x00\x00"), m.xmp)
+	}
+
+	// The signature, then the length, type, 13 bytes and CRC of the header
+	headerEnd := len(pngSignature) + 25
+	embedded := make([]byte, 0, len(data)+len(chunks))
+	embedded = append(embedded, data[:headerEnd]...)
+	embedded = append(embedded, chunks...)
+	return append(embedded, data[headerEnd:]...)
+}
+
+// readWebPChunks splits a WebP file into its chunks
+func readWebPChunks(data []byte) []webpChunk {
+	var chunks []webpChunk
+	for i := 12; i+8 <= len(data); {
+		size := int(binary.LittleEndian.Uint32(data[i+4:]))
+		if size < 0 || size > len(data)-i-8 {
+			break
+		}
+		chunks = append(chunks, webpChunk{string(data[i : i+4]), data[i+8 : i+8+size]})
+		i += 8 + size + size%2
+	}
+	return chunks
+}
+
+// readWebPMetadata reads the EXIF, XMP and ICCP chunks of the extended
+// container
+func readWebPMetadata(data []byte) imageMetadata {
+	var m imageMetadata
+	for _, chunk := range readWebPChunks(data) {
+		switch chunk.fourCC {
+		case "EXIF":
+			// Some writers keep the header of the JPEG segment
+			m.exif = bytes.TrimPrefix(chunk.data, jpegEXIFHeader)
+		case "XMP ":
+			m.xmp = chunk.data
+		case "ICCP":
+			m.icc = chunk.data
+		}
+	}
+	return m
+}
+
+// VP8X flags of the metadata chunks and the alpha channel
+const (
+	webpFlagICC   = 0x20
+	webpFlagAlpha = 0x10
+	webpFlagEXIF  = 0x08
+	webpFlagXMP   = 0x04
+)
+
+// embedWebPMetadata rewrites a WebP file in the extended container, which
+// holds the colour profile before the image data and EXIF and XMP after it
+func embedWebPMetadata(data []byte, m imageMetadata) []byte {
+	var header []byte
+	var frames []webpChunk
+	for _, chunk := range readWebPChunks(data) {
+		switch chunk.fourCC {
+		case "VP8X":
+			header = bytes.Clone(chunk.data)
+		case "ICCP", "EXIF", "XMP ":
+		case "VP8L":
+			frames = append(frames, chunk)
+			if header == nil && len(chunk.data) >= 5 {
+				// The signature, then 14 bits each of width and height less one
+				// and the alpha bit
+				bits := binary.LittleEndian.Uint32(chunk.data[1:])
+				header = webpHeader(int(bits&0x3fff)+1, int(bits>>14&0x3fff)+1)
+				if bits>>28&1 == 1 {
+					header[0] |= webpFlagAlpha
+				}
+			}
+		case "VP8 ":
+			frames = append(frames, chunk)
+			if header == nil && len(chunk.data) >= 10 {
+				// The frame tag and start code, then the 14-bit dimensions
+				header = webpHeader(int(binary.LittleEndian.Uint16(chunk.data[6:])&0x3fff),
+					int(binary.LittleEndian.Uint16(chunk.data[8:])&0x3fff))
+			}
+		default:
+			frames = append(frames, chunk)
+		}
+	}
+	if header == nil {
+		return data
+	}
+
+	chunks := []webpChunk{{"VP8X", header}}
+	if len(m.icc) > 0 {
+		header[0] |= webpFlagICC
+		chunks = append(chunks, webpChunk{"ICCP", m.icc})
+	}
+	chunks = append(chunks, frames...)
+	if len(m.exif) > 0 {
+		header[0] |= webpFlagEXIF
+		chunks = append(chunks, webpChunk{"EXIF", m.exif})
+	}
+	if len(m.xmp) > 0 {
+		header[0] |= webpFlagXMP
+		chunks = append(chunks, webpChunk{"XMP ", m.xmp})
+	}
+	var embedded bytes.Buffer
+	writeWebP(&embedded, chunks)
+	return embedded.Bytes()
+}
+
+// webpHeader returns the data of a VP8X chunk for a canvas, without flags
+func webpHeader(width, height int) []byte {
+	header := make([]byte, 10)
+	putUint24(header[4:], width-1)
+	putUint24(header[7:], height-1)
+	return header
+}
@@ -0,0 +1,188 @@
+package image
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/disintegration/imaging"
+	"github.com/eka026/File-Format-Converter/internal/domain"
+)
+
+// applyTransforms runs the transform pipeline over img in order. Steps that
+// would grow the image past the resource limits carried by ctx fail before
+// allocating it.
+func applyTransforms(ctx context.Context, img image.Image, transforms []domain.ImageTransform) (image.Image, error) {
+	if len(transforms) == 0 {
+		return img, nil
+	}
+	defer domain.StartStage(ctx, domain.StageTransform)()
+
+	limits := domain.ResourceLimitsFromContext(ctx)
+	for i, t := range transforms {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		var err error
+		switch t.Kind {
+		case domain.TransformResize:
+			img, err = resizeTransform(img, t, limits)
+		case domain.TransformCrop:
+			img, err = cropTransform(img, t)
+		case domain.TransformRotate:
+			img, err = rotateTransform(img, t.Angle, limits)
+		case domain.TransformFlip:
+			if t.Flip == domain.FlipVertical {
+				img = imaging.FlipV(img)
+			} else {
+				img = imaging.FlipH(img)
+			}
+		default:
+			err = t.Validate()
+		}
+		var conversionErr *domain.ConversionError
+		if errors.As(err, &conversionErr) {
+			return nil, err
+		}
+		if err != nil {
+			return nil, domain.Errorf(domain.ErrorCodeInvalidOptions, "transform %d: %w", i+1, err)
+		}
+	}
+	return img, nil
+}
+
+// resizeTransform scales img as the transform's mode describes
+func resizeTransform(img image.Image, t domain.ImageTransform, limits domain.ResourceLimits) (image.Image, error) {
+	filter := resampleFilter(t.Filter)
+	bounds := img.Bounds()
+	switch t.Mode {
+	case domain.ResizeFill:
+		if err := limits.CheckImageSize(t.Width, t.Height); err != nil {
+			return nil, err
+		}
+		return imaging.Fill(img, t.Width, t.Height, imaging.Center, filter), nil
+	case domain.ResizeExact:
+		// A zero dimension follows the aspect ratio, as imaging.Resize does
+		width, height := t.Width, t.Height
+		if width == 0 {
+			width = int(math.Round(float64(bounds.Dx()) * float64(height) / float64(bounds.Dy())))
+		}
+		if height == 0 {
+			height = int(math.Round(float64(bounds.Dy()) * float64(width) / float64(bounds.Dx())))
+		}
+		if err := limits.CheckImageSize(width, height); err != nil {
+			return nil, err
+		}
+		return imaging.Resize(img, t.Width, t.Height, filter), nil
+	default:
+		// Fit only shrinks, so it cannot exceed the limits
+		width, height := t.Width, t.Height
+		if width == 0 {
+			width = bounds.Dx()
+		}
+		if height == 0 {
+			height = bounds.Dy()
+		}
+		return imaging.Fit(img, width, height, filter), nil
+	}
+}
+
+// cropTransform cuts the transform's box or aspect ratio out of img
+func cropTransform(img image.Image, t domain.ImageTransform) (image.Image, error) {
+	bounds := img.Bounds()
+	if t.Aspect != "" {
+		aspectWidth, aspectHeight, err := domain.ParseAspect(t.Aspect)
+		if err != nil {
+			return nil, err
+		}
+		// The largest box of the ratio spans the full width or the full height
+		width, height := bounds.Dx(), bounds.Dy()
+		ratio := aspectWidth / aspectHeight
+		if float64(width) > float64(height)*ratio {
+			width = max(1, int(math.Round(float64(height)*ratio)))
+		} else {
+			height = max(1, int(math.Round(float64(width)/ratio)))
+		}
+		return imaging.CropCenter(img, width, height), nil
+	}
+	if t.Centered {
+		return imaging.CropCenter(img, t.Width, t.Height), nil
+	}
+
+	box := image.Rect(t.X, t.Y, t.X+t.Width, t.Y+t.Height).Add(bounds.Min)
+	if !box.Overlaps(bounds) {
+		return nil, fmt.Errorf("crop box %dx%d+%d+%d lies outside the %dx%d image", t.Width, t.Height, t.X, t.Y, bounds.Dx(), bounds.Dy())
+	}
+	return imaging.Crop(img, box), nil
+}
+
+// rotateTransform turns img clockwise by angle degrees. Quarter turns are
+// exact; other angles grow the canvas and leave transparent corners.
+func rotateTransform(img image.Image, angle float64, limits domain.ResourceLimits) (image.Image, error) {
+	angle = math.Mod(angle, 360)
+	if angle < 0 {
+		angle += 360
+	}
+	switch angle {
+	case 0:
+		return img, nil
+	case 90:
+		return imaging.Rotate270(img), nil
+	case 180:
+		return imaging.Rotate180(img), nil
+	case 270:
+		return imaging.Rotate90(img), nil
+	}
+
+	sin, cos := math.Sincos(angle * math.Pi / 180)
+	width, height := float64(img.Bounds().Dx()), float64(img.Bounds().Dy())
+	rotatedWidth := int(math.Ceil(math.Abs(width*cos) + math.Abs(height*sin)))
+	rotatedHeight := int(math.Ceil(math.Abs(width*sin) + math.Abs(height*cos)))
+	if err := limits.CheckImageSize(rotatedWidth, rotatedHeight); err != nil {
+		return nil, err
+	}
+	// imaging.Rotate turns counter-clockwise
+	return imaging.Rotate(img, -angle, color.Transparent), nil
+}
+
+// autoOrient turns img upright from the way an EXIF orientation (1-8) says
+// it was stored
+func autoOrient(img image.Image, orientation int) image.Image {
+	switch orientation {
+	case 2:
+		return imaging.FlipH(img)
+	case 3:
+		return imaging.Rotate180(img)
+	case 4:
+		return imaging.FlipV(img)
+	case 5:
+		return imaging.Transpose(img)
+	case 6:
+		return imaging.Rotate270(img)
+	case 7:
+		return imaging.Transverse(img)
+	case 8:
+		return imaging.Rotate90(img)
+	default:
+		return img
+	}
+}
+
+// resampleFilter returns the imaging filter for a resample filter name
+func resampleFilter(filter domain.ResampleFilter) imaging.ResampleFilter {
+	switch filter {
+	case domain.FilterCatmullRom:
+		return imaging.CatmullRom
+	case domain.FilterLinear:
+		return imaging.Linear
+	case domain.FilterBox:
+		return imaging.Box
+	case domain.FilterNearest:
+		return imaging.NearestNeighbor
+	default:
+		return imaging.Lanczos
+	}
+}
@@ -0,0 +1,1131 @@
+package image
+
+import (
+	"fmt"
+	"image"
+	"math"
+)
+
+// vp8MaxSize is the largest width or height of a VP8 frame
+const vp8MaxSize = 16383
+
+// Token probability planes of VP8 coefficients, in the order of the tables
+const (
+	vp8PlaneY1WithY2 = iota
+	vp8PlaneY2
+	vp8PlaneUV
+)
+
+// Intra prediction modes of a 16x16 luma or 8x8 chroma block
+const (
+	vp8PredDC = iota
+	vp8PredV
+	vp8PredH
+	vp8PredTM
+)
+
+// vp8MaxLevel is the largest quantized coefficient a token can code
+const vp8MaxLevel = 2048 + 66
+
+// encodeVP8 encodes img as a VP8 key frame at quality (1-100).
+//
+// Every macroblock is predicted as a whole from its reconstructed
+// neighbours, choosing the 16x16 luma and 8x8 chroma modes with the smallest
+// error, and its residual is coded with the default token probabilities. The
+// encoder reconstructs each macroblock exactly as a decoder will, so
+// predictions do not drift. Colours are converted to 4:2:0 Y'CbCr with the
+// BT.601 studio range that WebP decoders expect.
+func encodeVP8(img *image.NRGBA, quality int) ([]byte, error) {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > vp8MaxSize || height > vp8MaxSize {
+		return nil, fmt.Errorf("lossy WebP images are limited to %dx%d pixels, got %dx%d", vp8MaxSize, vp8MaxSize, width, height)
+	}
+
+	e := newVP8Encoder(width, height, quality)
+	e.importPixels(img)
+	e.writeHeader()
+	for mby := 0; mby < e.mbh; mby++ {
+		e.left = vp8NonZero{}
+		tokens := e.tokens[mby&(len(e.tokens)-1)]
+		for mbx := 0; mbx < e.mbw; mbx++ {
+			e.encodeMacroblock(mbx, mby, tokens)
+		}
+	}
+	return e.frame(width, height)
+}
+
+// vp8Encoder holds the state of encoding one frame
+type vp8Encoder struct {
+	mbw, mbh int
+	// qIndex is the quantizer index and quant the step sizes it selects
+	qIndex int
+	quant  vp8Quant
+	// src holds the source planes, padded to whole macroblocks, and rec the
+	// reconstruction a decoder will see, which predictions are made from
+	src, rec [3]vp8Plane
+	// modes is the first partition and tokens the coefficient partitions,
+	// one per macroblock row in turn
+	modes  *vp8BoolEncoder
+	tokens []*vp8BoolEncoder
+	// up holds whether the bottom blocks of the macroblocks above had
+	// coefficients, and left the same for the right blocks of the macroblock
+	// to the left
+	up   []vp8NonZero
+	left vp8NonZero
+}
+
+// vp8Plane is one 8-bit colour plane
+type vp8Plane struct {
+	pix    []uint8
+	stride int
+}
+
+// vp8Quant holds the DC and AC step sizes of each kind of block
+type vp8Quant struct {
+	y1, y2, uv [2]int32
+}
+
+// vp8NonZero records which blocks along one edge of a macroblock had
+// coefficients, which selects the probabilities of their neighbours' first
+// tokens
+type vp8NonZero struct {
+	y2   uint8
+	y    [4]uint8
+	u, v [2]uint8
+}
+
+func newVP8Encoder(width, height, quality int) *vp8Encoder {
+	e := &vp8Encoder{
+		mbw:    (width + 15) / 16,
+		mbh:    (height + 15) / 16,
+		qIndex: vp8QuantIndex(quality),
+		modes:  newVP8BoolEncoder(),
+	}
+	e.up = make([]vp8NonZero, e.mbw)
+	for i := range e.src {
+		size := 16
+		if i > 0 {
+			size = 8
+		}
+		stride := e.mbw * size
+		e.src[i] = vp8Plane{pix: make([]uint8, stride*e.mbh*size), stride: stride}
+		e.rec[i] = vp8Plane{pix: make([]uint8, stride*e.mbh*size), stride: stride}
+	}
+
+	q := e.qIndex
+	e.quant = vp8Quant{
+		y1: [2]int32{vp8DCQuant[q], vp8ACQuant[q]},
+		y2: [2]int32{vp8DCQuant[q] * 2, max(vp8ACQuant[q]*155/100, 8)},
+		// The chroma DC step is capped at 132
+		uv: [2]int32{vp8DCQuant[min(q, 117)], vp8ACQuant[q]},
+	}
+
+	// Large frames spread their coefficients over several partitions, each
+	// of which is limited to 16MiB
+	partitions := 1
+	for partitions < 8 && partitions < e.mbh && e.mbw*e.mbh/partitions > 1<<14 {
+		partitions *= 2
+	}
+	for i := 0; i < partitions; i++ {
+		e.tokens = append(e.tokens, newVP8BoolEncoder())
+	}
+	return e
+}
+
+// vp8QuantIndex maps quality (1-100) to a quantizer index on libwebp's
+// curve, so qualities compare with those of cwebp
+func vp8QuantIndex(quality int) int {
+	c := float64(quality) / 100
+	if c < 0.75 {
+		c *= 2.0 / 3
+	} else {
+		c = 2*c - 1
+	}
+	return int(127 * (1 - math.Cbrt(c)))
+}
+
+// writeHeader starts the first partition with the frame-wide settings: the
+// colour space, no segmentation, no loop filter, the quantizer and no changes
+// to the default token probabilities
+func (e *vp8Encoder) writeHeader() {
+	h := e.modes
+	h.putBit(false, 128) // colour space
+	h.putBit(false, 128) // clamping type
+	h.putBit(false, 128) // segmentation
+	h.putBit(false, 128) // filter type
+	h.putLiteral(0, 6)   // filter level
+	h.putLiteral(0, 3)   // sharpness
+	h.putBit(false, 128) // filter deltas
+	log2Partitions := 0
+	for 1<<log2Partitions < len(e.tokens) {
+		log2Partitions++
+	}
+	h.putLiteral(log2Partitions, 2)
+	h.putLiteral(e.qIndex, 7)
+	for i := 0; i < 5; i++ {
+		h.putBit(false, 128) // quantizer deltas
+	}
+	h.putBit(false, 128) // refresh entropy probabilities
+	for _, plane := range vp8TokenUpdateProbs {
+		for _, band := range plane {
+			for _, context := range band {
+				for _, prob := range context {
+					h.putBit(false, prob)
+				}
+			}
+		}
+	}
+	h.putBit(false, 128) // skip flags
+}
+
+// importPixels converts img to Y'CbCr as libwebp does, replicating the
+// right and bottom edges into the macroblock padding
+func (e *vp8Encoder) importPixels(img *image.NRGBA) {
+	bounds := img.Bounds()
+	rgb := func(x, y int) (int32, int32, int32) {
+		i := img.PixOffset(bounds.Min.X+min(x, bounds.Dx()-1), bounds.Min.Y+min(y, bounds.Dy()-1))
+		return int32(img.Pix[i]), int32(img.Pix[i+1]), int32(img.Pix[i+2])
+	}
+
+	luma := &e.src[0]
+	for y := 0; y < e.mbh*16; y++ {
+		for x := 0; x < e.mbw*16; x++ {
+			r, g, b := rgb(x, y)
+			luma.pix[y*luma.stride+x] = uint8((16839*r + 33059*g + 6420*b + 16<<16 + 1<<15) >> 16)
+		}
+	}
+
+	// Chroma is computed from the sum of each 2x2 block of pixels
+	cb, cr := &e.src[1], &e.src[2]
+	for y := 0; y < e.mbh*8; y++ {
+		for x := 0; x < e.mbw*8; x++ {
+			var r, g, b int32
+			for _, p := range [4][2]int{{0, 0}, {1, 0}, {0, 1}, {1, 1}} {
+				pr, pg, pb := rgb(2*x+p[0], 2*y+p[1])
+				r, g, b = r+pr, g+pg, b+pb
+			}
+			cb.pix[y*cb.stride+x] = clip8((-9719*r - 19081*g + 28800*b + 128<<18 + 1<<17) >> 18)
+			cr.pix[y*cr.stride+x] = clip8((28800*r - 24116*g - 4684*b + 128<<18 + 1<<17) >> 18)
+		}
+	}
+}
+
+// encodeMacroblock codes the prediction modes of one macroblock to the
+// first partition and its residual to tokens, and reconstructs it
+func (e *vp8Encoder) encodeMacroblock(mbx, mby int, tokens *vp8BoolEncoder) {
+	var lumaPred [16 * 16]uint8
+	lumaMode := e.bestPrediction(lumaPred[:], mbx, mby, 0)
+	var chromaPred [2][8 * 8]uint8
+	chromaMode := e.bestPrediction(chromaPred[0][:], mbx, mby, 1, 2)
+	e.predict(chromaPred[1][:], mbx, mby, 2, chromaMode)
+
+	// A key frame macroblock with 16x16 luma prediction, then the modes
+	m := e.modes
+	m.putBit(true, 145)
+	switch lumaMode {
+	case vp8PredDC:
+		m.putBit(false, 156)
+		m.putBit(false, 163)
+	case vp8PredV:
+		m.putBit(false, 156)
+		m.putBit(true, 163)
+	case vp8PredH:
+		m.putBit(true, 156)
+		m.putBit(false, 128)
+	case vp8PredTM:
+		m.putBit(true, 156)
+		m.putBit(true, 128)
+	}
+	m.putBit(chromaMode != vp8PredDC, 142)
+	if chromaMode != vp8PredDC {
+		m.putBit(chromaMode != vp8PredV, 114)
+		if chromaMode != vp8PredV {
+			m.putBit(chromaMode == vp8PredTM, 183)
+		}
+	}
+
+	up, left := &e.up[mbx], &e.left
+
+	// The DC coefficients of the 16 luma blocks are coded together, as the
+	// Walsh-Hadamard transform of a second order block
+	var coeffs [16][16]int32
+	var dcs [16]int32
+	for n := range coeffs {
+		x, y := 16*mbx+4*(n%4), 16*mby+4*(n/4)
+		coeffs[n] = e.residual(0, lumaPred[:], 16, x, y)
+		dcs[n] = coeffs[n][0]
+	}
+	y2 := vp8ForwardWHT(dcs)
+	var levels [16]int32
+	for i, c := range y2 {
+		levels[i] = quantizeCoefficient(c, e.quant.y2[min(i, 1)], vp8Bias[vp8PlaneY2][min(i, 1)])
+		y2[i] = dequantizeCoefficient(levels[i], e.quant.y2[min(i, 1)])
+	}
+	nz := tokens.putCoefficients(vp8PlaneY2, left.y2+up.y2, &levels, 0)
+	left.y2, up.y2 = nz, nz
+	dcs = vp8InverseWHT(y2)
+
+	for n := range coeffs {
+		for i := 1; i < 16; i++ {
+			levels[i] = quantizeCoefficient(coeffs[n][i], e.quant.y1[1], vp8Bias[vp8PlaneY1WithY2][1])
+			coeffs[n][i] = dequantizeCoefficient(levels[i], e.quant.y1[1])
+		}
+		coeffs[n][0] = dcs[n]
+		bx, by := n%4, n/4
+		nz := tokens.putCoefficients(vp8PlaneY1WithY2, left.y[by]+up.y[bx], &levels, 1)
+		left.y[by], up.y[bx] = nz, nz
+	}
+	for n := range coeffs {
+		x, y := 4*(n%4), 4*(n/4)
+		e.reconstruct(0, lumaPred[:], 16, 16*mbx, 16*mby, x, y, &coeffs[n])
+	}
+
+	for plane, nzUp, nzLeft := 1, &up.u, &left.u; plane <= 2; plane, nzUp, nzLeft = plane+1, &up.v, &left.v {
+		pred := chromaPred[plane-1][:]
+		for n := 0; n < 4; n++ {
+			bx, by := n%2, n/2
+			coeffs := e.residual(plane, pred, 8, 8*mbx+4*bx, 8*mby+4*by)
+			for i, c := range coeffs {
+				levels[i] = quantizeCoefficient(c, e.quant.uv[min(i, 1)], vp8Bias[vp8PlaneUV][min(i, 1)])
+				coeffs[i] = dequantizeCoefficient(levels[i], e.quant.uv[min(i, 1)])
+			}
+			nz := tokens.putCoefficients(vp8PlaneUV, nzLeft[by]+nzUp[bx], &levels, 0)
+			nzLeft[by], nzUp[bx] = nz, nz
+			e.reconstruct(plane, pred, 8, 8*mbx, 8*mby, 4*bx, 4*by, &coeffs)
+		}
+	}
+}
+
+// bestPrediction fills pred with the prediction of the first of planes that,
+// summed over planes, differs least from the source, and returns its mode
+func (e *vp8Encoder) bestPrediction(pred []uint8, mbx, mby int, planes ...int) int {
+	size := 16
+	if planes[0] > 0 {
+		size = 8
+	}
+	candidate := make([]uint8, size*size)
+	best, bestError := vp8PredDC, int64(-1)
+	for mode := vp8PredDC; mode <= vp8PredTM; mode++ {
+		var sse int64
+		for _, plane := range planes {
+			e.predict(candidate, mbx, mby, plane, mode)
+			src := &e.src[plane]
+			for y := 0; y < size; y++ {
+				row := src.pix[(mby*size+y)*src.stride+mbx*size:]
+				for x := 0; x < size; x++ {
+					d := int64(row[x]) - int64(candidate[y*size+x])
+					sse += d * d
+				}
+			}
+		}
+		if bestError < 0 || sse < bestError {
+			best, bestError = mode, sse
+		}
+	}
+	e.predict(pred, mbx, mby, planes[0], best)
+	return best
+}
+
+// predict fills pred with the mode prediction of a macroblock of plane from
+// its reconstructed neighbours. Like the decoder, it takes 127 for the row
+// above the frame and 129 for the column left of it.
+func (e *vp8Encoder) predict(pred []uint8, mbx, mby, plane, mode int) {
+	size := 16
+	if plane > 0 {
+		size = 8
+	}
+	rec := &e.rec[plane]
+	x0, y0 := mbx*size, mby*size
+
+	var top, left [16]int32
+	corner := int32(127)
+	for i := 0; i < size; i++ {
+		top[i], left[i] = 127, 129
+		if mby > 0 {
+			top[i] = int32(rec.pix[(y0-1)*rec.stride+x0+i])
+		}
+		if mbx > 0 {
+			left[i] = int32(rec.pix[(y0+i)*rec.stride+x0-1])
+		}
+	}
+	if mby > 0 && mbx > 0 {
+		corner = int32(rec.pix[(y0-1)*rec.stride+x0-1])
+	} else if mby > 0 {
+		corner = 129
+	}
+
+	var sumTop, sumLeft int32
+	for i := 0; i < size; i++ {
+		sumTop += top[i]
+		sumLeft += left[i]
+	}
+	dc := int32(128)
+	switch {
+	case mbx > 0 && mby > 0:
+		dc = (sumTop + sumLeft + int32(size)) / int32(2*size)
+	case mbx > 0:
+		dc = (sumLeft + int32(size/2)) / int32(size)
+	case mby > 0:
+		dc = (sumTop + int32(size/2)) / int32(size)
+	}
+
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			var p int32
+			switch mode {
+			case vp8PredDC:
+				p = dc
+			case vp8PredV:
+				p = top[x]
+			case vp8PredH:
+				p = left[y]
+			case vp8PredTM:
+				p = left[y] + top[x] - corner
+			}
+			pred[y*size+x] = clip8(p)
+		}
+	}
+}
+
+// residual returns the forward DCT of the 4x4 block at (x, y) of the source
+// plane less its prediction, which is size pixels wide
+func (e *vp8Encoder) residual(plane int, pred []uint8, size, x, y int) [16]int32 {
+	src := &e.src[plane]
+	px, py := x%size, y%size
+	var diff [16]int32
+	for j := 0; j < 4; j++ {
+		for i := 0; i < 4; i++ {
+			diff[4*j+i] = int32(src.pix[(y+j)*src.stride+x+i]) - int32(pred[(py+j)*size+px+i])
+		}
+	}
+	return vp8ForwardDCT(diff)
+}
+
+// reconstruct adds the inverse DCT of coeffs to the prediction of the 4x4
+// block at (x, y) of the macroblock at (mx, my), and stores it as the
+// decoder will see it
+func (e *vp8Encoder) reconstruct(plane int, pred []uint8, size, mx, my, x, y int, coeffs *[16]int32) {
+	rec := &e.rec[plane]
+	var block [16]int32
+	for j := 0; j < 4; j++ {
+		for i := 0; i < 4; i++ {
+			block[4*j+i] = int32(pred[(y+j)*size+x+i])
+		}
+	}
+	vp8InverseDCT(coeffs, &block)
+	for j := 0; j < 4; j++ {
+		for i := 0; i < 4; i++ {
+			rec.pix[(my+y+j)*rec.stride+mx+x+i] = uint8(block[4*j+i])
+		}
+	}
+}
+
+// frame assembles the frame header, the first partition and the
+// coefficient partitions
+func (e *vp8Encoder) frame(width, height int) ([]byte, error) {
+	first := e.modes.flush()
+	if len(first) >= 1<<19 {
+		return nil, fmt.Errorf("lossy WebP frame header of %d bytes is too large", len(first))
+	}
+
+	// A shown key frame of version 0
+	tag := uint32(len(first))<<5 | 1<<4
+	frame := []byte{
+		byte(tag), byte(tag >> 8), byte(tag >> 16),
+		0x9d, 0x01, 0x2a,
+		byte(width), byte(width >> 8), byte(height), byte(height >> 8),
+	}
+	frame = append(frame, first...)
+	partitions := make([][]byte, len(e.tokens))
+	for i, tokens := range e.tokens {
+		partitions[i] = tokens.flush()
+		if len(partitions[i]) >= 1<<24 {
+			return nil, fmt.Errorf("lossy WebP coefficient partition of %d bytes is too large", len(partitions[i]))
+		}
+	}
+	for _, partition := range partitions[:len(partitions)-1] {
+		frame = append(frame, byte(len(partition)), byte(len(partition)>>8), byte(len(partition)>>16))
+	}
+	for _, partition := range partitions {
+		frame = append(frame, partition...)
+	}
+	return frame, nil
+}
+
+// vp8Bias is the rounding of quantization in 1/256 steps of the DC and AC
+// coefficients of each plane, as libwebp rounds them
+var vp8Bias = [3][2]int32{
+	vp8PlaneY1WithY2: {96, 110},
+	vp8PlaneY2:       {96, 108},
+	vp8PlaneUV:       {110, 115},
+}
+
+// quantizeCoefficient divides c by step, rounding the magnitude up from
+// bias/256 of a step
+func quantizeCoefficient(c, step, bias int32) int32 {
+	level := (abs32(c)*256 + step*bias) / (step * 256)
+	level = min(level, vp8MaxLevel)
+	if c < 0 {
+		return -level
+	}
+	return level
+}
+
+// dequantizeCoefficient returns the coefficient a decoder reads for level,
+// which it stores in 16 bits
+func dequantizeCoefficient(level, step int32) int32 {
+	return int32(int16(level * step))
+}
+
+// vp8ForwardDCT transforms a 4x4 block of residuals, as libvpx does
+func vp8ForwardDCT(in [16]int32) [16]int32 {
+	var tmp, out [16]int32
+	for i := 0; i < 4; i++ {
+		row := in[4*i:]
+		a := (row[0] + row[3]) * 8
+		b := (row[1] + row[2]) * 8
+		c := (row[1] - row[2]) * 8
+		d := (row[0] - row[3]) * 8
+		tmp[4*i+0] = a + b
+		tmp[4*i+2] = a - b
+		tmp[4*i+1] = (c*2217 + d*5352 + 14500) >> 12
+		tmp[4*i+3] = (d*2217 - c*5352 + 7500) >> 12
+	}
+	for i := 0; i < 4; i++ {
+		a := tmp[i] + tmp[12+i]
+		b := tmp[4+i] + tmp[8+i]
+		c := tmp[4+i] - tmp[8+i]
+		d := tmp[i] - tmp[12+i]
+		out[i] = (a + b + 7) >> 4
+		out[8+i] = (a - b + 7) >> 4
+		out[4+i] = (c*2217 + d*5352 + 12000) >> 16
+		if d != 0 {
+			out[4+i]++
+		}
+		out[12+i] = (d*2217 - c*5352 + 51000) >> 16
+	}
+	return out
+}
+
+// vp8InverseDCT adds the inverse transform of coeffs to block, clipping to
+// 8 bits, with the decoder's integer arithmetic
+func vp8InverseDCT(coeffs *[16]int32, block *[16]int32) {
+	const (
+		c1 = 85627 // 65536 * cos(pi/8) * sqrt(2)
+		c2 = 35468 // 65536 * sin(pi/8) * sqrt(2)
+	)
+	var m [4][4]int32
+	for i := 0; i < 4; i++ {
+		a := coeffs[i] + coeffs[8+i]
+		b := coeffs[i] - coeffs[8+i]
+		c := (coeffs[4+i]*c2)>>16 - (coeffs[12+i]*c1)>>16
+		d := (coeffs[4+i]*c1)>>16 + (coeffs[12+i]*c2)>>16
+		m[i] = [4]int32{a + d, b + c, b - c, a - d}
+	}
+	for j := 0; j < 4; j++ {
+		dc := m[0][j] + 4
+		a := dc + m[2][j]
+		b := dc - m[2][j]
+		c := (m[1][j]*c2)>>16 - (m[3][j]*c1)>>16
+		d := (m[1][j]*c1)>>16 + (m[3][j]*c2)>>16
+		row := block[4*j:]
+		row[0] = int32(clip8(row[0] + (a+d)>>3))
+		row[1] = int32(clip8(row[1] + (b+c)>>3))
+		row[2] = int32(clip8(row[2] + (b-c)>>3))
+		row[3] = int32(clip8(row[3] + (a-d)>>3))
+	}
+}
+
+// vp8ForwardWHT transforms the DC coefficients of the 16 luma blocks of a
+// macroblock, as libvpx does
+func vp8ForwardWHT(in [16]int32) [16]int32 {
+	var tmp, out [16]int32
+	for i := 0; i < 4; i++ {
+		row := in[4*i:]
+		a := (row[0] + row[2]) * 4
+		d := (row[1] + row[3]) * 4
+		c := (row[1] - row[3]) * 4
+		b := (row[0] - row[2]) * 4
+		tmp[4*i+0] = a + d
+		if a != 0 {
+			tmp[4*i+0]++
+		}
+		tmp[4*i+1] = b + c
+		tmp[4*i+2] = b - c
+		tmp[4*i+3] = a - d
+	}
+	for i := 0; i < 4; i++ {
+		a := tmp[i] + tmp[8+i]
+		d := tmp[4+i] + tmp[12+i]
+		c := tmp[4+i] - tmp[12+i]
+		b := tmp[i] - tmp[8+i]
+		for k, v := range [4]int32{a + d, b + c, b - c, a - d} {
+			if v < 0 {
+				v++
+			}
+			out[4*k+i] = (v + 3) >> 3
+		}
+	}
+	return out
+}
+
+// vp8InverseWHT recovers the DC coefficients of the 16 luma blocks, with
+// the decoder's integer arithmetic
+func vp8InverseWHT(in [16]int32) [16]int32 {
+	var m, out [16]int32
+	for i := 0; i < 4; i++ {
+		a0 := in[i] + in[12+i]
+		a1 := in[4+i] + in[8+i]
+		a2 := in[4+i] - in[8+i]
+		a3 := in[i] - in[12+i]
+		m[i] = a0 + a1
+		m[8+i] = a0 - a1
+		m[4+i] = a3 + a2
+		m[12+i] = a3 - a2
+	}
+	for i := 0; i < 4; i++ {
+		dc := m[4*i] + 3
+		a0 := dc + m[4*i+3]
+		a1 := m[4*i+1] + m[4*i+2]
+		a2 := m[4*i+1] - m[4*i+2]
+		a3 := dc - m[4*i+3]
+		out[4*i+0] = int32(int16((a0 + a1) >> 3))
+		out[4*i+1] = int32(int16((a3 + a2) >> 3))
+		out[4*i+2] = int32(int16((a0 - a1) >> 3))
+		out[4*i+3] = int32(int16((a3 - a2) >> 3))
+	}
+	return out
+}
+
+func clip8(v int32) uint8 {
+	return uint8(min(max(v, 0), 255))
+}
+
+func abs32(v int32) int32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// vp8BoolEncoder is the boolean entropy encoder of RFC 6386 section 7
+type vp8BoolEncoder struct {
+	buf      []byte
+	rng      uint32
+	bottom   uint32
+	bitCount int
+}
+
+func newVP8BoolEncoder() *vp8BoolEncoder {
+	return &vp8BoolEncoder{rng: 255, bitCount: 24}
+}
+
+// putBit codes bit, which is false with probability prob/256
+func (b *vp8BoolEncoder) putBit(bit bool, prob uint8) {
+	split := 1 + (b.rng-1)*uint32(prob)>>8
+	if bit {
+		b.bottom += split
+		b.rng -= split
+	} else {
+		b.rng = split
+	}
+	for b.rng < 128 {
+		b.rng <<= 1
+		if b.bottom&(1<<31) != 0 {
+			b.carry()
+		}
+		b.bottom <<= 1
+		b.bitCount--
+		if b.bitCount == 0 {
+			b.buf = append(b.buf, byte(b.bottom>>24))
+			b.bottom &= 1<<24 - 1
+			b.bitCount = 8
+		}
+	}
+}
+
+// putLiteral codes the n low bits of v, most significant first, at even odds
+func (b *vp8BoolEncoder) putLiteral(v, n int) {
+	for n--; n >= 0; n-- {
+		b.putBit(v>>n&1 == 1, 128)
+	}
+}
+
+// carry propagates a carry into the bytes already written
+func (b *vp8BoolEncoder) carry() {
+	i := len(b.buf) - 1
+	for ; i >= 0 && b.buf[i] == 0xff; i-- {
+		b.buf[i] = 0
+	}
+	if i >= 0 {
+		b.buf[i]++
+	}
+}
+
+// flush writes out the pending bits and returns the coded bytes
+func (b *vp8BoolEncoder) flush() []byte {
+	c, v := b.bitCount, b.bottom
+	if v&(1<<(32-c)) != 0 {
+		b.carry()
+	}
+	v <<= c & 7
+	for c >>= 3; c > 0; c-- {
+		v <<= 8
+	}
+	for i := 0; i < 4; i++ {
+		b.buf = append(b.buf, byte(v>>24))
+		v <<= 8
+	}
+	return b.buf
+}
+
+// putCoefficients codes the quantized coefficients of one 4x4 block from
+// first on, in zig-zag order, with the token probabilities of plane and the
+// context of its neighbours. It returns 1 if any coefficient was coded.
+func (b *vp8BoolEncoder) putCoefficients(plane int, context uint8, levels *[16]int32, first int) uint8 {
+	last := -1
+	for n := 15; n >= first; n-- {
+		if levels[vp8Zigzag[n]] != 0 {
+			last = n
+			break
+		}
+	}
+
+	probs := &vp8DefaultTokenProbs[plane]
+	p := &probs[vp8Bands[first]][context]
+	b.putBit(last >= 0, p[0])
+	if last < 0 {
+		return 0
+	}
+	for n := first; n < 16; {
+		level := levels[vp8Zigzag[n]]
+		n++
+		if level == 0 {
+			b.putBit(false, p[1])
+			p = &probs[vp8Bands[n]][0]
+			continue
+		}
+		b.putBit(true, p[1])
+		if v := abs32(level); v == 1 {
+			b.putBit(false, p[2])
+			p = &probs[vp8Bands[n]][1]
+		} else {
+			b.putBit(true, p[2])
+			b.putLargeValue(p, v)
+			p = &probs[vp8Bands[n]][2]
+		}
+		b.putBit(level < 0, 128)
+		if n == 16 {
+			break
+		}
+		// End of block when no coefficients remain
+		b.putBit(n <= last, p[0])
+		if n > last {
+			break
+		}
+	}
+	return 1
+}
+
+// putLargeValue codes the magnitude v of a coefficient larger than 1
+func (b *vp8BoolEncoder) putLargeValue(p *[11]uint8, v int32) {
+	switch {
+	case v <= 4:
+		b.putBit(false, p[3])
+		b.putBit(v > 2, p[4])
+		if v > 2 {
+			b.putBit(v == 4, p[5])
+		}
+	case v <= 10:
+		b.putBit(true, p[3])
+		b.putBit(false, p[6])
+		if v <= 6 {
+			b.putBit(false, p[7])
+			b.putBit(v == 6, 159)
+		} else {
+			b.putBit(true, p[7])
+			b.putBit((v-7)&2 != 0, 165)
+			b.putBit((v-7)&1 != 0, 145)
+		}
+	default:
+		// Categories 3 to 6 code the offset from 11, 19, 35 and 67 in 3, 4,
+		// 5 and 11 extra bits
+		b.putBit(true, p[3])
+		b.putBit(true, p[6])
+		category := 3
+		for v < 3+8<<category {
+			category--
+		}
+		b.putBit(category >= 2, p[8])
+		b.putBit(category&1 == 1, p[9+category>>1])
+		extra := v - (3 + 8<<category)
+		probs := vp8ExtraBitProbs[category]
+		bits := 0
+		for bits < len(probs) && probs[bits] != 0 {
+			bits++
+		}
+		for i := 0; i < bits; i++ {
+			b.putBit(extra>>(bits-1-i)&1 == 1, probs[i])
+		}
+	}
+}
+
+// Coefficient positions in zig-zag order, in a 4x4 block
+var vp8Zigzag = [16]int{0, 1, 4, 8, 5, 2, 3, 6, 9, 12, 13, 10, 7, 11, 14, 15}
+
+// The band of each zig-zag position, which selects its token probabilities
+var vp8Bands = [17]int{0, 1, 2, 3, 6, 4, 5, 6, 6, 6, 6, 6, 6, 6, 6, 7, 0}
+
+// Probabilities of the extra bits of coefficient categories 3 to 6
+var vp8ExtraBitProbs = [4][12]uint8{
+	{173, 148, 140},
+	{176, 155, 140, 135},
+	{180, 157, 141, 134, 130},
+	{254, 254, 243, 230, 196, 177, 153, 140, 133, 130, 129},
+}
+
+// Step sizes of quantizer indexes, from RFC 6386 section 14.1
+var (
+	vp8DCQuant = [128]int32{
+		4, 5, 6, 7, 8, 9, 10, 10,
+		11, 12, 13, 14, 15, 16, 17, 17,
+		18, 19, 20, 20, 21, 21, 22, 22,
+		23, 23, 24, 25, 25, 26, 27, 28,
+		29, 30, 31, 32, 33, 34, 35, 36,
+		37, 37, 38, 39, 40, 41, 42, 43,
+		44, 45, 46, 46, 47, 48, 49, 50,
+		51, 52, 53, 54, 55, 56, 57, 58,
+		59, 60, 61, 62, 63, 64, 65, 66,
+		67, 68, 69, 70, 71, 72, 73, 74,
+		75, 76, 76, 77, 78, 79, 80, 81,
+		82, 83, 84, 85, 86, 87, 88, 89,
+		91, 93, 95, 96, 98, 100, 101, 102,
+		104, 106, 108, 110, 112, 114, 116, 118,
+		122, 124, 126, 128, 130, 132, 134, 136,
+		138, 140, 143, 145, 148, 151, 154, 157,
+	}
+	vp8ACQuant = [128]int32{
+		4, 5, 6, 7, 8, 9, 10, 11,
+		12, 13, 14, 15, 16, 17, 18, 19,
+		20, 21, 22, 23, 24, 25, 26, 27,
+		28, 29, 30, 31, 32, 33, 34, 35,
+		36, 37, 38, 39, 40, 41, 42, 43,
+		44, 45, 46, 47, 48, 49, 50, 51,
+		52, 53, 54, 55, 56, 57, 58, 60,
+		62, 64, 66, 68, 70, 72, 74, 76,
+		78, 80, 82, 84, 86, 88, 90, 92,
+		94, 96, 98, 100, 102, 104, 106, 108,
+		110, 112, 114, 116, 119, 122, 125, 128,
+		131, 134, 137, 140, 143, 146, 149, 152,
+		155, 158, 161, 164, 167, 170, 173, 177,
+		181, 185, 189, 193, 197, 201, 205, 209,
+		213, 217, 221, 225, 229, 234, 239, 245,
+		249, 254, 259, 264, 269, 274, 279, 284,
+	}
+)
+
+// Probabilities of updating each token probability of the four planes, from
+// RFC 6386 section 13.4. The encoder keeps the defaults, so it only codes
+// that none changes.
+var vp8TokenUpdateProbs = [4][8][3][11]uint8{
+	{
+		{
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{176, 246, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{223, 241, 252, 255, 255, 255, 255, 255, 255, 255, 255},
+			{249, 253, 253, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 244, 252, 255, 255, 255, 255, 255, 255, 255, 255},
+			{234, 254, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+			{253, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 246, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+			{239, 253, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+			{254, 255, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 248, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+			{251, 255, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 253, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+			{251, 254, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+			{254, 255, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 254, 253, 255, 254, 255, 255, 255, 255, 255, 255},
+			{250, 255, 254, 255, 254, 255, 255, 255, 255, 255, 255},
+			{254, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+	},
+	{
+		{
+			{217, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{225, 252, 241, 253, 255, 255, 254, 255, 255, 255, 255},
+			{234, 250, 241, 250, 253, 255, 253, 254, 255, 255, 255},
+		},
+		{
+			{255, 254, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{223, 254, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+			{238, 253, 254, 254, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 248, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+			{249, 254, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 253, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{247, 254, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 253, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+			{252, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 254, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+			{253, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 254, 253, 255, 255, 255, 255, 255, 255, 255, 255},
+			{250, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{254, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+	},
+	{
+		{
+			{186, 251, 250, 255, 255, 255, 255, 255, 255, 255, 255},
+			{234, 251, 244, 254, 255, 255, 255, 255, 255, 255, 255},
+			{251, 251, 243, 253, 254, 255, 254, 255, 255, 255, 255},
+		},
+		{
+			{255, 253, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+			{236, 253, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+			{251, 253, 253, 254, 254, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 254, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+			{254, 254, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 254, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{254, 254, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{254, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{254, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+	},
+	{
+		{
+			{248, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{250, 254, 252, 254, 255, 255, 255, 255, 255, 255, 255},
+			{248, 254, 249, 253, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 253, 253, 255, 255, 255, 255, 255, 255, 255, 255},
+			{246, 253, 253, 255, 255, 255, 255, 255, 255, 255, 255},
+			{252, 254, 251, 254, 254, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 254, 252, 255, 255, 255, 255, 255, 255, 255, 255},
+			{248, 254, 253, 255, 255, 255, 255, 255, 255, 255, 255},
+			{253, 255, 254, 254, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 251, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+			{245, 251, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+			{253, 253, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 251, 253, 255, 255, 255, 255, 255, 255, 255, 255},
+			{252, 253, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 254, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 252, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{249, 255, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 254, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 255, 253, 255, 255, 255, 255, 255, 255, 255, 255},
+			{250, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+		{
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{254, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+			{255, 255, 255, 255, 255, 255, 255, 255, 255, 255, 255},
+		},
+	},
+}
+
+// Default token probabilities of the first three planes, from RFC 6386
+// section 13.5
+var vp8DefaultTokenProbs = [3][8][3][11]uint8{
+	{
+		{
+			{128, 128, 128, 128, 128, 128, 128, 128, 128, 128, 128},
+			{128, 128, 128, 128, 128, 128, 128, 128, 128, 128, 128},
+			{128, 128, 128, 128, 128, 128, 128, 128, 128, 128, 128},
+		},
+		{
+			{253, 136, 254, 255, 228, 219, 128, 128, 128, 128, 128},
+			{189, 129, 242, 255, 227, 213, 255, 219, 128, 128, 128},
+			{106, 126, 227, 252, 214, 209, 255, 255, 128, 128, 128},
+		},
+		{
+			{1, 98, 248, 255, 236, 226, 255, 255, 128, 128, 128},
+			{181, 133, 238, 254, 221, 234, 255, 154, 128, 128, 128},
+			{78, 134, 202, 247, 198, 180, 255, 219, 128, 128, 128},
+		},
+		{
+			{1, 185, 249, 255, 243, 255, 128, 128, 128, 128, 128},
+			{184, 150, 247, 255, 236, 224, 128, 128, 128, 128, 128},
+			{77, 110, 216, 255, 236, 230, 128, 128, 128, 128, 128},
+		},
+		{
+			{1, 101, 251, 255, 241, 255, 128, 128, 128, 128, 128},
+			{170, 139, 241, 252, 236, 209, 255, 255, 128, 128, 128},
+			{37, 116, 196, 243, 228, 255, 255, 255, 128, 128, 128},
+		},
+		{
+			{1, 204, 254, 255, 245, 255, 128, 128, 128, 128, 128},
+			{207, 160, 250, 255, 238, 128, 128, 128, 128, 128, 128},
+			{102, 103, 231, 255, 211, 171, 128, 128, 128, 128, 128},
+		},
+		{
+			{1, 152, 252, 255, 240, 255, 128, 128, 128, 128, 128},
+			{177, 135, 243, 255, 234, 225, 128, 128, 128, 128, 128},
+			{80, 129, 211, 255, 194, 224, 128, 128, 128, 128, 128},
+		},
+		{
+			{1, 1, 255, 128, 128, 128, 128, 128, 128, 128, 128},
+			{246, 1, 255, 128, 128, 128, 128, 128, 128, 128, 128},
+			{255, 128, 128, 128, 128, 128, 128, 128, 128, 128, 128},
+		},
+	},
+	{
+		{
+			{198, 35, 237, 223, 193, 187, 162, 160, 145, 155, 62},
+			{131, 45, 198, 221, 172, 176, 220, 157, 252, 221, 1},
+			{68, 47, 146, 208, 149, 167, 221, 162, 255, 223, 128},
+		},
+		{
+			{1, 149, 241, 255, 221, 224, 255, 255, 128, 128, 128},
+			{184, 141, 234, 253, 222, 220, 255, 199, 128, 128, 128},
+			{81, 99, 181, 242, 176, 190, 249, 202, 255, 255, 128},
+		},
+		{
+			{1, 129, 232, 253, 214, 197, 242, 196, 255, 255, 128},
+			{99, 121, 210, 250, 201, 198, 255, 202, 128, 128, 128},
+			{23, 91, 163, 242, 170, 187, 247, 210, 255, 255, 128},
+		},
+		{
+			{1, 200, 246, 255, 234, 255, 128, 128, 128, 128, 128},
+			{109, 178, 241, 255, 231, 245, 255, 255, 128, 128, 128},
+			{44, 130, 201, 253, 205, 192, 255, 255, 128, 128, 128},
+		},
+		{
+			{1, 132, 239, 251, 219, 209, 255, 165, 128, 128, 128},
+			{94, 136, 225, 251, 218, 190, 255, 255, 128, 128, 128},
+			{22, 100, 174, 245, 186, 161, 255, 199, 128, 128, 128},
+		},
+		{
+			{1, 182, 249, 255, 232, 235, 128, 128, 128, 128, 128},
+			{124, 143, 241, 255, 227, 234, 128, 128, 128, 128, 128},
+			{35, 77, 181, 251, 193, 211, 255, 205, 128, 128, 128},
+		},
+		{
+			{1, 157, 247, 255, 236, 231, 255, 255, 128, 128, 128},
+			{121, 141, 235, 255, 225, 227, 255, 255, 128, 128, 128},
+			{45, 99, 188, 251, 195, 217, 255, 224, 128, 128, 128},
+		},
+		{
+			{1, 1, 251, 255, 213, 255, 128, 128, 128, 128, 128},
+			{203, 1, 248, 255, 255, 128, 128, 128, 128, 128, 128},
+			{137, 1, 177, 255, 224, 255, 128, 128, 128, 128, 128},
+		},
+	},
+	{
+		{
+			{253, 9, 248, 251, 207, 208, 255, 192, 128, 128, 128},
+			{175, 13, 224, 243, 193, 185, 249, 198, 255, 255, 128},
+			{73, 17, 171, 221, 161, 179, 236, 167, 255, 234, 128},
+		},
+		{
+			{1, 95, 247, 253, 212, 183, 255, 255, 128, 128, 128},
+			{239, 90, 244, 250, 211, 209, 255, 255, 128, 128, 128},
+			{155, 77, 195, 248, 188, 195, 255, 255, 128, 128, 128},
+		},
+		{
+			{1, 24, 239, 251, 218, 219, 255, 205, 128, 128, 128},
+			{201, 51, 219, 255, 196, 186, 128, 128, 128, 128, 128},
+			{69, 46, 190, 239, 201, 218, 255, 228, 128, 128, 128},
+		},
+		{
+			{1, 191, 251, 255, 255, 128, 128, 128, 128, 128, 128},
+			{223, 165, 249, 255, 213, 255, 128, 128, 128, 128, 128},
+			{141, 124, 248, 255, 255, 128, 128, 128, 128, 128, 128},
+		},
+		{
+			{1, 16, 248, 255, 255, 128, 128, 128, 128, 128, 128},
+			{190, 36, 230, 255, 236, 255, 128, 128, 128, 128, 128},
+			{149, 1, 255, 128, 128, 128, 128, 128, 128, 128, 128},
+		},
+		{
+			{1, 226, 255, 128, 128, 128, 128, 128, 128, 128, 128},
+			{247, 192, 255, 128, 128, 128, 128, 128, 128, 128, 128},
+			{240, 128, 255, 128, 128, 128, 128, 128, 128, 128, 128},
+		},
+		{
+			{1, 134, 252, 255, 255, 128, 128, 128, 128, 128, 128},
+			{213, 62, 250, 255, 255, 128, 128, 128, 128, 128, 128},
+			{55, 93, 255, 128, 128, 128, 128, 128, 128, 128, 128},
+		},
+		{
+			{128, 128, 128, 128, 128, 128, 128, 128, 128, 128, 128},
+			{128, 128, 128, 128, 128, 128, 128, 128, 128, 128, 128},
+			{128, 128, 128, 128, 128, 128, 128, 128, 128, 128, 128},
+		},
+	},
+}
@@ -0,0 +1,85 @@
+package image
+
+import (
+	"bufio"
+	"encoding/binary"
+	"image"
+	"image/draw"
+	"io"
+)
+
+// encodeWebPLossy writes img as a lossy WebP at quality (1-100). Transparent
+// images keep their alpha channel uncompressed in an ALPH chunk, which needs
+// the extended (VP8X) container; opaque ones use it only when extended is set.
+func encodeWebPLossy(w io.Writer, img image.Image, quality int, extended bool) error {
+	// VP8 codes the colour of transparent pixels as it is, not premultiplied
+	bounds := img.Bounds()
+	nrgba, ok := img.(*image.NRGBA)
+	if !ok {
+		nrgba = image.NewNRGBA(bounds)
+		draw.Draw(nrgba, bounds, img, bounds.Min, draw.Src)
+	}
+	frame, err := encodeVP8(nrgba, quality)
+	if err != nil {
+		return err
+	}
+
+	var alpha []byte
+	if !nrgba.Opaque() {
+		// No preprocessing, filtering or compression, then one byte a pixel
+		alpha = append(alpha, 0)
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				alpha = append(alpha, nrgba.Pix[nrgba.PixOffset(x, y)+3])
+			}
+		}
+		extended = true
+	}
+
+	var chunks []webpChunk
+	if extended {
+		header := webpHeader(bounds.Dx(), bounds.Dy())
+		if alpha != nil {
+			header[0] |= webpFlagAlpha
+		}
+		chunks = append(chunks, webpChunk{"VP8X", header})
+		if alpha != nil {
+			chunks = append(chunks, webpChunk{"ALPH", alpha})
+		}
+	}
+	chunks = append(chunks, webpChunk{"VP8 ", frame})
+	return writeWebP(w, chunks)
+}
+
+// webpChunk is one chunk of a WebP file
+type webpChunk struct {
+	fourCC string
+	data   []byte
+}
+
+// writeWebP writes chunks in a RIFF WebP container, padding each to an even
+// length
+func writeWebP(w io.Writer, chunks []webpChunk) error {
+	size := 4
+	for _, chunk := range chunks {
+		size += 8 + len(chunk.data) + len(chunk.data)%2
+	}
+	bw := bufio.NewWriter(w)
+	bw.WriteString("RIFF")
+	binary.Write(bw, binary.LittleEndian, uint32(size))
+	bw.WriteString("WEBP")
+	for _, chunk := range chunks {
+		bw.WriteString(chunk.fourCC)
+		binary.Write(bw, binary.LittleEndian, uint32(len(chunk.data)))
+		bw.Write(chunk.data)
+		if len(chunk.data)%2 == 1 {
+			bw.WriteByte(0)
+		}
+	}
+	return bw.Flush()
+}
+
+// putUint24 stores v in the three little-endian bytes of b
+func putUint24(b []byte, v int) {
+	b[0], b[1], b[2] = byte(v), byte(v>>8), byte(v>>16)
+}
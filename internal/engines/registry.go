@@ -1,32 +1,240 @@
 package engines
 
 import (
+	"context"
+	"fmt"
+	"sync"
+
 	"github.com/eka026/File-Format-Converter/internal/domain"
 )
 
-// Registry manages conversion engines
+// PriorityBuiltin is the priority of the engines shipped with the application.
+// Register an engine with a higher priority to take over a name or a conversion.
+const PriorityBuiltin = 0
+
+// Factory constructs an engine the first time a conversion needs it
+type Factory func() (domain.IConverter, error)
+
+// Registration describes an engine to a Registry
+type Registration struct {
+	// Name identifies the engine and keys its concurrency limit
+	Name string
+	// Capabilities declares the conversions and options of the engine so
+	// routes can be planned before the engine is constructed
+	Capabilities domain.Capabilities
+	// Priority decides between registrations of the same name and between
+	// engines declaring the same conversion; the highest priority wins
+	Priority int
+	// Factory constructs the engine on first use
+	Factory Factory
+}
+
+// Registry collects conversion engines contributed by any package and hands
+// them to the ConverterService, which discovers them through Engines.
+// Engines are constructed lazily by their factory.
 type Registry struct {
-	engines map[string]domain.IConverter
+	mu      sync.Mutex
+	entries []*registeredEngine
+}
+
+var (
+	defaultRegistry     *Registry
+	defaultRegistryOnce sync.Once
+)
+
+// NewRegistry creates an empty engine registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Default returns the process-wide registry used by the application.
+// Packages contributing engines register them here, typically from init.
+func Default() *Registry {
+	defaultRegistryOnce.Do(func() {
+		defaultRegistry = NewRegistry()
+	})
+	return defaultRegistry
+}
+
+// Register adds an engine to the default registry
+func Register(registration Registration) error {
+	return Default().Register(registration)
 }
 
-// NewRegistry creates a new engine registry
-func NewRegistry(
-	spreadsheetEngine domain.IConverter,
-	imageEngine domain.IConverter,
-	documentEngine domain.IConverter,
-) *Registry {
-	return &Registry{
-		engines: map[string]domain.IConverter{
-			"spreadsheet": spreadsheetEngine,
-			"image":       imageEngine,
-			"document":    documentEngine,
-		},
+// Register adds an engine. Registering a name again replaces the earlier
+// registration unless that one has a higher priority, in which case the new
+// registration is ignored.
+func (r *Registry) Register(registration Registration) error {
+	if registration.Name == "" {
+		return fmt.Errorf("engine registration has no name")
 	}
+	if registration.Factory == nil {
+		return fmt.Errorf("engine %s has no factory", registration.Name)
+	}
+	if len(registration.Capabilities.Conversions) == 0 {
+		return fmt.Errorf("engine %s declares no conversions", registration.Name)
+	}
+	registration.Capabilities.Name = registration.Name
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entry := &registeredEngine{
+		registration: registration,
+		capabilities: registration.Capabilities,
+		shared:       &lazyInstance{},
+	}
+	for i, existing := range r.entries {
+		if existing.registration.Name != registration.Name {
+			continue
+		}
+		if existing.registration.Priority > registration.Priority {
+			return nil
+		}
+		r.entries[i] = entry
+		return nil
+	}
+	r.entries = append(r.entries, entry)
+	return nil
 }
 
-// GetEngine returns a conversion engine by type
-func (r *Registry) GetEngine(engineType string) (domain.IConverter, bool) {
-	engine, exists := r.engines[engineType]
-	return engine, exists
+// GetEngine returns the engine registered under name
+func (r *Registry) GetEngine(name string) (domain.IConverter, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, entry := range r.entries {
+		if entry.registration.Name == name {
+			return entry, true
+		}
+	}
+	return nil, false
 }
 
+// Names returns the names of the registered engines in registration order
+func (r *Registry) Names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	names := make([]string, 0, len(r.entries))
+	for _, entry := range r.entries {
+		names = append(names, entry.registration.Name)
+	}
+	return names
+}
+
+// Engines returns the registered engines for routing. When several engines
+// declare the same conversion only the highest priority one keeps it (the
+// earliest registration on a tie), so an engine can override a built-in one
+// for a single format. Engines left without conversions are omitted.
+func (r *Registry) Engines() []domain.IConverter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	type conversion struct {
+		from domain.FileType
+		to   domain.Format
+	}
+	owners := make(map[conversion]*registeredEngine)
+	for _, entry := range r.entries {
+		for _, edge := range entry.registration.Capabilities.Conversions {
+			key := conversion{edge.From, edge.To}
+			if owner, ok := owners[key]; !ok || entry.registration.Priority > owner.registration.Priority {
+				owners[key] = entry
+			}
+		}
+	}
+
+	var engines []domain.IConverter
+	for _, entry := range r.entries {
+		capabilities := entry.registration.Capabilities
+		capabilities.Conversions = nil
+		for _, edge := range entry.registration.Capabilities.Conversions {
+			if owners[conversion{edge.From, edge.To}] == entry {
+				capabilities.Conversions = append(capabilities.Conversions, edge)
+			}
+		}
+		if len(capabilities.Conversions) == 0 {
+			continue
+		}
+		engines = append(engines, entry.withCapabilities(capabilities))
+	}
+	return engines
+}
+
+// registeredEngine stands in for a registered engine until a conversion
+// needs it, then delegates to the engine built by the factory
+type registeredEngine struct {
+	registration Registration
+	capabilities domain.Capabilities
+	// shared holds the constructed engine; views of one registration share it
+	shared *lazyInstance
+}
+
+// lazyInstance is an engine constructed on first use. A failed construction
+// is retried on the next use.
+type lazyInstance struct {
+	mu     sync.Mutex
+	engine domain.IConverter
+}
+
+// withCapabilities returns a view of the engine restricted to capabilities
+func (e *registeredEngine) withCapabilities(capabilities domain.Capabilities) *registeredEngine {
+	return &registeredEngine{registration: e.registration, capabilities: capabilities, shared: e.shared}
+}
+
+// ResolveEngine constructs the engine if needed and returns it
+func (e *registeredEngine) ResolveEngine() (domain.IConverter, error) {
+	e.shared.mu.Lock()
+	defer e.shared.mu.Unlock()
+	if e.shared.engine != nil {
+		return e.shared.engine, nil
+	}
+	engine, err := e.registration.Factory()
+	if err != nil {
+		// Keep the factory's error code (e.g. a missing browser) when it has one
+		if domain.ErrorCodeOf(err) != domain.ErrorCodeUnknown {
+			return nil, fmt.Errorf("creating %s engine: %w", e.registration.Name, err)
+		}
+		// A factory that fails does not recover on its own, so the failure is
+		// not worth retrying
+		return nil, domain.Errorf(domain.ErrorCodeEngineFailed, "creating %s engine: %w", e.registration.Name, err)
+	}
+	if engine == nil {
+		return nil, domain.Errorf(domain.ErrorCodeEngineFailed, "creating %s engine: factory returned no engine", e.registration.Name)
+	}
+	e.shared.engine = engine
+	return engine, nil
+}
+
+// Convert constructs the engine if needed and performs the conversion
+func (e *registeredEngine) Convert(ctx context.Context, input, output string, opts domain.ConversionOptions) error {
+	engine, err := e.ResolveEngine()
+	if err != nil {
+		return err
+	}
+	return engine.Convert(ctx, input, output, opts)
+}
+
+// Validate constructs the engine if needed and validates the file
+func (e *registeredEngine) Validate(ctx context.Context, file string) error {
+	engine, err := e.ResolveEngine()
+	if err != nil {
+		return err
+	}
+	return engine.Validate(ctx, file)
+}
+
+// ValidateOptions lets the engine reject options when it implements domain.OptionsValidator
+func (e *registeredEngine) ValidateOptions(opts domain.ConversionOptions, format domain.Format) error {
+	engine, err := e.ResolveEngine()
+	if err != nil {
+		return err
+	}
+	if validator, ok := engine.(domain.OptionsValidator); ok {
+		return validator.ValidateOptions(opts, format)
+	}
+	return nil
+}
+
+// Capabilities returns the declared capabilities without constructing the engine
+func (e *registeredEngine) Capabilities() domain.Capabilities {
+	return e.capabilities
+}
@@ -0,0 +1,213 @@
+package engines
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eka026/File-Format-Converter/internal/domain"
+)
+
+// markerEngine converts by writing its marker to the output
+type markerEngine struct {
+	marker string
+}
+
+func (e *markerEngine) Convert(ctx context.Context, input, output string, opts domain.ConversionOptions) error {
+	return os.WriteFile(output, []byte(e.marker), 0644)
+}
+
+func (e *markerEngine) Validate(ctx context.Context, file string) error { return nil }
+
+func (e *markerEngine) Capabilities() domain.Capabilities {
+	return domain.Capabilities{Name: e.marker}
+}
+
+// docxRegistration registers an engine converting DOCX to the given formats
+func docxRegistration(name string, priority int, factory Factory, formats ...domain.Format) Registration {
+	registration := Registration{Name: name, Priority: priority, Factory: factory}
+	for _, format := range formats {
+		registration.Capabilities.Conversions = append(registration.Capabilities.Conversions,
+			domain.ConversionEdge{From: domain.FileTypeDOCX, To: format, Cost: domain.EdgeCostInProcess})
+	}
+	return registration
+}
+
+// markerFactory returns a factory building a markerEngine and counts its calls
+func markerFactory(marker string, calls *int) Factory {
+	return func() (domain.IConverter, error) {
+		*calls++
+		return &markerEngine{marker: marker}, nil
+	}
+}
+
+// TestRegistry_LazyFactory tests that engines are constructed once, on first use
+func TestRegistry_LazyFactory(t *testing.T) {
+	r := NewRegistry()
+	calls := 0
+	if err := r.Register(docxRegistration("document", PriorityBuiltin, markerFactory("document", &calls), domain.FormatHTML)); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	engines := r.Engines()
+	if len(engines) != 1 || engines[0].Capabilities().Name != "document" {
+		t.Fatalf("Expected the document engine, got %d engines", len(engines))
+	}
+	if calls != 0 {
+		t.Fatalf("Expected no construction before use, got %d", calls)
+	}
+
+	output := filepath.Join(t.TempDir(), "out.html")
+	for i := 0; i < 2; i++ {
+		if err := engines[0].Convert(context.Background(), "in.docx", output, domain.ConversionOptions{}); err != nil {
+			t.Fatalf("Convert failed: %v", err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("Expected one construction, got %d", calls)
+	}
+}
+
+// TestRegistry_FactoryFailureIsNotCached tests that a failed construction is
+// reported as a non-retryable engine failure and tried again on the next call
+func TestRegistry_FactoryFailureIsNotCached(t *testing.T) {
+	r := NewRegistry()
+	fail := true
+	factory := func() (domain.IConverter, error) {
+		if fail {
+			return nil, errors.New("not ready")
+		}
+		return &markerEngine{marker: "document"}, nil
+	}
+	if err := r.Register(docxRegistration("document", PriorityBuiltin, factory, domain.FormatHTML)); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	engine := r.Engines()[0]
+
+	err := engine.Validate(context.Background(), "in.docx")
+	if !errors.Is(err, domain.ErrEngineFailed) || domain.IsRetryable(err) {
+		t.Fatalf("Expected a non-retryable ErrEngineFailed, got %v", err)
+	}
+	fail = false
+	if err := engine.Validate(context.Background(), "in.docx"); err != nil {
+		t.Errorf("Expected the second construction to succeed, got %v", err)
+	}
+}
+
+// TestRegistry_PriorityOverridesConversion tests that a higher priority engine takes over a single conversion
+func TestRegistry_PriorityOverridesConversion(t *testing.T) {
+	r := NewRegistry()
+	var builtinCalls, pluginCalls int
+	if err := r.Register(docxRegistration("document", PriorityBuiltin, markerFactory("document", &builtinCalls), domain.FormatHTML, domain.FormatPDF)); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := r.Register(docxRegistration("in-house-pdf", PriorityBuiltin+10, markerFactory("in-house-pdf", &pluginCalls), domain.FormatPDF)); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	owners := make(map[domain.Format]string)
+	for _, engine := range r.Engines() {
+		for _, edge := range engine.Capabilities().Conversions {
+			owners[edge.To] = engine.Capabilities().Name
+		}
+	}
+	if owners[domain.FormatHTML] != "document" {
+		t.Errorf("Expected the built-in engine to keep HTML, got %q", owners[domain.FormatHTML])
+	}
+	if owners[domain.FormatPDF] != "in-house-pdf" {
+		t.Errorf("Expected the in-house engine to take over PDF, got %q", owners[domain.FormatPDF])
+	}
+}
+
+// TestRegistry_ReplaceByName tests that re-registering a name honours priority
+func TestRegistry_ReplaceByName(t *testing.T) {
+	r := NewRegistry()
+	var first, second, third int
+	r.Register(docxRegistration("document", 5, markerFactory("first", &first), domain.FormatHTML))
+	// A lower priority registration of the same name is ignored
+	r.Register(docxRegistration("document", PriorityBuiltin, markerFactory("second", &second), domain.FormatHTML))
+	// An equal or higher priority registration replaces it
+	r.Register(docxRegistration("document", 5, markerFactory("third", &third), domain.FormatHTML))
+
+	if names := r.Names(); len(names) != 1 {
+		t.Fatalf("Expected one registration, got %v", names)
+	}
+	engine, ok := r.GetEngine("document")
+	if !ok {
+		t.Fatal("Expected the document engine to be registered")
+	}
+	output := filepath.Join(t.TempDir(), "out.html")
+	if err := engine.Convert(context.Background(), "in.docx", output, domain.ConversionOptions{}); err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+	if data, _ := os.ReadFile(output); string(data) != "third" {
+		t.Errorf("Expected the replacing engine to convert, got %q", data)
+	}
+}
+
+// TestRegistry_RejectsIncompleteRegistrations tests registration validation
+func TestRegistry_RejectsIncompleteRegistrations(t *testing.T) {
+	r := NewRegistry()
+	calls := 0
+	tests := map[string]Registration{
+		"no name":        docxRegistration("", PriorityBuiltin, markerFactory("x", &calls), domain.FormatHTML),
+		"no factory":     docxRegistration("document", PriorityBuiltin, nil, domain.FormatHTML),
+		"no conversions": docxRegistration("document", PriorityBuiltin, markerFactory("x", &calls)),
+	}
+	for name, registration := range tests {
+		if err := r.Register(registration); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+// TestConverterService_DiscoversRegistryEngines tests that the service routes through registry engines
+func TestConverterService_DiscoversRegistryEngines(t *testing.T) {
+	r := NewRegistry()
+	calls := 0
+	if err := r.Register(docxRegistration("document", PriorityBuiltin, markerFactory("converted", &calls), domain.FormatHTML)); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	dir := t.TempDir()
+	source := filepath.Join(dir, "report.docx")
+	if err := os.WriteFile(source, []byte("docx"), 0644); err != nil {
+		t.Fatalf("Failed to create source: %v", err)
+	}
+	service := domain.NewConverterService(nil, nopLogger{}, nopNotifier{}, existsWriter{}, domain.WithEngineSource(r))
+
+	matrix := service.GetConversionMatrix()
+	if len(matrix[domain.FileTypeDOCX]) != 1 {
+		t.Fatalf("Expected DOCX to be convertible, got %v", matrix)
+	}
+	result := service.Convert(context.Background(), source, filepath.Join(dir, "report.html"), domain.ConversionOptions{})
+	if !result.Success {
+		t.Fatalf("Conversion failed: %v", result.Error)
+	}
+	if data, _ := os.ReadFile(result.OutputPath); string(data) != "converted" {
+		t.Errorf("Expected the registry engine's output, got %q", data)
+	}
+}
+
+// nopLogger discards all log output
+type nopLogger struct{}
+
+func (nopLogger) Info(msg string)             {}
+func (nopLogger) Error(msg string, err error) {}
+func (nopLogger) Debug(msg string)            {}
+
+// nopNotifier discards all progress notifications
+type nopNotifier struct{}
+
+func (nopNotifier) NotifyProgress(pct int, msg string)  {}
+func (nopNotifier) NotifyComplete(result domain.Result) {}
+func (nopNotifier) NotifyError(err error)               {}
+
+// existsWriter is a FileWriter that reports every path as existing
+type existsWriter struct{}
+
+func (existsWriter) Write(path string, data []byte) error { return nil }
+func (existsWriter) Read(path string) ([]byte, error)     { return nil, nil }
+func (existsWriter) Exists(path string) bool              { return true }
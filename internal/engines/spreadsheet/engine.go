@@ -2,74 +2,154 @@ package spreadsheet
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"sync"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/eka026/File-Format-Converter/internal/domain"
-	"github.com/eka026/File-Format-Converter/internal/engines/image"
+	"github.com/eka026/File-Format-Converter/internal/scheduler"
+	"github.com/xuri/excelize/v2"
 )
 
-// SpreadsheetEngine implements IConverter for spreadsheet conversions (Excel → PDF)
+// SpreadsheetEngine implements IConverter for spreadsheet conversions (Excel → HTML/PDF)
 type SpreadsheetEngine struct {
 	parser       *ExcelParser
 	htmlRenderer *HTMLRenderer
 	pdfGenerator *PDFGenerator
-	workerPool   *image.WorkerPool
+	scheduler    *scheduler.Scheduler
 }
 
 // NewSpreadsheetEngine creates a new spreadsheet conversion engine
-func NewSpreadsheetEngine(htmlRenderer *HTMLRenderer, pdfGenerator *PDFGenerator) domain.IConverter {
+// Batch conversions run on the given scheduler; nil uses scheduler.Default()
+func NewSpreadsheetEngine(sched *scheduler.Scheduler, htmlRenderer *HTMLRenderer, pdfGenerator *PDFGenerator) domain.IConverter {
+	if sched == nil {
+		sched = scheduler.Default()
+	}
 	return &SpreadsheetEngine{
 		parser:       NewExcelParser(),
 		htmlRenderer: htmlRenderer,
 		pdfGenerator: pdfGenerator,
-		workerPool:   image.NewWorkerPool(),
+		scheduler:    sched,
 	}
 }
 
-// Convert converts an Excel file to PDF
-func (e *SpreadsheetEngine) Convert(ctx context.Context, input, output string) error {
+// Convert converts an Excel file to HTML or PDF depending on the output extension
+func (e *SpreadsheetEngine) Convert(ctx context.Context, input, output string, opts domain.ConversionOptions) error {
 	// Check for cancellation before starting
 	if ctx.Err() != nil {
 		return ctx.Err()
 	}
 
 	// Parse Excel file using excelize via our parser
-	f, err := e.parser.Parse(input)
+	parser := e.parser.WithLimits(domain.ResourceLimitsFromContext(ctx))
+	f, err := parser.Parse(input)
 	if err != nil {
-		return fmt.Errorf("parsing excel file: %w", err)
+		return parseError("parsing excel file", err)
 	}
 	defer f.Close()
 
-	// Check for cancellation after parsing
+	htmlContent, err := e.renderHTML(ctx, parser, f, opts.Sheet)
+	if err != nil {
+		return err
+	}
+
+	// Determine output format from file extension
+	outputExt := strings.ToLower(filepath.Ext(output))
+	switch outputExt {
+	case ".html", ".htm":
+		// Write HTML directly
+		if err := os.WriteFile(output, []byte(htmlContent), 0644); err != nil {
+			return domain.NewError(domain.ErrorCodeOutputNotWritable, err)
+		}
+		return nil
+	case ".pdf":
+		if e.pdfGenerator == nil {
+			return domain.Errorf(domain.ErrorCodeBrowserMissing, "pdf generator not available")
+		}
+		// Generate PDF from HTML
+		if err := e.pdfGenerator.Generate(ctx, htmlContent, output, opts.Page); err != nil {
+			return fmt.Errorf("generating pdf: %w", err)
+		}
+		return nil
+	default:
+		return domain.Errorf(domain.ErrorCodeUnsupportedFormat, "unsupported output format: %s", outputExt)
+	}
+}
+
+// ConvertStream converts Excel data read from input to HTML or PDF written to output
+func (e *SpreadsheetEngine) ConvertStream(ctx context.Context, input io.Reader, output io.Writer, format domain.Format, opts domain.ConversionOptions) error {
+	// Check for cancellation before starting
 	if ctx.Err() != nil {
 		return ctx.Err()
 	}
 
-	// Convert to HTML (internally parses workbook data)
-	htmlContent, err := e.htmlRenderer.Render(f)
+	parser := e.parser.WithLimits(domain.ResourceLimitsFromContext(ctx))
+	f, err := parser.ParseFromReader(input)
 	if err != nil {
-		return fmt.Errorf("rendering html: %w", err)
+		return parseError("parsing excel data", err)
 	}
+	defer f.Close()
 
-	// Check for cancellation after rendering
+	htmlContent, err := e.renderHTML(ctx, parser, f, opts.Sheet)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case domain.FormatHTML:
+		_, err := io.WriteString(output, htmlContent)
+		return err
+	case domain.FormatPDF:
+		if e.pdfGenerator == nil {
+			return domain.Errorf(domain.ErrorCodeBrowserMissing, "pdf generator not available")
+		}
+		pdf, err := e.pdfGenerator.GenerateBytes(ctx, htmlContent, opts.Page)
+		if err != nil {
+			return fmt.Errorf("generating pdf: %w", err)
+		}
+		_, err = output.Write(pdf)
+		return err
+	default:
+		return domain.Errorf(domain.ErrorCodeUnsupportedFormat, "unsupported output format: %s", format)
+	}
+}
+
+// renderHTML renders the selected sheets of a parsed workbook as an HTML
+// document, reading the sheets with parser so its limits apply
+func (e *SpreadsheetEngine) renderHTML(ctx context.Context, parser *ExcelParser, f *excelize.File, sheets domain.SheetOptions) (string, error) {
+	// Check for cancellation after parsing
 	if ctx.Err() != nil {
-		return ctx.Err()
+		return "", ctx.Err()
 	}
 
-	// Generate PDF from HTML
-	if err := e.pdfGenerator.Generate(ctx, htmlContent, output); err != nil {
-		return fmt.Errorf("generating pdf: %w", err)
+	parsed := domain.StartStage(ctx, domain.StageParse)
+	workbook, err := parser.ParseSheets(f, sheets.Sheets)
+	parsed()
+	if err != nil {
+		return "", fmt.Errorf("rendering html: parsing workbook: %w", err)
 	}
+	reportOmitted(ctx, f, workbook)
 
-	return nil
+	rendered := domain.StartStage(ctx, domain.StageRender)
+	htmlContent := e.htmlRenderer.RenderWorkbook(workbook)
+	rendered()
+
+	// Check for cancellation after rendering
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+
+	return htmlContent, nil
 }
 
 // ConvertBytes converts Excel data from bytes to HTML string
 func (e *SpreadsheetEngine) ConvertBytes(data []byte) (string, error) {
 	f, err := e.parser.ParseFromBytes(data)
 	if err != nil {
-		return "", fmt.Errorf("parsing excel data: %w", err)
+		return "", domain.Errorf(domain.ErrorCodeCorruptInput, "parsing excel data: %w", err)
 	}
 	defer f.Close()
 
@@ -87,29 +167,97 @@ func (e *SpreadsheetEngine) Validate(ctx context.Context, file string) error {
 	if ctx.Err() != nil {
 		return ctx.Err()
 	}
-	f, err := e.parser.Parse(file)
+	f, err := e.parser.WithLimits(domain.ResourceLimitsFromContext(ctx)).Parse(file)
 	if err != nil {
-		return fmt.Errorf("invalid excel file: %w", err)
+		return parseError("invalid excel file", err)
 	}
 	f.Close()
 	return nil
 }
 
+// reportOmitted reports the rendered sheet count and the workbook content
+// the HTML renderer cannot reproduce
+func reportOmitted(ctx context.Context, f *excelize.File, workbook *WorkbookData) {
+	domain.RecordCounts(ctx, domain.ContentCounts{Sheets: len(workbook.Sheets)})
+
+	var charts, images int
+	f.Pkg.Range(func(key, value any) bool {
+		name, _ := key.(string)
+		switch {
+		case strings.HasPrefix(name, "xl/charts/chart"):
+			charts++
+		case strings.HasPrefix(name, "xl/media/"):
+			images++
+		}
+		return true
+	})
+	domain.RecordCounts(ctx, domain.ContentCounts{Images: images})
+	if charts > 0 {
+		domain.RecordFidelityWarning(ctx, domain.FidelityChartsDropped, fmt.Sprintf("%d charts were dropped", charts))
+	}
+	if images > 0 {
+		domain.RecordFidelityWarning(ctx, domain.FidelityImagesDropped, fmt.Sprintf("%d embedded images were dropped", images))
+	}
+
+	var formatted, commented []string
+	for _, sheet := range workbook.Sheets {
+		if formats, err := f.GetConditionalFormats(sheet.Name); err == nil && len(formats) > 0 {
+			formatted = append(formatted, sheet.Name)
+		}
+		if comments, err := f.GetComments(sheet.Name); err == nil && len(comments) > 0 {
+			commented = append(commented, sheet.Name)
+		}
+	}
+	if len(formatted) > 0 {
+		domain.RecordFidelityWarning(ctx, domain.FidelityConditionalFormatting,
+			fmt.Sprintf("conditional formatting was ignored on sheets: %s", strings.Join(formatted, ", ")))
+	}
+	if len(commented) > 0 {
+		domain.RecordFidelityWarning(ctx, domain.FidelityCommentsDropped,
+			fmt.Sprintf("comments were dropped on sheets: %s", strings.Join(commented, ", ")))
+	}
+}
+
+// parseError classifies a failure to open a workbook as corrupt input unless
+// the workbook exceeded a resource limit
+func parseError(msg string, err error) error {
+	if errors.Is(err, domain.ErrResourceLimit) {
+		return err
+	}
+	return domain.Errorf(domain.ErrorCodeCorruptInput, "%s: %w", msg, err)
+}
+
 // ValidateBytes checks if the input bytes represent a valid Excel file
 func (e *SpreadsheetEngine) ValidateBytes(data []byte) error {
 	f, err := e.parser.ParseFromBytes(data)
 	if err != nil {
-		return fmt.Errorf("invalid excel data: %w", err)
+		return domain.Errorf(domain.ErrorCodeCorruptInput, "invalid excel data: %w", err)
 	}
 	f.Close()
 	return nil
 }
 
+// Capabilities reports the conversions this engine can perform
+func (e *SpreadsheetEngine) Capabilities() domain.Capabilities {
+	return domain.Capabilities{
+		Name:    "spreadsheet",
+		Version: "1",
+		Conversions: []domain.ConversionEdge{
+			{From: domain.FileTypeXLSX, To: domain.FormatHTML, Cost: domain.EdgeCostInProcess},
+			{From: domain.FileTypeXLSX, To: domain.FormatPDF, Cost: domain.EdgeCostBrowser},
+		},
+		Options: append([]domain.OptionDescriptor{
+			{Name: "sheets", Type: "string", Description: "Comma-separated worksheet names to include (default: all sheets)"},
+		}, domain.PageOptionDescriptors()...),
+	}
+}
+
 // BatchConversionTask represents a single conversion task in a batch
 type BatchConversionTask struct {
 	InputPath  string
 	OutputPath string
 	Index      int
+	Options    domain.ConversionOptions
 }
 
 // BatchConversionResult represents the result of a batch conversion task
@@ -118,43 +266,32 @@ type BatchConversionResult struct {
 	Error error
 }
 
-// BatchConvert processes multiple spreadsheet conversions in parallel using the worker pool
-// It takes a slice of input/output path pairs and processes them concurrently
-// utilizing all available CPU cores through the worker pool
-func (e *SpreadsheetEngine) BatchConvert(tasks []BatchConversionTask) []BatchConversionResult {
+// BatchConvert processes multiple spreadsheet conversions in parallel on the shared scheduler
+// It takes a slice of input/output path pairs and processes them concurrently,
+// bounded by the scheduler's worker pool and the spreadsheet engine's concurrency limit
+func (e *SpreadsheetEngine) BatchConvert(ctx context.Context, tasks []BatchConversionTask) []BatchConversionResult {
 	if len(tasks) == 0 {
 		return nil
 	}
 
-	results := make([]BatchConversionResult, len(tasks))
-	var wg sync.WaitGroup
-	var mu sync.Mutex
-
-	// Submit all tasks to the worker pool
-	for _, task := range tasks {
-		wg.Add(1)
+	// Submit all tasks before waiting so they are queued together
+	// Cancelling ctx fails the tasks not yet started and is passed on to
+	// the running ones
+	handles := make([]*scheduler.Handle, len(tasks))
+	for i, task := range tasks {
 		task := task // Capture loop variable
-
-		e.workerPool.Submit(func() {
-			defer wg.Done()
-
-			// Perform the conversion with background context
-			// Note: For batch operations, we use background context as cancellation
-			// should be handled at the batch level, not individual task level
-			err := e.Convert(context.Background(), task.InputPath, task.OutputPath)
-
-			// Store result thread-safely
-			mu.Lock()
-			results[task.Index] = BatchConversionResult{
-				Index: task.Index,
-				Error: err,
-			}
-			mu.Unlock()
+		handles[i] = e.scheduler.Submit(ctx, "spreadsheet", scheduler.PriorityNormal, func(ctx context.Context) error {
+			return e.Convert(ctx, task.InputPath, task.OutputPath, task.Options)
 		})
 	}
 
-	// Wait for all conversions to complete
-	wg.Wait()
+	results := make([]BatchConversionResult, len(tasks))
+	for i, task := range tasks {
+		results[task.Index] = BatchConversionResult{
+			Index: task.Index,
+			Error: handles[i].Wait(),
+		}
+	}
 
 	return results
 }
@@ -2,11 +2,13 @@ package spreadsheet
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/eka026/File-Format-Converter/internal/adapters/browser"
+	"github.com/eka026/File-Format-Converter/internal/domain"
 	"github.com/xuri/excelize/v2"
 )
 
@@ -16,7 +18,7 @@ func TestSpreadsheetEngine_Validate_ValidXLSX(t *testing.T) {
 	tmpFile := createTempExcelFile(t)
 	defer os.Remove(tmpFile)
 
-	engine := NewSpreadsheetEngine(nil, nil).(*SpreadsheetEngine)
+	engine := NewSpreadsheetEngine(nil, nil, nil).(*SpreadsheetEngine)
 	ctx := context.Background()
 
 	err := engine.Validate(ctx, tmpFile)
@@ -34,7 +36,7 @@ func TestSpreadsheetEngine_Validate_InvalidFile(t *testing.T) {
 	}
 	defer os.Remove(tmpFile)
 
-	engine := NewSpreadsheetEngine(nil, nil).(*SpreadsheetEngine)
+	engine := NewSpreadsheetEngine(nil, nil, nil).(*SpreadsheetEngine)
 	ctx := context.Background()
 
 	err := engine.Validate(ctx, tmpFile)
@@ -48,7 +50,7 @@ func TestSpreadsheetEngine_ParseExcelFile(t *testing.T) {
 	tmpFile := createTempExcelFile(t)
 	defer os.Remove(tmpFile)
 
-	engine := NewSpreadsheetEngine(nil, nil).(*SpreadsheetEngine)
+	engine := NewSpreadsheetEngine(nil, nil, nil).(*SpreadsheetEngine)
 	parser := engine.parser
 
 	// Test parsing
@@ -79,7 +81,7 @@ func TestSpreadsheetEngine_RenderToHTML(t *testing.T) {
 	tmpFile := createTempExcelFile(t)
 	defer os.Remove(tmpFile)
 
-	engine := NewSpreadsheetEngine(nil, nil).(*SpreadsheetEngine)
+	engine := NewSpreadsheetEngine(nil, nil, nil).(*SpreadsheetEngine)
 	parser := engine.parser
 	renderer := NewHTMLRenderer()
 
@@ -116,6 +118,60 @@ func TestSpreadsheetEngine_RenderToHTML(t *testing.T) {
 	}
 }
 
+// TestSpreadsheetEngine_Convert_SheetSelection tests that only the selected sheets are rendered
+func TestSpreadsheetEngine_Convert_SheetSelection(t *testing.T) {
+	f := excelize.NewFile()
+	if _, err := f.NewSheet("Summary"); err != nil {
+		t.Fatalf("Failed to add sheet: %v", err)
+	}
+	f.SetCellValue("Sheet1", "A1", "Raw data")
+	f.SetCellValue("Summary", "A1", "Totals")
+	tmpFile := filepath.Join(t.TempDir(), "sheets.xlsx")
+	if err := f.SaveAs(tmpFile); err != nil {
+		t.Fatalf("Failed to save Excel file: %v", err)
+	}
+	f.Close()
+
+	engine := NewSpreadsheetEngine(nil, NewHTMLRenderer(), nil)
+	outputFile := filepath.Join(t.TempDir(), "output.html")
+	opts := domain.ConversionOptions{Sheet: domain.SheetOptions{Sheets: []string{"Summary"}}}
+	if err := engine.Convert(context.Background(), tmpFile, outputFile, opts); err != nil {
+		t.Fatalf("Conversion with sheet selection failed: %v", err)
+	}
+
+	html, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	if !contains(string(html), "Totals") || contains(string(html), "Raw data") {
+		t.Error("Expected only the Summary sheet to be rendered")
+	}
+
+	opts.Sheet.Sheets = []string{"Missing"}
+	if err := engine.Convert(context.Background(), tmpFile, outputFile, opts); err == nil {
+		t.Error("Expected an error for an unknown sheet name")
+	}
+}
+
+// TestSpreadsheetEngine_Convert_SheetLimits tests that oversized sheets are rejected with a resource limit error
+func TestSpreadsheetEngine_Convert_SheetLimits(t *testing.T) {
+	tmpFile := createTempExcelFile(t)
+	engine := NewSpreadsheetEngine(nil, NewHTMLRenderer(), nil)
+	outputFile := filepath.Join(t.TempDir(), "output.html")
+
+	// The test sheet has one row of two cells
+	ctx := domain.ContextWithResourceLimits(context.Background(), domain.ResourceLimits{MaxSheetCells: 1})
+	err := engine.Convert(ctx, tmpFile, outputFile, domain.ConversionOptions{})
+	if !errors.Is(err, domain.ErrResourceLimit) {
+		t.Errorf("Expected ErrResourceLimit, got %v", err)
+	}
+
+	// The same sheet converts within the default limits
+	if err := engine.Convert(context.Background(), tmpFile, outputFile, domain.ConversionOptions{}); err != nil {
+		t.Errorf("Expected the sheet to convert within the default limits, got %v", err)
+	}
+}
+
 // TestSpreadsheetEngine_Convert_EndToEnd tests the full conversion flow including PDF generation
 // This tests FR-04: The system shall export the rendered HTML to PDF using a headless browser engine
 func TestSpreadsheetEngine_Convert_EndToEnd(t *testing.T) {
@@ -130,7 +186,7 @@ func TestSpreadsheetEngine_Convert_EndToEnd(t *testing.T) {
 	defer os.Remove(outputFile)
 
 	// Test HTML rendering separately (PDF generation requires browser)
-	engine := NewSpreadsheetEngine(nil, nil).(*SpreadsheetEngine)
+	engine := NewSpreadsheetEngine(nil, nil, nil).(*SpreadsheetEngine)
 	parser := engine.parser
 	renderer := NewHTMLRenderer()
 
@@ -163,11 +219,11 @@ func TestSpreadsheetEngine_Convert_WithRealBrowser(t *testing.T) {
 
 	// This test requires Chrome/Chromium to be installed
 	// Skip if browser is not available
-	browser, err := createTestBrowser()
+	headless, err := createTestBrowser()
 	if err != nil {
 		t.Skipf("Skipping test: browser not available: %v", err)
 	}
-	defer browser.Close()
+	defer headless.Close()
 
 	tmpFile := createTempExcelFile(t)
 	defer os.Remove(tmpFile)
@@ -175,11 +231,11 @@ func TestSpreadsheetEngine_Convert_WithRealBrowser(t *testing.T) {
 	outputFile := filepath.Join(t.TempDir(), "output.pdf")
 	defer os.Remove(outputFile)
 
-	pdfGen := NewPDFGenerator(browser)
-	engine := NewSpreadsheetEngine(nil, pdfGen).(*SpreadsheetEngine)
+	pdfGen := NewPDFGenerator(func() (*browser.HeadlessBrowser, error) { return headless, nil })
+	engine := NewSpreadsheetEngine(nil, nil, pdfGen).(*SpreadsheetEngine)
 
 	ctx := context.Background()
-	err = engine.Convert(ctx, tmpFile, outputFile)
+	err = engine.Convert(ctx, tmpFile, outputFile, domain.ConversionOptions{})
 	if err != nil {
 		t.Fatalf("FR-04: Conversion to PDF failed: %v", err)
 	}
@@ -243,5 +299,5 @@ func containsMiddle(s, substr string) bool {
 
 // createTestBrowser creates a headless browser for testing (if available)
 func createTestBrowser() (*browser.HeadlessBrowser, error) {
-	return browser.NewHeadlessBrowser()
+	return browser.NewHeadlessBrowser(nil)
 }
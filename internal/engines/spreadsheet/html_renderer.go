@@ -22,7 +22,13 @@ func NewHTMLRenderer() *HTMLRenderer {
 
 // Render converts an Excel file to HTML using the parsed workbook data
 func (r *HTMLRenderer) Render(f *excelize.File) (string, error) {
-	workbook, err := r.parser.ParseWorkbook(f)
+	return r.RenderSheets(f, nil)
+}
+
+// RenderSheets converts the selected sheets of an Excel file to HTML.
+// An empty selection renders every sheet.
+func (r *HTMLRenderer) RenderSheets(f *excelize.File, sheets []string) (string, error) {
+	workbook, err := r.parser.ParseSheets(f, sheets)
 	if err != nil {
 		return "", fmt.Errorf("parsing workbook: %w", err)
 	}
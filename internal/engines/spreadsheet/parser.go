@@ -1,9 +1,12 @@
 package spreadsheet
 
 import (
+	"archive/zip"
 	"bytes"
 	"fmt"
+	"io"
 
+	"github.com/eka026/File-Format-Converter/internal/domain"
 	"github.com/xuri/excelize/v2"
 )
 
@@ -46,30 +49,92 @@ type WorkbookData struct {
 }
 
 // ExcelParser wraps excelize parser functionality
-type ExcelParser struct{}
+type ExcelParser struct {
+	limits domain.ResourceLimits
+}
 
-// NewExcelParser creates a new Excel parser
+// NewExcelParser creates a new Excel parser bounded by domain.DefaultResourceLimits
 func NewExcelParser() *ExcelParser {
-	return &ExcelParser{}
+	return &ExcelParser{limits: domain.DefaultResourceLimits}
+}
+
+// WithLimits returns a parser bounded by limits instead
+func (p *ExcelParser) WithLimits(limits domain.ResourceLimits) *ExcelParser {
+	return &ExcelParser{limits: limits}
 }
 
 // Parse parses an Excel file from a file path
 func (p *ExcelParser) Parse(filePath string) (*excelize.File, error) {
+	reader, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, err
+	}
+	err = p.limits.CheckZip(reader.File)
+	reader.Close()
+	if err != nil {
+		return nil, err
+	}
 	return excelize.OpenFile(filePath)
 }
 
 // ParseFromBytes parses an Excel file from byte data (for in-memory processing)
 func (p *ExcelParser) ParseFromBytes(data []byte) (*excelize.File, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	if err := p.limits.CheckZip(reader.File); err != nil {
+		return nil, err
+	}
 	return excelize.OpenReader(bytes.NewReader(data))
 }
 
+// ParseFromReader parses an Excel file streamed from r
+// The archive is buffered in memory, as excelize would, so its entries can be
+// checked against the limits before they are decompressed
+func (p *ExcelParser) ParseFromReader(r io.Reader) (*excelize.File, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return p.ParseFromBytes(data)
+}
+
 // ParseWorkbook extracts all data from an Excel file into structured format
 func (p *ExcelParser) ParseWorkbook(f *excelize.File) (*WorkbookData, error) {
+	return p.ParseSheets(f, nil)
+}
+
+// ParseSheets extracts the named sheets, in workbook order, into structured format.
+// An empty selection parses every sheet.
+func (p *ExcelParser) ParseSheets(f *excelize.File, selected []string) (*WorkbookData, error) {
 	workbook := &WorkbookData{
 		Sheets: make([]SheetData, 0),
 	}
 
 	sheetList := f.GetSheetList()
+	if len(selected) > 0 {
+		available := make(map[string]bool, len(sheetList))
+		for _, sheetName := range sheetList {
+			available[sheetName] = true
+		}
+		wanted := make(map[string]bool, len(selected))
+		for _, sheetName := range selected {
+			if !available[sheetName] {
+				return nil, domain.Errorf(domain.ErrorCodeInvalidOptions, "sheet not found: %s", sheetName)
+			}
+			wanted[sheetName] = true
+		}
+
+		filtered := make([]string, 0, len(selected))
+		for _, sheetName := range sheetList {
+			if wanted[sheetName] {
+				filtered = append(filtered, sheetName)
+			}
+		}
+		sheetList = filtered
+	}
+
 	for _, sheetName := range sheetList {
 		sheetData, err := p.parseSheet(f, sheetName)
 		if err != nil {
@@ -89,9 +154,9 @@ func (p *ExcelParser) parseSheet(f *excelize.File, sheetName string) (*SheetData
 	}
 
 	// Get all rows
-	rows, err := f.GetRows(sheetName)
+	rows, err := p.readRows(f, sheetName)
 	if err != nil {
-		return nil, fmt.Errorf("getting rows: %w", err)
+		return nil, err
 	}
 
 	// Get merged cells for this sheet
@@ -168,6 +233,39 @@ func (p *ExcelParser) parseSheet(f *excelize.File, sheetName string) (*SheetData
 	return sheet, nil
 }
 
+// readRows reads the rows of a worksheet like excelize's GetRows, failing as
+// soon as the sheet exceeds the parser's row or cell limit
+func (p *ExcelParser) readRows(f *excelize.File, sheetName string) ([][]string, error) {
+	iterator, err := f.Rows(sheetName)
+	if err != nil {
+		return nil, fmt.Errorf("getting rows: %w", err)
+	}
+	defer iterator.Close()
+
+	rows := make([][]string, 0, 64)
+	var cells int64
+	lastNonEmpty := 0
+	for iterator.Next() {
+		row, err := iterator.Columns()
+		if err != nil {
+			return nil, fmt.Errorf("getting rows: %w", err)
+		}
+		rows = append(rows, row)
+		cells += int64(len(row))
+		if err := p.limits.CheckSheet(sheetName, len(rows), cells); err != nil {
+			return nil, err
+		}
+		// Trailing empty rows are dropped, as GetRows does
+		if len(row) > 0 {
+			lastNonEmpty = len(rows)
+		}
+	}
+	if err := iterator.Error(); err != nil {
+		return nil, fmt.Errorf("getting rows: %w", err)
+	}
+	return rows[:lastNonEmpty], nil
+}
+
 // mergeInfo holds information about a merged cell range
 type mergeInfo struct {
 	startCell string
@@ -2,23 +2,40 @@ package spreadsheet
 
 import (
 	"context"
+
 	"github.com/eka026/File-Format-Converter/internal/adapters/browser"
+	"github.com/eka026/File-Format-Converter/internal/domain"
 )
 
 // PDFGenerator generates PDF from HTML content
 type PDFGenerator struct {
-	browser *browser.HeadlessBrowser
+	// launchBrowser returns the headless browser, starting it if needed
+	launchBrowser func() (*browser.HeadlessBrowser, error)
 }
 
 // NewPDFGenerator creates a new PDF generator
-func NewPDFGenerator(browser *browser.HeadlessBrowser) *PDFGenerator {
+// launchBrowser is only called once a PDF is generated
+func NewPDFGenerator(launchBrowser func() (*browser.HeadlessBrowser, error)) *PDFGenerator {
 	return &PDFGenerator{
-		browser: browser,
+		launchBrowser: launchBrowser,
 	}
 }
 
 // Generate generates a PDF file from HTML content
-func (g *PDFGenerator) Generate(ctx context.Context, htmlContent, outputPath string) error {
-	return g.browser.GeneratePDFFromHTML(ctx, htmlContent, outputPath)
+func (g *PDFGenerator) Generate(ctx context.Context, htmlContent, outputPath string, page domain.PageOptions) error {
+	b, err := g.launchBrowser()
+	if err != nil {
+		return err
+	}
+	return b.GeneratePDFFromHTML(ctx, htmlContent, outputPath, page)
+}
+
+// GenerateBytes generates a PDF from HTML content and returns the PDF bytes
+func (g *PDFGenerator) GenerateBytes(ctx context.Context, htmlContent string, page domain.PageOptions) ([]byte, error) {
+	b, err := g.launchBrowser()
+	if err != nil {
+		return nil, err
+	}
+	return b.GeneratePDFFromHTMLBytes(ctx, htmlContent, page)
 }
 
@@ -0,0 +1,64 @@
+package scheduler
+
+// Metrics is a point-in-time snapshot of the scheduler's state
+type Metrics struct {
+	// Workers is the size of the worker pool
+	Workers int `json:"workers"`
+	// Queued is the number of tasks waiting to start
+	Queued int `json:"queued"`
+	// QueuedByPriority breaks Queued down by priority name
+	QueuedByPriority map[string]int `json:"queuedByPriority"`
+	// QueuedByKey breaks Queued down by engine
+	QueuedByKey map[string]int `json:"queuedByKey"`
+	// Running is the number of tasks currently executing
+	Running int `json:"running"`
+	// RunningByKey breaks Running down by engine
+	RunningByKey map[string]int `json:"runningByKey"`
+	// Limits are the configured per-engine concurrency caps
+	Limits map[string]int `json:"limits"`
+	// Completed counts tasks that returned without error
+	Completed uint64 `json:"completed"`
+	// Failed counts tasks that returned an error or panicked
+	Failed uint64 `json:"failed"`
+	// Panicked counts tasks that panicked; they are included in Failed
+	Panicked uint64 `json:"panicked"`
+	// Cancelled counts tasks dropped from the queue because their context ended
+	Cancelled uint64 `json:"cancelled"`
+}
+
+// Metrics returns a snapshot of queue depth, running tasks and task outcomes
+func (s *Scheduler) Metrics() Metrics {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	metrics := Metrics{
+		Workers:          s.workers,
+		QueuedByPriority: make(map[string]int),
+		QueuedByKey:      make(map[string]int),
+		RunningByKey:     make(map[string]int, len(s.running)),
+		Limits:           make(map[string]int, len(s.limits)),
+		Completed:        s.completed,
+		Failed:           s.failed,
+		Panicked:         s.panicked,
+		Cancelled:        s.cancelled,
+	}
+	for priority, queue := range s.queues {
+		if len(queue) == 0 {
+			continue
+		}
+		metrics.Queued += len(queue)
+		metrics.QueuedByPriority[Priority(priority).String()] = len(queue)
+		for _, j := range queue {
+			metrics.QueuedByKey[j.key]++
+		}
+	}
+	for key, running := range s.running {
+		metrics.Running += running
+		metrics.RunningByKey[key] = running
+	}
+	for key, limit := range s.limits {
+		metrics.Limits[key] = limit
+	}
+
+	return metrics
+}
@@ -0,0 +1,20 @@
+package scheduler
+
+import "context"
+
+// priorityKey is the context key carrying a task priority
+type priorityKey struct{}
+
+// WithPriority returns a context whose tasks are scheduled at the given priority.
+// Interactive conversions use it to run ahead of queued batch work.
+func WithPriority(ctx context.Context, priority Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, priority)
+}
+
+// PriorityFromContext returns the priority set by WithPriority, or PriorityNormal
+func PriorityFromContext(ctx context.Context) Priority {
+	if priority, ok := ctx.Value(priorityKey{}).(Priority); ok {
+		return priority
+	}
+	return PriorityNormal
+}
@@ -0,0 +1,332 @@
+// Package scheduler provides the process-wide task scheduler shared by the
+// conversion engines. It runs tasks on a fixed set of workers, ordered by
+// priority, while capping how many tasks of each engine run at once.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sync"
+)
+
+// Priority orders queued tasks; higher priorities are started first
+type Priority int
+
+const (
+	PriorityLow Priority = iota
+	PriorityNormal
+	PriorityHigh
+
+	priorityLevels = int(PriorityHigh) + 1
+)
+
+// String returns the priority name used in metrics
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityNormal:
+		return "normal"
+	case PriorityHigh:
+		return "high"
+	default:
+		return fmt.Sprintf("priority(%d)", int(p))
+	}
+}
+
+// clamp maps out-of-range priorities onto the nearest level
+func (p Priority) clamp() Priority {
+	if p < PriorityLow {
+		return PriorityLow
+	}
+	if p > PriorityHigh {
+		return PriorityHigh
+	}
+	return p
+}
+
+// Task is a unit of work; it receives the context it was submitted with
+type Task func(ctx context.Context) error
+
+// ErrClosed is returned for tasks submitted after Close
+var ErrClosed = errors.New("scheduler closed")
+
+// PanicError is returned for a task that panicked instead of returning
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+// Error implements the error interface
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("task panicked: %v", e.Value)
+}
+
+// Handle tracks a submitted task
+type Handle struct {
+	done chan struct{}
+	err  error
+}
+
+// Done is closed once the task has finished or was dropped from the queue
+func (h *Handle) Done() <-chan struct{} {
+	return h.done
+}
+
+// Wait blocks until the task has finished and returns its error
+func (h *Handle) Wait() error {
+	<-h.done
+	return h.err
+}
+
+// finish records the task's outcome and releases waiters
+func (h *Handle) finish(err error) {
+	h.err = err
+	close(h.done)
+}
+
+// job is a queued task together with its scheduling attributes
+type job struct {
+	ctx      context.Context
+	key      string
+	priority Priority
+	task     Task
+	handle   *Handle
+	// stop detaches the cancellation callback once the job leaves the queue
+	stop func() bool
+}
+
+// Scheduler runs tasks on a fixed pool of workers.
+// Tasks are grouped by key (the engine name); SetLimit caps how many tasks
+// of a key run concurrently, and a capped task does not hold up other keys.
+type Scheduler struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	workers int
+	queues  [priorityLevels][]*job
+	limits  map[string]int
+	running map[string]int
+	closed  bool
+	wg      sync.WaitGroup
+
+	completed uint64
+	failed    uint64
+	panicked  uint64
+	cancelled uint64
+}
+
+// New creates a scheduler with the given number of workers; workers <= 0 uses one per CPU
+func New(workers int) *Scheduler {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	s := &Scheduler{
+		workers: workers,
+		limits:  make(map[string]int),
+		running: make(map[string]int),
+	}
+	s.cond = sync.NewCond(&s.mu)
+
+	for i := 0; i < workers; i++ {
+		s.wg.Add(1)
+		go s.worker()
+	}
+
+	return s
+}
+
+var (
+	defaultOnce      sync.Once
+	defaultScheduler *Scheduler
+)
+
+// Default returns the process-wide scheduler shared by every engine.
+// It is created on first use with one worker per CPU.
+func Default() *Scheduler {
+	defaultOnce.Do(func() {
+		defaultScheduler = New(0)
+	})
+	return defaultScheduler
+}
+
+// SetLimit caps how many tasks with the given key run at once; limit <= 0 removes the cap
+func (s *Scheduler) SetLimit(key string, limit int) {
+	s.mu.Lock()
+	if limit > 0 {
+		s.limits[key] = limit
+	} else {
+		delete(s.limits, key)
+	}
+	s.mu.Unlock()
+
+	// A raised limit may make queued tasks runnable
+	s.cond.Broadcast()
+}
+
+// Submit queues a task and returns immediately.
+// If ctx is cancelled while the task is still queued, the task is dropped
+// without running and its handle reports ctx.Err(). Once started, the task
+// is responsible for observing ctx itself.
+func (s *Scheduler) Submit(ctx context.Context, key string, priority Priority, task Task) *Handle {
+	handle := &Handle{done: make(chan struct{})}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		handle.finish(ErrClosed)
+		return handle
+	}
+	if err := ctx.Err(); err != nil {
+		s.cancelled++
+		s.mu.Unlock()
+		handle.finish(err)
+		return handle
+	}
+
+	j := &job{
+		ctx:      ctx,
+		key:      key,
+		priority: priority.clamp(),
+		task:     task,
+		handle:   handle,
+	}
+	// The callback blocks on s.mu, so it cannot observe the job before it is queued
+	j.stop = context.AfterFunc(ctx, func() { s.drop(j) })
+	s.queues[j.priority] = append(s.queues[j.priority], j)
+	s.mu.Unlock()
+
+	s.cond.Signal()
+	return handle
+}
+
+// Run submits a task at the priority carried by ctx and waits for it to finish
+func (s *Scheduler) Run(ctx context.Context, key string, task func(ctx context.Context) error) error {
+	return s.Submit(ctx, key, PriorityFromContext(ctx), task).Wait()
+}
+
+// Close stops accepting tasks, waits for queued and running tasks to finish
+// and stops the workers
+func (s *Scheduler) Close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	s.mu.Unlock()
+
+	s.cond.Broadcast()
+	s.wg.Wait()
+}
+
+// worker starts runnable tasks until the scheduler is closed and drained
+func (s *Scheduler) worker() {
+	defer s.wg.Done()
+
+	for {
+		s.mu.Lock()
+		j := s.next()
+		for j == nil {
+			if s.closed && s.queuedLocked() == 0 {
+				s.mu.Unlock()
+				return
+			}
+			s.cond.Wait()
+			j = s.next()
+		}
+		s.running[j.key]++
+		s.mu.Unlock()
+
+		err := s.execute(j)
+
+		s.mu.Lock()
+		s.running[j.key]--
+		if s.running[j.key] == 0 {
+			delete(s.running, j.key)
+		}
+		var panicErr *PanicError
+		switch {
+		case errors.As(err, &panicErr):
+			s.panicked++
+			s.failed++
+		case err != nil:
+			s.failed++
+		default:
+			s.completed++
+		}
+		s.mu.Unlock()
+
+		// A finished task frees a slot for its key
+		s.cond.Broadcast()
+		j.handle.finish(err)
+	}
+}
+
+// execute runs a task, converting a panic into a *PanicError
+func (s *Scheduler) execute(j *job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+
+	// The context may have been cancelled just as the job was dequeued
+	if err := j.ctx.Err(); err != nil {
+		return err
+	}
+	return j.task(j.ctx)
+}
+
+// next removes and returns the highest-priority task whose key is below its
+// limit, or nil when nothing can start. Callers must hold s.mu.
+func (s *Scheduler) next() *job {
+	for priority := priorityLevels - 1; priority >= 0; priority-- {
+		queue := s.queues[priority]
+		for i, j := range queue {
+			if limit, ok := s.limits[j.key]; ok && s.running[j.key] >= limit {
+				continue
+			}
+			s.queues[priority] = append(queue[:i:i], queue[i+1:]...)
+			j.stop()
+			return j
+		}
+	}
+	return nil
+}
+
+// drop removes a cancelled task from the queue and finishes it with the
+// context's error; it does nothing when the task has already started
+func (s *Scheduler) drop(j *job) {
+	s.mu.Lock()
+	queue := s.queues[j.priority]
+	index := -1
+	for i, queued := range queue {
+		if queued == j {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		s.mu.Unlock()
+		return
+	}
+	s.queues[j.priority] = append(queue[:index:index], queue[index+1:]...)
+	s.cancelled++
+	s.mu.Unlock()
+
+	// Closing workers may be waiting for the queue to drain
+	s.cond.Broadcast()
+	j.handle.finish(j.ctx.Err())
+}
+
+// queuedLocked returns the number of queued tasks. Callers must hold s.mu.
+func (s *Scheduler) queuedLocked() int {
+	total := 0
+	for _, queue := range s.queues {
+		total += len(queue)
+	}
+	return total
+}
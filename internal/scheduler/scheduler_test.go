@@ -0,0 +1,211 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestScheduler_Run_ReturnsTaskError tests that a task's error is reported to the caller
+func TestScheduler_Run_ReturnsTaskError(t *testing.T) {
+	s := New(2)
+	defer s.Close()
+
+	want := errors.New("conversion failed")
+	err := s.Run(context.Background(), "image", func(ctx context.Context) error {
+		return want
+	})
+	if !errors.Is(err, want) {
+		t.Errorf("Expected task error %v, got %v", want, err)
+	}
+}
+
+// TestScheduler_Submit_Priority tests that queued high-priority tasks start before lower ones
+func TestScheduler_Submit_Priority(t *testing.T) {
+	s := New(1)
+	defer s.Close()
+
+	// Occupy the only worker so the following tasks queue up
+	release := make(chan struct{})
+	blocker := s.Submit(context.Background(), "image", PriorityNormal, func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+	waitFor(t, func() bool { return s.Metrics().Running == 1 })
+
+	var mu sync.Mutex
+	var order []Priority
+	record := func(priority Priority) Task {
+		return func(ctx context.Context) error {
+			mu.Lock()
+			order = append(order, priority)
+			mu.Unlock()
+			return nil
+		}
+	}
+	handles := []*Handle{
+		s.Submit(context.Background(), "image", PriorityLow, record(PriorityLow)),
+		s.Submit(context.Background(), "image", PriorityNormal, record(PriorityNormal)),
+		s.Submit(context.Background(), "image", PriorityHigh, record(PriorityHigh)),
+	}
+
+	close(release)
+	blocker.Wait()
+	for _, handle := range handles {
+		handle.Wait()
+	}
+
+	want := []Priority{PriorityHigh, PriorityNormal, PriorityLow}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("Expected execution order %v, got %v", want, order)
+		}
+	}
+}
+
+// TestScheduler_SetLimit tests that a per-engine cap bounds concurrency without blocking other engines
+func TestScheduler_SetLimit(t *testing.T) {
+	s := New(4)
+	defer s.Close()
+	s.SetLimit("browser", 1)
+
+	var running, peak int32
+	release := make(chan struct{})
+	var handles []*Handle
+	for i := 0; i < 3; i++ {
+		handles = append(handles, s.Submit(context.Background(), "browser", PriorityNormal, func(ctx context.Context) error {
+			current := atomic.AddInt32(&running, 1)
+			for {
+				old := atomic.LoadInt32(&peak)
+				if current <= old || atomic.CompareAndSwapInt32(&peak, old, current) {
+					break
+				}
+			}
+			<-release
+			atomic.AddInt32(&running, -1)
+			return nil
+		}))
+	}
+
+	// Another engine still runs while the capped engine has work queued
+	if err := s.Run(context.Background(), "image", func(ctx context.Context) error { return nil }); err != nil {
+		t.Fatalf("Uncapped task failed: %v", err)
+	}
+	if queued := s.Metrics().QueuedByKey["browser"]; queued != 2 {
+		t.Errorf("Expected 2 queued browser tasks, got %d", queued)
+	}
+
+	close(release)
+	for _, handle := range handles {
+		handle.Wait()
+	}
+	if peak != 1 {
+		t.Errorf("Expected at most 1 concurrent browser task, got %d", peak)
+	}
+}
+
+// TestScheduler_Submit_CancelledWhileQueued tests that cancelling a context drops its queued tasks
+func TestScheduler_Submit_CancelledWhileQueued(t *testing.T) {
+	s := New(1)
+	defer s.Close()
+
+	release := make(chan struct{})
+	blocker := s.Submit(context.Background(), "image", PriorityNormal, func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+	waitFor(t, func() bool { return s.Metrics().Running == 1 })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var ran atomic.Bool
+	queued := s.Submit(ctx, "image", PriorityNormal, func(ctx context.Context) error {
+		ran.Store(true)
+		return nil
+	})
+
+	cancel()
+	select {
+	case <-queued.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Expected the queued task to finish as soon as its context was cancelled")
+	}
+	if err := queued.Wait(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	close(release)
+	blocker.Wait()
+	if ran.Load() {
+		t.Error("Cancelled task should not have run")
+	}
+	if metrics := s.Metrics(); metrics.Cancelled != 1 || metrics.Queued != 0 {
+		t.Errorf("Expected 1 cancelled and 0 queued tasks, got %+v", metrics)
+	}
+}
+
+// TestScheduler_Run_RecoversPanic tests that a panicking task becomes an error instead of crashing the worker
+func TestScheduler_Run_RecoversPanic(t *testing.T) {
+	s := New(1)
+	defer s.Close()
+
+	err := s.Run(context.Background(), "document", func(ctx context.Context) error {
+		panic("malformed input")
+	})
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("Expected a *PanicError, got %v", err)
+	}
+	if panicErr.Value != "malformed input" || len(panicErr.Stack) == 0 {
+		t.Errorf("Expected panic value and stack to be recorded, got %+v", panicErr)
+	}
+
+	// The worker survives and keeps serving tasks
+	if err := s.Run(context.Background(), "document", func(ctx context.Context) error { return nil }); err != nil {
+		t.Errorf("Expected the scheduler to keep working after a panic, got %v", err)
+	}
+	if metrics := s.Metrics(); metrics.Panicked != 1 || metrics.Failed != 1 || metrics.Completed != 1 {
+		t.Errorf("Unexpected task counters: %+v", metrics)
+	}
+}
+
+// TestScheduler_Close tests that Close drains queued tasks and rejects new ones
+func TestScheduler_Close(t *testing.T) {
+	s := New(1)
+
+	var count int32
+	var handles []*Handle
+	for i := 0; i < 5; i++ {
+		handles = append(handles, s.Submit(context.Background(), "image", PriorityNormal, func(ctx context.Context) error {
+			atomic.AddInt32(&count, 1)
+			return nil
+		}))
+	}
+	s.Close()
+
+	for _, handle := range handles {
+		if err := handle.Wait(); err != nil {
+			t.Errorf("Queued task failed: %v", err)
+		}
+	}
+	if count != 5 {
+		t.Errorf("Expected 5 tasks to run before Close returned, got %d", count)
+	}
+	if err := s.Run(context.Background(), "image", func(ctx context.Context) error { return nil }); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed after Close, got %v", err)
+	}
+}
+
+// waitFor polls cond until it holds or the test times out
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for scheduler state")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"embed"
 
 	"github.com/eka026/File-Format-Converter/internal/adapters/gui"
+	"github.com/eka026/File-Format-Converter/internal/config"
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
 	"github.com/wailsapp/wails/v2/pkg/options/assetserver"
@@ -21,9 +22,15 @@ import (
 var assets embed.FS
 
 func main() {
-	app := gui.NewApp()
+	// An invalid configuration is reported but does not keep the window from opening
+	cfg, err := config.Load()
+	if err != nil {
+		println("Error: invalid configuration, using the defaults:", err.Error())
+		cfg = config.DefaultConfig()
+	}
+	app := gui.NewApp(cfg)
 
-	err := wails.Run(&options.App{
+	err = wails.Run(&options.App{
 		Title:  "File Format Converter",
 		Width:  1024,
 		Height: 768,
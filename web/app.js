@@ -2,8 +2,16 @@
 
 let selectedFiles = [];
 
+// Per-input-type output formats reported by the backend (see App.GetSupportedFormats)
+let formatMatrix = [];
+
+// Display names for output formats that are not simply upper-cased
+const FORMAT_LABELS = {
+    webp: 'WebP'
+};
+
 // Supported file types
-const SUPPORTED_EXTENSIONS = ['.xlsx', '.docx', '.jpeg', '.jpg', '.png', '.webp'];
+const SUPPORTED_EXTENSIONS = ['.xlsx', '.docx', '.jpeg', '.jpg', '.png', '.webp', '.gif', '.bmp', '.tiff', '.tif'];
 const XLSX_MIME_TYPES = [
     'application/vnd.openxmlformats-officedocument.spreadsheetml.sheet'
 ];
@@ -21,6 +29,16 @@ const PNG_MIME_TYPES = [
 const WEBP_MIME_TYPES = [
     'image/webp'
 ];
+const GIF_MIME_TYPES = [
+    'image/gif'
+];
+const BMP_MIME_TYPES = [
+    'image/bmp',
+    'image/x-ms-bmp'
+];
+const TIFF_MIME_TYPES = [
+    'image/tiff'
+];
 
 // Validates if a file is a supported .xlsx file
 function isValidXlsxFile(file) {
@@ -62,9 +80,34 @@ function isValidWebpFile(file) {
     return hasValidExtension && hasValidMimeType;
 }
 
+// Validates if a file is a supported GIF image
+function isValidGifFile(file) {
+    const fileName = file.name.toLowerCase();
+    const hasValidExtension = fileName.endsWith('.gif');
+    const hasValidMimeType = GIF_MIME_TYPES.includes(file.type) || file.type === '';
+    return hasValidExtension && hasValidMimeType;
+}
+
+// Validates if a file is a supported BMP image
+function isValidBmpFile(file) {
+    const fileName = file.name.toLowerCase();
+    const hasValidExtension = fileName.endsWith('.bmp');
+    const hasValidMimeType = BMP_MIME_TYPES.includes(file.type) || file.type === '';
+    return hasValidExtension && hasValidMimeType;
+}
+
+// Validates if a file is a supported TIFF image
+function isValidTiffFile(file) {
+    const fileName = file.name.toLowerCase();
+    const hasValidExtension = fileName.endsWith('.tiff') || fileName.endsWith('.tif');
+    const hasValidMimeType = TIFF_MIME_TYPES.includes(file.type) || file.type === '';
+    return hasValidExtension && hasValidMimeType;
+}
+
 // Validates if a file is a supported file type
 function isValidFile(file) {
-    return isValidXlsxFile(file) || isValidDocxFile(file) || isValidJpegFile(file) || isValidPngFile(file) || isValidWebpFile(file);
+    return isValidXlsxFile(file) || isValidDocxFile(file) || isValidJpegFile(file) || isValidPngFile(file) || isValidWebpFile(file) ||
+        isValidGifFile(file) || isValidBmpFile(file) || isValidTiffFile(file);
 }
 
 // Gets file extension from filename
@@ -72,6 +115,276 @@ function getFileExtension(filename) {
     return filename.slice(filename.lastIndexOf('.')).toLowerCase();
 }
 
+// Finds the backend input type entry matching a file name's extension
+function findInputType(fileName) {
+    const extension = getFileExtension(fileName);
+    return formatMatrix.find(entry => entry.extensions.includes(extension)) || null;
+}
+
+// Returns the display label for an output format
+function formatLabel(format) {
+    return FORMAT_LABELS[format] || format.toUpperCase();
+}
+
+// Loads the supported format matrix from the backend
+async function loadFormatMatrix() {
+    if (typeof window.go === 'undefined' || !window.go.gui || !window.go.gui.App || !window.go.gui.App.GetSupportedFormats) {
+        return;
+    }
+    try {
+        formatMatrix = await window.go.gui.App.GetSupportedFormats() || [];
+    } catch (error) {
+        console.error('Failed to load supported formats:', error);
+        formatMatrix = [];
+    }
+}
+
+// Reads a non-negative integer from a number input, or 0 when it is empty
+function readNumber(id) {
+    const value = parseFloat(document.getElementById(id).value);
+    return Number.isFinite(value) && value > 0 ? value : 0;
+}
+
+// Collects the conversion options for the selected target format (see gui.ConversionOptions)
+function collectConversionOptions(targetFormat) {
+    const options = {};
+    const applies = id => !document.getElementById(id).closest('.option-group').hidden;
+
+    // A WebP quality selects lossy encoding
+    if ((targetFormat === 'jpeg' || targetFormat === 'webp') && applies('optQuality')) {
+        options.quality = Math.round(readNumber('optQuality'));
+    }
+    if (targetFormat === 'jpeg' && applies('optSubsampling')) {
+        options.subsampling = document.getElementById('optSubsampling').value;
+    }
+    if (targetFormat === 'png' && applies('optCompression')) {
+        options.compression = document.getElementById('optCompression').value;
+    }
+    if (targetFormat === 'webp' && applies('optWebPExtended')) {
+        options.webpExtended = document.getElementById('optWebPExtended').checked;
+    }
+    if (applies('optMetadata')) {
+        options.metadata = document.getElementById('optMetadata').value;
+    }
+    if (applies('optIgnoreOrientation')) {
+        options.ignoreOrientation = document.getElementById('optIgnoreOrientation').checked;
+    }
+    if (applies('optWidth')) {
+        options.width = Math.round(readNumber('optWidth'));
+        options.height = Math.round(readNumber('optHeight'));
+    }
+    if (targetFormat === 'pdf') {
+        options.pageSize = document.getElementById('optPageSize').value;
+        options.orientation = document.getElementById('optOrientation').value;
+        const margin = document.getElementById('optMargin').value;
+        if (margin !== '') {
+            const inches = readNumber('optMargin');
+            options.margins = { top: inches, right: inches, bottom: inches, left: inches };
+        }
+    }
+    if (applies('optSheets')) {
+        options.sheets = document.getElementById('optSheets').value
+            .split(',')
+            .map(name => name.trim())
+            .filter(name => name !== '');
+    }
+    if (applies('optRotate')) {
+        options.transforms = collectTransforms();
+    }
+    options.collision = document.getElementById('optCollision').value;
+    return options;
+}
+
+// Builds the image transform steps in the order they run: rotate, flip, crop, resize
+function collectTransforms() {
+    const transforms = [];
+    const angle = parseInt(document.getElementById('optRotate').value, 10);
+    if (angle) {
+        transforms.push({ kind: 'rotate', angle });
+    }
+    const flip = document.getElementById('optFlip').value;
+    if (flip) {
+        transforms.push({ kind: 'flip', flip });
+    }
+    const aspect = document.getElementById('optCropAspect').value;
+    if (aspect) {
+        transforms.push({ kind: 'crop', aspect });
+    }
+    const mode = document.getElementById('optResizeMode').value;
+    const width = Math.round(readNumber('optResizeWidth'));
+    const height = Math.round(readNumber('optResizeHeight'));
+    if (mode && (width || height)) {
+        transforms.push({
+            kind: 'resize',
+            width,
+            height,
+            mode,
+            filter: document.getElementById('optResizeFilter').value,
+        });
+    }
+    return transforms;
+}
+
+// Shows only the option groups relevant to the target format and selected input types
+function updateOptionVisibility() {
+    const targetFormat = document.getElementById('targetFormat').value;
+    const inputTypes = selectedFiles
+        .map(file => findInputType(file.name))
+        .filter(entry => entry !== null)
+        .map(entry => entry.type.toLowerCase());
+
+    document.querySelectorAll('.option-group').forEach(group => {
+        const formats = group.dataset.formats.split(',');
+        const inputs = group.dataset.inputs ? group.dataset.inputs.toLowerCase().split(',') : null;
+        const matchesFormat = formats.includes(targetFormat);
+        const matchesInput = !inputs || inputTypes.some(type => inputs.includes(type));
+        group.hidden = !(matchesFormat && matchesInput);
+    });
+    // Without a quality, WebP output is lossless
+    document.getElementById('optQuality').placeholder = targetFormat === 'webp' ? 'lossless' : '95';
+}
+
+// Advances the progress bar as the backend reports finished batch files.
+// Returns a function that stops listening.
+function listenForFileProgress(total, progressFill, progressText) {
+    if (!window.runtime || !window.runtime.EventsOn) {
+        return () => {};
+    }
+    let finished = 0;
+    const cancel = window.runtime.EventsOn('conversion:file-finished', () => {
+        finished++;
+        const progress = Math.floor((finished / total) * 60) + 35;
+        progressFill.style.width = progress + '%';
+        progressText.textContent = `${progress}% (${finished} of ${total} files)`;
+    });
+    return typeof cancel === 'function' ? cancel : () => window.runtime.EventsOff('conversion:file-finished');
+}
+
+// Actionable hints for the backend's stable error codes (see domain.ErrorCode)
+const errorHints = {
+    unsupported_format: 'This conversion is not supported. Choose another output format.',
+    corrupt_input: 'The file appears to be damaged or is not the type its extension suggests.',
+    input_not_found: 'The file could not be found. It may have been moved or deleted.',
+    invalid_options: 'Check the conversion options for this output format.',
+    engine_unavailable: 'The converter for this file type is not ready yet.',
+    engine_failed: 'The converter for this file type could not be started. Check the application log.',
+    browser_missing: 'PDF output needs Chrome, Chromium or Edge installed locally.',
+    cancelled: 'The conversion was cancelled.',
+    timeout: 'The conversion took too long.',
+    output_not_writable: 'The output location is not writable. Check free space and permissions.',
+    output_exists: 'A file with the output name already exists.',
+    resource_limit_exceeded: 'The file is too large or too complex to convert safely.',
+};
+
+// Returns the HTML describing why a conversion failed and whether to retry it
+function describeFailure(result) {
+    const hint = errorHints[result.code];
+    const retry = result.retryable ? ' You can try again.' : '';
+    return `${escapeHtml(result.error || 'Unknown error')}` +
+        (hint || retry ? `<br><span class="error-hint">${escapeHtml(hint || '')}${retry}</span>` : '');
+}
+
+// Returns the HTML noting engine calls that were retried, or '' when none were
+function describeRetries(result) {
+    const retried = (result.attempts || []).filter(attempt => attempt.number > 1);
+    if (retried.length === 0) {
+        return '';
+    }
+    const engines = [...new Set(retried.map(attempt => attempt.engine))].join(', ');
+    const outcome = result.success ? 'Succeeded' : 'Failed';
+    return `<p class="file-note">${outcome} after ${retried.length} ${retried.length === 1 ? 'retry' : 'retries'} (${escapeHtml(engines)})</p>`;
+}
+
+// Returns the HTML summarising sizes, engines, counts and stage timings
+function describeConversion(result) {
+    const parts = [];
+    if (result.inputSize && result.outputSize) {
+        parts.push(`${formatBytes(result.inputSize)} → ${formatBytes(result.outputSize)}`);
+    }
+    const counts = [['pages', 'page'], ['sheets', 'sheet'], ['images', 'image']]
+        .filter(([field]) => result[field] > 0)
+        .map(([field, noun]) => `${result[field]} ${noun}${result[field] === 1 ? '' : 's'}`);
+    parts.push(...counts);
+    const engines = (result.engines || [])
+        .map(engine => engine.version ? `${engine.name} ${engine.version}` : engine.name);
+    if (engines.length > 0) {
+        parts.push(engines.join(' → '));
+    }
+    const encoder = describeEncoder(result.encoder);
+    if (encoder) {
+        parts.push(encoder);
+    }
+    const stages = (result.stages || []).map(stage => `${stage.stage} ${stage.durationMs} ms`);
+    if (stages.length > 0) {
+        parts.push(stages.join(', '));
+    }
+    if (parts.length === 0) {
+        return '';
+    }
+    return `<p class="file-note">${escapeHtml(parts.join(' · '))}</p>`;
+}
+
+// Returns the encoder settings of an image output, such as "JPEG q90 4:4:4"
+function describeEncoder(encoder) {
+    if (!encoder) {
+        return '';
+    }
+    const settings = [encoder.format];
+    if (encoder.quality) {
+        settings.push(`q${encoder.quality}`);
+    } else if (encoder.format.toLowerCase() === 'webp') {
+        settings.push('lossless');
+    }
+    if (encoder.subsampling) {
+        settings.push(encoder.subsampling);
+    }
+    if (encoder.compression) {
+        settings.push(`${encoder.compression} compression`);
+    }
+    if (encoder.extended) {
+        settings.push('extended');
+    }
+    return settings.join(' ');
+}
+
+// Returns the HTML listing content the engines skipped or approximated
+function describeFidelity(result) {
+    return (result.fidelity || [])
+        .map(warning => `<p class="warning">${escapeHtml(warning.message)}</p>`)
+        .join('');
+}
+
+// Formats a byte count for display
+function formatBytes(bytes) {
+    if (bytes < 1024) {
+        return `${bytes} B`;
+    }
+    const units = ['KB', 'MB', 'GB'];
+    let value = bytes / 1024;
+    let unit = 0;
+    while (value >= 1024 && unit < units.length - 1) {
+        value /= 1024;
+        unit++;
+    }
+    return `${value.toFixed(1)} ${units[unit]}`;
+}
+
+// Shows how much the conversion cache holds
+async function refreshCacheStats() {
+    const summary = document.getElementById('cacheSummary');
+    if (typeof window.go === 'undefined' || !window.go.gui || !window.go.gui.App || !window.go.gui.App.GetCacheStats) {
+        return;
+    }
+    try {
+        const stats = await window.go.gui.App.GetCacheStats();
+        summary.textContent = stats.entries > 0
+            ? `${stats.entries} file${stats.entries === 1 ? '' : 's'}, ${formatBytes(stats.sizeBytes)} of ${formatBytes(stats.maxBytes)}`
+            : 'empty';
+    } catch (error) {
+        console.error('Failed to read cache stats:', error);
+    }
+}
+
 // Escapes HTML special characters to prevent XSS attacks
 function escapeHtml(text) {
     if (text == null) {
@@ -89,8 +402,8 @@ document.addEventListener('DOMContentLoaded', () => {
     const fileList = document.getElementById('fileList');
     const fileItems = document.getElementById('fileItems');
 
-    // Initialize format selection
-    updateFormatSelection();
+    // Initialize format selection once the backend reports its capabilities
+    loadFormatMatrix().then(updateFormatSelection);
 
     // Drag and drop handlers
     dropZone.addEventListener('click', () => fileInput.click());
@@ -102,6 +415,24 @@ document.addEventListener('DOMContentLoaded', () => {
     // Convert button handler
     convertButton.addEventListener('click', handleConvert);
 
+    // Batch manifests describe recurring conversions in a JSON or YAML file
+    const manifestButton = document.getElementById('manifestButton');
+    manifestButton.addEventListener('click', handleRunManifest);
+
+    // Only show the options that apply to the chosen target format
+    document.getElementById('targetFormat').addEventListener('change', updateOptionVisibility);
+
+    // Cache summary and clearing
+    refreshCacheStats();
+    document.getElementById('clearCacheButton').addEventListener('click', async () => {
+        try {
+            await window.go.gui.App.ClearCache();
+        } catch (error) {
+            console.error('Failed to clear cache:', error);
+        }
+        refreshCacheStats();
+    });
+
     function handleDragOver(e) {
         e.preventDefault();
         dropZone.classList.add('dragover');
@@ -155,7 +486,7 @@ document.addEventListener('DOMContentLoaded', () => {
             <div class="result-item error">
                 <strong>Invalid file type</strong>
                 <p>The following files are not supported: ${fileNames}</p>
-                <p>Supported formats: .xlsx (Excel files), .docx (Word documents), .jpeg/.jpg (JPEG images), .png (PNG images), .webp (WebP images)</p>
+                <p>Supported formats: .xlsx (Excel files), .docx (Word documents), .jpeg/.jpg (JPEG images), .png (PNG images), .webp (WebP images), .gif (GIF images), .bmp (BMP images), .tiff/.tif (TIFF images)</p>
             </div>
         `;
     }
@@ -175,6 +506,9 @@ document.addEventListener('DOMContentLoaded', () => {
             const isJpeg = fileName.endsWith('.jpeg') || fileName.endsWith('.jpg');
             const isPng = fileName.endsWith('.png');
             const isWebp = fileName.endsWith('.webp');
+            const isGif = fileName.endsWith('.gif');
+            const isBmp = fileName.endsWith('.bmp');
+            const isTiff = fileName.endsWith('.tiff') || fileName.endsWith('.tif');
             let fileType = 'UNKNOWN';
             let fileIcon = '📁';
             if (isDocx) {
@@ -192,6 +526,15 @@ document.addEventListener('DOMContentLoaded', () => {
             } else if (isWebp) {
                 fileType = 'WEBP';
                 fileIcon = '🖼️';
+            } else if (isGif) {
+                fileType = 'GIF';
+                fileIcon = '🖼️';
+            } else if (isBmp) {
+                fileType = 'BMP';
+                fileIcon = '🖼️';
+            } else if (isTiff) {
+                fileType = 'TIFF';
+                fileIcon = '🖼️';
             }
             
             return `
@@ -212,57 +555,28 @@ document.addEventListener('DOMContentLoaded', () => {
         const targetFormatSelect = document.getElementById('targetFormat');
         const currentValue = targetFormatSelect.value;
 
+        let formats = [];
         if (selectedFiles.length === 0) {
-            // Show all formats when no files selected
-            targetFormatSelect.innerHTML = `
-                <option value="pdf">PDF</option>
-                <option value="png">PNG</option>
-                <option value="jpeg">JPEG</option>
-                <option value="webp">WebP</option>
-            `;
-            targetFormatSelect.value = currentValue || 'pdf';
-            return;
-        }
-
-        // Determine file types in selection
-        let hasImages = false;
-        let hasDocuments = false;
-
-        selectedFiles.forEach(file => {
-            const fileName = file.name.toLowerCase();
-            if (fileName.endsWith('.jpeg') || fileName.endsWith('.jpg') || fileName.endsWith('.png') || fileName.endsWith('.webp')) {
-                hasImages = true;
-            } else if (fileName.endsWith('.docx') || fileName.endsWith('.xlsx')) {
-                hasDocuments = true;
-            }
-        });
-
-        // Build format options based on file types
-        let formatOptions = '';
-
-        if (hasImages && !hasDocuments) {
-            // Only images selected - show image formats (PNG, JPEG, WebP)
-            formatOptions = `
-                <option value="png">PNG</option>
-                <option value="jpeg">JPEG</option>
-                <option value="webp">WebP</option>
-            `;
-        } else if (hasDocuments && !hasImages) {
-            // Only documents selected - show PDF
-            formatOptions = `
-                <option value="pdf">PDF</option>
-            `;
+            // Show every format any input type can reach when no files are selected
+            formatMatrix.forEach(entry => {
+                entry.formats.forEach(format => {
+                    if (!formats.includes(format)) {
+                        formats.push(format);
+                    }
+                });
+            });
         } else {
-            // Mixed selection - show all formats
-            formatOptions = `
-                <option value="pdf">PDF</option>
-                <option value="png">PNG</option>
-                <option value="jpeg">JPEG</option>
-                <option value="webp">WebP</option>
-            `;
+            // Only offer formats every selected file can be converted to
+            selectedFiles.forEach((file, index) => {
+                const entry = findInputType(file.name);
+                const available = entry ? entry.formats : [];
+                formats = index === 0 ? [...available] : formats.filter(format => available.includes(format));
+            });
         }
 
-        targetFormatSelect.innerHTML = formatOptions;
+        targetFormatSelect.innerHTML = formats
+            .map(format => `<option value="${format}">${formatLabel(format)}</option>`)
+            .join('');
 
         // Try to preserve current selection, or select first option
         if (targetFormatSelect.querySelector(`option[value="${currentValue}"]`)) {
@@ -270,6 +584,7 @@ document.addEventListener('DOMContentLoaded', () => {
         } else {
             targetFormatSelect.selectedIndex = 0;
         }
+        updateOptionVisibility();
     }
 
     window.removeFile = (index) => {
@@ -285,6 +600,7 @@ document.addEventListener('DOMContentLoaded', () => {
         if (targetFormat === 'jpg') {
             targetFormat = 'jpeg';
         }
+        const options = collectConversionOptions(targetFormat);
         const progressContainer = document.getElementById('progressContainer');
         const progressFill = document.getElementById('progressFill');
         const progressText = document.getElementById('progressText');
@@ -363,9 +679,29 @@ document.addEventListener('DOMContentLoaded', () => {
             progressText.textContent = '5%';
             await new Promise(resolve => setTimeout(resolve, 100));
 
-            // Convert each file
-            for (let i = 0; i < selectedFiles.length; i++) {
-                const file = selectedFiles[i];
+            // Files that exist on disk are converted as one batch; the backend runs
+            // them concurrently and reports each finished file as an event
+            let sequentialFiles = selectedFiles;
+            if (app.BatchConvertFiles && selectedFiles.length > 1 && selectedFiles.every(file => file.path)) {
+                sequentialFiles = [];
+                const stopListening = listenForFileProgress(selectedFiles.length, progressFill, progressText);
+                try {
+                    const batchResults = await app.BatchConvertFiles(selectedFiles.map(file => file.path), targetFormat, options);
+                    conversionResults.push(...(batchResults || []));
+                } catch (error) {
+                    console.error('Batch conversion failed:', error);
+                    selectedFiles.forEach(() => conversionResults.push({
+                        success: false,
+                        error: error.message || 'Unknown conversion error'
+                    }));
+                } finally {
+                    stopListening();
+                }
+            }
+
+            // Convert each remaining file
+            for (let i = 0; i < sequentialFiles.length; i++) {
+                const file = sequentialFiles[i];
                 
                 // Update progress - preparing file
                 const prepProgress = Math.floor((i / selectedFiles.length) * 30) + 5;
@@ -376,7 +712,38 @@ document.addEventListener('DOMContentLoaded', () => {
                 let filePath = file.path || file.name;
                 let isTempInputFile = false;
                 
-                // If we have a browser File object (from drag-and-drop), save it to temp first
+                // Browser File objects (from drag-and-drop) are converted in memory when the
+                // backend supports it, so the upload never has to be staged on disk
+                if (file instanceof File && !file.path && app.ConvertFromBytes) {
+                    try {
+                        const arrayBuffer = await file.arrayBuffer();
+                        const fileData = new Uint8Array(arrayBuffer);
+
+                        const conversionStartProgress = Math.floor((i / selectedFiles.length) * 60) + 35;
+                        progressFill.style.width = conversionStartProgress + '%';
+                        progressText.textContent = conversionStartProgress + '%';
+
+                        const result = await app.ConvertFromBytes(file.name, Array.from(fileData), targetFormat, options);
+                        if (!result.success) {
+                            console.error('Conversion failed:', result.error);
+                        }
+                        conversionResults.push(result);
+                    } catch (error) {
+                        console.error('Conversion exception caught:', error);
+                        conversionResults.push({
+                            success: false,
+                            error: error.message || 'Unknown conversion error'
+                        });
+                    }
+
+                    const postConversionProgress = Math.floor(((i + 1) / selectedFiles.length) * 60) + 35;
+                    progressFill.style.width = postConversionProgress + '%';
+                    progressText.textContent = postConversionProgress + '%';
+                    await new Promise(resolve => setTimeout(resolve, 50)); // Ensure UI updates
+                    continue;
+                }
+                
+                // Otherwise save the browser File object to temp first
                 if (file instanceof File && !file.path) {
                     try {
                         // Update progress - reading file
@@ -417,7 +784,7 @@ document.addEventListener('DOMContentLoaded', () => {
                 console.log('Progress before conversion:', conversionStartProgress + '%');
                 try {
                     // Convert with empty path - backend will use default location
-                    const result = await app.ConvertFile(filePath, targetFormat);
+                    const result = await app.ConvertFile(filePath, targetFormat, options);
                     console.log('Conversion result:', JSON.stringify(result, null, 2));
                     if (!result.success) {
                         console.error('Conversion failed:', result.error);
@@ -470,6 +837,7 @@ document.addEventListener('DOMContentLoaded', () => {
             
             progressFill.style.width = '100%';
             progressText.textContent = '100%';
+            refreshCacheStats();
             await new Promise(resolve => setTimeout(resolve, 100)); // Ensure UI updates
 
             // Show results with download/open options
@@ -486,6 +854,9 @@ document.addEventListener('DOMContentLoaded', () => {
 
             // Add download/open buttons for successful conversions
             conversionResults.forEach((result, index) => {
+                const warningsHTML = (result.warnings || [])
+                    .map(warning => `<p class="warning">${warning.replace(/&/g, '&amp;').replace(/</g, '&lt;').replace(/>/g, '&gt;')}</p>`)
+                    .join('');
                 if (result.success && result.outputPath) {
                     // Escape the path properly for use in HTML/JavaScript
                     // Replace backslashes with forward slashes for display
@@ -501,6 +872,12 @@ document.addEventListener('DOMContentLoaded', () => {
                         <div class="result-item success file-result" data-file-path="${safePathAttr}">
                             <p><strong>File ${index + 1}:</strong> ${safeFileName}</p>
                             <p class="file-path">${safeDisplayPath}</p>
+                            ${result.skipped ? '<p class="warning">Skipped: the output file already exists</p>' : ''}
+                            ${result.cached ? '<p class="file-note">Served from the conversion cache</p>' : ''}
+                            ${describeConversion(result)}
+                            ${describeRetries(result)}
+                            ${warningsHTML}
+                            ${describeFidelity(result)}
                             <div class="file-actions">
                                 <button class="action-button open-pdf-btn">Open ${formatDisplayName}</button>
                                 <button class="action-button show-folder-btn">Show in Folder</button>
@@ -510,7 +887,9 @@ document.addEventListener('DOMContentLoaded', () => {
                 } else if (!result.success) {
                     resultHTML += `
                         <div class="result-item error">
-                            <p><strong>File ${index + 1} failed:</strong> ${(result.error || 'Unknown error').replace(/</g, '&lt;').replace(/>/g, '&gt;')}</p>
+                            <p><strong>File ${index + 1} failed:</strong> ${describeFailure(result)}</p>
+                            ${describeRetries(result)}
+                            ${warningsHTML}
                         </div>
                     `;
                 }
@@ -549,6 +928,7 @@ document.addEventListener('DOMContentLoaded', () => {
             // Complete progress even on error
             progressFill.style.width = '100%';
             progressText.textContent = '100%';
+            refreshCacheStats();
             await new Promise(resolve => setTimeout(resolve, 100));
             
             results.innerHTML = `
@@ -567,6 +947,89 @@ document.addEventListener('DOMContentLoaded', () => {
         }
     }
 
+    // Runs a batch manifest chosen by the user once they confirm what it converts
+    async function handleRunManifest() {
+        const progressContainer = document.getElementById('progressContainer');
+        const progressFill = document.getElementById('progressFill');
+        const progressText = document.getElementById('progressText');
+        const results = document.getElementById('results');
+        const showError = (title, error) => {
+            results.innerHTML = `
+                <div class="result-item error">
+                    <strong>${escapeHtml(title)}</strong>
+                    <p>${escapeHtml(error.message || String(error))}</p>
+                </div>
+            `;
+        };
+
+        if (typeof window.go === 'undefined' || !window.go.gui || !window.go.gui.App || !window.go.gui.App.RunManifest) {
+            showError('Error', 'Backend connection not available. Please ensure the application is running properly.');
+            return;
+        }
+        const app = window.go.gui.App;
+
+        let plan;
+        try {
+            const path = await app.ChooseManifest();
+            if (!path) {
+                return;
+            }
+            plan = await app.LoadManifest(path);
+        } catch (error) {
+            showError('Invalid Manifest', error);
+            return;
+        }
+        const total = (plan.conversions || []).length;
+        if (total === 0) {
+            showError('Nothing to Convert', 'The manifest\'s inputs match no files.');
+            return;
+        }
+        if (!confirm(`Run ${total} conversion(s) from ${plan.entries} manifest entr${plan.entries === 1 ? 'y' : 'ies'}?`)) {
+            return;
+        }
+
+        manifestButton.disabled = true;
+        convertButton.disabled = true;
+        results.innerHTML = '';
+        progressContainer.style.display = 'block';
+        progressFill.style.width = '35%';
+        progressText.textContent = `35% (0 of ${total} files)`;
+        const stopListening = listenForFileProgress(total, progressFill, progressText);
+        try {
+            const run = await app.RunManifest(plan.path);
+            progressFill.style.width = '100%';
+            progressText.textContent = '100%';
+
+            let resultHTML = `
+                <div class="result-item ${run.failed === 0 ? 'success' : 'error'}">
+                    <strong>Batch Manifest ${run.failed === 0 ? 'Complete' : 'Finished with Errors'}</strong>
+                    <p>${run.succeeded} converted, ${run.skipped} skipped, ${run.failed} failed</p>
+                    <p class="file-path">Report: ${escapeHtml(run.reportPath)}</p>
+                </div>
+            `;
+            (run.results || []).forEach((result, index) => {
+                if (!result.success) {
+                    const conversion = plan.conversions[index];
+                    resultHTML += `
+                        <div class="result-item error">
+                            <p><strong>${escapeHtml(conversion ? conversion.input : `File ${index + 1}`)} failed:</strong> ${describeFailure(result)}</p>
+                            ${describeRetries(result)}
+                        </div>
+                    `;
+                }
+            });
+            results.innerHTML = resultHTML;
+        } catch (error) {
+            progressContainer.style.display = 'none';
+            showError('Batch Manifest Failed', error);
+        } finally {
+            stopListening();
+            manifestButton.disabled = false;
+            convertButton.disabled = selectedFiles.length === 0;
+            refreshCacheStats();
+        }
+    }
+
     // Helper function to open a file
     window.openFile = async function(filePath) {
         try {